@@ -14,6 +14,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"path"
 	"reflect"
 	"strconv"
 	"strings"
@@ -222,6 +223,9 @@ const (
 	defaultSyncTimeout    = "3"
 	healthCheckEndpoint   = "/healthcheck"
 	addVCEndpoint         = "/add-vc"
+	addVCBatchEndpoint    = "/add-vc-batch"
+	addVPEndpoint         = "/add-vp"
+	validateVCEndpoint    = "/validate-vc"
 	webFingerEndpoint     = "/.well-known/webfinger"
 )
 
@@ -292,9 +296,29 @@ func (s *HTTPServer) ListenAndServe(host string, router http.Handler, certFile,
 // StorageProvider represents a storage provider.
 type StorageProvider storage.Provider
 
+// Option configures the start command with what cannot be set with flags, e.g. code of the deployment.
+type Option func(*options)
+
+type options struct {
+	admissionPolicy command.AdmissionPolicy
+}
+
+// WithAdmissionPolicy sets the policy that decides whether credentials are admitted to the logs, see
+// command.AdmissionPolicy. By default, all credentials of the accepted issuers are admitted.
+func WithAdmissionPolicy(policy command.AdmissionPolicy) Option {
+	return func(o *options) {
+		o.admissionPolicy = policy
+	}
+}
+
 // Cmd returns the Cobra start command.
-func Cmd(server server) (*cobra.Command, error) {
-	startCmd := createStartCMD(server)
+func Cmd(server server, opts ...Option) (*cobra.Command, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	startCmd := createStartCMD(server, o)
 
 	createFlags(startCmd)
 
@@ -317,6 +341,7 @@ type agentParameters struct {
 	kmsParams           *kmsParameters
 	readToken           string
 	writeToken          string
+	admissionPolicy     command.AdmissionPolicy
 }
 
 type tlsParameters struct {
@@ -392,7 +417,7 @@ func parseLogs(logsRaw string, issuersRaw []string) ([]command.Log, bool) { //no
 	return result, starTrillian
 }
 
-func createStartCMD(server server) *cobra.Command { //nolint: funlen,gocognit,gocyclo,cyclop
+func createStartCMD(server server, opts *options) *cobra.Command { //nolint: funlen,gocognit,gocyclo,cyclop
 	return &cobra.Command{
 		Use:   "start",
 		Short: "Starts vct service",
@@ -553,6 +578,7 @@ func createStartCMD(server server) *cobra.Command { //nolint: funlen,gocognit,go
 				kmsParams:           kmsParams,
 				readToken:           readToken,
 				writeToken:          writeToken,
+				admissionPolicy:     opts.admissionPolicy,
 			}
 
 			return startAgent(parameters)
@@ -828,6 +854,8 @@ func startAgent(parameters *agentParameters) error { //nolint:funlen,gocyclo,cyc
 		},
 		BaseURL:         parameters.baseURL,
 		DocumentLoaders: loaders,
+		AdmissionPolicy: parameters.admissionPolicy,
+		Version:         rest.BuildVersion,
 	}, mf)
 	if err != nil {
 		return fmt.Errorf("create command instance: %w", err)
@@ -1110,7 +1138,7 @@ func (p *customizedStorageProvider) SetStoreConfig(name string, config storage.S
 }
 
 type ldStoreProvider struct {
-	ContextStore        ldstore.ContextStore
+	ContextStore        *ldcontext.ContextStore
 	RemoteProviderStore ldstore.RemoteProviderStore
 }
 
@@ -1134,7 +1162,8 @@ func createLDStoreProvider(provider storage.Provider) (*ldStoreProvider, error)
 	}
 
 	return &ldStoreProvider{
-		ContextStore:        contextStore,
+		// the contexts put into the store are recorded, so that the log can list them with get-accepted-contexts
+		ContextStore:        ldcontext.NewContextStore(contextStore),
 		RemoteProviderStore: remoteProviderStore,
 	}, nil
 }
@@ -1156,18 +1185,19 @@ func createJSONLDDocumentLoader(ldStore *ldStoreProvider, httpClient *http.Clien
 		return nil, fmt.Errorf("new document loader: %w", err)
 	}
 
-	return loader, nil
+	return ldcontext.NewDocumentLoader(loader, ldStore.ContextStore), nil
 }
 
 // ValidateAuthorizationBearerToken validate token.
 func ValidateAuthorizationBearerToken(w http.ResponseWriter, r *http.Request, readToken, writeToken string) bool {
-	if r.RequestURI == healthCheckEndpoint || strings.Contains(r.RequestURI, webFingerEndpoint) {
+	// The decoded path is the one the router matches, e.g. /add%2Dvc is routed to add-vc.
+	if r.URL.Path == healthCheckEndpoint || r.URL.Path == webFingerEndpoint {
 		return true
 	}
 
 	token := readToken
 
-	if strings.Contains(r.RequestURI, addVCEndpoint) {
+	if isWriteEndpoint(r.URL.Path) {
 		if writeToken == "" {
 			return true
 		}
@@ -1190,6 +1220,17 @@ func ValidateAuthorizationBearerToken(w http.ResponseWriter, r *http.Request, re
 	return true
 }
 
+// isWriteEndpoint reports whether the path is of an endpoint that adds to the log, or checks what would be
+// added like validate-vc, i.e. one authorized with the write token.
+func isWriteEndpoint(p string) bool {
+	switch "/" + path.Base(p) {
+	case addVCEndpoint, addVCBatchEndpoint, addVPEndpoint, validateVCEndpoint:
+		return true
+	default:
+		return false
+	}
+}
+
 func authorizationMiddleware(readToken, writeToken string) mux.MiddlewareFunc {
 	middleware := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
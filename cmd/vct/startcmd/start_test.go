@@ -19,6 +19,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/trillian"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 
@@ -431,17 +432,85 @@ func TestCmd(t *testing.T) {
 }
 
 func TestValidateAuthorizationBearerToken(t *testing.T) {
-	require.True(t, startcmd.ValidateAuthorizationBearerToken(&httptest.ResponseRecorder{},
-		&http.Request{RequestURI: "/healthcheck"}, "read", "write"))
+	request := func(target, token string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, target, nil)
 
-	require.False(t, startcmd.ValidateAuthorizationBearerToken(&httptest.ResponseRecorder{},
-		&http.Request{
-			RequestURI: "/add-vc",
-			Header:     map[string][]string{"Authorization": {"Bearer 123"}},
-		}, "read", "write"))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		return req
+	}
+
+	require.True(t, startcmd.ValidateAuthorizationBearerToken(httptest.NewRecorder(),
+		request("/healthcheck", ""), "read", "write"))
+
+	require.False(t, startcmd.ValidateAuthorizationBearerToken(httptest.NewRecorder(),
+		request("/add-vc", "123"), "read", "write"))
+
+	require.True(t, startcmd.ValidateAuthorizationBearerToken(httptest.NewRecorder(),
+		request("/add-vc", ""), "read", ""))
+
+	require.False(t, startcmd.ValidateAuthorizationBearerToken(httptest.NewRecorder(),
+		request("/maple2020/v1/get-sth?resource=/.well-known/webfinger", ""), "read", "write"))
+
+	t.Run("Only write token", func(t *testing.T) {
+		for _, uri := range []string{"/add-vc", "/maple2020/v1/add-vc-batch", "/maple2020/v1/add-vp?alias=maple2020"} {
+			require.False(t, startcmd.ValidateAuthorizationBearerToken(httptest.NewRecorder(),
+				request(uri, ""), "", "write"), uri)
+
+			require.True(t, startcmd.ValidateAuthorizationBearerToken(httptest.NewRecorder(),
+				request(uri, "write"), "", "write"), uri)
+		}
+
+		require.True(t, startcmd.ValidateAuthorizationBearerToken(httptest.NewRecorder(),
+			request("/maple2020/v1/get-sth", ""), "", "write"))
+	})
+
+	t.Run("Distinct read and write tokens", func(t *testing.T) {
+		for _, uri := range []string{
+			"/maple2020/v1/add-vc", "/maple2020/v1/add-vc-batch", "/maple2020/v1/add-vp", "/maple2020/v1/validate-vc",
+		} {
+			require.True(t, startcmd.ValidateAuthorizationBearerToken(httptest.NewRecorder(),
+				request(uri, "write"), "read", "write"), uri)
 
-	require.True(t, startcmd.ValidateAuthorizationBearerToken(&httptest.ResponseRecorder{},
-		&http.Request{RequestURI: "/add-vc"}, "read", ""))
+			require.False(t, startcmd.ValidateAuthorizationBearerToken(httptest.NewRecorder(),
+				request(uri, "read"), "read", "write"), uri)
+		}
+
+		require.True(t, startcmd.ValidateAuthorizationBearerToken(httptest.NewRecorder(),
+			request("/maple2020/v1/get-sth", "read"), "read", "write"))
+
+		require.False(t, startcmd.ValidateAuthorizationBearerToken(httptest.NewRecorder(),
+			request("/maple2020/v1/get-sth", "write"), "read", "write"))
+	})
+
+	t.Run("Percent-encoded path", func(t *testing.T) {
+		router := mux.NewRouter()
+
+		for uri, route := range map[string]string{
+			"/maple2020/v1/add%2Dvc":       "/{alias}/v1/add-vc",
+			"/maple2020/v1/add-vc%2Dbatch": "/{alias}/v1/add-vc-batch",
+			"/maple2020/v1/add%2Dvp":       "/{alias}/v1/add-vp",
+			"/maple2020/v1/validate%2Dvc":  "/{alias}/v1/validate-vc",
+		} {
+			router.HandleFunc(route, func(http.ResponseWriter, *http.Request) {}).Methods(http.MethodPost)
+
+			// the router matches the decoded path, so the request reaches the write endpoint
+			var match mux.RouteMatch
+			require.True(t, router.Match(request(uri, ""), &match), uri)
+
+			template, err := match.Route.GetPathTemplate()
+			require.NoError(t, err)
+			require.Equal(t, route, template)
+
+			require.False(t, startcmd.ValidateAuthorizationBearerToken(httptest.NewRecorder(),
+				request(uri, "read"), "read", "write"), uri)
+
+			require.True(t, startcmd.ValidateAuthorizationBearerToken(httptest.NewRecorder(),
+				request(uri, "write"), "read", "write"), uri)
+		}
+	})
 }
 
 func TestAwsMetricsProvider(t *testing.T) {
@@ -23,6 +23,7 @@ import (
 	"container/list"
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"unicode/utf16"
@@ -38,9 +39,17 @@ type nameValueType struct {
 var asciiEscapes = []byte{'\\', '"', 'b', 'f', 'n', 'r', 't'}
 var binaryEscapes = []byte{'\\', '"', '\b', '\f', '\n', '\r', '\t'}
 
+// ErrDuplicateKey is returned for an object with duplicate keys. RFC 8259 leaves the value of a duplicate key
+// undefined and parsers disagree on it, so such objects are rejected rather than canonicalized.
+var ErrDuplicateKey = errors.New("duplicate key")
+
 // JSON literals.
 var literals = []string{"true", "false", "null"}
 
+// JSON number grammar (RFC 8259). strconv.ParseFloat alone also accepts e.g. hexadecimal numbers, "Inf" and
+// leading zeros, which other JCS implementations reject.
+var numberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
 func Transform(jsonData []byte) (result []byte, e error) { //nolint: funlen,gocognit,gocyclo,cyclop
 	// JSON data MUST be UTF-8 encoded
 	jsonDataLength := len(jsonData)
@@ -233,6 +242,10 @@ func Transform(jsonData []byte) (result []byte, e error) { //nolint: funlen,goco
 			}
 		}
 		// Apparently not so we assume that it is a I-JSON number
+		if !numberPattern.MatchString(value) {
+			setError("Invalid JSON number: " + value)
+			return value
+		}
 		ieeeF64, err := strconv.ParseFloat(value, 64)
 		checkError(err)
 		value, err = NumberToJSON(ieeeF64)
@@ -295,7 +308,7 @@ func Transform(jsonData []byte) (result []byte, e error) { //nolint: funlen,goco
 			return true
 		}
 		if len(sortKey) == len(oldSortKey) {
-			setError("Duplicate key: " + e.Value.(nameValueType).name)
+			checkError(fmt.Errorf("%w %q", ErrDuplicateKey, e.Value.(nameValueType).name))
 		}
 		// Longer => No match
 		return false
@@ -373,5 +386,8 @@ func Transform(jsonData []byte) (result []byte, e error) { //nolint: funlen,goco
 		}
 		index++
 	}
-	return []byte(transformed), globalError
+	if globalError != nil {
+		return nil, globalError
+	}
+	return []byte(transformed), nil
 }
@@ -7,8 +7,12 @@ SPDX-License-Identifier: Apache-2.0
 package ldcontext_test
 
 import (
+	"errors"
 	"testing"
 
+	aldcontext "github.com/hyperledger/aries-framework-go/pkg/doc/ldcontext"
+	ldstore "github.com/hyperledger/aries-framework-go/pkg/store/ld"
+	jsonld "github.com/piprate/json-gold/ld"
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/vct/internal/pkg/ldcontext"
@@ -20,3 +24,59 @@ func TestMustGetAll(t *testing.T) {
 	require.Equal(t, "https://w3id.org/activityanchors/v1", res[0].URL)
 	require.Equal(t, "https://www.w3.org/ns/activitystreams", res[1].URL)
 }
+
+func TestContextStore(t *testing.T) {
+	store := ldcontext.NewContextStore(&memContextStore{})
+
+	contexts, err := store.Contexts()
+	require.NoError(t, err)
+	require.Empty(t, contexts)
+
+	require.NoError(t, store.Import(ldcontext.MustGetAll()))
+	require.NoError(t, store.Put("https://example.com/context/v1", &jsonld.RemoteDocument{}))
+
+	loader := ldcontext.NewDocumentLoader(nil, store)
+
+	contexts, err = loader.Contexts()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"https://example.com/context/v1",
+		"https://w3id.org/activityanchors/v1",
+		"https://www.w3.org/ns/activitystreams",
+	}, contexts)
+
+	require.NoError(t, store.Delete(ldcontext.MustGetAll()[:1]))
+
+	contexts, err = store.Contexts()
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://example.com/context/v1", "https://www.w3.org/ns/activitystreams"}, contexts)
+
+	t.Run("Store error", func(t *testing.T) {
+		failing := ldcontext.NewContextStore(&memContextStore{err: errors.New("store error")})
+
+		require.EqualError(t, failing.Import(ldcontext.MustGetAll()), "store error")
+		require.EqualError(t, failing.Put("https://example.com/context/v1", nil), "store error")
+
+		contexts, err := failing.Contexts()
+		require.NoError(t, err)
+		require.Empty(t, contexts)
+	})
+}
+
+type memContextStore struct {
+	ldstore.ContextStore
+
+	err error
+}
+
+func (s *memContextStore) Put(string, *jsonld.RemoteDocument) error {
+	return s.err
+}
+
+func (s *memContextStore) Import([]aldcontext.Document) error {
+	return s.err
+}
+
+func (s *memContextStore) Delete([]aldcontext.Document) error {
+	return s.err
+}
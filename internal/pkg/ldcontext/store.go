@@ -0,0 +1,111 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ldcontext
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/ldcontext"
+	ldstore "github.com/hyperledger/aries-framework-go/pkg/store/ld"
+	jsonld "github.com/piprate/json-gold/ld"
+)
+
+// ContextStore is a JSON-LD context store which records the URLs of the contexts put into it, so that they can be
+// listed. Contexts stored by a previous process, e.g. in a persistent store, are not listed unless they are
+// imported again, as the contexts a document loader is created with are.
+type ContextStore struct {
+	ldstore.ContextStore
+
+	mu   sync.RWMutex
+	urls map[string]struct{}
+}
+
+// NewContextStore returns a context store which records the contexts put into the given store.
+func NewContextStore(store ldstore.ContextStore) *ContextStore {
+	return &ContextStore{ContextStore: store, urls: map[string]struct{}{}}
+}
+
+// Put puts the context document into the store.
+func (s *ContextStore) Put(u string, rd *jsonld.RemoteDocument) error {
+	if err := s.ContextStore.Put(u, rd); err != nil {
+		return err // nolint: wrapcheck
+	}
+
+	s.mu.Lock()
+	s.urls[u] = struct{}{}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Import imports the context documents into the store.
+func (s *ContextStore) Import(documents []ldcontext.Document) error {
+	if err := s.ContextStore.Import(documents); err != nil {
+		return err // nolint: wrapcheck
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, doc := range documents {
+		s.urls[doc.URL] = struct{}{}
+	}
+
+	return nil
+}
+
+// Delete deletes the context documents from the store.
+func (s *ContextStore) Delete(documents []ldcontext.Document) error {
+	if err := s.ContextStore.Delete(documents); err != nil {
+		return err // nolint: wrapcheck
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, doc := range documents {
+		delete(s.urls, doc.URL)
+	}
+
+	return nil
+}
+
+// Contexts returns the sorted URLs of the contexts in the store.
+func (s *ContextStore) Contexts() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	urls := make([]string, 0, len(s.urls))
+
+	for u := range s.urls {
+		urls = append(urls, u)
+	}
+
+	sort.Strings(urls)
+
+	return urls, nil
+}
+
+// DocumentLoader is a JSON-LD document loader which lists the contexts of its context store, see
+// command.ContextLister.
+type DocumentLoader struct {
+	jsonld.DocumentLoader
+
+	store *ContextStore
+}
+
+// NewDocumentLoader returns a document loader which loads documents with the given loader, and lists the
+// contexts of the given store, i.e. the context store of the loader.
+func NewDocumentLoader(loader jsonld.DocumentLoader, store *ContextStore) *DocumentLoader {
+	return &DocumentLoader{DocumentLoader: loader, store: store}
+}
+
+// Contexts returns the sorted URLs of the contexts of the context store of the loader.
+func (l *DocumentLoader) Contexts() ([]string, error) {
+	return l.store.Contexts()
+}
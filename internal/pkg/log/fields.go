@@ -28,6 +28,7 @@ const (
 	FieldSignature            = "signature"
 	FieldTimestamp            = "timestamp"
 	FieldPublicKey            = "publicKey"
+	FieldFilePath             = "filePath"
 )
 
 // WithError sets the error field.
@@ -100,6 +101,11 @@ func WithPublicKey(value interface{}) zap.Field {
 	return zap.Inline(NewObjectMarshaller(FieldPublicKey, value))
 }
 
+// WithFilePath sets the file path field.
+func WithFilePath(value string) zap.Field {
+	return zap.String(FieldFilePath, value)
+}
+
 // ObjectMarshaller uses reflection to marshal an object's fields.
 type ObjectMarshaller struct {
 	key string
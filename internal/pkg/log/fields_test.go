@@ -67,7 +67,7 @@ func TestStandardFields(t *testing.T) {
 			WithBackoff(time.Minute), WithServiceEndpoint(u1.String()), WithTreeID(1234),
 			WithLeaf(leaf), WithStore("store1"), WithCommand("doit"),
 			WithVerifiableCredential([]byte(`"id":"vc1"`)), WithSignature([]byte("my signature")),
-			WithTimestamp(321232), WithPublicKey(pubKey),
+			WithTimestamp(321232), WithPublicKey(pubKey), WithFilePath("/etc/certs/ca.pem"),
 		)
 
 		t.Logf(stdOut.String())
@@ -87,6 +87,7 @@ func TestStandardFields(t *testing.T) {
 		require.Equal(t, "my signature", l.Signature)
 		require.Equal(t, 321232, l.Timestamp)
 		require.Equal(t, pubKey, l.PublicKey)
+		require.Equal(t, "/etc/certs/ca.pem", l.FilePath)
 	})
 }
 
@@ -116,6 +117,7 @@ type logData struct {
 	Signature            string      `json:"signature"`
 	Timestamp            int         `json:"timestamp"`
 	PublicKey            *mockObject `json:"publicKey"`
+	FilePath             string      `json:"filePath"`
 }
 
 func unmarshalLogData(t *testing.T, b []byte) *logData {
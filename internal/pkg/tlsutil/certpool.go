@@ -7,42 +7,123 @@ package tlsutil
 
 import (
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/trustbloc/vct/internal/pkg/log"
 )
 
 var logger = log.New("tlsutil")
 
+// systemCertPoolLoader loads the system trust store, it is replaced in tests.
+var systemCertPoolLoader = x509.SystemCertPool //nolint:gochecknoglobals
+
 // CertPool is a thread safe wrapper around the x509 standard library
 // cert pool implementation.
 // It optionally allows loading the system trust store.
 type CertPool struct {
+	// counters are accessed atomically and come first to be 64-bit aligned on 32-bit platforms
+	rebuilds  uint64
+	cacheHits uint64
+
 	certPool       *x509.CertPool
 	certs          []*x509.Certificate
 	certsByName    map[string][]int
 	lock           sync.RWMutex
 	dirty          int32
 	systemCertPool bool
+	strict         bool
 }
 
-// NewCertPool new CertPool implementation.
-func NewCertPool(useSystemCertPool bool) (*CertPool, error) {
-	c, err := loadSystemCertPool(useSystemCertPool)
-	if err != nil {
-		return nil, err
+// CertPoolOpt is an option of NewCertPool and NewCertPoolFromDir.
+type CertPoolOpt func(*CertPool)
+
+// WithStrictSystemCertPool makes the cert pool fail if the system trust store is used but cannot be loaded,
+// instead of falling back to an empty cert pool.
+func WithStrictSystemCertPool() CertPoolOpt {
+	return func(c *CertPool) {
+		c.strict = true
 	}
+}
+
+// PoolStats contains the size of a CertPool and how often Get() returned the cached certpool.
+type PoolStats struct {
+	// NumCerts is the number of certs added to the pool, certs of the system trust store are not included.
+	NumCerts int
+	// Rebuilds is the number of times Get() rebuilt the certpool as certs were added or removed.
+	Rebuilds uint64
+	// CacheHits is the number of times Get() returned the certpool without rebuilding it.
+	CacheHits uint64
+}
 
+// NewCertPool new CertPool implementation.
+// If the system trust store is used but cannot be loaded, e.g. on some Windows or musl environments, a warning
+// is logged and the certpool starts empty, so that the certs added to the pool are still trusted; the system
+// trust store is loaded again whenever the certpool is rebuilt. See WithStrictSystemCertPool to fail instead.
+func NewCertPool(useSystemCertPool bool, opts ...CertPoolOpt) (*CertPool, error) {
 	newCertPool := &CertPool{
 		certsByName:    make(map[string][]int),
-		certPool:       c,
 		systemCertPool: useSystemCertPool,
 	}
 
+	for _, opt := range opts {
+		opt(newCertPool)
+	}
+
+	c, err := newCertPool.loadCertPool()
+	if err != nil {
+		return nil, err
+	}
+
+	newCertPool.certPool = c
+
 	return newCertPool, nil
 }
 
+// NewCertPoolWithCerts new CertPool implementation seeded with given certs. Unlike a subsequent Add() call, the
+// certs are in the certpool before the CertPool is returned, so the first Get() already includes them without
+// rebuilding the certpool. Nil and duplicate certs are skipped as with Add().
+func NewCertPoolWithCerts(useSystemCertPool bool, certs ...*x509.Certificate) (*CertPool, error) {
+	certPool, err := NewCertPool(useSystemCertPool)
+	if err != nil {
+		return nil, err
+	}
+
+	// the cert pool is not shared yet, so the certpool may be built in place
+	certPool.add(certs...)
+
+	for _, cert := range certPool.certs {
+		certPool.certPool.AddCert(cert)
+	}
+
+	certPool.dirty = 0
+
+	return certPool, nil
+}
+
+// NewCertPoolFromDir new CertPool implementation with certs loaded from the PEM files in given directory.
+// See Reload for the files which are loaded.
+func NewCertPoolFromDir(dir string, useSystemCertPool bool, opts ...CertPoolOpt) (*CertPool, error) {
+	certPool, err := NewCertPool(useSystemCertPool, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = certPool.Reload(dir); err != nil {
+		return nil, err
+	}
+
+	return certPool, nil
+}
+
 // Get returns certpool.
 // If there are any certs in cert queue added by any previous Add() call
 // it adds those certs to certpool before returning.
@@ -54,6 +135,8 @@ func (c *CertPool) Get() (*x509.CertPool, error) {
 		if err != nil {
 			return nil, err
 		}
+	} else {
+		atomic.AddUint64(&c.cacheHits, 1)
 	}
 
 	c.lock.RLock()
@@ -62,10 +145,70 @@ func (c *CertPool) Get() (*x509.CertPool, error) {
 	return c.certPool, nil
 }
 
+// Stats returns the number of certs of cert pool and the number of certpool rebuilds and cache hits of Get().
+func (c *CertPool) Stats() PoolStats {
+	c.lock.RLock()
+	numCerts := len(c.certs)
+	c.lock.RUnlock()
+
+	return PoolStats{
+		NumCerts:  numCerts,
+		Rebuilds:  atomic.LoadUint64(&c.rebuilds),
+		CacheHits: atomic.LoadUint64(&c.cacheHits),
+	}
+}
+
+// Subjects returns the subject names of the certs of cert pool in the order they were added, as decoded from
+// their RawSubject. Certs of the system trust store are not included.
+func (c *CertPool) Subjects() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	subjects := make([]string, 0, len(c.certs))
+
+	for _, cert := range c.certs {
+		subjects = append(subjects, subjectName(cert))
+	}
+
+	return subjects
+}
+
+// Contains returns true if given cert was added to cert pool and not removed since.
+// Certs of the system trust store are not taken into account.
+func (c *CertPool) Contains(cert *x509.Certificate) bool {
+	if cert == nil {
+		return false
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, p := range c.certsByName[string(cert.RawSubject)] {
+		if c.certs[p].Equal(cert) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Add adds given certs to cert pool queue, those certs will be added to certpool during subsequent Get() call.
 func (c *CertPool) Add(certs ...*x509.Certificate) {
+	c.add(certs...)
+}
+
+// AddPEM adds all certs of CERTIFICATE blocks in given PEM data to cert pool queue and returns the number of
+// certs which were not in the pool yet. If a block fails to parse, the certs of the other blocks are added
+// nevertheless and an error naming the block is returned.
+func (c *CertPool) AddPEM(pemBytes []byte) (int, error) {
+	certs, err := parsePEMCerts(pemBytes)
+
+	return c.add(certs...), err
+}
+
+func (c *CertPool) add(certs ...*x509.Certificate) int {
 	if len(certs) == 0 {
-		return
+		return 0
 	}
 
 	// filter certs to be added, check if they already exist or duplicate
@@ -85,10 +228,119 @@ func (c *CertPool) Add(certs ...*x509.Certificate) {
 
 		atomic.CompareAndSwapInt32(&c.dirty, 0, 1)
 	}
+
+	return len(certsToBeAdded)
+}
+
+// Remove removes given certs from cert pool, those certs will be removed from certpool during subsequent
+// Get() call.
+func (c *CertPool) Remove(certs ...*x509.Certificate) {
+	if len(certs) == 0 {
+		return
+	}
+
+	c.removeIf(func(cert *x509.Certificate) bool {
+		return containsCert(certs, cert)
+	})
+}
+
+// Expired returns the certs of cert pool which are expired at given time, i.e. past their NotAfter.
+// Certs of the system trust store are not included.
+func (c *CertPool) Expired(now time.Time) []*x509.Certificate {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var expired []*x509.Certificate
+
+	for _, cert := range c.certs {
+		if isExpired(cert, now) {
+			expired = append(expired, cert)
+		}
+	}
+
+	return expired
+}
+
+// PruneExpired removes the certs of cert pool which are expired at given time and returns the number of removed
+// certs. As with Remove, those certs will be removed from certpool during subsequent Get() call.
+func (c *CertPool) PruneExpired(now time.Time) int {
+	return c.removeIf(func(cert *x509.Certificate) bool {
+		return isExpired(cert, now)
+	})
+}
+
+// removeIf removes the certs for which given func returns true and returns the number of removed certs.
+func (c *CertPool) removeIf(remove func(*x509.Certificate) bool) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	remaining := make([]*x509.Certificate, 0, len(c.certs))
+
+	for _, cert := range c.certs {
+		if !remove(cert) {
+			remaining = append(remaining, cert)
+		}
+	}
+
+	removed := len(c.certs) - len(remaining)
+	if removed == 0 {
+		return 0
+	}
+
+	// rebuild cert name index as positions of remaining certs have changed
+	certsByName := make(map[string][]int)
+
+	for i, cert := range remaining {
+		name := string(cert.RawSubject)
+		certsByName[name] = append(certsByName[name], i)
+	}
+
+	c.certs = remaining
+	c.certsByName = certsByName
+
+	atomic.CompareAndSwapInt32(&c.dirty, 0, 1)
+
+	return removed
+}
+
+// Reload replaces the certs of cert pool with the certs of all *.pem and *.crt files in given directory.
+// Files which fail to be read or parsed are logged and skipped. The new certpool is built before it is swapped
+// in, so concurrent Get() calls return either the previous or the new certpool.
+func (c *CertPool) Reload(dir string) error {
+	certs, err := loadCertsFromDir(dir)
+	if err != nil {
+		return err
+	}
+
+	newCertPool, err := c.loadCertPool()
+	if err != nil {
+		return err
+	}
+
+	certs = removeDuplicates(certs...)
+	certsByName := make(map[string][]int)
+
+	for i, cert := range certs {
+		name := string(cert.RawSubject)
+		certsByName[name] = append(certsByName[name], i)
+
+		newCertPool.AddCert(cert)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.certs = certs
+	c.certsByName = certsByName
+	c.certPool = newCertPool
+
+	atomic.StoreInt32(&c.dirty, 0)
+
+	return nil
 }
 
 func (c *CertPool) swapCertPool() error {
-	newCertPool, err := loadSystemCertPool(c.systemCertPool)
+	newCertPool, err := c.loadCertPool()
 	if err != nil {
 		return err
 	}
@@ -104,6 +356,8 @@ func (c *CertPool) swapCertPool() error {
 	// swap old certpool with new one
 	c.certPool = newCertPool
 
+	atomic.AddUint64(&c.rebuilds, 1)
+
 	return nil
 }
 
@@ -132,13 +386,74 @@ CertLoop:
 	return removeDuplicates(filtered...)
 }
 
+// parsePEMCerts parses the certs of all CERTIFICATE blocks in given PEM data. If a block fails to parse, the
+// certs of the other blocks are returned together with an error naming the block.
+func parsePEMCerts(pemBytes []byte) ([]*x509.Certificate, error) {
+	var (
+		certs    []*x509.Certificate
+		parseErr error
+	)
+
+	for i := 0; len(pemBytes) > 0; i++ {
+		var block *pem.Block
+
+		block, pemBytes = pem.Decode(pemBytes)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			if parseErr == nil {
+				parseErr = fmt.Errorf("failed to parse cert of PEM block %d: %w", i, err)
+			}
+
+			continue
+		}
+
+		certs = append(certs, cert)
+	}
+
+	return certs, parseErr
+}
+
+func containsCert(certs []*x509.Certificate, cert *x509.Certificate) bool {
+	for _, c := range certs {
+		if c != nil && c.Equal(cert) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// subjectName returns the RFC 2253 string of the RawSubject of given cert, which keeps all of its attributes.
+func subjectName(cert *x509.Certificate) string {
+	var subject pkix.RDNSequence
+
+	if rest, err := asn1.Unmarshal(cert.RawSubject, &subject); err != nil || len(rest) > 0 {
+		return cert.Subject.String()
+	}
+
+	return subject.String()
+}
+
+func isExpired(cert *x509.Certificate, now time.Time) bool {
+	return now.After(cert.NotAfter)
+}
+
 func removeDuplicates(certs ...*x509.Certificate) []*x509.Certificate {
-	encountered := map[*x509.Certificate]bool{}
+	encountered := map[string]bool{}
 	result := []*x509.Certificate{}
 
 	for v := range certs {
-		if !encountered[certs[v]] {
-			encountered[certs[v]] = true
+		// compare raw certs, equal certs may be parsed into distinct instances
+		if !encountered[string(certs[v].Raw)] {
+			encountered[string(certs[v].Raw)] = true
 
 			result = append(result, certs[v])
 		}
@@ -147,12 +462,65 @@ func removeDuplicates(certs ...*x509.Certificate) []*x509.Certificate {
 	return result
 }
 
+func loadCertsFromDir(dir string) ([]*x509.Certificate, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cert dir: %w", err)
+	}
+
+	var certs []*x509.Certificate
+
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".pem" && ext != ".crt") {
+			continue
+		}
+
+		file := filepath.Join(dir, entry.Name())
+
+		pemBytes, readErr := os.ReadFile(filepath.Clean(file))
+		if readErr != nil {
+			logger.Warn("Failed to read cert file", log.WithFilePath(file), log.WithError(readErr))
+
+			continue
+		}
+
+		fileCerts, parseErr := parsePEMCerts(pemBytes)
+		if parseErr != nil {
+			logger.Warn("Failed to parse cert file", log.WithFilePath(file), log.WithError(parseErr))
+
+			continue
+		}
+
+		certs = append(certs, fileCerts...)
+	}
+
+	return certs, nil
+}
+
+// loadCertPool returns a new certpool, with the certs of the system trust store if it is used. Unless strict, a
+// system trust store which fails to load is logged and replaced with an empty certpool.
+func (c *CertPool) loadCertPool() (*x509.CertPool, error) {
+	certPool, err := loadSystemCertPool(c.systemCertPool)
+	if err != nil {
+		if c.strict {
+			return nil, err
+		}
+
+		logger.Warn("Failed to load system cert pool, falling back to an empty cert pool", log.WithError(err))
+
+		return x509.NewCertPool(), nil
+	}
+
+	return certPool, nil
+}
+
 func loadSystemCertPool(useSystemCertPool bool) (*x509.CertPool, error) {
 	if !useSystemCertPool {
 		return x509.NewCertPool(), nil
 	}
 
-	systemCertPool, err := x509.SystemCertPool()
+	systemCertPool, err := systemCertPoolLoader()
 	if err != nil {
 		return nil, err
 	}
@@ -9,8 +9,11 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -464,3 +467,447 @@ func getCertFromPEMBytes(pemCerts []byte) (*x509.Certificate, error) {
 
 	return nil, errors.New("empty cert bytes provided")
 }
+
+func TestRemovingCertsFromPool(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip()
+
+		return
+	}
+
+	// prepare 3 certs
+	certOrg1, err := getCertFromPEMBytes([]byte(tlsCaOrg1))
+	require.NoError(t, err)
+
+	certOrg2, err := getCertFromPEMBytes([]byte(tlsCaOrg2))
+	require.NoError(t, err)
+
+	certOrderer, err := getCertFromPEMBytes([]byte(tlsOrdererCert))
+	require.NoError(t, err)
+
+	// create certpool instance without system cert pool
+	tlsCertPool, err := NewCertPool(false)
+	require.NoError(t, err)
+
+	tlsCertPool.Add(certOrderer, certOrg1, certOrg2)
+	pool, err := tlsCertPool.Get()
+	require.NoError(t, err)
+	verifyCertPoolInstance(t, pool, tlsCertPool, 3, 3, 3, 0, 0)
+
+	// remove nothing, pool should be unchanged and dirty flag should be off
+	tlsCertPool.Remove()
+	verifyCertPoolInstance(t, pool, tlsCertPool, 3, 3, 3, 0, 0)
+
+	// remove 1 cert, queue should have one cert less and dirty flag should be on
+	tlsCertPool.Remove(certOrg1)
+	verifyCertPoolInstance(t, pool, tlsCertPool, 3, 2, 2, 0, 1)
+	pool, err = tlsCertPool.Get()
+	require.NoError(t, err)
+	verifyCertPoolInstance(t, pool, tlsCertPool, 2, 2, 2, 0, 0)
+
+	// remove cert which is not in the pool, pool should be unchanged and dirty flag should be off
+	tlsCertPool.Remove(certOrg1)
+	verifyCertPoolInstance(t, pool, tlsCertPool, 2, 2, 2, 0, 0)
+
+	// name index should point to the remaining certs
+	for name, indexes := range tlsCertPool.certsByName {
+		for _, i := range indexes {
+			require.Equal(t, name, string(tlsCertPool.certs[i].RawSubject))
+		}
+	}
+
+	// removed cert is filtered no more, so it can be added again
+	tlsCertPool.Add(certOrg1)
+	verifyCertPoolInstance(t, pool, tlsCertPool, 2, 3, 3, 0, 1)
+
+	// remove all certs
+	tlsCertPool.Remove(certOrderer, certOrg1, certOrg2)
+	pool, err = tlsCertPool.Get()
+	require.NoError(t, err)
+	verifyCertPoolInstance(t, pool, tlsCertPool, 0, 0, 0, 0, 0)
+}
+
+func TestExpiredCertsInPool(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip()
+
+		return
+	}
+
+	// the org certs expire on 2028-07-22
+	certOrg1, err := getCertFromPEMBytes([]byte(tlsCaOrg1))
+	require.NoError(t, err)
+
+	certOrg2, err := getCertFromPEMBytes([]byte(tlsCaOrg2))
+	require.NoError(t, err)
+
+	now := certOrg1.NotAfter.Add(time.Hour)
+
+	certs := createNCerts(2)
+	certs[0].NotAfter = now.Add(-time.Second)
+	certs[1].NotAfter = now.Add(time.Second)
+
+	tlsCertPool, err := NewCertPool(false)
+	require.NoError(t, err)
+
+	tlsCertPool.Add(certOrg1, certs[0], certOrg2, certs[1])
+	pool, err := tlsCertPool.Get()
+	require.NoError(t, err)
+	verifyCertPoolInstance(t, pool, tlsCertPool, 4, 4, 4, 0, 0)
+
+	t.Run("Expired", func(t *testing.T) {
+		require.Empty(t, tlsCertPool.Expired(certOrg1.NotAfter))
+		require.Equal(t, []*x509.Certificate{certOrg1, certs[0], certOrg2}, tlsCertPool.Expired(now))
+	})
+
+	t.Run("Prune expired", func(t *testing.T) {
+		// nothing expired, pool should be unchanged and dirty flag should be off
+		require.Zero(t, tlsCertPool.PruneExpired(certOrg1.NotAfter))
+		verifyCertPoolInstance(t, pool, tlsCertPool, 4, 4, 4, 0, 0)
+
+		require.Equal(t, 3, tlsCertPool.PruneExpired(now))
+		verifyCertPoolInstance(t, pool, tlsCertPool, 4, 1, 1, 0, 1)
+
+		pool, err = tlsCertPool.Get()
+		require.NoError(t, err)
+		verifyCertPoolInstance(t, pool, tlsCertPool, 1, 1, 1, 0, 0)
+		require.Equal(t, []*x509.Certificate{certs[1]}, tlsCertPool.certs)
+		require.Equal(t, map[string][]int{string(certs[1].RawSubject): {0}}, tlsCertPool.certsByName)
+		require.Empty(t, tlsCertPool.Expired(now))
+	})
+}
+
+func TestCertPoolStats(t *testing.T) {
+	tlsCertPool, err := NewCertPool(false)
+	require.NoError(t, err)
+
+	require.Equal(t, PoolStats{}, tlsCertPool.Stats())
+
+	certs := createNCerts(3)
+
+	tlsCertPool.Add(certs...)
+	require.Equal(t, PoolStats{NumCerts: 3}, tlsCertPool.Stats())
+
+	_, err = tlsCertPool.Get()
+	require.NoError(t, err)
+
+	_, err = tlsCertPool.Get()
+	require.NoError(t, err)
+	require.Equal(t, PoolStats{NumCerts: 3, Rebuilds: 1, CacheHits: 1}, tlsCertPool.Stats())
+
+	tlsCertPool.Remove(certs[0])
+
+	_, err = tlsCertPool.Get()
+	require.NoError(t, err)
+	require.Equal(t, PoolStats{NumCerts: 2, Rebuilds: 2, CacheHits: 1}, tlsCertPool.Stats())
+
+	t.Run("Concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+
+		for _, cert := range createNCerts(100) {
+			wg.Add(2)
+
+			go func(cert *x509.Certificate) {
+				defer wg.Done()
+
+				tlsCertPool.Add(cert)
+
+				_, errGet := tlsCertPool.Get()
+				assert.NoError(t, errGet)
+			}(cert)
+
+			go func() {
+				defer wg.Done()
+
+				tlsCertPool.Stats()
+			}()
+		}
+
+		wg.Wait()
+
+		// certs 1 and 2 are in the pool already
+		stats := tlsCertPool.Stats()
+		require.Equal(t, 100, stats.NumCerts)
+		require.Equal(t, uint64(103), stats.Rebuilds+stats.CacheHits)
+	})
+}
+
+func TestCertPoolSubjectsAndContains(t *testing.T) {
+	tlsCertPool, err := NewCertPool(false)
+	require.NoError(t, err)
+
+	org1, err := getCertFromPEMBytes([]byte(tlsCaOrg1))
+	require.NoError(t, err)
+
+	org2, err := getCertFromPEMBytes([]byte(tlsCaOrg2))
+	require.NoError(t, err)
+
+	orderer, err := getCertFromPEMBytes([]byte(tlsOrdererCert))
+	require.NoError(t, err)
+
+	require.Empty(t, tlsCertPool.Subjects())
+	require.False(t, tlsCertPool.Contains(org1))
+
+	tlsCertPool.Add(org1, org2)
+
+	require.Equal(t, []string{
+		"CN=tlsca.org1.example.com,O=org1.example.com,L=San Francisco,ST=California,C=US",
+		"CN=tlsca.org2.example.com,O=org2.example.com,L=San Francisco,ST=California,C=US",
+	}, tlsCertPool.Subjects())
+	require.True(t, tlsCertPool.Contains(org1))
+	require.True(t, tlsCertPool.Contains(org2))
+	require.False(t, tlsCertPool.Contains(orderer))
+	require.False(t, tlsCertPool.Contains(nil))
+
+	// introspection does not rebuild the certpool
+	require.Equal(t, PoolStats{NumCerts: 2}, tlsCertPool.Stats())
+
+	tlsCertPool.Remove(org1)
+
+	require.False(t, tlsCertPool.Contains(org1))
+	require.Len(t, tlsCertPool.Subjects(), 1)
+}
+
+func TestNewCertPoolWithCerts(t *testing.T) {
+	org1, err := getCertFromPEMBytes([]byte(tlsCaOrg1))
+	require.NoError(t, err)
+
+	org2, err := getCertFromPEMBytes([]byte(tlsCaOrg2))
+	require.NoError(t, err)
+
+	org1Copy, err := getCertFromPEMBytes([]byte(tlsCaOrg1))
+	require.NoError(t, err)
+
+	tlsCertPool, err := NewCertPoolWithCerts(false, org1, nil, org2, org1Copy)
+	require.NoError(t, err)
+
+	// certs are seeded before the first Get(), so it does not rebuild the certpool
+	require.Equal(t, PoolStats{NumCerts: 2}, tlsCertPool.Stats())
+	require.True(t, tlsCertPool.Contains(org1))
+	require.True(t, tlsCertPool.Contains(org2))
+
+	pool, err := tlsCertPool.Get()
+	require.NoError(t, err)
+	require.Len(t, pool.Subjects(), 2)
+	require.Equal(t, PoolStats{NumCerts: 2, CacheHits: 1}, tlsCertPool.Stats())
+
+	orderer, err := getCertFromPEMBytes([]byte(tlsOrdererCert))
+	require.NoError(t, err)
+
+	tlsCertPool.Add(orderer)
+
+	pool, err = tlsCertPool.Get()
+	require.NoError(t, err)
+	require.Len(t, pool.Subjects(), 3)
+
+	t.Run("No certs", func(t *testing.T) {
+		tlsCertPool, err := NewCertPoolWithCerts(false)
+		require.NoError(t, err)
+
+		pool, err := tlsCertPool.Get()
+		require.NoError(t, err)
+		require.Empty(t, pool.Subjects())
+	})
+}
+
+func TestAddingPEMToPool(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip()
+
+		return
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		tlsCertPool, err := NewCertPool(false)
+		require.NoError(t, err)
+
+		n, err := tlsCertPool.AddPEM([]byte(tlsCaOrg1 + "\n" + tlsCaOrg2 + "\n" + tlsCaOrg1))
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+
+		pool, err := tlsCertPool.Get()
+		require.NoError(t, err)
+		verifyCertPoolInstance(t, pool, tlsCertPool, 2, 2, 2, 0, 0)
+
+		// certs which already exist are not counted
+		n, err = tlsCertPool.AddPEM([]byte(tlsCaOrg2 + "\n" + tlsOrdererCert))
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		verifyCertPoolInstance(t, pool, tlsCertPool, 2, 3, 3, 0, 1)
+	})
+
+	t.Run("No certs", func(t *testing.T) {
+		tlsCertPool, err := NewCertPool(false)
+		require.NoError(t, err)
+
+		n, err := tlsCertPool.AddPEM([]byte("not a PEM"))
+		require.NoError(t, err)
+		require.Zero(t, n)
+	})
+
+	t.Run("Invalid block", func(t *testing.T) {
+		tlsCertPool, err := NewCertPool(false)
+		require.NoError(t, err)
+
+		invalid := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("invalid")}))
+
+		n, err := tlsCertPool.AddPEM([]byte(tlsCaOrg1 + "\n" + invalid + tlsCaOrg2))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to parse cert of PEM block 1")
+		require.Equal(t, 2, n)
+
+		pool, err := tlsCertPool.Get()
+		require.NoError(t, err)
+		verifyCertPoolInstance(t, pool, tlsCertPool, 2, 2, 2, 0, 0)
+	})
+}
+
+func TestCertPoolFromDir(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip()
+
+		return
+	}
+
+	writeFile := func(t *testing.T, dir, name, content string) {
+		t.Helper()
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		dir := t.TempDir()
+
+		writeFile(t, dir, "org1.pem", tlsCaOrg1)
+		writeFile(t, dir, "org2.CRT", tlsCaOrg2)
+		writeFile(t, dir, "orderer.txt", tlsOrdererCert)
+		writeFile(t, dir, "invalid.pem", "-----BEGIN CERTIFICATE-----\naW52YWxpZA==\n-----END CERTIFICATE-----\n")
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "dir.pem"), 0o700))
+
+		tlsCertPool, err := NewCertPoolFromDir(dir, false)
+		require.NoError(t, err)
+
+		pool, err := tlsCertPool.Get()
+		require.NoError(t, err)
+		verifyCertPoolInstance(t, pool, tlsCertPool, 2, 2, 2, 0, 0)
+	})
+
+	t.Run("Reload", func(t *testing.T) {
+		dir := t.TempDir()
+
+		writeFile(t, dir, "org1.pem", tlsCaOrg1)
+		writeFile(t, dir, "org2.pem", tlsCaOrg2)
+
+		tlsCertPool, err := NewCertPoolFromDir(dir, false)
+		require.NoError(t, err)
+
+		pool, err := tlsCertPool.Get()
+		require.NoError(t, err)
+		verifyCertPoolInstance(t, pool, tlsCertPool, 2, 2, 2, 0, 0)
+
+		require.NoError(t, os.Remove(filepath.Join(dir, "org1.pem")))
+		writeFile(t, dir, "orderer.pem", tlsOrdererCert+"\n"+tlsCaOrg2)
+
+		require.NoError(t, tlsCertPool.Reload(dir))
+
+		pool, err = tlsCertPool.Get()
+		require.NoError(t, err)
+		verifyCertPoolInstance(t, pool, tlsCertPool, 2, 2, 2, 0, 0)
+
+		certOrg1, err := getCertFromPEMBytes([]byte(tlsCaOrg1))
+		require.NoError(t, err)
+
+		// removed cert is not in the pool anymore, so it is added again
+		tlsCertPool.Add(certOrg1)
+		verifyCertPoolInstance(t, pool, tlsCertPool, 2, 3, 3, 0, 1)
+	})
+
+	t.Run("Concurrent reload", func(t *testing.T) {
+		dir := t.TempDir()
+
+		writeFile(t, dir, "org1.pem", tlsCaOrg1)
+
+		tlsCertPool, err := NewCertPoolFromDir(dir, false)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+
+			go func() {
+				defer wg.Done()
+
+				assert.NoError(t, tlsCertPool.Reload(dir))
+			}()
+
+			go func() {
+				defer wg.Done()
+
+				pool, err := tlsCertPool.Get()
+				assert.NoError(t, err)
+				assert.NotNil(t, pool)
+			}()
+		}
+
+		wg.Wait()
+	})
+
+	t.Run("Missing dir", func(t *testing.T) {
+		_, err := NewCertPoolFromDir(filepath.Join(t.TempDir(), "missing"), false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read cert dir")
+	})
+}
+
+func TestSystemCertPoolFallback(t *testing.T) {
+	loader := systemCertPoolLoader
+	systemCertPoolLoader = func() (*x509.CertPool, error) {
+		return nil, errors.New("system cert pool not available")
+	}
+
+	t.Cleanup(func() { systemCertPoolLoader = loader })
+
+	cert, err := getCertFromPEMBytes([]byte(tlsCaOrg1))
+	require.NoError(t, err)
+
+	t.Run("Fallback to empty cert pool", func(t *testing.T) {
+		tlsCertPool, err := NewCertPool(true)
+		require.NoError(t, err)
+
+		pool, err := tlsCertPool.Get()
+		require.NoError(t, err)
+		require.Empty(t, pool.Subjects())
+
+		tlsCertPool.Add(cert)
+
+		pool, err = tlsCertPool.Get()
+		require.NoError(t, err)
+		require.Len(t, pool.Subjects(), 1)
+
+		tlsCertPool, err = NewCertPoolWithCerts(true, cert)
+		require.NoError(t, err)
+
+		pool, err = tlsCertPool.Get()
+		require.NoError(t, err)
+		require.Len(t, pool.Subjects(), 1)
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		_, err := NewCertPool(true, WithStrictSystemCertPool())
+		require.EqualError(t, err, "system cert pool not available")
+
+		_, err = NewCertPoolFromDir(t.TempDir(), true, WithStrictSystemCertPool())
+		require.EqualError(t, err, "system cert pool not available")
+
+		// the system cert pool is not loaded if it is not used
+		tlsCertPool, err := NewCertPool(false, WithStrictSystemCertPool())
+		require.NoError(t, err)
+
+		tlsCertPool.Add(cert)
+
+		pool, err := tlsCertPool.Get()
+		require.NoError(t, err)
+		require.Len(t, pool.Subjects(), 1)
+	})
+}
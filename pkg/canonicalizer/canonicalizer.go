@@ -7,24 +7,287 @@ SPDX-License-Identifier: Apache-2.0
 package canonicalizer
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	ariesjsonld "github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	jsonld "github.com/piprate/json-gold/ld"
 
 	"github.com/trustbloc/vct/internal/pkg/jsoncanonicalizer"
 )
 
+// Algorithm is a canonicalization algorithm.
+//
+// The VCT server canonicalizes the credential of a log entry with URDNA2015, after removing its proof, and
+// the resulting Merkle tree leaf with JCS; the leaf hash is calculated from the JCS bytes. Verifiers that
+// recalculate leaf hashes must use the same algorithms.
+type Algorithm int
+
+const (
+	// JCS is the JSON Canonicalization Scheme (RFC 8785). Numbers are IEEE 754 double precision values serialized
+	// as by ECMAScript, so integers beyond 2^53 lose precision as they do in other JCS implementations. Numbers
+	// out of the double precision range and invalid JSON numbers are rejected.
+	JCS Algorithm = iota
+	// URDNA2015 is the RDF Dataset Normalization algorithm of JSON-LD documents, which produces N-Quads.
+	URDNA2015
+)
+
+const urdna2015 = "URDNA2015"
+
+// ErrDuplicateKey is returned when a JSON object has duplicate keys. RFC 8259 leaves the value of a duplicate
+// key undefined: some parsers keep the last value, e.g. encoding/json, others the first one or reject the
+// object. The canonical form of such an object, and the leaf hash calculated from it, would depend on the
+// parser, so JSON documents with duplicate keys, at any depth, are rejected by every algorithm rather than
+// canonicalized.
+var ErrDuplicateKey = jsoncanonicalizer.ErrDuplicateKey // nolint: gochecknoglobals
+
+// Opt represents MarshalCanonicalWith option func.
+type Opt func(*options)
+
+type options struct {
+	loader     jsonld.DocumentLoader
+	sortArrays [][]string
+}
+
+// WithDocumentLoader sets the loader of the JSON-LD contexts used by URDNA2015. Without it, contexts are
+// loaded over the network.
+func WithDocumentLoader(loader jsonld.DocumentLoader) Opt {
+	return func(o *options) {
+		o.loader = loader
+	}
+}
+
+// WithSortScalarArrays sorts the arrays at the given paths before JCS canonicalization, so that documents which
+// differ only in the order of the elements of these arrays have the same canonical form, e.g. the "type" array of
+// a credential. A path is a dot separated list of object keys from the root of the document, e.g.
+// "credentialSubject.type"; arrays of objects are not traversed. A path that does not exist, or whose value is
+// not an array, is ignored; an array at a path that has an object or array element is rejected rather than
+// sorted. Elements are sorted on their canonical form; duplicates are kept. Other arrays are never reordered.
+//
+// The canonical form, and the leaf hash calculated from it, then differs from the canonical form of the VCT
+// server, unless the server sorts the same paths: verifiers, clients and the log must use the same setting for
+// leaf hashes to match. URDNA2015 ignores the option, as the order of the values of a JSON-LD array that is not
+// a @list does not change its canonical form.
+func WithSortScalarArrays(paths ...string) Opt {
+	return func(o *options) {
+		for _, p := range paths {
+			o.sortArrays = append(o.sortArrays, strings.Split(p, "."))
+		}
+	}
+}
+
 // MarshalCanonical marshals the given object into a canonicalized form
 // (using JCS RFC canonicalization).
 func MarshalCanonical(value interface{}) ([]byte, error) {
-	valueBytes, ok := value.([]byte)
+	return MarshalCanonicalWith(value, JCS)
+}
 
-	if !ok {
-		var err error
+// MarshalCanonicalWith marshals the given object into a canonicalized form using the given algorithm.
+// JSON bytes are canonicalized as is.
+func MarshalCanonicalWith(value interface{}, alg Algorithm, opts ...Opt) ([]byte, error) {
+	switch alg {
+	case JCS:
+		valueBytes, err := marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		o := &options{}
+		for _, fn := range opts {
+			fn(o)
+		}
 
-		valueBytes, err = json.Marshal(value)
+		if len(o.sortArrays) > 0 {
+			if valueBytes, err = sortScalarArrays(valueBytes, o.sortArrays); err != nil {
+				return nil, err
+			}
+		}
+
+		return jsoncanonicalizer.Transform(valueBytes)
+	case URDNA2015:
+		return marshalURDNA2015(value, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported canonicalization algorithm %d", alg)
+	}
+}
+
+func marshalURDNA2015(value interface{}, opts ...Opt) ([]byte, error) {
+	o := &options{}
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	doc, ok := value.(map[string]interface{})
+	if !ok {
+		valueBytes, err := marshal(value)
 		if err != nil {
 			return nil, err
 		}
+
+		// The document is parsed with encoding/json, which would keep the last value of a duplicate key.
+		if err = CheckDuplicateKeys(valueBytes); err != nil {
+			return nil, err
+		}
+
+		if err = json.Unmarshal(valueBytes, &doc); err != nil {
+			return nil, fmt.Errorf("unmarshal document: %w", err)
+		}
+	}
+
+	var processorOpts []ariesjsonld.ProcessorOpts
+	if o.loader != nil {
+		processorOpts = append(processorOpts, ariesjsonld.WithDocumentLoader(o.loader))
+	}
+
+	return ariesjsonld.NewProcessor(urdna2015).GetCanonicalDocument(doc, processorOpts...) // nolint: wrapcheck
+}
+
+// sortScalarArrays returns the JSON document with the arrays at the given paths sorted on the canonical form of
+// their elements.
+func sortScalarArrays(data []byte, paths [][]string) ([]byte, error) {
+	// The document is parsed with encoding/json, which would keep the last value of a duplicate key.
+	if err := CheckDuplicateKeys(data); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("unmarshal document: %w", err)
+	}
+
+	for _, path := range paths {
+		if err := sortScalarArray(doc, path); err != nil {
+			return nil, fmt.Errorf("sort array %q: %w", strings.Join(path, "."), err)
+		}
+	}
+
+	return json.Marshal(doc) // nolint: wrapcheck
+}
+
+func sortScalarArray(doc interface{}, path []string) error {
+	for _, key := range path[:len(path)-1] {
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		doc = obj[key]
+	}
+
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	arr, ok := obj[path[len(path)-1]].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, len(arr))
+
+	for i, v := range arr {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return fmt.Errorf("element %d is not a scalar", i)
+		}
+
+		// The canonical form of a scalar is the canonical form of an array of it without the brackets.
+		elem, err := json.Marshal([]interface{}{v})
+		if err != nil {
+			return fmt.Errorf("marshal element %d: %w", i, err)
+		}
+
+		canonical, err := jsoncanonicalizer.Transform(elem)
+		if err != nil {
+			return err // nolint: wrapcheck
+		}
+
+		keys[i] = string(canonical)
+	}
+
+	sort.Sort(byKey{keys: keys, values: arr})
+
+	return nil
+}
+
+// byKey sorts values on their keys.
+type byKey struct {
+	keys   []string
+	values []interface{}
+}
+
+func (s byKey) Len() int           { return len(s.keys) }
+func (s byKey) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+
+func (s byKey) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+}
+
+func marshal(value interface{}) ([]byte, error) {
+	if valueBytes, ok := value.([]byte); ok {
+		return valueBytes, nil
+	}
+
+	return json.Marshal(value) // nolint: wrapcheck
+}
+
+// CheckDuplicateKeys returns an error wrapping ErrDuplicateKey if an object of the JSON document, at any depth,
+// has duplicate keys, e.g. before the document is parsed with encoding/json, which keeps the last value of a
+// duplicate key. Keys are compared after unescaping, so "\u0061" and "a" are duplicates.
+func CheckDuplicateKeys(data []byte) error {
+	if !json.Valid(data) {
+		return errors.New("invalid JSON")
+	}
+
+	i := skipWhiteSpace(data, 0)
+
+	return checkDuplicateKeys(data[i:valueEnd(data, i)])
+}
+
+// checkDuplicateKeys checks the given valid JSON value.
+func checkDuplicateKeys(data []byte) error {
+	if data[0] != '{' && data[0] != '[' {
+		return nil
+	}
+
+	keys := map[string]struct{}{}
+
+	for i := skipWhiteSpace(data, 1); data[i] != '}' && data[i] != ']'; {
+		end := valueEnd(data, i)
+
+		if data[0] == '{' {
+			key, err := unquote(data[i:end])
+			if err != nil {
+				return err
+			}
+
+			if _, ok := keys[key]; ok {
+				return fmt.Errorf("%w %q", ErrDuplicateKey, key)
+			}
+
+			keys[key] = struct{}{}
+
+			i = skipWhiteSpace(data, skipWhiteSpace(data, end)+1)
+			end = valueEnd(data, i)
+		}
+
+		if err := checkDuplicateKeys(data[i:end]); err != nil {
+			return err
+		}
+
+		if i = skipWhiteSpace(data, end); data[i] == ',' {
+			i = skipWhiteSpace(data, i+1)
+		}
 	}
 
-	return jsoncanonicalizer.Transform(valueBytes)
+	return nil
 }
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package canonicalizer
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -41,3 +42,162 @@ func TestMarshalCanonical(t *testing.T) {
 		require.Contains(t, err.Error(), "json: unsupported type: chan int")
 	})
 }
+
+func TestMarshalCanonical_RFC8785(t *testing.T) {
+	t.Run("success - sample", func(t *testing.T) {
+		// Sample of RFC 8785, section 3.2.2.
+		input := `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`
+
+		result, err := MarshalCanonical([]byte(input))
+		require.NoError(t, err)
+		require.Equal(t, `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],`+
+			`"string":"€$\u000f\nA'B\"\\\\\"/"}`, string(result))
+	})
+
+	t.Run("success - numbers", func(t *testing.T) {
+		for _, tc := range []struct {
+			input    string
+			expected string
+		}{
+			{"1e30", "1e+30"},
+			{"-0", "0"},
+			{"-0.0e5", "0"},
+			{"1E+21", "1e+21"},
+			{"100000000000000000000", "100000000000000000000"},
+			{"0.000001", "0.000001"},
+			{"0.0000001", "1e-7"},
+			{"9007199254740993", "9007199254740992"},
+			{"333333333.33333329", "333333333.3333333"},
+			{"1424953923781206.25", "1424953923781206.2"},
+			{"-1.7976931348623157e308", "-1.7976931348623157e+308"},
+			{"5e-324", "5e-324"},
+			{"1e-400", "0"},
+		} {
+			result, err := MarshalCanonical([]byte(`[` + tc.input + `]`))
+			require.NoError(t, err, tc.input)
+			require.Equal(t, `[`+tc.expected+`]`, string(result), tc.input)
+		}
+	})
+
+	t.Run("invalid numbers", func(t *testing.T) {
+		for _, input := range []string{
+			"1e400", "-1e400", "Infinity", "NaN", "0x10", "+1", "01", ".5", "1.", "1e", "1_000",
+		} {
+			result, err := MarshalCanonical([]byte(`[` + input + `]`))
+			require.Error(t, err, input)
+			require.Empty(t, result, input)
+		}
+	})
+}
+
+func TestMarshalCanonical_DuplicateKeys(t *testing.T) {
+	for _, input := range []string{
+		`{"a":1,"a":1}`,
+		`{"a":1,"b":{"c":2,"c":3}}`,
+		`[{"a":1},{"b":2,"b":2}]`,
+		`{"a":1,"\u0061":2}`,
+	} {
+		err := CheckDuplicateKeys([]byte(input))
+		require.True(t, errors.Is(err, ErrDuplicateKey), input)
+
+		// The canonical form is not defined, whichever value a parser would keep.
+		for _, alg := range []Algorithm{JCS, URDNA2015} {
+			result, err := MarshalCanonicalWith([]byte(input), alg)
+			require.True(t, errors.Is(err, ErrDuplicateKey), input)
+			require.Empty(t, result, input)
+		}
+	}
+
+	require.EqualError(t, CheckDuplicateKeys([]byte(`{"b":{"a":1,"a":2}}`)), `duplicate key "a"`)
+	require.EqualError(t, CheckDuplicateKeys([]byte(`{"a":1,}`)), "invalid JSON")
+
+	for _, input := range []string{
+		`{"a":{"a":1},"b":[{"a":1},{"a":2}]}`,
+		`{"a":"a","A":"a"," a":"a"}`,
+		`[1,"a",{}]`,
+		` "a" `,
+	} {
+		require.NoError(t, CheckDuplicateKeys([]byte(input)), input)
+	}
+}
+
+func TestMarshalCanonicalWith(t *testing.T) {
+	t.Run("success - JCS", func(t *testing.T) {
+		result, err := MarshalCanonicalWith([]byte(`{"beta":"beta","alpha":"alpha"}`), JCS)
+		require.NoError(t, err)
+		require.Equal(t, string(result), `{"alpha":"alpha","beta":"beta"}`)
+	})
+
+	t.Run("success - URDNA2015", func(t *testing.T) {
+		doc := []byte(`{"@id":"http://example.com/alice","http://schema.org/name":"Alice"}`)
+
+		result, err := MarshalCanonicalWith(doc, URDNA2015)
+		require.NoError(t, err)
+		require.Equal(t, "<http://example.com/alice> <http://schema.org/name> \"Alice\" .\n", string(result))
+	})
+
+	t.Run("URDNA2015 - not a JSON object", func(t *testing.T) {
+		result, err := MarshalCanonicalWith([]byte(`[]`), URDNA2015)
+		require.Error(t, err)
+		require.Empty(t, result)
+		require.Contains(t, err.Error(), "unmarshal document")
+	})
+
+	t.Run("unsupported algorithm", func(t *testing.T) {
+		result, err := MarshalCanonicalWith([]byte(`{}`), Algorithm(-1))
+		require.EqualError(t, err, "unsupported canonicalization algorithm -1")
+		require.Empty(t, result)
+	})
+}
+
+func TestWithSortScalarArrays(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		a := []byte(`{"type":["VerifiableCredential","UniversityDegreeCredential"],` +
+			`"credentialSubject":{"type":["b","a"],"degree":["x","y"]},"proof":[2,1]}`)
+		b := []byte(`{"type":["UniversityDegreeCredential","VerifiableCredential"],` +
+			`"credentialSubject":{"type":["a","b"],"degree":["x","y"]},"proof":[2,1]}`)
+
+		resultA, err := MarshalCanonicalWith(a, JCS, WithSortScalarArrays("type", "credentialSubject.type"))
+		require.NoError(t, err)
+
+		resultB, err := MarshalCanonicalWith(b, JCS, WithSortScalarArrays("type", "credentialSubject.type"))
+		require.NoError(t, err)
+
+		require.Equal(t, `{"credentialSubject":{"degree":["x","y"],"type":["a","b"]},"proof":[2,1],`+
+			`"type":["UniversityDegreeCredential","VerifiableCredential"]}`, string(resultA))
+		require.Equal(t, resultA, resultB)
+
+		// The arrays are not sorted by default.
+		resultA, err = MarshalCanonicalWith(a, JCS)
+		require.NoError(t, err)
+		require.NotEqual(t, resultA, resultB)
+	})
+
+	t.Run("scalars", func(t *testing.T) {
+		result, err := MarshalCanonicalWith([]byte(`{"a":[true,"b",1e1,null,"a",2,"b"]}`), JCS,
+			WithSortScalarArrays("a"))
+		require.NoError(t, err)
+		require.Equal(t, `{"a":["a","b","b",10,2,null,true]}`, string(result))
+	})
+
+	t.Run("missing paths are ignored", func(t *testing.T) {
+		result, err := MarshalCanonicalWith([]byte(`{"type":"VerifiableCredential","a":{"b":1}}`), JCS,
+			WithSortScalarArrays("type", "a.b", "a.b.c", "x.y"))
+		require.NoError(t, err)
+		require.Equal(t, `{"a":{"b":1},"type":"VerifiableCredential"}`, string(result))
+	})
+
+	t.Run("not a scalar array", func(t *testing.T) {
+		_, err := MarshalCanonicalWith([]byte(`{"a":["b",{"c":1}]}`), JCS, WithSortScalarArrays("a"))
+		require.EqualError(t, err, `sort array "a": element 1 is not a scalar`)
+	})
+
+	t.Run("duplicate keys", func(t *testing.T) {
+		_, err := MarshalCanonicalWith([]byte(`{"a":["b"],"a":["c"]}`), JCS, WithSortScalarArrays("a"))
+		require.True(t, errors.Is(err, ErrDuplicateKey))
+	})
+}
@@ -0,0 +1,317 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package canonicalizer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/trustbloc/vct/internal/pkg/jsoncanonicalizer"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// Encoder writes the canonical JSON (JCS) form of values to an output stream.
+type Encoder struct {
+	w *bufio.Writer
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+// Encode writes the canonical form of v to the stream; the output is byte-identical to the output of
+// MarshalCanonical, without a trailing newline. JSON bytes are canonicalized as is.
+//
+// Only the output is streamed: v is marshaled and validated in memory first, so the memory used grows with the
+// size of its JSON encoding. The canonical form is then written as it is produced, instead of being built in
+// memory as well as by MarshalCanonical, e.g. to hash a credential with large embedded blobs. If an error is
+// returned, the output written so far is incomplete.
+func (e *Encoder) Encode(v interface{}) error {
+	data, err := marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if !json.Valid(data) {
+		return errors.New("invalid JSON")
+	}
+
+	data = data[skipWhiteSpace(data, 0):valueEnd(data, skipWhiteSpace(data, 0))]
+
+	if data[0] != '{' && data[0] != '[' {
+		return errors.New("expected JSON object or array")
+	}
+
+	if err = e.value(data); err != nil {
+		return err
+	}
+
+	return e.w.Flush() // nolint: wrapcheck
+}
+
+type member struct {
+	key     string
+	sortKey []uint16
+	value   []byte
+}
+
+// value writes the canonical form of the given valid JSON value.
+func (e *Encoder) value(data []byte) error {
+	switch data[0] {
+	case '{':
+		return e.object(data)
+	case '[':
+		return e.array(data)
+	case '"':
+		s, err := unquote(data)
+		if err != nil {
+			return err
+		}
+
+		e.writeString(s)
+	case 't', 'f', 'n':
+		e.w.Write(data) // nolint: errcheck,gosec
+	default:
+		f, err := strconv.ParseFloat(string(data), 64)
+		if err != nil {
+			return fmt.Errorf("parse number: %w", err)
+		}
+
+		s, err := jsoncanonicalizer.NumberToJSON(f)
+		if err != nil {
+			return err // nolint: wrapcheck
+		}
+
+		e.w.WriteString(s) // nolint: errcheck,gosec
+	}
+
+	return nil
+}
+
+func (e *Encoder) object(data []byte) error {
+	var members []member
+
+	for i := skipWhiteSpace(data, 1); data[i] != '}'; {
+		end := valueEnd(data, i)
+
+		key, err := unquote(data[i:end])
+		if err != nil {
+			return err
+		}
+
+		i = skipWhiteSpace(data, skipWhiteSpace(data, end)+1)
+		end = valueEnd(data, i)
+
+		members = append(members, member{key: key, sortKey: utf16.Encode([]rune(key)), value: data[i:end]})
+
+		if i = skipWhiteSpace(data, end); data[i] == ',' {
+			i = skipWhiteSpace(data, i+1)
+		}
+	}
+
+	// Keys are sorted on their UTF-16 code units.
+	sort.SliceStable(members, func(i, j int) bool {
+		return compareUTF16(members[i].sortKey, members[j].sortKey) < 0
+	})
+
+	e.w.WriteByte('{') // nolint: errcheck,gosec
+
+	for i, m := range members {
+		if i > 0 {
+			if compareUTF16(members[i-1].sortKey, m.sortKey) == 0 {
+				return fmt.Errorf("%w %q", ErrDuplicateKey, m.key)
+			}
+
+			e.w.WriteByte(',') // nolint: errcheck,gosec
+		}
+
+		e.writeString(m.key)
+		e.w.WriteByte(':') // nolint: errcheck,gosec
+
+		if err := e.value(m.value); err != nil {
+			return err
+		}
+	}
+
+	return e.w.WriteByte('}') // nolint: wrapcheck
+}
+
+func (e *Encoder) array(data []byte) error {
+	e.w.WriteByte('[') // nolint: errcheck,gosec
+
+	for i, first := skipWhiteSpace(data, 1), true; data[i] != ']'; first = false {
+		if !first {
+			// The separator of the elements.
+			e.w.WriteByte(',') // nolint: errcheck,gosec
+			i = skipWhiteSpace(data, i+1)
+		}
+
+		end := valueEnd(data, i)
+
+		if err := e.value(data[i:end]); err != nil {
+			return err
+		}
+
+		i = skipWhiteSpace(data, end)
+	}
+
+	return e.w.WriteByte(']') // nolint: wrapcheck
+}
+
+// writeString writes the string quoted, escaping only the characters JCS requires to be escaped.
+func (e *Encoder) writeString(s string) {
+	e.w.WriteByte('"') // nolint: errcheck,gosec
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch c {
+		case '\\', '"':
+			e.w.WriteByte('\\') // nolint: errcheck,gosec
+			e.w.WriteByte(c)    // nolint: errcheck,gosec
+		case '\b':
+			e.w.WriteString(`\b`) // nolint: errcheck,gosec
+		case '\f':
+			e.w.WriteString(`\f`) // nolint: errcheck,gosec
+		case '\n':
+			e.w.WriteString(`\n`) // nolint: errcheck,gosec
+		case '\r':
+			e.w.WriteString(`\r`) // nolint: errcheck,gosec
+		case '\t':
+			e.w.WriteString(`\t`) // nolint: errcheck,gosec
+		default:
+			if c < 0x20 {
+				e.w.WriteString(`\u00`)          // nolint: errcheck,gosec
+				e.w.WriteByte(hexDigits[c>>4])   // nolint: errcheck,gosec
+				e.w.WriteByte(hexDigits[c&0x0f]) // nolint: errcheck,gosec
+
+				continue
+			}
+
+			e.w.WriteByte(c) // nolint: errcheck,gosec
+		}
+	}
+
+	e.w.WriteByte('"') // nolint: errcheck,gosec
+}
+
+// unquote returns the value of the given valid JSON string. Invalid UTF-8 is kept as is and unpaired
+// surrogates are replaced, as by MarshalCanonical.
+func unquote(data []byte) (string, error) {
+	var b strings.Builder
+
+	for i := 1; i < len(data)-1; i++ {
+		c := data[i]
+		if c != '\\' {
+			b.WriteByte(c)
+
+			continue
+		}
+
+		i++
+
+		switch data[i] {
+		case 'u':
+			r := hexRune(data[i+1 : i+5])
+			i += 4
+
+			if utf16.IsSurrogate(r) {
+				if data[i+1] != '\\' || data[i+2] != 'u' {
+					return "", errors.New("missing surrogate")
+				}
+
+				r = utf16.DecodeRune(r, hexRune(data[i+3:i+7]))
+				i += 6
+			}
+
+			b.WriteRune(r)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		default:
+			// '"', '\\' and '/'.
+			b.WriteByte(data[i])
+		}
+	}
+
+	return b.String(), nil
+}
+
+func hexRune(hex []byte) rune {
+	r, _ := strconv.ParseUint(string(hex), 16, 32) // nolint: errcheck // hex digits of valid JSON
+
+	return rune(r)
+}
+
+func compareUTF16(a, b []uint16) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return int(a[i]) - int(b[i])
+		}
+	}
+
+	return len(a) - len(b)
+}
+
+func skipWhiteSpace(data []byte, i int) int {
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t' || data[i] == '\n' || data[i] == '\r') {
+		i++
+	}
+
+	return i
+}
+
+// valueEnd returns the index after the valid JSON value that starts at the given index.
+func valueEnd(data []byte, i int) int {
+	switch data[i] {
+	case '"':
+		for i++; data[i] != '"'; i++ {
+			if data[i] == '\\' {
+				i++
+			}
+		}
+
+		return i + 1
+	case '{', '[':
+		depth := 0
+
+		for ; ; i++ {
+			switch data[i] {
+			case '"':
+				i = valueEnd(data, i) - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				if depth--; depth == 0 {
+					return i + 1
+				}
+			}
+		}
+	default:
+		for i < len(data) && !strings.ContainsRune(",]} \t\n\r", rune(data[i])) {
+			i++
+		}
+
+		return i
+	}
+}
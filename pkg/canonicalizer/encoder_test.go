@@ -0,0 +1,95 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package canonicalizer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder_Encode(t *testing.T) {
+	t.Run("success - identical to MarshalCanonical", func(t *testing.T) {
+		for _, input := range []string{
+			`{}`,
+			`[]`,
+			` { "b" : [ 1 , 2.50 , -0 , 1E30 ] , "a" : { } , "c" : [ ] } `,
+			`{"numbers":[333333333.33333329,1E30,4.50,2e-3,0.000000000000000000000000001]}`,
+			`{"string":"\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/"}`,
+			`{"literals":[null,true,false]}`,
+			`{"€":1,"\r":2,"😀":3,"דּ":4,"1":5,"\u0080":6,"ö":7,"\ud83d\ude02":8}`,
+			`[{"z":{"y":[{"x":"\"}]"}]}},"[{\"a\":1}]",[[[]]]]`,
+			`{"escapes":"\b\f\n\r\t\u0000\u001f\u007f "}`,
+		} {
+			expected, err := MarshalCanonical([]byte(input))
+			require.NoError(t, err, input)
+
+			var buf bytes.Buffer
+
+			require.NoError(t, NewEncoder(&buf).Encode([]byte(input)), input)
+			require.Equal(t, string(expected), buf.String(), input)
+		}
+	})
+
+	t.Run("success - value", func(t *testing.T) {
+		value := struct {
+			Beta     string            `json:"beta"`
+			Alpha    string            `json:"alpha"`
+			Evidence map[string]string `json:"evidence"`
+		}{
+			Beta:     "beta",
+			Alpha:    "alpha",
+			Evidence: map[string]string{"blob": strings.Repeat("<data>", 1<<20)},
+		}
+
+		expected, err := MarshalCanonical(value)
+		require.NoError(t, err)
+
+		hash := sha256.New()
+
+		expectedHash := sha256.Sum256(expected)
+
+		require.NoError(t, NewEncoder(hash).Encode(value))
+		require.Equal(t, expectedHash[:], hash.Sum(nil))
+	})
+
+	t.Run("success - multiple values", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		encoder := NewEncoder(&buf)
+		require.NoError(t, encoder.Encode([]byte(`{"b":1,"a":2}`)))
+		require.NoError(t, encoder.Encode([]byte(`[3]`)))
+		require.Equal(t, `{"a":2,"b":1}[3]`, buf.String())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			`{"a":1,}`:            "invalid JSON",
+			`{"a":0x10}`:          "invalid JSON",
+			`"a"`:                 "expected JSON object or array",
+			`{"a":1,"b":2,"a":3}`: `duplicate key "a"`,
+			`[1e400]`:             `parse number: strconv.ParseFloat: parsing "1e400": value out of range`,
+			`{"a":"\ud800"}`:      "missing surrogate",
+			`{"a":"\ud800A"}`:     "missing surrogate",
+		} {
+			err := NewEncoder(&bytes.Buffer{}).Encode([]byte(input))
+			require.EqualError(t, err, expected, input)
+
+			_, err = MarshalCanonical([]byte(input))
+			require.Error(t, err, input)
+		}
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		err := NewEncoder(&bytes.Buffer{}).Encode(make(chan int))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "json: unsupported type: chan int")
+	})
+}
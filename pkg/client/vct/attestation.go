@@ -0,0 +1,182 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/trustbloc/vct/pkg/canonicalizer"
+)
+
+const (
+	// AttestationPayloadType is the payload type of the attestation envelope.
+	AttestationPayloadType = "application/vnd.in-toto+json"
+	// AttestationStatementType is the in-toto statement type used by attestations.
+	AttestationStatementType = "https://in-toto.io/Statement/v0.1"
+	// AttestationPredicateType identifies the VCT verification predicate.
+	AttestationPredicateType = "https://trustbloc.dev/ns/vct/verification/v1"
+	// AttestationDigestAlgorithm is the digest name under which the leaf hash is stored in the subject.
+	AttestationDigestAlgorithm = "vctLeafHash"
+)
+
+// Signer signs attestations.
+type Signer interface {
+	// KeyID returns the ID of the signing key. It is stored in the envelope.
+	KeyID() string
+	// Sign signs the given data.
+	Sign(data []byte) ([]byte, error)
+}
+
+// SignatureVerifier verifies attestation signatures.
+type SignatureVerifier interface {
+	// Verify verifies the signature of the given data produced by the key with the given ID.
+	Verify(keyID string, data, signature []byte) error
+}
+
+// AttestationEnvelope is a DSSE (Dead Simple Signing Envelope) that wraps an attestation statement.
+// The signature is calculated over the PAE (pre-authentication encoding) of the payload type and payload.
+//
+//	{
+//	  "payloadType": "application/vnd.in-toto+json",
+//	  "payload": "<base64 encoded canonical statement>",
+//	  "signatures": [{"keyid": "<key ID>", "sig": "<base64 encoded signature>"}]
+//	}
+type AttestationEnvelope struct {
+	PayloadType string                 `json:"payloadType"`
+	Payload     []byte                 `json:"payload"`
+	Signatures  []AttestationSignature `json:"signatures"`
+}
+
+// AttestationSignature is a signature of the attestation envelope.
+type AttestationSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   []byte `json:"sig"`
+}
+
+// AttestationStatement is an in-toto statement about a verified credential.
+type AttestationStatement struct {
+	Type          string                `json:"_type"`
+	Subject       []AttestationSubject  `json:"subject"`
+	PredicateType string                `json:"predicateType"`
+	Predicate     VerificationPredicate `json:"predicate"`
+}
+
+// AttestationSubject identifies the credential the statement is about.
+type AttestationSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// VerificationPredicate describes what was checked and against which tree head.
+type VerificationPredicate struct {
+	Log            string        `json:"log"`
+	TreeSize       uint64        `json:"tree_size"`
+	SHA256RootHash []byte        `json:"sha256_root_hash"`
+	Checks         []CheckResult `json:"checks"`
+}
+
+// AttestVerification produces a signed attestation (a DSSE envelope with an in-toto statement)
+// for the given verification result.
+func AttestVerification(result *VerificationResult, signer Signer) ([]byte, error) {
+	if result == nil {
+		return nil, errors.New("verification result is required")
+	}
+
+	if signer == nil {
+		return nil, errors.New("signer is required")
+	}
+
+	checks := result.Checks
+	if checks == nil {
+		checks = []CheckResult{}
+	}
+
+	payload, err := canonicalizer.MarshalCanonical(AttestationStatement{
+		Type: AttestationStatementType,
+		Subject: []AttestationSubject{{
+			Name:   result.CredentialID,
+			Digest: map[string]string{AttestationDigestAlgorithm: hex.EncodeToString(result.LeafHash)},
+		}},
+		PredicateType: AttestationPredicateType,
+		Predicate: VerificationPredicate{
+			Log:            result.Endpoint,
+			TreeSize:       result.TreeSize,
+			SHA256RootHash: result.RootHash,
+			Checks:         checks,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal statement: %w", err)
+	}
+
+	sig, err := signer.Sign(preAuthEncoding(AttestationPayloadType, payload))
+	if err != nil {
+		return nil, fmt.Errorf("sign statement: %w", err)
+	}
+
+	return json.Marshal(AttestationEnvelope{ // nolint: wrapcheck
+		PayloadType: AttestationPayloadType,
+		Payload:     payload,
+		Signatures:  []AttestationSignature{{KeyID: signer.KeyID(), Sig: sig}},
+	})
+}
+
+// VerifyAttestation verifies the attestation produced by AttestVerification and returns its statement.
+// The attestation is accepted if at least one of its signatures is valid.
+func VerifyAttestation(attestation []byte, verifier SignatureVerifier) (*AttestationStatement, error) {
+	var envelope AttestationEnvelope
+
+	if err := json.Unmarshal(attestation, &envelope); err != nil {
+		return nil, fmt.Errorf("unmarshal envelope: %w", err)
+	}
+
+	if envelope.PayloadType != AttestationPayloadType {
+		return nil, fmt.Errorf("unsupported payload type %q", envelope.PayloadType)
+	}
+
+	if len(envelope.Signatures) == 0 {
+		return nil, errors.New("envelope has no signatures")
+	}
+
+	data := preAuthEncoding(envelope.PayloadType, envelope.Payload)
+
+	var err error
+
+	for _, sig := range envelope.Signatures {
+		if err = verifier.Verify(sig.KeyID, data, sig.Sig); err == nil {
+			break
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("verify signature: %w", err)
+	}
+
+	var statement *AttestationStatement
+
+	if err = json.Unmarshal(envelope.Payload, &statement); err != nil {
+		return nil, fmt.Errorf("unmarshal statement: %w", err)
+	}
+
+	if statement.Type != AttestationStatementType {
+		return nil, fmt.Errorf("unsupported statement type %q", statement.Type)
+	}
+
+	if statement.PredicateType != AttestationPredicateType {
+		return nil, fmt.Errorf("unsupported predicate type %q", statement.PredicateType)
+	}
+
+	return statement, nil
+}
+
+// preAuthEncoding returns the DSSE v1 pre-authentication encoding of the payload.
+func preAuthEncoding(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
@@ -0,0 +1,178 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+)
+
+//go:embed testdata/attestation.json
+var attestationGolden []byte // nolint: gochecknoglobals
+
+type ed25519Signer struct {
+	keyID string
+	key   ed25519.PrivateKey
+}
+
+func newEd25519Signer(keyID string, seed byte) *ed25519Signer {
+	return &ed25519Signer{
+		keyID: keyID,
+		key:   ed25519.NewKeyFromSeed(bytes.Repeat([]byte{seed}, ed25519.SeedSize)),
+	}
+}
+
+func (s *ed25519Signer) KeyID() string { return s.keyID }
+
+func (s *ed25519Signer) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, data), nil
+}
+
+func (s *ed25519Signer) Verify(keyID string, data, signature []byte) error {
+	if keyID != s.keyID {
+		return errors.New("unknown key")
+	}
+
+	if !ed25519.Verify(s.key.Public().(ed25519.PublicKey), data, signature) {
+		return errors.New("invalid signature")
+	}
+
+	return nil
+}
+
+type failingSigner struct{}
+
+func (failingSigner) KeyID() string { return "failing" }
+
+func (failingSigner) Sign([]byte) ([]byte, error) { return nil, errors.New("sign error") }
+
+func verificationResult() *vct.VerificationResult {
+	return &vct.VerificationResult{
+		CredentialID: "http://example.gov/credentials/789012",
+		Endpoint:     endpoint,
+		LeafHash:     []byte{0x01, 0x02, 0x03, 0x04},
+		TreeSize:     42,
+		RootHash:     []byte{0x0a, 0x0b, 0x0c, 0x0d},
+		Checks: []vct.CheckResult{
+			{Name: "sth_signature", Passed: true},
+			{Name: "inclusion", Passed: true},
+		},
+	}
+}
+
+func TestAttestVerification(t *testing.T) {
+	t.Run("Golden", func(t *testing.T) {
+		attestation, err := vct.AttestVerification(verificationResult(), newEd25519Signer("key-1", 1))
+		require.NoError(t, err)
+		require.JSONEq(t, string(attestationGolden), string(attestation))
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		signer := newEd25519Signer("key-1", 1)
+
+		attestation, err := vct.AttestVerification(verificationResult(), signer)
+		require.NoError(t, err)
+
+		statement, err := vct.VerifyAttestation(attestation, signer)
+		require.NoError(t, err)
+		require.Equal(t, vct.AttestationStatementType, statement.Type)
+		require.Equal(t, vct.AttestationPredicateType, statement.PredicateType)
+		require.Equal(t, []vct.AttestationSubject{{
+			Name:   "http://example.gov/credentials/789012",
+			Digest: map[string]string{vct.AttestationDigestAlgorithm: "01020304"},
+		}}, statement.Subject)
+		require.Equal(t, endpoint, statement.Predicate.Log)
+		require.Equal(t, uint64(42), statement.Predicate.TreeSize)
+		require.Equal(t, []byte{0x0a, 0x0b, 0x0c, 0x0d}, statement.Predicate.SHA256RootHash)
+		require.Equal(t, verificationResult().Checks, statement.Predicate.Checks)
+	})
+
+	t.Run("No result", func(t *testing.T) {
+		_, err := vct.AttestVerification(nil, newEd25519Signer("key-1", 1))
+		require.EqualError(t, err, "verification result is required")
+	})
+
+	t.Run("No signer", func(t *testing.T) {
+		_, err := vct.AttestVerification(verificationResult(), nil)
+		require.EqualError(t, err, "signer is required")
+	})
+
+	t.Run("Sign error", func(t *testing.T) {
+		_, err := vct.AttestVerification(verificationResult(), failingSigner{})
+		require.EqualError(t, err, "sign statement: sign error")
+	})
+}
+
+func TestVerifyAttestation(t *testing.T) {
+	signer := newEd25519Signer("key-1", 1)
+
+	attestation, err := vct.AttestVerification(verificationResult(), signer)
+	require.NoError(t, err)
+
+	t.Run("Wrong key", func(t *testing.T) {
+		_, err := vct.VerifyAttestation(attestation, newEd25519Signer("key-1", 2))
+		require.EqualError(t, err, "verify signature: invalid signature")
+	})
+
+	t.Run("Unknown key ID", func(t *testing.T) {
+		_, err := vct.VerifyAttestation(attestation, newEd25519Signer("key-2", 1))
+		require.EqualError(t, err, "verify signature: unknown key")
+	})
+
+	t.Run("Tampered payload", func(t *testing.T) {
+		var envelope vct.AttestationEnvelope
+		require.NoError(t, json.Unmarshal(attestation, &envelope))
+
+		envelope.Payload = bytes.Replace(envelope.Payload, []byte(`"tree_size":42`), []byte(`"tree_size":43`), 1)
+
+		tampered, err := json.Marshal(envelope)
+		require.NoError(t, err)
+
+		_, err = vct.VerifyAttestation(tampered, signer)
+		require.EqualError(t, err, "verify signature: invalid signature")
+	})
+
+	t.Run("Unsupported payload type", func(t *testing.T) {
+		var envelope vct.AttestationEnvelope
+		require.NoError(t, json.Unmarshal(attestation, &envelope))
+
+		envelope.PayloadType = "application/json"
+
+		data, err := json.Marshal(envelope)
+		require.NoError(t, err)
+
+		_, err = vct.VerifyAttestation(data, signer)
+		require.EqualError(t, err, `unsupported payload type "application/json"`)
+	})
+
+	t.Run("No signatures", func(t *testing.T) {
+		var envelope vct.AttestationEnvelope
+		require.NoError(t, json.Unmarshal(attestation, &envelope))
+
+		envelope.Signatures = nil
+
+		data, err := json.Marshal(envelope)
+		require.NoError(t, err)
+
+		_, err = vct.VerifyAttestation(data, signer)
+		require.EqualError(t, err, "envelope has no signatures")
+	})
+
+	t.Run("Malformed envelope", func(t *testing.T) {
+		_, err := vct.VerifyAttestation([]byte(`{`), signer)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal envelope")
+	})
+}
@@ -8,7 +8,12 @@ package vct
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -19,12 +24,14 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/trillian/merkle/rfc6962/hasher"
-	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
-	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	jsonld "github.com/piprate/json-gold/ld"
+	"golang.org/x/time/rate"
 
 	"github.com/trustbloc/vct/pkg/canonicalizer"
 	"github.com/trustbloc/vct/pkg/controller/command"
@@ -41,6 +48,34 @@ func WithHTTPClient(client HTTPClient) ClientOpt {
 	}
 }
 
+// WithTLSConfig sets the TLS configuration of the default HTTP client. It is ignored if an HTTP client is
+// provided with WithHTTPClient.
+func WithTLSConfig(config *tls.Config) ClientOpt {
+	return func(o *Client) {
+		o.tlsConfig = config
+	}
+}
+
+// WithTLSCertPool sets the root certificate authorities the default HTTP client verifies the certificate of
+// the log with, e.g. a private CA, instead of the system trust store. The pool is read once, when the client is
+// created: certificates added to or removed from the pool later, e.g. when it is reloaded, are not used by the
+// client; create a new client to pick them up. If the pool cannot be read, every request fails. It is ignored if
+// an HTTP client is provided with WithHTTPClient.
+func WithTLSCertPool(pool CertPool) ClientOpt {
+	return func(o *Client) {
+		o.tlsCertPool = pool
+	}
+}
+
+// WithInsecureSkipVerify makes the default HTTP client accept any certificate of the log, e.g. a self-signed
+// certificate of a local log. It is meant for development only: a warning is logged when the client is created,
+// see WithLogger, and it is ignored if an HTTP client is provided with WithHTTPClient.
+func WithInsecureSkipVerify() ClientOpt {
+	return func(o *Client) {
+		o.insecureSkipVerify = true
+	}
+}
+
 // WithAuthReadToken add auth token.
 func WithAuthReadToken(authToken string) ClientOpt {
 	return func(o *Client) {
@@ -48,320 +83,1907 @@ func WithAuthReadToken(authToken string) ClientOpt {
 	}
 }
 
-// WithAuthWriteToken add auth token.
-func WithAuthWriteToken(authToken string) ClientOpt {
-	return func(o *Client) {
-		o.authWriteToken = authToken
+// WithAuthWriteToken add auth token.
+func WithAuthWriteToken(authToken string) ClientOpt {
+	return func(o *Client) {
+		o.authWriteToken = authToken
+	}
+}
+
+// TokenSource returns the bearer token to authorize a request with, e.g. a fresh OAuth2 access token.
+type TokenSource func(ctx context.Context) (string, error)
+
+// WithTokenSource sets the source of the bearer tokens of all requests. The source is called before each request
+// is sent, and takes precedence over the tokens set with WithAuthReadToken and WithAuthWriteToken.
+func WithTokenSource(source TokenSource) ClientOpt {
+	return func(o *Client) {
+		o.readTokenSource = source
+		o.writeTokenSource = source
+	}
+}
+
+// WithReadTokenSource sets the source of the bearer tokens of read requests, see WithTokenSource.
+func WithReadTokenSource(source TokenSource) ClientOpt {
+	return func(o *Client) {
+		o.readTokenSource = source
+	}
+}
+
+// WithWriteTokenSource sets the source of the bearer tokens of write requests, e.g. AddVC, see WithTokenSource.
+func WithWriteTokenSource(source TokenSource) ClientOpt {
+	return func(o *Client) {
+		o.writeTokenSource = source
+	}
+}
+
+// TokenSelector returns the bearer token to authorize the requests of the given client method with, an empty token
+// sends the request without authorization. The method is the name of the client method that sends the request,
+// as passed to MetricsRecorder: AddVC, AddVP, AddVCBatch, ValidateVC, GetSTH, GetSTHConsistency, GetProofByHash,
+// GetEntries, GetIssuers, GetRoots, GetAcceptedContexts, GetLogInfo, GetEntryAndProof or Webfinger. Methods built
+// on top of those, e.g. GetPublicKey or GetProofsByHashes, pass the name of the method they send the requests with.
+type TokenSelector func(method string) string
+
+// WithTokenSelector sets the selector of the bearer token of each request, e.g. to send the write token with
+// Webfinger requests. The selector overrides the built-in mapping of the read and write tokens to the requests,
+// and takes precedence over the tokens set with WithAuthReadToken, WithAuthWriteToken and the token sources.
+func WithTokenSelector(selector TokenSelector) ClientOpt {
+	return func(o *Client) {
+		o.tokenSelector = selector
+	}
+}
+
+// WithLedgerURI sets the ledger URI. By default, the ledger URI is set to the
+// endpoint URL.
+func WithLedgerURI(ledgerURI string) ClientOpt {
+	return func(o *Client) {
+		o.ledgerURI = ledgerURI
+	}
+}
+
+// WithWebfingerURL sets the absolute URL the webfinger request is sent to, e.g. when a gateway serves the
+// webfinger endpoint on another host than the log API. By default, the webfinger request is sent to
+// /.well-known/webfinger on the host of the endpoint. An override that is not an absolute URL fails Webfinger.
+func WithWebfingerURL(webfingerURL string) ClientOpt {
+	return func(o *Client) {
+		o.webfingerURL = webfingerURL
+	}
+}
+
+// WithMaxAuditPathLength sets the maximum audit path length accepted in proof responses. Responses with
+// longer audit paths are rejected with a DecodeError before they reach verification. By default, the maximum
+// is 64 which covers any tree whose size fits in uint64.
+func WithMaxAuditPathLength(length int) ClientOpt {
+	return func(o *Client) {
+		o.maxAuditPathLength = length
+	}
+}
+
+// WithDIDResourceResolver sets the resolver used to resolve DID-linked resources.
+func WithDIDResourceResolver(resolver DIDResourceResolver) ClientOpt {
+	return func(o *Client) {
+		o.didResourceResolver = resolver
+	}
+}
+
+// WithDetectErrorInSuccessBody enables detection of error responses returned with status 200, e.g. by
+// misconfigured gateways. Such a body is treated as an error if it is an error response with a non-empty
+// message and does not match the expected response. Disabled by default to avoid false positives.
+func WithDetectErrorInSuccessBody() ClientOpt {
+	return func(o *Client) {
+		o.detectErrorInSuccessBody = true
+	}
+}
+
+// WithoutClientValidation disables the validation of the arguments of the calls before the requests are sent,
+// e.g. of the tree sizes of GetSTHConsistency, so that the log can be probed with arguments it should reject,
+// and of the responses against the arguments, e.g. of the number of entries returned by GetEntries.
+func WithoutClientValidation() ClientOpt {
+	return func(o *Client) {
+		o.skipValidation = true
+	}
+}
+
+// WithIssuerAllowlist sets the issuers accepted by VerifyCredential. The issuer of a verified credential
+// must be in the allowlist, otherwise the issuer check fails.
+func WithIssuerAllowlist(issuers []string) ClientOpt {
+	return func(o *Client) {
+		o.issuerAllowlist = issuers
+	}
+}
+
+// WithIssuerAllowlistFromLog makes VerifyCredential fetch the issuers currently accepted by the log and add
+// them to the issuer allowlist. A log that returns no issuers accepts credentials from any issuer; in that
+// case only the issuers set by WithIssuerAllowlist (if any) are enforced.
+func WithIssuerAllowlistFromLog() ClientOpt {
+	return func(o *Client) {
+		o.issuerAllowlistFromLog = true
+	}
+}
+
+// WithRetry enables retries of AddVC and of the read-only calls when the log responds with 429, 502, 503 or 504
+// or the request times out. Up to maxAttempts attempts are made, with a jittered exponential backoff starting
+// at baseDelay between them; if the log responds with a Retry-After header, the delay it asks for is waited for
+// instead, and the request is not retried if that delay exceeds a minute. Requests are not retried once the
+// context is done, and other client errors (4xx) are never retried. If a request still fails after being
+// retried, a RetryError with the number of attempts made is returned. The backoff can be replaced with
+// WithBackoff.
+func WithRetry(maxAttempts int, baseDelay time.Duration) ClientOpt {
+	return func(o *Client) {
+		o.retry = &retryPolicy{maxAttempts: maxAttempts, backoff: ExponentialBackoff{Base: baseDelay}}
+	}
+}
+
+// WithKeyResolver sets the resolver of the public key of a log that advertises it as a DID in its webfinger
+// document, e.g. DIDKeyResolver for did:key, instead of the base64 encoded key. The key is resolved by
+// GetPublicKey and wherever the client retrieves the public key of the log. Without a resolver, only keys
+// advertised as base64 are supported.
+func WithKeyResolver(resolver KeyResolver) ClientOpt {
+	return func(o *Client) {
+		o.keyResolver = resolver
+	}
+}
+
+// WithBackoff sets the backoff between the attempts of retried requests, e.g. ConstantBackoff for fixed
+// intervals, instead of the exponential backoff of WithRetry. A Retry-After header of the log still takes
+// precedence, and the wait ends early when the context is done. It has no effect unless WithRetry is used.
+func WithBackoff(backoff Backoff) ClientOpt {
+	return func(o *Client) {
+		o.backoff = backoff
+	}
+}
+
+// WithRateLimit limits the requests sent to the log to rps requests per second, with bursts of up to burst
+// requests. Every request, including every retried attempt, waits for the limiter before it is sent; the wait
+// ends early with an error when the context is done.
+func WithRateLimit(rps float64, burst int) ClientOpt {
+	return func(o *Client) {
+		o.limiter = rate.NewLimiter(rate.Limit(rps), burst)
+	}
+}
+
+// WithRequestIDFromContext sets the extractor of the request ID from the context of a request, e.g. the ID the
+// caller's platform assigned to the request being served. A non-empty request ID is sent with the X-Request-ID
+// header and added to the debug logs of the client, see WithLogger. By default, no request ID is sent.
+func WithRequestIDFromContext(extractor func(ctx context.Context) string) ClientOpt {
+	return func(o *Client) {
+		o.requestID = extractor
+	}
+}
+
+// WithTimeout sets the default timeout of every request sent to the log. The timeout bounds each attempt of
+// a request, so that callers passing a context without deadline still get a bounded request. A deadline of
+// the context passed by the caller that is earlier than the timeout takes precedence.
+func WithTimeout(timeout time.Duration) ClientOpt {
+	return func(o *Client) {
+		o.timeout = timeout
+	}
+}
+
+// WithCompression makes the client accept gzip compressed responses, which are decompressed transparently.
+// Responses of logs that ignore the Accept-Encoding header are read as they are.
+func WithCompression() ClientOpt {
+	return func(o *Client) {
+		o.compression = true
+	}
+}
+
+// WithRequestCompression makes the client gzip compress the body of requests, e.g. the credentials sent with
+// AddVC, and set the Content-Encoding header accordingly. Only use it with logs that support compressed requests.
+func WithRequestCompression() ClientOpt {
+	return func(o *Client) {
+		o.requestCompression = true
+	}
+}
+
+// WithVerifySCT makes AddVC verify the signature of the timestamp returned by the log with the public key the
+// log advertises, so that a forged timestamp is rejected. The loader is used to canonicalize the credential.
+// The public key is retrieved with PublicKey.
+func WithVerifySCT(loader jsonld.DocumentLoader) ClientOpt {
+	return func(o *Client) {
+		o.sctLoader = loader
+	}
+}
+
+// WithRequireProof makes AddVC, AddVCWithResponse, AddVCRaw and AddCredential check that the credential is signed
+// before it is sent, so that an unsigned credential, which cannot be verified once logged, is not added by
+// mistake. An error matching ErrNoProof is returned, without sending the request, unless the credential has a
+// proof with a type and a signature, i.e. a "proofValue", "jws" or "signatureValue"; empty proof objects, e.g.
+// placeholders, do not count. A JWT credential must have a signature. The proof is not verified. Disabled by
+// default, as some callers log unsigned data on purpose.
+func WithRequireProof() ClientOpt {
+	return func(o *Client) {
+		o.requireProof = true
+	}
+}
+
+// WithLeafHasher sets the hasher of Merkle leaves, for logs that are configured with another leaf encoding than
+// the VCT server, e.g. another hash algorithm or domain separation prefix. It is used wherever the client
+// calculates the leaf hash of a credential or of an entry, e.g. GetProofByCredential, VerifyCredential,
+// VerifyInclusionByCredential, GetVerifiedEntry, ReconstructAndCompareSTH and the ContinuousSampler. Inclusion
+// proofs are still verified with RFC 6962 node hashing. By default, RFC6962LeafHasher is used.
+func WithLeafHasher(leafHasher LeafHasher) ClientOpt {
+	return func(o *Client) {
+		o.leafHasher = leafHasher
+	}
+}
+
+// WithSTHCacheTTL makes the client cache the signed tree head retrieved by TreeSize for the given duration, and
+// check the arguments of GetEntries, GetProofByHash and GetEntryAndProof against the tree size of the log before
+// the request is sent. Arguments out of range are rejected with an error matching ErrOutOfRange; the signed tree
+// head is retrieved again before rejecting an argument beyond a cached tree size, as the log may have grown since.
+// Disabled by default, in which case the arguments are checked by the log only.
+func WithSTHCacheTTL(ttl time.Duration) ClientOpt {
+	return func(o *Client) {
+		o.sthCacheTTL = ttl
+	}
+}
+
+// WithH2C makes the default HTTP client speak HTTP/2 over cleartext TCP (h2c) with prior knowledge to http://
+// endpoints, e.g. a log deployed behind an internal load balancer that only serves h2c; https:// endpoints are
+// not affected. h2c is neither encrypted nor authenticated: requests, including the Authorization header, and
+// responses can be read and tampered with by anyone on the network path, so it must only be used on trusted
+// networks. It is ignored if an HTTP client is provided with WithHTTPClient.
+func WithH2C() ClientOpt {
+	return func(o *Client) {
+		o.h2c = true
+	}
+}
+
+// WithMaxIdleConnsPerHost sets the maximum number of idle connections to the log the default HTTP client keeps
+// for reuse. It defaults to 100 and is ignored if an HTTP client is provided with WithHTTPClient.
+func WithMaxIdleConnsPerHost(n int) ClientOpt {
+	return func(o *Client) {
+		o.maxIdleConnsPerHost = n
+	}
+}
+
+// WithMaxResponseBytes sets the maximum size of a response body, after decompression. Responses with larger
+// bodies fail with ErrResponseTooLarge. It defaults to 32 MiB, a limit of zero or less disables the check.
+func WithMaxResponseBytes(n int64) ClientOpt {
+	return func(o *Client) {
+		o.maxResponseBytes = n
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request.
+func WithUserAgent(userAgent string) ClientOpt {
+	return WithHeader("User-Agent", userAgent)
+}
+
+// WithHeader adds the header to every request; it may be repeated to add several headers, or several values of
+// a header. The headers the client manages, e.g. Authorization, take precedence over headers added this way.
+func WithHeader(key, value string) ClientOpt {
+	return func(o *Client) {
+		if o.headers == nil {
+			o.headers = http.Header{}
+		}
+
+		o.headers.Add(key, value)
+	}
+}
+
+// CertPool provides the root certificate authorities of the log, see WithTLSCertPool.
+type CertPool interface {
+	Get() (*x509.CertPool, error)
+}
+
+// HTTPClient represents HTTP client.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client represents VCT REST client. Client is safe for concurrent use by multiple goroutines; a single client
+// should be shared so that connections to the log are reused.
+type Client struct {
+	endpoint       string
+	ledgerURI      string
+	webfingerURL   string
+	http           HTTPClient
+	authReadToken  string
+	authWriteToken string
+
+	maxAuditPathLength       int
+	didResourceResolver      DIDResourceResolver
+	detectErrorInSuccessBody bool
+	skipValidation           bool
+	requireProof             bool
+	issuerAllowlist          []string
+	issuerAllowlistFromLog   bool
+	retry                    *retryPolicy
+	backoff                  Backoff
+	keyResolver              KeyResolver
+	limiter                  *rate.Limiter
+	timeout                  time.Duration
+	tlsConfig                *tls.Config
+	tlsCertPool              CertPool
+	insecureSkipVerify       bool
+	compression              bool
+	requestCompression       bool
+	sctLoader                jsonld.DocumentLoader
+	maxIdleConnsPerHost      int
+	metrics                  MetricsRecorder
+	logger                   Logger
+	tracing                  bool
+	headers                  http.Header
+	requestID                func(ctx context.Context) string
+	readTokenSource          TokenSource
+	writeTokenSource         TokenSource
+	tokenSelector            TokenSelector
+	sthCacheTTL              time.Duration
+	keyCacheTTL              time.Duration
+	maxResponseBytes         int64
+	leafHasher               LeafHasher
+	h2c                      bool
+	transport                idleConnectionsCloser
+	closed                   int32
+
+	keyCache keyCache
+
+	sthMu        sync.Mutex
+	sth          *command.GetSTHResponse
+	sthFetchedAt time.Time
+}
+
+const (
+	defaultMaxAuditPathLength  = 64
+	defaultMaxIdleConnsPerHost = 100
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultMaxResponseBytes    = 32 << 20
+	maxErrorSnippetLength      = 256
+)
+
+// New returns VCT REST client.
+func New(endpoint string, opts ...ClientOpt) *Client {
+	defaultHTTPClient := &http.Client{
+		Timeout: time.Minute,
+	}
+
+	c := &Client{
+		endpoint:            endpoint,
+		ledgerURI:           endpoint,
+		http:                defaultHTTPClient,
+		maxAuditPathLength:  defaultMaxAuditPathLength,
+		maxIdleConnsPerHost: defaultMaxIdleConnsPerHost,
+		maxResponseBytes:    defaultMaxResponseBytes,
+		leafHasher:          RFC6962LeafHasher{},
+		metrics:             noopMetricsRecorder{},
+		logger:              noopLogger{},
+	}
+
+	for _, fn := range opts {
+		fn(c)
+	}
+
+	if c.retry != nil && c.backoff != nil {
+		c.retry.backoff = c.backoff
+	}
+
+	if c.http == defaultHTTPClient {
+		transport := c.defaultTransport()
+
+		if c.h2c {
+			transport = newH2CTransport(transport)
+		}
+
+		defaultHTTPClient.Transport = transport
+		c.transport, _ = transport.(idleConnectionsCloser)
+	}
+
+	return c
+}
+
+// Close releases the resources of the client, i.e. closes the idle connections of the transport the client
+// created; it is a no-op for the HTTP client set with WithHTTPClient, which the caller owns. Close is safe to
+// call once, the client is unusable afterwards: requests fail with ErrClientClosed. A Monitor or
+// ContinuousSampler of the client is not stopped by Close, but by canceling the context passed to its Run.
+func (c *Client) Close() error {
+	if !atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		return nil
+	}
+
+	if c.transport != nil {
+		c.transport.CloseIdleConnections()
+	}
+
+	return nil
+}
+
+// defaultTransport returns the transport of the default HTTP client, which keeps idle connections to the log
+// for reuse and is configured with the TLS options.
+func (c *Client) defaultTransport() http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone() // nolint: errcheck
+	transport.MaxIdleConnsPerHost = c.maxIdleConnsPerHost
+	transport.IdleConnTimeout = defaultIdleConnTimeout
+
+	if c.tlsConfig == nil && c.tlsCertPool == nil && !c.insecureSkipVerify {
+		return transport
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.tlsConfig != nil {
+		config = c.tlsConfig.Clone()
+	}
+
+	if c.tlsCertPool != nil {
+		rootCAs, err := c.tlsCertPool.Get()
+		if err != nil {
+			c.logger.Errorf("get TLS cert pool: %v", err)
+
+			// Fail every request rather than falling back to a trust store the caller did not ask for.
+			return failingTransport{err: fmt.Errorf("get TLS cert pool: %w", err)}
+		}
+
+		config.RootCAs = rootCAs
+	}
+
+	if c.insecureSkipVerify {
+		c.logger.Warnf("TLS certificate verification of the log %s is disabled, do not use in production", c.endpoint)
+
+		config.InsecureSkipVerify = true // nolint: gosec
+	}
+
+	transport.TLSClientConfig = config
+
+	return transport
+}
+
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// AddVC adds verifiable credential to log. The body is gzip compressed with WithRequestCompression, see AddVCRaw
+// to send it verbatim.
+func (c *Client) AddVC(ctx context.Context, credential []byte) (*command.AddVCResponse, error) {
+	return c.addVC(ctx, credential)
+}
+
+// AddVCWithResponse adds verifiable credential to log like AddVC, and also returns the header of the log
+// response, e.g. to read the rate limit headers. The header is returned with the error too if the log responded,
+// e.g. with 429 Too Many Requests; it is nil if no response was received.
+func (c *Client) AddVCWithResponse(ctx context.Context, credential []byte) (*command.AddVCResponse, http.Header,
+	error) {
+	var header http.Header
+
+	resp, err := c.addVC(ctx, credential, withResponseHeader(&header))
+
+	return resp, header, err
+}
+
+// AddVCRaw adds verifiable credential to log like AddVC, but sends the given bytes verbatim: unlike AddVC, the
+// body is never compressed, even with WithRequestCompression, so that the log receives exactly these bytes.
+//
+// Sending the bytes verbatim does not make the log hash them: the log parses the credential and hashes the leaf
+// of its canonical form, see CanonicalizeForLog. The canonical form can be computed and stored before the
+// credential is added, and the leaf hash is calculated from it with CalculateLeafHashFromCanonical once the log
+// returns the timestamp of the entry.
+func (c *Client) AddVCRaw(ctx context.Context, canonical []byte) (*command.AddVCResponse, error) {
+	return c.addVC(ctx, canonical, withVerbatimBody())
+}
+
+func (c *Client) addVC(ctx context.Context, credential []byte, opts ...opt) (*command.AddVCResponse, error) {
+	if c.requireProof {
+		if err := checkProof(credential); err != nil {
+			return nil, fmt.Errorf("add VC: %w", err)
+		}
+	}
+
+	opts = append([]opt{withMethod(http.MethodPost), withBody(credential), c.withWriteToken(), withRetryable()},
+		opts...)
+
+	var result *command.AddVCResponse
+	if err := c.do(ctx, rest.AddVCPath, &result, opts...); err != nil {
+		return nil, fmt.Errorf("add VC: %w", err)
+	}
+
+	if c.sctLoader != nil {
+		if err := c.verifySCT(ctx, result, credential); err != nil {
+			return nil, fmt.Errorf("add VC: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// ValidateVC checks that the log would add the verifiable credential, without adding it: the log runs the checks
+// it runs for AddVC, i.e. it parses the credential and checks its proofs, checks that its issuer is accepted and
+// that its contexts can be resolved, but does not append it. A rejected credential is reported like by AddVC,
+// e.g. with an error matching ErrBadRequest.
+//
+// A successful validation does not guarantee that the credential is added later: the issuers or the contexts
+// accepted by the log may change in between. Like AddVC, the request is authorized with the write token.
+func (c *Client) ValidateVC(ctx context.Context, credential []byte) error {
+	var result *command.ValidateVCResponse
+
+	err := c.do(ctx, rest.ValidateVCPath, &result, withMethod(http.MethodPost), withBody(credential),
+		c.withWriteToken(), withRetryable())
+	if err != nil {
+		return fmt.Errorf("validate VC: %w", err)
+	}
+
+	return nil
+}
+
+// AddCredential adds verifiable credential to log. The credential is sent as its JCS (RFC 8785) canonical JSON,
+// so that the request does not depend on how the credential was marshalled. The log does not hash these bytes:
+// the entry holds the canonical form of the credential without its proof, see CanonicalizeForLog, and the leaf
+// hash of the entry is calculated with CalculateLeafHash from the credential and the timestamp of the response.
+func (c *Client) AddCredential(ctx context.Context, vc *verifiable.Credential) (*command.AddVCResponse, error) {
+	if vc == nil {
+		return nil, errors.New("add credential: credential is nil")
+	}
+
+	credential, err := canonicalizer.MarshalCanonical(vc)
+	if err != nil {
+		return nil, fmt.Errorf("add credential: marshal canonical: %w", err)
+	}
+
+	return c.AddVC(ctx, credential)
+}
+
+// checkProof returns an error matching ErrNoProof if the credential, a JSON-LD credential or a JWT, is not signed.
+func checkProof(credential []byte) error {
+	credential = bytes.TrimSpace(credential)
+
+	if len(credential) > 0 && credential[0] != '{' {
+		var jwt string
+		if err := json.Unmarshal(credential, &jwt); err != nil {
+			jwt = string(credential)
+		}
+
+		if parts := strings.Split(jwt, "."); len(parts) != 3 || parts[2] == "" {
+			return fmt.Errorf("%w: JWT has no signature", ErrNoProof)
+		}
+
+		return nil
+	}
+
+	var vc struct {
+		Proof json.RawMessage `json:"proof"`
+	}
+
+	if err := json.Unmarshal(credential, &vc); err != nil {
+		return fmt.Errorf("parse credential: %w", err)
+	}
+
+	var proofs []map[string]interface{}
+
+	// The proof is a single object or an array of them.
+	if err := json.Unmarshal(vc.Proof, &proofs); err != nil {
+		var proof map[string]interface{}
+		if json.Unmarshal(vc.Proof, &proof) == nil {
+			proofs = append(proofs, proof)
+		}
+	}
+
+	for _, proof := range proofs {
+		if proofType, _ := proof["type"].(string); proofType == "" {
+			continue
+		}
+
+		for _, key := range []string{"proofValue", "jws", "signatureValue"} {
+			if value, _ := proof[key].(string); value != "" {
+				return nil
+			}
+		}
+	}
+
+	return ErrNoProof
+}
+
+func (c *Client) verifySCT(ctx context.Context, resp *command.AddVCResponse, credential []byte) error {
+	pubKey, err := c.PublicKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	extensions, err := decodeExtensions(resp.Extensions)
+	if err == nil {
+		err = VerifyVCTimestampSignatureWithExtensions(resp.Signature, pubKey, resp.Timestamp, credential, extensions,
+			c.sctLoader)
+	}
+
+	if err != nil {
+		return &VerificationError{Check: CheckSCTSignature, Err: err}
+	}
+
+	return nil
+}
+
+// AddVP adds verifiable presentation to log. The log stores the presentation in an entry of the VPLogEntryType
+// type, see CalculateVPLeafHash for its leaf hash. With WithVerifySCT, the signed timestamp of the response is
+// verified like the one of AddVC.
+func (c *Client) AddVP(ctx context.Context, presentation []byte) (*command.AddVCResponse, error) {
+	var result *command.AddVCResponse
+	if err := c.do(ctx, rest.AddVPPath, &result, withMethod(http.MethodPost), withBody(presentation),
+		c.withWriteToken(), withRetryable()); err != nil {
+		return nil, fmt.Errorf("add VP: %w", err)
+	}
+
+	if c.sctLoader != nil {
+		pubKey, err := c.PublicKey(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("add VP: %w", err)
+		}
+
+		leaf, err := command.CreateVPLeaf(result.Timestamp, presentation, c.sctLoader)
+		if err != nil {
+			return nil, fmt.Errorf("add VP: create leaf: %w", err)
+		}
+
+		if err = verifyTimestampSignature(result.Signature, pubKey, leaf); err != nil {
+			return nil, fmt.Errorf("add VP: %w", &VerificationError{Check: CheckSCTSignature, Err: err})
+		}
+	}
+
+	return result, nil
+}
+
+// AddVCBatch adds verifiable credentials to log in a single request. The results are aligned by index with
+// the credentials; a credential that could not be added has the error set in its result, see BatchResultError.
+func (c *Client) AddVCBatch(ctx context.Context, credentials [][]byte) ([]*command.AddVCBatchResult, error) {
+	vcEntries := make([]json.RawMessage, len(credentials))
+	for i, credential := range credentials {
+		vcEntries[i] = credential
+	}
+
+	body, err := json.Marshal(vcEntries)
+	if err != nil {
+		return nil, fmt.Errorf("add VC batch: marshal credentials: %w", err)
+	}
+
+	var result *command.AddVCBatchResponse
+	if err = c.do(ctx, rest.AddVCBatchPath, &result, withMethod(http.MethodPost), withBody(body),
+		c.withWriteToken()); err != nil {
+		return nil, fmt.Errorf("add VC batch: %w", err)
+	}
+
+	if result == nil || len(result.Results) != len(credentials) {
+		return nil, fmt.Errorf("add VC batch: %w", &DecodeError{
+			Field: "results",
+			Err:   fmt.Errorf("expected %d results", len(credentials)),
+		})
+	}
+
+	return result.Results, nil
+}
+
+// BatchResultError returns the error of a result of AddVCBatch as an Error with the status code add-vc would have
+// responded with, so it matches the sentinel errors, e.g. errors.Is(err, ErrConflict) for a duplicate credential.
+// It returns nil if the credential was added.
+func BatchResultError(result *command.AddVCBatchResult) error {
+	if result == nil || result.Error == "" {
+		return nil
+	}
+
+	return &Error{StatusCode: result.StatusCode, Op: "AddVCBatch", Message: result.Error}
+}
+
+// HealthCheck check health.
+func (c *Client) HealthCheck(ctx context.Context) error {
+	_, err := c.healthStatus(ctx, rest.HealthCheckPath)
+
+	// The detail is discarded, so a log responding with a body other than the health response is healthy.
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		return nil
+	}
+
+	return err
+}
+
+// HealthStatus returns the health status of the log, including the status and the size of the tree of the log of
+// the client. An error is returned if the log is not healthy, as with HealthCheck. Unlike HealthCheck, the request
+// is authorized with the read token.
+func (c *Client) HealthStatus(ctx context.Context) (*command.HealthResponse, error) {
+	result, err := c.healthStatus(ctx, rest.LogHealthCheckPath, c.withReadToken())
+	if err != nil {
+		return nil, fmt.Errorf("health status: %w", err)
+	}
+
+	return result, nil
+}
+
+func (c *Client) healthStatus(ctx context.Context, path string, opts ...opt) (*command.HealthResponse, error) {
+	var body []byte
+
+	if err := c.do(ctx, path, nil, append(opts, withRawResponse(&body))...); err != nil {
+		return nil, err
+	}
+
+	result := &command.HealthResponse{}
+
+	// Logs of previous versions respond with other fields, which are left unset.
+	if err := newDecoder(bytes.NewReader(body)).Decode(result); err != nil {
+		return nil, &DecodeError{Field: "body", Err: err}
+	}
+
+	return result, nil
+}
+
+// Webfinger returns discovery info, see WithWebfingerURL for where it is requested from.
+func (c *Client) Webfinger(ctx context.Context) (*command.WebFingerResponse, error) {
+	const resourceParamName = "resource"
+
+	opts := []opt{withValueAdd(resourceParamName, c.ledgerURI)}
+
+	if c.webfingerURL != "" {
+		opts = append(opts, withURL(c.webfingerURL))
+	}
+
+	var result *command.WebFingerResponse
+	if err := c.do(ctx, rest.WebfingerPath, &result, opts...); err != nil {
+		return nil, fmt.Errorf("webfinger: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetPublicKey returns the public key of the log advertised by its webfinger document. The key is in the format
+// expected by VerifyVCTimestampSignature, e.g. DER for ECDSA keys. With WithKeyResolver, a log that advertises
+// its key as a DID is supported too.
+func (c *Client) GetPublicKey(ctx context.Context) ([]byte, error) {
+	resp, err := c.Webfinger(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get public key: %w", err)
+	}
+
+	if c.keyResolver != nil {
+		if did, ok := resp.Properties[command.PublicKeyType].(string); ok && strings.HasPrefix(did, "did:") {
+			pubKey, resolveErr := c.keyResolver.ResolveKey(ctx, did)
+			if resolveErr != nil {
+				return nil, fmt.Errorf("get public key: resolve key: %w", resolveErr)
+			}
+
+			return pubKey, nil
+		}
+	}
+
+	pubKey, err := publicKeyFromWebfinger(resp)
+	if err != nil {
+		return nil, fmt.Errorf("get public key: %w", err)
+	}
+
+	return pubKey, nil
+}
+
+func publicKeyFromWebfinger(resp *command.WebFingerResponse) ([]byte, error) {
+	if resp == nil {
+		return nil, errors.New("empty webfinger response")
+	}
+
+	return resp.PublicKey() // nolint: wrapcheck
+}
+
+// GetIssuers returns issuers.
+func (c *Client) GetIssuers(ctx context.Context) ([]string, error) {
+	var result []string
+	if err := c.do(ctx, rest.GetIssuersPath, &result, c.withReadToken()); err != nil {
+		return nil, fmt.Errorf("get issuers: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetRoots returns the trust anchors of the issuers the log accepts, e.g. DER encoded certificates or DID
+// documents, so that a submitter can check whether the issuer of a credential will be accepted before adding it.
+func (c *Client) GetRoots(ctx context.Context) ([][]byte, error) {
+	var result *command.GetRootsResponse
+	if err := c.do(ctx, rest.GetRootsPath, &result, c.withReadToken()); err != nil {
+		return nil, fmt.Errorf("get roots: %w", err)
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("get roots: %w", &DecodeError{Field: "body", Err: errors.New("empty response")})
+	}
+
+	return result.Certificates, nil
+}
+
+// GetAcceptedContexts returns the URLs of the JSON-LD contexts the log is provisioned with, so that a submitter
+// can check whether the contexts of a credential will be recognized before adding it. Logs that cannot list
+// their contexts respond with an error matching ErrNotFound.
+func (c *Client) GetAcceptedContexts(ctx context.Context) ([]string, error) {
+	var result []string
+	if err := c.do(ctx, rest.GetAcceptedContextsPath, &result, c.withReadToken()); err != nil {
+		return nil, fmt.Errorf("get accepted contexts: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetLogInfo returns the metadata of the log: the version of its software, the hash algorithm of its tree, the
+// maximum number of entries it returns with a get-entries request and the optional features it supports, e.g. to
+// size the pages of GetEntries or to check LogInfoResponse.HasFeature(command.FeatureAddVCBatch) before AddVCBatch.
+// Logs that predate the endpoint respond with an error matching ErrNotFound.
+func (c *Client) GetLogInfo(ctx context.Context) (*command.LogInfoResponse, error) {
+	var result *command.LogInfoResponse
+	if err := c.do(ctx, rest.GetLogInfoPath, &result, c.withReadToken()); err != nil {
+		return nil, fmt.Errorf("get log info: %w", err)
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("get log info: %w", &DecodeError{Field: "body", Err: errors.New("empty response")})
+	}
+
+	return result, nil
+}
+
+// GetSTH retrieves latest signed tree head.
+func (c *Client) GetSTH(ctx context.Context) (*command.GetSTHResponse, error) {
+	var result *command.GetSTHResponse
+	if err := c.do(ctx, rest.GetSTHPath, &result, c.withReadToken()); err != nil {
+		return nil, fmt.Errorf("get STH: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetSTHIfChanged retrieves the latest signed tree head if its tree size differs from the last seen tree size,
+// e.g. when polling the log. It returns nil and false if the tree size is unchanged. The log is asked not to send
+// an unchanged signed tree head with the If-None-Match header; the signed tree head sent by a log that does not
+// support it is compared by the client.
+func (c *Client) GetSTHIfChanged(ctx context.Context, lastTreeSize uint64) (*command.GetSTHResponse, bool, error) {
+	var result *command.GetSTHResponse
+
+	err := c.do(ctx, rest.GetSTHPath, &result, c.withReadToken(),
+		withHeader("If-None-Match", rest.TreeSizeETag(lastTreeSize)))
+	if errors.Is(err, errNotModified) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, fmt.Errorf("get STH if changed: %w", err)
+	}
+
+	if result == nil {
+		return nil, false, fmt.Errorf("get STH if changed: %w", &DecodeError{
+			Field: "body",
+			Err:   errors.New("empty response"),
+		})
+	}
+
+	if result.TreeSize == lastTreeSize {
+		return nil, false, nil
+	}
+
+	return result, true, nil
+}
+
+// TreeSize returns the tree size of the latest signed tree head. The signed tree head is cached if enabled with
+// WithSTHCacheTTL.
+func (c *Client) TreeSize(ctx context.Context) (uint64, error) {
+	sth, _, err := c.cachedSTH(ctx, false)
+	if err != nil {
+		return 0, fmt.Errorf("tree size: %w", err)
+	}
+
+	return sth.TreeSize, nil
+}
+
+// cachedSTH returns the cached signed tree head unless it has expired or refresh is set, in which case the latest
+// signed tree head is retrieved; fetched reports whether it was.
+func (c *Client) cachedSTH(ctx context.Context, refresh bool) (sth *command.GetSTHResponse, fetched bool, err error) {
+	c.sthMu.Lock()
+	defer c.sthMu.Unlock()
+
+	if !refresh && c.sth != nil && time.Since(c.sthFetchedAt) < c.sthCacheTTL {
+		return c.sth, false, nil
+	}
+
+	sth, err = c.GetSTH(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if sth == nil {
+		return nil, false, fmt.Errorf("get STH: %w", &DecodeError{Field: "body", Err: errors.New("empty response")})
+	}
+
+	c.sth = sth
+	c.sthFetchedAt = time.Now()
+
+	return sth, true, nil
+}
+
+// checkTreeSize checks that the log has a tree of at least the given size, if enabled with WithSTHCacheTTL.
+func (c *Client) checkTreeSize(ctx context.Context, treeSize uint64) error {
+	if c.sthCacheTTL <= 0 {
+		return nil
+	}
+
+	sth, fetched, err := c.cachedSTH(ctx, false)
+	if err == nil && !fetched && treeSize > sth.TreeSize {
+		sth, _, err = c.cachedSTH(ctx, true)
+	}
+
+	if err != nil {
+		return fmt.Errorf("check tree size: %w", err)
+	}
+
+	if treeSize > sth.TreeSize {
+		return fmt.Errorf("%w: tree size %d exceeds tree size %d of the log", ErrOutOfRange, treeSize, sth.TreeSize)
+	}
+
+	return nil
+}
+
+// checkLeafIndex checks that the leaf index is in the tree of the given size, and that the log has a tree of that
+// size, if enabled with WithSTHCacheTTL.
+func (c *Client) checkLeafIndex(ctx context.Context, leafIndex, treeSize uint64) error {
+	if c.sthCacheTTL <= 0 {
+		return nil
+	}
+
+	if leafIndex >= treeSize {
+		return fmt.Errorf("%w: leaf index %d is not in tree of size %d", ErrOutOfRange, leafIndex, treeSize)
+	}
+
+	return c.checkTreeSize(ctx, treeSize)
+}
+
+// GetSTHConsistency retrieves merkle consistency proofs between signed tree heads. The first tree size must not be
+// zero nor greater than the second one, otherwise ErrInvalidRange is returned without sending the request.
+func (c *Client) GetSTHConsistency(ctx context.Context, first, second uint64) (*command.GetSTHConsistencyResponse, error) { // nolint: lll
+	const (
+		firstParamName  = "first"
+		secondParamName = "second"
+	)
+
+	if !c.skipValidation && (first == 0 || first > second) {
+		return nil, fmt.Errorf("get STH consistency: %w: first tree size %d is not in range [1, %d]",
+			ErrInvalidRange, first, second)
+	}
+
+	opts := []opt{
+		withValueAdd(firstParamName, strconv.FormatUint(first, 10)),
+		withValueAdd(secondParamName, strconv.FormatUint(second, 10)),
+		c.withReadToken(),
+	}
+
+	var result *command.GetSTHConsistencyResponse
+	if err := c.do(ctx, rest.GetSTHConsistencyPath, &result, opts...); err != nil {
+		return nil, fmt.Errorf("get STH consistency: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetProofByHash retrieves Merkle Audit proof from Log by leaf hash. If the leaf is not in the tree of the given
+// size, the error matches ErrNotFound.
+func (c *Client) GetProofByHash(ctx context.Context, hash string, treeSize uint64) (*command.GetProofByHashResponse, error) { // nolint: lll
+	const (
+		hashParamName     = "hash"
+		treeSizeParamName = "tree_size"
+	)
+
+	if err := c.checkTreeSize(ctx, treeSize); err != nil {
+		return nil, fmt.Errorf("get proof by hash: %w", err)
+	}
+
+	opts := []opt{
+		withValueAdd(hashParamName, hash),
+		withValueAdd(treeSizeParamName, strconv.FormatUint(treeSize, 10)),
+		c.withReadToken(),
+	}
+
+	var result *command.GetProofByHashResponse
+	if err := c.do(ctx, rest.GetProofByHashPath, &result, opts...); err != nil {
+		return nil, fmt.Errorf("get proof by hash: %w", err)
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("get proof by hash: %w", &DecodeError{Field: "body", Err: errors.New("empty response")})
+	}
+
+	if err := c.checkAuditPath(result.AuditPath); err != nil {
+		return nil, fmt.Errorf("get proof by hash: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetProofsByHashes retrieves Merkle Audit proofs from Log by leaf hashes, like GetProofByHash for each of them.
+//
+// The proofs are retrieved concurrently by up to 8 workers. The returned proofs and errors are aligned by index
+// with the hashes; the error of a hash is nil if its proof was retrieved, otherwise its proof is the zero value,
+// e.g. for a leaf not in the log. An error is returned instead if the context is done before all the proofs are
+// retrieved, the requests in flight are canceled with the context.
+func (c *Client) GetProofsByHashes(ctx context.Context, hashes []string,
+	treeSize uint64) ([]command.GetProofByHashResponse, []error, error) {
+	const maxWorkers = 8
+
+	workers := maxWorkers
+	if workers > len(hashes) {
+		workers = len(hashes)
+	}
+
+	proofs := make([]command.GetProofByHashResponse, len(hashes))
+	errs := make([]error, len(hashes))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for index := range indexes {
+				proof, err := c.GetProofByHash(ctx, hashes[index], treeSize)
+				if err != nil {
+					errs[index] = err
+
+					continue
+				}
+
+				proofs[index] = *proof
+			}
+		}()
+	}
+
+	var err error
+
+	for i := 0; i < len(hashes) && err == nil; i++ {
+		// A ready worker may be selected over a done context.
+		if err = ctx.Err(); err != nil {
+			break
+		}
+
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	if err == nil {
+		// the context may be done while the last proofs are retrieved
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("get proofs by hashes: %w", err)
+	}
+
+	return proofs, errs, nil
+}
+
+// GetProofByCredential retrieves Merkle Audit proof from Log by the credential logged with the given timestamp.
+// The leaf hash is calculated the same way as the log does, see CalculateLeafHashWith and WithLeafHasher.
+func (c *Client) GetProofByCredential(ctx context.Context, timestamp uint64, credential []byte,
+	loader jsonld.DocumentLoader, treeSize uint64) (*command.GetProofByHashResponse, error) {
+	hash, err := CalculateLeafHashWith(c.leafHasher, timestamp, credential, loader)
+	if err != nil {
+		return nil, fmt.Errorf("get proof by credential: %w", err)
+	}
+
+	return c.GetProofByHash(ctx, hash, treeSize)
+}
+
+// GetEntries retrieves entries from log. A response with more entries than the range [start, end] holds is
+// rejected with an error matching ErrInvalidResponse, see WithoutClientValidation; the log may return fewer. So is
+// a response whose Start, End and TreeSize do not match its entries, if the log returns them. If the log responds
+// with 404 Not Found, the error matches ErrNotFound.
+func (c *Client) GetEntries(ctx context.Context, start, end uint64) (*command.GetEntriesResponse, error) {
+	const (
+		startParamName = "start"
+		endParamName   = "end"
+	)
+
+	if err := c.checkTreeSize(ctx, start+1); err != nil {
+		return nil, fmt.Errorf("get entries: %w", err)
+	}
+
+	opts := []opt{
+		withValueAdd(startParamName, strconv.FormatUint(start, 10)),
+		withValueAdd(endParamName, strconv.FormatUint(end, 10)),
+		c.withReadToken(),
+	}
+
+	var result *command.GetEntriesResponse
+	if err := c.do(ctx, rest.GetEntriesPath, &result, opts...); err != nil {
+		return nil, fmt.Errorf("get entries: %w", err)
+	}
+
+	if !c.skipValidation && result != nil && end >= start && uint64(len(result.Entries)) > end-start+1 {
+		return nil, fmt.Errorf("get entries: %w: %d entries for range [%d, %d]", ErrInvalidResponse,
+			len(result.Entries), start, end)
+	}
+
+	if !c.skipValidation && result != nil && result.TreeSize > 0 {
+		if err := checkEntriesRange(result, start); err != nil {
+			return nil, fmt.Errorf("get entries: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// checkEntriesRange checks that the range and tree size the log served the entries from match the entries.
+func checkEntriesRange(resp *command.GetEntriesResponse, start uint64) error {
+	if len(resp.Entries) == 0 {
+		if resp.Start != nil || resp.End != nil {
+			return fmt.Errorf("%w: range served for no entries from %d", ErrInvalidResponse, start)
+		}
+
+		return nil
+	}
+
+	end := int64(start) + int64(len(resp.Entries)) - 1
+
+	if resp.Start == nil || resp.End == nil || *resp.Start != int64(start) || *resp.End != end {
+		return fmt.Errorf("%w: range [%s, %s] served for %d entries from %d", ErrInvalidResponse,
+			formatIndex(resp.Start), formatIndex(resp.End), len(resp.Entries), start)
+	}
+
+	if uint64(end) >= resp.TreeSize {
+		return fmt.Errorf("%w: range [%d, %d] served from tree size %d", ErrInvalidResponse,
+			*resp.Start, *resp.End, resp.TreeSize)
+	}
+
+	return nil
+}
+
+// formatIndex formats an optional leaf index of a response.
+func formatIndex(index *int64) string {
+	if index == nil {
+		return "unset"
+	}
+
+	return strconv.FormatInt(*index, 10)
+}
+
+// GetEntryAndProof retrieves entry and merkle audit proof from log. If the log responds with 404 Not Found, the
+// error matches ErrNotFound.
+func (c *Client) GetEntryAndProof(ctx context.Context, leafIndex, treeSize uint64) (*command.GetEntryAndProofResponse, error) { // nolint: lll
+	const (
+		leafIndexParamName = "leaf_index"
+		treeSizeParamName  = "tree_size"
+	)
+
+	if err := c.checkLeafIndex(ctx, leafIndex, treeSize); err != nil {
+		return nil, fmt.Errorf("get entry and proof: %w", err)
+	}
+
+	opts := []opt{
+		withValueAdd(leafIndexParamName, strconv.FormatUint(leafIndex, 10)),
+		withValueAdd(treeSizeParamName, strconv.FormatUint(treeSize, 10)),
+		c.withReadToken(),
+	}
+
+	var result *command.GetEntryAndProofResponse
+	if err := c.do(ctx, rest.GetEntryAndProofPath, &result, opts...); err != nil {
+		return nil, fmt.Errorf("get entry and proof: %w", err)
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("get entry and proof: %w", &DecodeError{Field: "body", Err: errors.New("empty response")})
+	}
+
+	if err := c.checkAuditPath(result.AuditPath); err != nil {
+		return nil, fmt.Errorf("get entry and proof: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetVerifiedEntry retrieves entry and merkle audit proof from log, and verifies the inclusion of the entry in
+// the tree with the given size and root hash, e.g. of the last signed tree head seen. A VerificationError is
+// returned if the proof is invalid.
+func (c *Client) GetVerifiedEntry(ctx context.Context, leafIndex, treeSize uint64,
+	rootHash []byte) (*command.GetEntryAndProofResponse, error) {
+	result, err := c.GetEntryAndProof(ctx, leafIndex, treeSize)
+	if err != nil {
+		return nil, fmt.Errorf("get verified entry: %w", err)
+	}
+
+	err = NewProofVerifier(WithProofLeafHasher(c.leafHasher)).VerifyInclusion(result.LeafInput, leafIndex, treeSize,
+		result.AuditPath, rootHash)
+	if err != nil {
+		return nil, fmt.Errorf("get verified entry: %w", &VerificationError{Check: CheckInclusion, Err: err})
+	}
+
+	return result, nil
+}
+
+func (c *Client) checkAuditPath(auditPath [][]byte) error {
+	if len(auditPath) > c.maxAuditPathLength {
+		return &DecodeError{
+			Field: "audit_path",
+			Err:   fmt.Errorf("length %d exceeds maximum %d", len(auditPath), c.maxAuditPathLength),
+		}
+	}
+
+	return nil
+}
+
+// CalculateLeafHash calculates hash for given credentials with RFC6962LeafHasher, see CalculateLeafHashWith.
+func CalculateLeafHash(timestamp uint64, vcBytes []byte, loader jsonld.DocumentLoader) (string, error) {
+	return CalculateLeafHashContext(context.Background(), timestamp, vcBytes, loader)
+}
+
+// CalculateLeafHashWith calculates hash for given credentials like CalculateLeafHash, with the given leaf hasher
+// instead of RFC6962LeafHasher.
+func CalculateLeafHashWith(leafHasher LeafHasher, timestamp uint64, vcBytes []byte,
+	loader jsonld.DocumentLoader) (string, error) {
+	hash, err := calculateLeafHash(leafHasher, timestamp, vcBytes, loader)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(hash), nil
+}
+
+// CalculateLeafHashContext calculates hash for given credentials like CalculateLeafHash, but returns the error of
+// the context as soon as it is done, e.g. while the document loader fetches a slow remote JSON-LD context of an
+// untrusted credential. The loader is not called anymore once the context is done, so that the canonicalization
+// stops at its next document load; a load in progress is only interrupted if the loader has its own timeout.
+func CalculateLeafHashContext(ctx context.Context, timestamp uint64, vcBytes []byte,
+	loader jsonld.DocumentLoader) (string, error) {
+	if ctx.Done() == nil {
+		// The context is never done.
+		return CalculateLeafHashWith(RFC6962LeafHasher{}, timestamp, vcBytes, loader)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("calculate leaf hash: %w", err)
+	}
+
+	if loader != nil {
+		loader = &contextDocumentLoader{ctx: ctx, loader: loader}
+	}
+
+	type result struct {
+		hash []byte
+		err  error
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		hash, err := calculateLeafHash(RFC6962LeafHasher{}, timestamp, vcBytes, loader)
+		done <- result{hash: hash, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", fmt.Errorf("calculate leaf hash: %w", ctx.Err())
+	case r := <-done:
+		if r.err != nil {
+			return "", r.err
+		}
+
+		return base64.StdEncoding.EncodeToString(r.hash), nil
+	}
+}
+
+// contextDocumentLoader fails to load documents once the context is done.
+type contextDocumentLoader struct {
+	ctx    context.Context // nolint: containedctx
+	loader jsonld.DocumentLoader
+}
+
+func (l *contextDocumentLoader) LoadDocument(u string) (*jsonld.RemoteDocument, error) {
+	if err := l.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("load document %s: %w", u, err)
+	}
+
+	return l.loader.LoadDocument(u) // nolint: wrapcheck
+}
+
+// CalculateLeafHashFromCanonical calculates hash for given credential in canonical form, as returned by
+// CanonicalizeForLog. Unlike CalculateLeafHash, the credential is not canonicalized again: exactly the given
+// bytes are hashed with the timestamp, so no document loader is needed.
+func CalculateLeafHashFromCanonical(timestamp uint64, canonical []byte) (string, error) {
+	leafData, err := canonicalizer.MarshalCanonical(command.NewLeaf(timestamp, canonical))
+	if err != nil {
+		return "", fmt.Errorf("marshal leaf: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(hasher.DefaultHasher.HashLeaf(leafData)), nil
+}
+
+// CalculateVPLeafHash calculates hash for given presentation, as logged by AddVP.
+//
+// The pre-image of the leaf hash is the JCS (RFC 8785) canonical JSON of the MerkleTreeLeaf, which verifiers
+// reconstruct from the presentation as follows:
+//   - the "proof" of the presentation is removed, the proofs of its credentials are kept;
+//   - the presentation is canonicalized with URDNA2015 into N-Quads, the vc_entry of the timestamped entry;
+//   - the timestamped entry has the timestamp returned by AddVP and the entry_type 101 (VPLogEntryType), which
+//     tells it apart from the entry_type 100 (VCLogEntryType) of credentials;
+//   - the leaf has the version 0 (V1) and the leaf_type 100 (TimestampedEntryLeafType).
+//
+// The leaf hash is the SHA-256 of the byte 0x00 followed by the pre-image, as in RFC 6962, and is returned
+// base64 encoded like the one of CalculateLeafHash.
+func CalculateVPLeafHash(timestamp uint64, vpBytes []byte, loader jsonld.DocumentLoader) (string, error) {
+	leaf, err := command.CreateVPLeaf(timestamp, vpBytes, loader)
+	if err != nil {
+		return "", fmt.Errorf("create leaf: %w", err)
+	}
+
+	leafData, err := canonicalizer.MarshalCanonical(leaf)
+	if err != nil {
+		return "", fmt.Errorf("marshal leaf: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(hasher.DefaultHasher.HashLeaf(leafData)), nil
+}
+
+func calculateLeafHash(leafHasher LeafHasher, timestamp uint64, vcBytes []byte,
+	loader jsonld.DocumentLoader) ([]byte, error) {
+	hash, _, err := calculateLeafHashDebug(leafHasher, timestamp, vcBytes, loader)
+
+	return hash, err
+}
+
+// CalculateLeafHashDebug calculates hash for given credentials, and also returns the canonical Merkle tree leaf
+// the hash is calculated from, which contains the timestamp and the canonical credential. Comparing it with the
+// leaf input returned by the log helps to find out why hashes diverge.
+func CalculateLeafHashDebug(timestamp uint64, vcBytes []byte,
+	loader jsonld.DocumentLoader) (hash, canonical []byte, err error) {
+	return calculateLeafHashDebug(RFC6962LeafHasher{}, timestamp, vcBytes, loader)
+}
+
+func calculateLeafHashDebug(leafHasher LeafHasher, timestamp uint64, vcBytes []byte,
+	loader jsonld.DocumentLoader) (hash, canonical []byte, err error) {
+	leaf, err := command.CreateLeaf(timestamp, vcBytes, loader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create leaf: %w", err)
+	}
+
+	leafData, err := canonicalizer.MarshalCanonical(leaf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal leaf: %w", err)
+	}
+
+	return leafHasher.HashLeaf(leafData), leafData, nil
+}
+
+// VerifyVCTimestampSignature verifies VC timestamp signature. See VerifyVCTimestampSignatureWithKey to verify it
+// against a parsed public key.
+func VerifyVCTimestampSignature(signature, pubKey []byte, timestamp uint64, vcBytes []byte,
+	loader jsonld.DocumentLoader) error {
+	_, err := VerifyVCTimestampSignatureWithBytes(signature, pubKey, timestamp, vcBytes, loader)
+
+	return err
+}
+
+// VerifyVCTimestampSignatureWithBytes verifies VC timestamp signature and returns the canonical credential bytes
+// covered by the signature. Callers may compare them with the result of CanonicalizeForLog to confirm what
+// exactly was signed.
+func VerifyVCTimestampSignatureWithBytes(signature, pubKey []byte, timestamp uint64, vcBytes []byte,
+	loader jsonld.DocumentLoader) ([]byte, error) {
+	sig, err := unmarshalSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := parsePublicKey(sig, pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf, err := verifyVCTimestampSignatureWithKey(sig, key, timestamp, vcBytes, loader)
+	if err != nil {
+		return nil, err
+	}
+
+	return leaf.TimestampedEntry.VCEntry, nil
+}
+
+// unmarshalSignature unmarshals the signed timestamp of the response of AddVC. A null signature is rejected, as
+// there is nothing to verify.
+func unmarshalSignature(signature []byte) (*command.DigitallySigned, error) {
+	var sig *command.DigitallySigned
+
+	if err := json.Unmarshal(signature, &sig); err != nil {
+		return nil, fmt.Errorf("unmarshal signature: %w", err)
+	}
+
+	if sig == nil {
+		return nil, errors.New("unmarshal signature: empty signature")
+	}
+
+	return sig, nil
+}
+
+// verifyTimestampSignature verifies the signed timestamp of the leaf.
+func verifyTimestampSignature(signature, pubKey []byte, leaf *command.MerkleTreeLeaf) error {
+	sig, err := unmarshalSignature(signature)
+	if err != nil {
+		return err
+	}
+
+	return verifyLeafSignature(sig, pubKey, leaf)
+}
+
+func verifyLeafSignature(sig *command.DigitallySigned, pubKey []byte, leaf *command.MerkleTreeLeaf) error {
+	key, err := parsePublicKey(sig, pubKey)
+	if err != nil {
+		return err
+	}
+
+	return verifyLeafSignatureWithKey(sig, key, leaf)
+}
+
+func verifyLeafSignatureWithKey(sig *command.DigitallySigned, key crypto.PublicKey,
+	leaf *command.MerkleTreeLeaf) error {
+	data, err := canonicalizer.MarshalCanonical(command.CreateVCTimestampSignature(leaf))
+	if err != nil {
+		return fmt.Errorf("marshal VC timestamp signature: %w", err)
+	}
+
+	return verifySignatureWithKey(sig, key, data)
+}
+
+// CanonicalizeForLog returns the canonical form of the credential as it is stored in the log entry.
+// The proof of the credential is not part of the canonical form.
+func CanonicalizeForLog(vcBytes []byte, loader jsonld.DocumentLoader) ([]byte, error) {
+	leaf, err := command.CreateLeaf(0, vcBytes, loader)
+	if err != nil {
+		return nil, fmt.Errorf("create leaf: %w", err)
+	}
+
+	return leaf.TimestampedEntry.VCEntry, nil
+}
+
+// verifySignature verifies the signature of the data with the public key, parsed as the key type of the signature
+// requires, see parsePublicKey.
+func verifySignature(sig *command.DigitallySigned, pubKey, data []byte) error {
+	key, err := parsePublicKey(sig, pubKey)
+	if err != nil {
+		return err
 	}
+
+	return verifySignatureWithKey(sig, key, data)
 }
 
-// WithLedgerURI sets the ledger URI. By default, the ledger URI is set to the
-// endpoint URL.
-func WithLedgerURI(ledgerURI string) ClientOpt {
-	return func(o *Client) {
-		o.ledgerURI = ledgerURI
+func verifyED25519Signature(signature, pubKey, data []byte) error {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid ED25519 public key size %d, expected %d", len(pubKey), ed25519.PublicKeySize)
+	}
+
+	if !ed25519.Verify(pubKey, data, signature) {
+		return errors.New("ED25519 signature verification failed")
 	}
+
+	return nil
 }
 
-// HTTPClient represents HTTP client.
-type HTTPClient interface {
-	Do(req *http.Request) (*http.Response, error)
+const (
+	gzipEncoding    = "gzip"
+	requestIDHeader = "X-Request-ID"
+)
+
+type options struct {
+	operation       string
+	method          string
+	body            []byte
+	contentEncoding string
+	values          url.Values
+	token           string
+	tokenSource     TokenSource
+	retryable       bool
+	verbatim        bool
+	header          http.Header
+	responseHeader  *http.Header
+	rawResponse     *[]byte
+	url             string
 }
 
-// Client represents VCT REST client.
-type Client struct {
-	endpoint       string
-	ledgerURI      string
-	http           HTTPClient
-	authReadToken  string
-	authWriteToken string
+type opt func(*options)
+
+func withBody(val []byte) opt {
+	return func(o *options) {
+		o.body = val
+	}
 }
 
-// New returns VCT REST client.
-func New(endpoint string, opts ...ClientOpt) *Client {
-	c := &Client{
-		endpoint:  endpoint,
-		ledgerURI: endpoint,
-		http: &http.Client{
-			Timeout: time.Minute,
-		},
+func withValueAdd(key, val string) opt {
+	return func(o *options) {
+		o.values.Add(key, val)
 	}
+}
 
-	for _, fn := range opts {
-		fn(c)
+func withMethod(val string) opt {
+	return func(o *options) {
+		o.method = val
+	}
+}
+
+func withToken(val string, source TokenSource) opt {
+	return func(o *options) {
+		o.token = val
+		o.tokenSource = source
 	}
+}
 
-	return c
+func (c *Client) withReadToken() opt {
+	return withToken(c.authReadToken, c.readTokenSource)
 }
 
-// AddVC adds verifiable credential to log.
-func (c *Client) AddVC(ctx context.Context, credential []byte) (*command.AddVCResponse, error) {
-	var result *command.AddVCResponse
-	if err := c.do(ctx, rest.AddVCPath, &result, withMethod(http.MethodPost), withBody(credential),
-		withToken(c.authWriteToken)); err != nil {
-		return nil, fmt.Errorf("add VC: %w", err)
+func (c *Client) withWriteToken() opt {
+	return withToken(c.authWriteToken, c.writeTokenSource)
+}
+
+// withHeader sets the header of the request.
+func withHeader(key, val string) opt {
+	return func(o *options) {
+		o.header.Set(key, val)
 	}
+}
 
-	return result, nil
+// withVerbatimBody marks a request whose body is sent as is, i.e. never compressed.
+func withVerbatimBody() opt {
+	return func(o *options) {
+		o.verbatim = true
+	}
 }
 
-// HealthCheck check health.
-func (c *Client) HealthCheck(ctx context.Context) error {
-	parseURL, err := url.Parse(c.endpoint)
+// withResponseHeader captures the header of the response, of the last attempt if the request is retried.
+func withResponseHeader(header *http.Header) opt {
+	return func(o *options) {
+		o.responseHeader = header
+	}
+}
+
+// withRawResponse reads the body of a successful response into body instead of decoding it, e.g. to decode it
+// with an error of its own.
+func withRawResponse(body *[]byte) opt {
+	return func(o *options) {
+		o.rawResponse = body
+	}
+}
+
+// withURL sends the request to the absolute URL instead of the path of the REST API.
+func withURL(val string) opt {
+	return func(o *options) {
+		o.url = val
+	}
+}
+
+// withRetryable marks a request that is safe to retry although it is not a GET request.
+func withRetryable() opt {
+	return func(o *options) {
+		o.retryable = true
+	}
+}
+
+func (c *Client) do(ctx context.Context, path string, v interface{}, opts ...opt) error {
+	op := &options{operation: operationName(path), method: http.MethodGet, values: url.Values{}, header: http.Header{}}
+	for _, fn := range opts {
+		fn(op)
+	}
+
+	if c.tokenSelector != nil {
+		op.token, op.tokenSource = c.tokenSelector(op.operation), nil
+	}
+
+	var p string
+
+	var err error
+
+	if op.url != "" {
+		p, err = absoluteURL(op.url, op.values)
+	} else {
+		p, err = requestURL(c.endpoint, path, op.values)
+	}
+
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
-		parseURL.Scheme+"://"+parseURL.Host+rest.HealthCheckPath, nil)
-	if err != nil {
-		return fmt.Errorf("new request with context: %w", err)
+	if c.requestCompression && op.body != nil && !op.verbatim {
+		if op.body, err = gzipCompress(op.body); err != nil {
+			return fmt.Errorf("compress body: %w", err)
+		}
+
+		op.contentEncoding = gzipEncoding
 	}
 
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return fmt.Errorf("http do: %w", err)
+	if c.tracing {
+		return c.traced(ctx, op, p, func(spanCtx context.Context) error {
+			return c.sendAll(spanCtx, p, op, v)
+		})
 	}
 
-	defer resp.Body.Close() // nolint: errcheck
+	return c.sendAll(ctx, p, op, v)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return getError(resp.Body)
+// requestURL returns the URL of the request to the path of the REST API. The alias segment of the path is
+// replaced with the path of the endpoint, which may have several segments, e.g. a path prefix followed by the
+// alias of the log; a trailing slash of the endpoint is ignored. Paths without alias segment, e.g. the health
+// check, are relative to the host of the endpoint.
+func requestURL(endpoint, path string, values url.Values) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("parse URL: %w", err)
 	}
 
-	return nil
+	return (&url.URL{
+		Scheme:   u.Scheme,
+		Host:     u.Host,
+		Path:     strings.Replace(path, rest.AliasPath, strings.TrimRight(u.Path, "/"), 1),
+		RawQuery: values.Encode(),
+	}).String(), nil
 }
 
-// Webfinger returns discovery info.
-func (c *Client) Webfinger(ctx context.Context) (*command.WebFingerResponse, error) {
-	const resourceParamName = "resource"
+// absoluteURL returns the URL with the values added to its query, it fails if the URL is not absolute.
+func absoluteURL(rawURL string, values url.Values) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("parse URL: %w", err)
+	}
 
-	var result *command.WebFingerResponse
-	if err := c.do(ctx, rest.WebfingerPath, &result, withValueAdd(resourceParamName, c.ledgerURI)); err != nil {
-		return nil, fmt.Errorf("webfinger: %w", err)
+	if !u.IsAbs() || u.Host == "" {
+		return "", fmt.Errorf("URL %q is not absolute", rawURL)
 	}
 
-	return result, nil
+	query := u.Query()
+
+	for key, vals := range values {
+		query[key] = append(query[key], vals...)
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
 }
 
-// GetIssuers returns issuers.
-func (c *Client) GetIssuers(ctx context.Context) ([]string, error) {
-	var result []string
-	if err := c.do(ctx, rest.GetIssuersPath, &result, withToken(c.authReadToken)); err != nil {
-		return nil, fmt.Errorf("get issuers: %w", err)
+// sendAll sends the request, retrying it if the client is configured to.
+func (c *Client) sendAll(ctx context.Context, p string, op *options, v interface{}) error {
+	if c.retry == nil || (op.method != http.MethodGet && !op.retryable) {
+		_, err := c.send(ctx, p, op, v)
+
+		return err
 	}
 
-	return result, nil
+	return c.retry.do(ctx, func() (bool, error) {
+		return c.send(ctx, p, op, v)
+	}, func(attempt int, delay time.Duration, err error) {
+		c.logger.Warnf("%s: attempt %d failed, retrying in %s: %v", op.operation, attempt, delay, err)
+	})
 }
 
-// GetSTH retrieves latest signed tree head.
-func (c *Client) GetSTH(ctx context.Context) (*command.GetSTHResponse, error) {
-	var result *command.GetSTHResponse
-	if err := c.do(ctx, rest.GetSTHPath, &result, withToken(c.authReadToken)); err != nil {
-		return nil, fmt.Errorf("get STH: %w", err)
+// requestIDSuffix returns the request ID to append to a log message, if any.
+func requestIDSuffix(requestID string) string {
+	if requestID == "" {
+		return ""
 	}
 
-	return result, nil
+	return fmt.Sprintf(" (request ID %s)", requestID)
 }
 
-// GetSTHConsistency retrieves merkle consistency proofs between signed tree heads.
-func (c *Client) GetSTHConsistency(ctx context.Context, first, second uint64) (*command.GetSTHConsistencyResponse, error) { // nolint: lll
-	const (
-		firstParamName  = "first"
-		secondParamName = "second"
-	)
+// send sends the request once and decodes the response into v. It returns true together with the error if
+// the request failed for a reason that may be transient.
+func (c *Client) send(ctx context.Context, p string, op *options, v interface{}) (bool, error) {
+	if atomic.LoadInt32(&c.closed) == 1 {
+		return false, ErrClientClosed
+	}
 
-	opts := []opt{
-		withValueAdd(firstParamName, strconv.FormatUint(first, 10)),
-		withValueAdd(secondParamName, strconv.FormatUint(second, 10)),
-		withToken(c.authReadToken),
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return false, fmt.Errorf("rate limit: %w", err)
+		}
 	}
 
-	var result *command.GetSTHConsistencyResponse
-	if err := c.do(ctx, rest.GetSTHConsistencyPath, &result, opts...); err != nil {
-		return nil, fmt.Errorf("get STH consistency: %w", err)
+	var body io.Reader
+	if op.body != nil {
+		body = bytes.NewReader(op.body)
 	}
 
-	return result, nil
-}
+	reqCtx := ctx
 
-// GetProofByHash retrieves Merkle Audit proof from Log by leaf hash.
-func (c *Client) GetProofByHash(ctx context.Context, hash string, treeSize uint64) (*command.GetProofByHashResponse, error) { // nolint: lll
-	const (
-		hashParamName     = "hash"
-		treeSizeParamName = "tree_size"
-	)
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
 
-	opts := []opt{
-		withValueAdd(hashParamName, hash),
-		withValueAdd(treeSizeParamName, strconv.FormatUint(treeSize, 10)),
-		withToken(c.authReadToken),
+		// The derived context keeps the deadline of the caller if it is earlier.
+		reqCtx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
 	}
 
-	var result *command.GetProofByHashResponse
-	if err := c.do(ctx, rest.GetProofByHashPath, &result, opts...); err != nil {
-		return nil, fmt.Errorf("get proof by hash: %w", err)
+	req, err := http.NewRequestWithContext(reqCtx, op.method, p, body)
+	if err != nil {
+		return false, fmt.Errorf("new request with context: %w", err)
 	}
 
-	return result, nil
-}
+	for key, values := range c.headers {
+		req.Header[key] = append([]string{}, values...)
+	}
 
-// GetEntries retrieves entries from log.
-func (c *Client) GetEntries(ctx context.Context, start, end uint64) (*command.GetEntriesResponse, error) {
-	const (
-		startParamName = "start"
-		endParamName   = "end"
-	)
+	token := op.token
 
-	opts := []opt{
-		withValueAdd(startParamName, strconv.FormatUint(start, 10)),
-		withValueAdd(endParamName, strconv.FormatUint(end, 10)),
-		withToken(c.authReadToken),
+	if op.tokenSource != nil {
+		if token, err = op.tokenSource(reqCtx); err != nil {
+			return false, fmt.Errorf("get token: %w", err)
+		}
 	}
 
-	var result *command.GetEntriesResponse
-	if err := c.do(ctx, rest.GetEntriesPath, &result, opts...); err != nil {
-		return nil, fmt.Errorf("get entries: %w", err)
+	for key, values := range op.header {
+		req.Header[key] = append([]string{}, values...)
 	}
 
-	return result, nil
-}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
 
-// GetEntryAndProof retrieves entry and merkle audit proof from log.
-func (c *Client) GetEntryAndProof(ctx context.Context, leafIndex, treeSize uint64) (*command.GetEntryAndProofResponse, error) { // nolint: lll
-	const (
-		leafIndexParamName = "leaf_index"
-		treeSizeParamName  = "tree_size"
-	)
+	var requestID string
 
-	opts := []opt{
-		withValueAdd(leafIndexParamName, strconv.FormatUint(leafIndex, 10)),
-		withValueAdd(treeSizeParamName, strconv.FormatUint(treeSize, 10)),
-		withToken(c.authReadToken),
+	if c.requestID != nil {
+		requestID = c.requestID(ctx)
 	}
 
-	var result *command.GetEntryAndProofResponse
-	if err := c.do(ctx, rest.GetEntryAndProofPath, &result, opts...); err != nil {
-		return nil, fmt.Errorf("get entry and proof: %w", err)
+	if requestID != "" {
+		req.Header.Set(requestIDHeader, requestID)
 	}
 
-	return result, nil
-}
+	if op.contentEncoding != "" {
+		req.Header.Set("Content-Encoding", op.contentEncoding)
+	}
 
-// CalculateLeafHash calculates hash for given credentials.
-func CalculateLeafHash(timestamp uint64, vcBytes []byte, loader jsonld.DocumentLoader) (string, error) {
-	leaf, err := command.CreateLeaf(timestamp, vcBytes, loader)
-	if err != nil {
-		return "", fmt.Errorf("create leaf: %w", err)
+	if c.compression {
+		req.Header.Set("Accept-Encoding", gzipEncoding)
 	}
 
-	leafData, err := canonicalizer.MarshalCanonical(leaf)
+	recordStatus := c.injectTraceContext(ctx, req)
+	start := time.Now()
+
+	resp, err := c.http.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("marshal leaf: %w", err)
-	}
+		c.metrics.ObserveRequest(op.operation, 0, time.Since(start))
+		c.logger.Debugf("%s: %s %s failed%s: %v", op.operation, op.method, p, requestIDSuffix(requestID), err)
 
-	return base64.StdEncoding.EncodeToString(hasher.DefaultHasher.HashLeaf(leafData)), nil
-}
+		return ctx.Err() == nil && isTimeout(err), fmt.Errorf("http do: %w", err)
+	}
 
-// VerifyVCTimestampSignature verifies VC timestamp signature.
-func VerifyVCTimestampSignature(signature, pubKey []byte, timestamp uint64, vcBytes []byte,
-	loader jsonld.DocumentLoader) error {
-	var sig *command.DigitallySigned
+	c.metrics.ObserveRequest(op.operation, resp.StatusCode, time.Since(start))
+	recordStatus(resp.StatusCode)
 
-	if err := json.Unmarshal(signature, &sig); err != nil {
-		return fmt.Errorf("unmarshal signature: %w", err)
+	if op.responseHeader != nil {
+		*op.responseHeader = resp.Header.Clone()
 	}
 
-	leaf, err := command.CreateLeaf(timestamp, vcBytes, loader)
+	c.logger.Debugf("%s: %s %s responded with status %d%s", op.operation, op.method, p, resp.StatusCode,
+		requestIDSuffix(requestID))
+
+	defer resp.Body.Close() // nolint: errcheck
+
+	respBody, err := c.responseBody(resp)
 	if err != nil {
-		return fmt.Errorf("create leaf: %w", err)
+		return false, err
 	}
 
-	data, err := canonicalizer.MarshalCanonical(command.CreateVCTimestampSignature(leaf))
-	if err != nil {
-		return fmt.Errorf("marshal VC timestamp signature: %w", err)
+	if resp.StatusCode == http.StatusNotModified {
+		return false, errNotModified
 	}
 
-	kh, err := (&localkms.LocalKMS{}).PubKeyBytesToHandle(pubKey, sig.Algorithm.Type)
-	if err != nil {
-		return fmt.Errorf("pub key to handle: %w", err)
+	if resp.StatusCode != http.StatusOK {
+		err = getError(op.operation, resp.StatusCode, respBody)
+
+		var vctErr *Error
+		if errors.As(err, &vctErr) {
+			vctErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+
+		return isRetryableStatus(resp.StatusCode), err
+	}
+
+	if op.rawResponse != nil {
+		*op.rawResponse, err = ioutil.ReadAll(respBody)
+
+		return false, err // nolint: wrapcheck
 	}
 
-	return (&tinkcrypto.Crypto{}).Verify(sig.Signature, data, kh) // nolint: wrapcheck
+	if c.detectErrorInSuccessBody {
+		return false, decodeSuccessBody(op.operation, resp.StatusCode, respBody, v)
+	}
+
+	return false, newDecoder(respBody).Decode(&v) // nolint: wrapcheck
 }
 
-type options struct {
-	method string
-	body   io.Reader
-	values url.Values
-	token  string
+// newDecoder returns the decoder of a response body. Numbers are decoded as json.Number where the type of the
+// value is not known, so that timestamps and tree sizes beyond 2^53 do not lose precision as float64.
+func newDecoder(reader io.Reader) *json.Decoder {
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+
+	return decoder
 }
 
-type opt func(*options)
+// responseBody returns the reader of the response body, which decompresses it if needed and fails once the body
+// exceeds the maximum response size.
+func (c *Client) responseBody(resp *http.Response) (io.Reader, error) {
+	if !c.compression || !strings.EqualFold(resp.Header.Get("Content-Encoding"), gzipEncoding) {
+		return c.limitBody(resp.Body), nil
+	}
 
-func withBody(val []byte) opt {
-	return func(o *options) {
-		o.body = bytes.NewBuffer(val)
+	reader, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("decompress body: %w", err)
 	}
+
+	return c.limitBody(reader), nil
 }
 
-func withValueAdd(key, val string) opt {
-	return func(o *options) {
-		o.values.Add(key, val)
+// limitBody returns the reader limited to the maximum response size, see WithMaxResponseBytes.
+func (c *Client) limitBody(reader io.Reader) io.Reader {
+	if c.maxResponseBytes <= 0 {
+		return reader
 	}
-}
 
-func withMethod(val string) opt {
-	return func(o *options) {
-		o.method = val
+	return &maxBytesReader{
+		reader:    io.LimitReader(reader, c.maxResponseBytes+1),
+		remaining: c.maxResponseBytes,
+		limit:     c.maxResponseBytes,
 	}
 }
 
-func withToken(val string) opt {
-	return func(o *options) {
-		o.token = val
+// maxBytesReader reads up to one byte more than the remaining bytes from a limited reader and fails with
+// ErrResponseTooLarge once that byte is read.
+type maxBytesReader struct {
+	reader    io.Reader
+	remaining int64
+	limit     int64
+}
+
+func (r *maxBytesReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n + int(r.remaining), fmt.Errorf("%w: exceeds %d bytes", ErrResponseTooLarge, r.limit)
 	}
+
+	return n, err
 }
 
-func (c *Client) do(ctx context.Context, path string, v interface{}, opts ...opt) error {
-	op := &options{method: http.MethodGet, values: url.Values{}}
-	for _, fn := range opts {
-		fn(op)
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writer := gzip.NewWriter(&buf)
+
+	if _, err := writer.Write(data); err != nil {
+		return nil, err // nolint: wrapcheck
 	}
 
-	u, err := url.Parse(c.endpoint)
-	if err != nil {
-		return fmt.Errorf("parse URL: %w", err)
+	if err := writer.Close(); err != nil {
+		return nil, err // nolint: wrapcheck
 	}
 
-	p := fmt.Sprintf("%s://%s%s?%s", u.Scheme, u.Host,
-		strings.Replace(path, rest.AliasPath, u.Path, 1),
-		op.values.Encode())
+	return buf.Bytes(), nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, op.method, p, op.body)
+func decodeSuccessBody(operation string, statusCode int, reader io.Reader, v interface{}) error {
+	body, err := ioutil.ReadAll(reader)
 	if err != nil {
-		return fmt.Errorf("new request with context: %w", err)
+		return fmt.Errorf("read body: %w", err)
 	}
 
-	if op.token != "" {
-		req.Header.Add("Authorization", "Bearer "+op.token)
-	}
+	decoder := newDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
 
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return fmt.Errorf("http do: %w", err)
+	if decoder.Decode(&v) == nil {
+		return nil
 	}
 
-	defer resp.Body.Close() // nolint: errcheck
+	var errMsg *rest.ErrorResponse
 
-	if resp.StatusCode != http.StatusOK {
-		return getError(resp.Body)
+	if json.Unmarshal(body, &errMsg) == nil && errMsg != nil && errMsg.Message != "" {
+		return &Error{StatusCode: statusCode, Op: operation, Message: errMsg.Message}
 	}
 
-	return json.NewDecoder(resp.Body).Decode(&v) // nolint: wrapcheck
+	return newDecoder(bytes.NewReader(body)).Decode(&v) // nolint: wrapcheck
 }
 
-func getError(reader io.Reader) error {
+func getError(operation string, statusCode int, reader io.Reader) error {
 	msgBytes, err := ioutil.ReadAll(reader)
 	if err != nil {
 		return fmt.Errorf("read message body: %w", err)
@@ -370,9 +1992,27 @@ func getError(reader io.Reader) error {
 	var errMsg *rest.ErrorResponse
 
 	err = json.Unmarshal(msgBytes, &errMsg)
-	if err != nil {
-		return fmt.Errorf("%s", msgBytes)
+	if err != nil || errMsg == nil || errMsg.Message == "" {
+		// The response is not an error of the log, e.g. an HTML page of a proxy in front of it.
+		return &Error{StatusCode: statusCode, Op: operation, Message: unexpectedResponseMessage(statusCode, msgBytes)}
+	}
+
+	return &Error{StatusCode: statusCode, Op: operation, Message: errMsg.Message}
+}
+
+// unexpectedResponseMessage describes a response without an error message of the log by its status code and
+// the beginning of its body, with whitespace collapsed.
+func unexpectedResponseMessage(statusCode int, body []byte) string {
+	status := fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode))
+
+	snippet := strings.Join(strings.Fields(string(body)), " ")
+	if snippet == "" {
+		return fmt.Sprintf("unexpected response with status %s and empty body", status)
+	}
+
+	if len(snippet) > maxErrorSnippetLength {
+		snippet = strings.ToValidUTF8(snippet[:maxErrorSnippetLength], "") + "..."
 	}
 
-	return errors.New(errMsg.Message)
+	return fmt.Sprintf("unexpected response with status %s: %s", status, snippet)
 }
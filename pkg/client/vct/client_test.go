@@ -10,19 +10,41 @@ package vct_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
 	_ "embed"
+	"encoding/asn1"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
+	"math/big"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/piprate/json-gold/ld"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
+	"github.com/trustbloc/vct/internal/pkg/tlsutil"
 	"github.com/trustbloc/vct/pkg/canonicalizer"
 	"github.com/trustbloc/vct/pkg/client/vct"
 	"github.com/trustbloc/vct/pkg/controller/command"
@@ -97,46 +119,2417 @@ func TestClient_AddVC(t *testing.T) {
 	})
 }
 
+func TestClient_ValidateVC(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		expectedCredential := []byte(`{credential}`)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, http.MethodPost, req.Method)
+			require.Equal(t, "/maple2020/v1/validate-vc", req.URL.Path)
+			require.Equal(t, "Bearer tk2", req.Header.Get("Authorization"))
+
+			credential, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.Equal(t, expectedCredential, credential)
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		})
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithAuthReadToken("tk1"),
+			vct.WithAuthWriteToken("tk2"))
+		require.NoError(t, client.ValidateVC(context.Background(), expectedCredential))
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"bad request: issuer is not in a list"}`)),
+			StatusCode: http.StatusBadRequest,
+		}, nil)
+
+		err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).ValidateVC(context.Background(), []byte(`{}`))
+		require.EqualError(t, err, "validate VC: bad request: issuer is not in a list")
+		require.True(t, errors.Is(err, vct.ErrBadRequest))
+	})
+}
+
+func TestClient_AddVP(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		expectedPresentation := []byte(`{presentation}`)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, http.MethodPost, req.Method)
+			require.Equal(t, "/maple2020/v1/add-vp", req.URL.Path)
+			require.Equal(t, "Bearer tk2", req.Header.Get("Authorization"))
+
+			presentation, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.Equal(t, expectedPresentation, presentation)
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"timestamp":1}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		})
+
+		resp, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithAuthWriteToken("tk2")).
+			AddVP(context.Background(), expectedPresentation)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), resp.Timestamp)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"error"}`)),
+			StatusCode: http.StatusBadRequest,
+		}, nil)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).AddVP(context.Background(), []byte{})
+		require.EqualError(t, err, "add VP: error")
+		require.True(t, errors.Is(err, vct.ErrBadRequest))
+	})
+
+	t.Run("Empty signature", func(t *testing.T) {
+		vp, err := json.Marshal(map[string]interface{}{
+			"@context":             []string{"https://www.w3.org/2018/credentials/v1"},
+			"type":                 []string{"VerifiablePresentation"},
+			"verifiableCredential": []interface{}{simpleVC},
+		})
+		require.NoError(t, err)
+
+		// The signature of the response is the JSON null.
+		fakeResp, err := json.Marshal(command.AddVCResponse{Timestamp: 1, Signature: []byte(`null`)})
+		require.NoError(t, err)
+
+		log := newFakeLog(t)
+
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodPost {
+				return &http.Response{
+					Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+					StatusCode: http.StatusOK,
+				}, nil
+			}
+
+			return http.DefaultClient.Do(req)
+		}).Times(2)
+
+		_, err = log.client(vct.WithHTTPClient(httpClient), vct.WithVerifySCT(testutil.GetLoader(t))).
+			AddVP(context.Background(), vp)
+		require.EqualError(t, err, "add VP: sct_signature check failed: unmarshal signature: empty signature")
+	})
+}
+
+func TestClient_AddVCWithResponse(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Header:     http.Header{"X-Ratelimit-Remaining": []string{"9"}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"timestamp":1}`)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		resp, header, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).
+			AddVCWithResponse(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), resp.Timestamp)
+		require.Equal(t, "9", header.Get("X-RateLimit-Remaining"))
+	})
+
+	t.Run("Error response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Header:     http.Header{"Retry-After": []string{"5"}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"slow down"}`)),
+			StatusCode: http.StatusTooManyRequests,
+		}, nil)
+
+		_, header, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).
+			AddVCWithResponse(context.Background(), vcBachelorDegree)
+		require.True(t, errors.Is(err, vct.ErrTooManyRequests))
+		require.Equal(t, "5", header.Get("Retry-After"))
+	})
+
+	t.Run("No response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(nil, errors.New("connection refused"))
+
+		_, header, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).
+			AddVCWithResponse(context.Background(), vcBachelorDegree)
+		require.Error(t, err)
+		require.Nil(t, header)
+	})
+}
+
+func TestClient_WithRequireProof(t *testing.T) {
+	respond := func(t *testing.T) *MockHTTPClient {
+		t.Helper()
+
+		fakeResp, err := json.Marshal(command.AddVCResponse{SVCTVersion: 1, Timestamp: 1234567889})
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		return httpClient
+	}
+
+	t.Run("Signed", func(t *testing.T) {
+		for _, credential := range []string{
+			`{"id":"vc-1","proof":{"type":"Ed25519Signature2018","jws":"eyJ..abc"}}`,
+			`{"id":"vc-1","proof":[{},{"type":"Ed25519Signature2020","proofValue":"z3FX"}]}`,
+			`{"id":"vc-1","proof":[{"type":"RsaSignature2018","signatureValue":"abc"}]}`,
+			`eyJhbGciOiJFZERTQSJ9.eyJ2YyI6e319.c2lnbmF0dXJl`,
+			`"eyJhbGciOiJFZERTQSJ9.eyJ2YyI6e319.c2lnbmF0dXJl"`,
+		} {
+			_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t)), vct.WithRequireProof()).
+				AddVC(context.Background(), []byte(credential))
+			require.NoError(t, err, credential)
+		}
+	})
+
+	t.Run("Unsigned", func(t *testing.T) {
+		// The request must not be sent.
+		client := vct.New(endpoint, vct.WithHTTPClient(NewMockHTTPClient(gomock.NewController(t))),
+			vct.WithRequireProof())
+
+		for _, credential := range []string{
+			`{"id":"vc-1"}`,
+			`{"id":"vc-1","proof":null}`,
+			`{"id":"vc-1","proof":[]}`,
+			`{"id":"vc-1","proof":[{},{}]}`,
+			`{"id":"vc-1","proof":{"type":"Ed25519Signature2018"}}`,
+			`{"id":"vc-1","proof":{"jws":"eyJ..abc"}}`,
+			`eyJhbGciOiJub25lIn0.eyJ2YyI6e319.`,
+		} {
+			_, err := client.AddVC(context.Background(), []byte(credential))
+			require.True(t, errors.Is(err, vct.ErrNoProof), credential)
+		}
+
+		_, err := client.AddCredential(context.Background(), simpleVC)
+		require.EqualError(t, err, "add VC: credential has no proof")
+
+		_, err = client.AddVCRaw(context.Background(), []byte(`{"id":"vc-1"}`))
+		require.True(t, errors.Is(err, vct.ErrNoProof))
+	})
+
+	t.Run("Invalid credential", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(NewMockHTTPClient(gomock.NewController(t))),
+			vct.WithRequireProof()).AddVC(context.Background(), []byte(`{"id":`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "add VC: parse credential")
+	})
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t))).AddCredential(context.Background(), simpleVC)
+		require.NoError(t, err)
+	})
+}
+
+func TestClient_AddVCRaw(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	canonical := []byte(`{"@context":["https://www.w3.org/2018/credentials/v1"],"type":["VerifiableCredential"]}`)
+
+	httpClient := NewMockHTTPClient(ctrl)
+	httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		require.Empty(t, req.Header.Get("Content-Encoding"))
+
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.Equal(t, canonical, body)
+
+		return &http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"timestamp":1234567889}`)),
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+
+	resp, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRequestCompression()).
+		AddVCRaw(context.Background(), canonical)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1234567889), resp.Timestamp)
+}
+
+func TestClient_AddCredential(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		expectedCredential, err := canonicalizer.MarshalCanonical(simpleVC)
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			credential, readErr := ioutil.ReadAll(req.Body)
+			require.NoError(t, readErr)
+			require.Equal(t, expectedCredential, credential)
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"timestamp":1}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		})
+
+		resp, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).
+			AddCredential(context.Background(), simpleVC)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), resp.Timestamp)
+	})
+
+	t.Run("Nil credential", func(t *testing.T) {
+		_, err := vct.New(endpoint).AddCredential(context.Background(), nil)
+		require.EqualError(t, err, "add credential: credential is nil")
+	})
+}
+
+func TestClient_AddVCBatch(t *testing.T) {
+	credentials := [][]byte{[]byte(`{"id":"vc-1"}`), []byte(`{"id":"vc-2"}`)}
+
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		expected := command.AddVCBatchResponse{Results: []*command.AddVCBatchResult{
+			{Response: &command.AddVCResponse{Timestamp: 1234567889, Signature: []byte(`signature`)}},
+			{Error: "parse credential: invalid", StatusCode: http.StatusBadRequest},
+		}}
+
+		fakeResp, err := json.Marshal(expected)
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, http.MethodPost, req.Method)
+			require.Equal(t, "/maple2020/v1/add-vc-batch", req.URL.Path)
+			require.Equal(t, "Bearer tk2", req.Header.Get("Authorization"))
+
+			body, e := ioutil.ReadAll(req.Body)
+			require.NoError(t, e)
+			require.JSONEq(t, `[{"id":"vc-1"},{"id":"vc-2"}]`, string(body))
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+				StatusCode: http.StatusOK,
+			}, nil
+		})
+
+		results, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithAuthWriteToken("tk2")).
+			AddVCBatch(context.Background(), credentials)
+		require.NoError(t, err)
+		require.Equal(t, expected.Results, results)
+
+		require.NoError(t, vct.BatchResultError(results[0]))
+
+		err = vct.BatchResultError(results[1])
+		require.ErrorIs(t, err, vct.ErrBadRequest)
+		require.NotErrorIs(t, err, vct.ErrConflict)
+		require.EqualError(t, err, "parse credential: invalid")
+	})
+
+	t.Run("Results not aligned", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"results":[{"error":"error"}]}`)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).AddVCBatch(context.Background(), credentials)
+		require.EqualError(t, err, "add VC batch: decode results: expected 2 results")
+	})
+
+	t.Run("Invalid credential", func(t *testing.T) {
+		_, err := vct.New(endpoint).AddVCBatch(context.Background(), [][]byte{[]byte(`{credential}`)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "add VC batch: marshal credentials")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"batch is empty"}`)),
+			StatusCode: http.StatusBadRequest,
+		}, nil)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).AddVCBatch(context.Background(), nil)
+		require.EqualError(t, err, "add VC batch: batch is empty")
+	})
+}
+
 func TestClient_HealthCheck(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		httpClient := NewMockHTTPClient(ctrl)
-		httpClient.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
-		}).Return(&http.Response{
-			Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("{}"))),
-			StatusCode: http.StatusOK,
-		}, nil)
+		httpClient.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		}).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("{}"))),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		client := vct.New("https://vct:22/maple2020", vct.WithHTTPClient(httpClient), vct.WithAuthReadToken("tk1"))
+		err := client.HealthCheck(context.Background())
+		require.NoError(t, err)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		}).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("{}"))),
+			StatusCode: http.StatusInternalServerError,
+		}, nil)
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithAuthReadToken("tk1"))
+		err := client.HealthCheck(context.Background())
+		require.Error(t, err)
+	})
+}
+
+func TestClient_HealthStatus(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, "https://vct:22/maple2020/v1/healthcheck", req.URL.String())
+			require.Equal(t, "Bearer tk1", req.Header.Get("Authorization"))
+
+			return &http.Response{
+				Body: ioutil.NopCloser(bytes.NewBufferString(`{"status":"success","dbStatus":"success",` +
+					`"trillianStatus":"success","currentTime":"2022-09-01T21:18:03.14Z",` +
+					`"currentTimeMillis":1662067083140,"treeSize":5,"version":"v1.0.0"}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		})
+
+		client := vct.New("https://vct:22/maple2020", vct.WithHTTPClient(httpClient), vct.WithAuthReadToken("tk1"),
+			vct.WithAuthWriteToken("tk2"))
+
+		resp, err := client.HealthStatus(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, &command.HealthResponse{
+			Status:         command.HealthStatusSuccess,
+			DBStatus:       "success",
+			TrillianStatus: "success",
+			Time:           time.UnixMilli(1662067083140).UTC(),
+			CurrentTime:    1662067083140,
+			TreeSize:       5,
+			Version:        "v1.0.0",
+		}, resp)
+	})
+
+	t.Run("Unavailable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body: ioutil.NopCloser(bytes.NewBufferString(
+				`{"status":"unavailable","trillianStatus":"trillian unavailable"}`)),
+			StatusCode: http.StatusServiceUnavailable,
+		}, nil)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).HealthStatus(context.Background())
+		require.Error(t, err)
+		require.True(t, errors.Is(err, vct.ErrServerError))
+	})
+
+	t.Run("Malformed response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(*http.Request) (*http.Response, error) {
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`OK`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		}).Times(2)
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
+
+		_, err := client.HealthStatus(context.Background())
+
+		var decodeErr *vct.DecodeError
+		require.True(t, errors.As(err, &decodeErr))
+
+		// the detail is discarded by HealthCheck
+		require.NoError(t, client.HealthCheck(context.Background()))
+	})
+}
+
+func TestClient_GetPublicKey(t *testing.T) {
+	webfinger := func(t *testing.T, properties map[string]interface{}) *MockHTTPClient {
+		t.Helper()
+
+		fakeResp, err := json.Marshal(command.WebFingerResponse{Properties: properties})
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		return httpClient
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		log := newFakeLog(t)
+
+		pubKey, err := log.client().GetPublicKey(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, log.pubKey, pubKey)
+	})
+
+	t.Run("Missing property", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(webfinger(t, map[string]interface{}{}))).
+			GetPublicKey(context.Background())
+		require.EqualError(t, err,
+			"get public key: webfinger response has no https://trustbloc.dev/ns/public-key property")
+	})
+
+	t.Run("Property is not a string", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(webfinger(t, map[string]interface{}{
+			command.PublicKeyType: 1,
+		}))).GetPublicKey(context.Background())
+		require.EqualError(t, err, "get public key: https://trustbloc.dev/ns/public-key property is not a string")
+	})
+
+	t.Run("Malformed public key", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(webfinger(t, map[string]interface{}{
+			command.PublicKeyType: "%%%",
+		}))).GetPublicKey(context.Background())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get public key: decode public key")
+	})
+
+	t.Run("Webfinger error", func(t *testing.T) {
+		_, err := vct.New("http://127.0.0.1:0/maple2020").GetPublicKey(context.Background())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get public key: webfinger")
+	})
+}
+
+func TestClient_GetIssuers(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		expected := []string{"issuer_1", "issuer_2"}
+
+		fakeResp, err := json.Marshal(expected)
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
+		resp, err := client.GetIssuers(context.Background())
+		require.NoError(t, err)
+
+		bytesResp, err := json.Marshal(resp)
+		require.NoError(t, err)
+
+		require.Equal(t, fakeResp, bytesResp)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		expected := rest.ErrorResponse{Message: "error"}
+
+		fakeResp, err := json.Marshal(expected)
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+			StatusCode: http.StatusInternalServerError,
+		}, nil)
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
+		_, err = client.GetIssuers(context.Background())
+		require.EqualError(t, err, "get issuers: error")
+	})
+}
+
+func TestClient_GetRoots(t *testing.T) {
+	respond := func(t *testing.T, statusCode int, body string) *MockHTTPClient {
+		t.Helper()
+
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, "/maple2020/v1/get-roots", req.URL.Path)
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+				StatusCode: statusCode,
+			}, nil
+		})
+
+		return httpClient
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		fakeResp, err := json.Marshal(command.GetRootsResponse{
+			Certificates: [][]byte{[]byte("root_1"), []byte("root_2")},
+		})
+		require.NoError(t, err)
+
+		roots, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusOK, string(fakeResp)))).
+			GetRoots(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("root_1"), []byte("root_2")}, roots)
+	})
+
+	t.Run("Empty response", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusOK, "null"))).
+			GetRoots(context.Background())
+		require.EqualError(t, err, "get roots: decode body: empty response")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusInternalServerError, `{"message":"error"}`))).
+			GetRoots(context.Background())
+		require.EqualError(t, err, "get roots: error")
+	})
+}
+
+func TestClient_GetAcceptedContexts(t *testing.T) {
+	respond := func(t *testing.T, statusCode int, body string) *MockHTTPClient {
+		t.Helper()
+
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, "/maple2020/v1/get-accepted-contexts", req.URL.Path)
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+				StatusCode: statusCode,
+			}, nil
+		})
+
+		return httpClient
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		contexts, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusOK,
+			`["https://w3id.org/security/v2","https://www.w3.org/2018/credentials/v1"]`))).
+			GetAcceptedContexts(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{"https://w3id.org/security/v2", "https://www.w3.org/2018/credentials/v1"}, contexts)
+	})
+
+	t.Run("Not available", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusNotFound,
+			`{"message":"contexts of \"maple2020\" are not available"}`))).
+			GetAcceptedContexts(context.Background())
+		require.True(t, errors.Is(err, vct.ErrNotFound))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusInternalServerError, `{"message":"error"}`))).
+			GetAcceptedContexts(context.Background())
+		require.EqualError(t, err, "get accepted contexts: error")
+	})
+}
+
+func TestClient_GetLogInfo(t *testing.T) {
+	respond := func(t *testing.T, statusCode int, body string) *MockHTTPClient {
+		t.Helper()
+
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, "/maple2020/v1/get-log-info", req.URL.Path)
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+				StatusCode: statusCode,
+			}, nil
+		})
+
+		return httpClient
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		info, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusOK,
+			`{"version":"v1.0.0","hash_algorithm":"SHA-256","max_get_entries":1000,`+
+				`"features":["add-vc-batch","future-feature"],"future_field":true}`))).
+			GetLogInfo(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, &command.LogInfoResponse{
+			Version:       "v1.0.0",
+			HashAlgorithm: command.HashAlgorithm,
+			MaxGetEntries: command.MaxGetEntries,
+			Features:      []string{command.FeatureAddVCBatch, "future-feature"},
+		}, info)
+		require.True(t, info.HasFeature(command.FeatureAddVCBatch))
+		require.False(t, info.HasFeature(command.FeatureAddVP))
+	})
+
+	t.Run("Not available", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusNotFound, `404 page not found`))).
+			GetLogInfo(context.Background())
+		require.True(t, errors.Is(err, vct.ErrNotFound))
+	})
+
+	t.Run("Empty response", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusOK, `null`))).
+			GetLogInfo(context.Background())
+		require.EqualError(t, err, "get log info: decode body: empty response")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusInternalServerError, `{"message":"error"}`))).
+			GetLogInfo(context.Background())
+		require.EqualError(t, err, "get log info: error")
+	})
+}
+
+func TestClient_Webfinger(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		expected := command.WebFingerResponse{
+			Subject: "https://vct.com/maple2021",
+			Properties: map[string]interface{}{
+				"https://trustbloc.dev/ns/public-key": "cHVibGljIGtleQ==",
+			},
+			Links: []command.WebFingerLink{{
+				Rel:  "self",
+				Href: "https://vct.com/maple2021",
+			}},
+		}
+
+		fakeResp, err := json.Marshal(expected)
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		client := vct.New(endpoint,
+			vct.WithHTTPClient(httpClient),
+			vct.WithLedgerURI("https://vct.com/maple2021"),
+		)
+		resp, err := client.Webfinger(context.Background())
+		require.NoError(t, err)
+
+		bytesResp, err := json.Marshal(resp)
+		require.NoError(t, err)
+
+		require.Equal(t, fakeResp, bytesResp)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		expected := rest.ErrorResponse{Message: "error"}
+
+		fakeResp, err := json.Marshal(expected)
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+			StatusCode: http.StatusInternalServerError,
+		}, nil)
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
+		_, err = client.Webfinger(context.Background())
+		require.EqualError(t, err, "webfinger: error")
+	})
+}
+
+func TestClient_LargeNumbers(t *testing.T) {
+	const large = uint64(1)<<53 + 1 // not representable as float64
+
+	respond := func(t *testing.T, body string) *MockHTTPClient {
+		t.Helper()
+
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		return httpClient
+	}
+
+	addVCResp := fmt.Sprintf(`{"svct_version":1,"timestamp":%d}`, large)
+
+	for _, opts := range [][]vct.ClientOpt{nil, {vct.WithDetectErrorInSuccessBody()}} {
+		resp, err := vct.New(endpoint, append(opts, vct.WithHTTPClient(respond(t, addVCResp)))...).
+			AddVC(context.Background(), []byte(`{}`))
+		require.NoError(t, err)
+		require.Equal(t, large, resp.Timestamp)
+	}
+
+	webfingerResp := fmt.Sprintf(`{"subject":"https://vct.com/maple2021","properties":{"tree_size":%d}}`, large)
+
+	resp, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, webfingerResp))).Webfinger(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, json.Number(strconv.FormatUint(large, 10)), resp.Properties["tree_size"])
+}
+
+func TestClient_WithWebfingerURL(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, "https://gateway.example.com/.well-known/webfinger?"+
+				"resource=https%3A%2F%2Fvct.com%2Fmaple2021&tenant=maple", req.URL.String())
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"subject":"https://vct.com/maple2021"}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		})
+
+		client := vct.New(endpoint,
+			vct.WithHTTPClient(httpClient),
+			vct.WithLedgerURI("https://vct.com/maple2021"),
+			vct.WithWebfingerURL("https://gateway.example.com/.well-known/webfinger?tenant=maple"),
+		)
+
+		resp, err := client.Webfinger(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "https://vct.com/maple2021", resp.Subject)
+	})
+
+	t.Run("Not absolute", func(t *testing.T) {
+		for _, webfingerURL := range []string{"/.well-known/webfinger", "gateway.example.com/.well-known/webfinger"} {
+			client := vct.New(endpoint,
+				vct.WithHTTPClient(NewMockHTTPClient(gomock.NewController(t))),
+				vct.WithWebfingerURL(webfingerURL),
+			)
+
+			_, err := client.Webfinger(context.Background())
+			require.EqualError(t, err, fmt.Sprintf("webfinger: URL %q is not absolute", webfingerURL))
+		}
+	})
+}
+
+func TestClient_EndpointPath(t *testing.T) {
+	calls := []struct {
+		path string
+		call func(client *vct.Client) error
+	}{
+		{
+			path: "/v1/get-sth",
+			call: func(client *vct.Client) error {
+				_, err := client.GetSTH(context.Background())
+
+				return err
+			},
+		},
+		{
+			path: "/v1/add-vc",
+			call: func(client *vct.Client) error {
+				_, err := client.AddVC(context.Background(), vcBachelorDegree)
+
+				return err
+			},
+		},
+		{
+			path: "/v1/get-entries?end=1&start=0",
+			call: func(client *vct.Client) error {
+				_, err := client.GetEntries(context.Background(), 0, 1)
+
+				return err
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		endpoint string
+		expected string
+	}{
+		{name: "Alias", endpoint: "https://vct:22/maple2020", expected: "https://vct:22/maple2020"},
+		{name: "Trailing slash", endpoint: "https://vct:22/maple2020/", expected: "https://vct:22/maple2020"},
+		{name: "Base path", endpoint: "https://vct:22/api/vct/maple2020", expected: "https://vct:22/api/vct/maple2020"},
+		{
+			name:     "Base path with trailing slash",
+			endpoint: "https://vct:22/api/vct/maple2020/",
+			expected: "https://vct:22/api/vct/maple2020",
+		},
+		{name: "No alias", endpoint: "https://vct:22", expected: "https://vct:22"},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			for _, call := range calls {
+				ctrl := gomock.NewController(t)
+
+				httpClient := NewMockHTTPClient(ctrl)
+				httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					require.Equal(t, tc.expected+call.path, req.URL.String())
+
+					return &http.Response{
+						Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+						StatusCode: http.StatusOK,
+					}, nil
+				})
+
+				require.NoError(t, call.call(vct.New(tc.endpoint, vct.WithHTTPClient(httpClient))))
+			}
+
+			ctrl := gomock.NewController(t)
+
+			// the health check and Webfinger paths are relative to the host
+			httpClient := NewMockHTTPClient(ctrl)
+			gomock.InOrder(
+				httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					require.Equal(t, "https://vct:22/healthcheck", req.URL.String())
+
+					return &http.Response{
+						Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+						StatusCode: http.StatusOK,
+					}, nil
+				}),
+				httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					require.Equal(t, "/.well-known/webfinger", req.URL.Path)
+
+					return &http.Response{
+						Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+						StatusCode: http.StatusOK,
+					}, nil
+				}),
+			)
+
+			client := vct.New(tc.endpoint, vct.WithHTTPClient(httpClient))
+			require.NoError(t, client.HealthCheck(context.Background()))
+
+			_, err := client.Webfinger(context.Background())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestClient_Error(t *testing.T) {
+	respond := func(t *testing.T, statusCode int, body string) *MockHTTPClient {
+		t.Helper()
+
+		ctrl := gomock.NewController(t)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+			StatusCode: statusCode,
+		}, nil)
+
+		return httpClient
+	}
+
+	t.Run("Status codes", func(t *testing.T) {
+		for statusCode, sentinel := range map[int]error{
+			http.StatusBadRequest:          vct.ErrBadRequest,
+			http.StatusUnauthorized:        vct.ErrUnauthorized,
+			http.StatusForbidden:           vct.ErrForbidden,
+			http.StatusNotFound:            vct.ErrNotFound,
+			http.StatusConflict:            vct.ErrConflict,
+			http.StatusTooManyRequests:     vct.ErrTooManyRequests,
+			http.StatusInternalServerError: vct.ErrServerError,
+			http.StatusBadGateway:          vct.ErrServerError,
+		} {
+			_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, statusCode, `{"message":"failed"}`))).
+				AddVC(context.Background(), vcBachelorDegree)
+			require.EqualError(t, err, "add VC: failed")
+			require.True(t, errors.Is(err, sentinel), "status %d", statusCode)
+
+			var vctErr *vct.Error
+			require.True(t, errors.As(err, &vctErr))
+			require.Equal(t, &vct.Error{StatusCode: statusCode, Op: "AddVC", Message: "failed"}, vctErr)
+		}
+	})
+
+	t.Run("Retry-After", func(t *testing.T) {
+		retryAfter := func(value string) time.Duration {
+			ctrl := gomock.NewController(t)
+
+			httpClient := NewMockHTTPClient(ctrl)
+			httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+				Header:     http.Header{"Retry-After": []string{value}},
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"slow down"}`)),
+				StatusCode: http.StatusTooManyRequests,
+			}, nil)
+
+			_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).GetSTH(context.Background())
+			require.EqualError(t, err, "get STH: slow down")
+			require.True(t, errors.Is(err, vct.ErrTooManyRequests))
+
+			var vctErr *vct.Error
+			require.True(t, errors.As(err, &vctErr))
+
+			return vctErr.RetryAfter
+		}
+
+		require.Equal(t, 120*time.Second, retryAfter("120"))
+		require.InDelta(t, float64(time.Hour), float64(retryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))),
+			float64(2*time.Second))
+		require.Zero(t, retryAfter(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)))
+		require.Zero(t, retryAfter("-1"))
+		require.Zero(t, retryAfter("soon"))
+		require.Zero(t, retryAfter(""))
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		const body = `{"message":"leaf not found"}`
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusNotFound, body))).
+			GetProofByHash(context.Background(), "hash", 2)
+		require.EqualError(t, err, "get proof by hash: leaf not found")
+		require.True(t, errors.Is(err, vct.ErrNotFound))
+
+		_, err = vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusNotFound, body))).
+			GetEntryAndProof(context.Background(), 1, 2)
+		require.EqualError(t, err, "get entry and proof: leaf not found")
+		require.True(t, errors.Is(err, vct.ErrNotFound))
+
+		_, err = vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusNotFound, body))).
+			GetEntries(context.Background(), 0, 1)
+		require.EqualError(t, err, "get entries: leaf not found")
+		require.True(t, errors.Is(err, vct.ErrNotFound))
+
+		var vctErr *vct.Error
+		require.True(t, errors.As(err, &vctErr))
+		require.Equal(t, "GetEntries", vctErr.Op)
+	})
+
+	t.Run("Other sentinel", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusUnauthorized, `{"message":"failed"}`))).
+			GetSTH(context.Background())
+		require.False(t, errors.Is(err, vct.ErrForbidden))
+		require.False(t, errors.Is(err, vct.ErrServerError))
+	})
+
+	t.Run("Body is not JSON", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusNotFound, `page not found`))).
+			GetSTH(context.Background())
+		require.EqualError(t, err, "get STH: unexpected response with status 404 Not Found: page not found")
+		require.True(t, errors.Is(err, vct.ErrNotFound))
+	})
+
+	t.Run("HTML body", func(t *testing.T) {
+		page := "<html>\n<head><title>502 Bad Gateway</title></head>\n<body>\n<center><h1>502 Bad Gateway</h1>" +
+			"</center>\n<hr><center>nginx</center>\n</body>\n</html>\n" + strings.Repeat("<!-- padding -->\n", 20)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusBadGateway, page))).
+			GetSTH(context.Background())
+		require.True(t, errors.Is(err, vct.ErrServerError))
+
+		var vctErr *vct.Error
+		require.True(t, errors.As(err, &vctErr))
+		require.Equal(t, http.StatusBadGateway, vctErr.StatusCode)
+		require.True(t, strings.HasPrefix(vctErr.Message, "unexpected response with status 502 Bad Gateway: "+
+			"<html> <head><title>502 Bad Gateway</title></head> <body>"), vctErr.Message)
+		require.True(t, strings.HasSuffix(vctErr.Message, "..."))
+		require.Less(t, len(vctErr.Message), 350)
+	})
+
+	t.Run("Empty body", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusServiceUnavailable, ""))).
+			GetSTH(context.Background())
+		require.EqualError(t, err, "get STH: unexpected response with status 503 Service Unavailable and empty body")
+	})
+
+	t.Run("JSON body without message", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusBadGateway, `{"error":"upstream"}`))).
+			GetSTH(context.Background())
+		require.EqualError(t, err, `get STH: unexpected response with status 502 Bad Gateway: {"error":"upstream"}`)
+	})
+
+	t.Run("Error in success body", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, http.StatusOK, `{"message":"failed"}`)),
+			vct.WithDetectErrorInSuccessBody()).GetSTH(context.Background())
+
+		var vctErr *vct.Error
+		require.True(t, errors.As(err, &vctErr))
+		require.Equal(t, &vct.Error{StatusCode: http.StatusOK, Op: "GetSTH", Message: "failed"}, vctErr)
+	})
+}
+
+func TestClient_DetectErrorInSuccessBody(t *testing.T) {
+	respond := func(t *testing.T, body string) *MockHTTPClient {
+		t.Helper()
+
+		ctrl := gomock.NewController(t)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		return httpClient
+	}
+
+	t.Run("Error body", func(t *testing.T) {
+		client := vct.New(endpoint,
+			vct.WithHTTPClient(respond(t, `{"message":"upstream unavailable"}`)),
+			vct.WithDetectErrorInSuccessBody(),
+		)
+
+		resp, err := client.GetSTH(context.Background())
+		require.EqualError(t, err, "get STH: upstream unavailable")
+		require.Nil(t, resp)
+	})
+
+	t.Run("Error body without detection", func(t *testing.T) {
+		client := vct.New(endpoint, vct.WithHTTPClient(respond(t, `{"message":"upstream unavailable"}`)))
+
+		resp, err := client.GetSTH(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, &command.GetSTHResponse{}, resp)
+	})
+
+	t.Run("Success body", func(t *testing.T) {
+		client := vct.New(endpoint,
+			vct.WithHTTPClient(respond(t, `{"tree_size":1,"timestamp":1234567889}`)),
+			vct.WithDetectErrorInSuccessBody(),
+		)
+
+		resp, err := client.GetSTH(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), resp.TreeSize)
+		require.Equal(t, uint64(1234567889), resp.Timestamp)
+	})
+
+	t.Run("Unknown fields without message", func(t *testing.T) {
+		client := vct.New(endpoint,
+			vct.WithHTTPClient(respond(t, `{"tree_size":1,"extension":"value"}`)),
+			vct.WithDetectErrorInSuccessBody(),
+		)
+
+		resp, err := client.GetSTH(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), resp.TreeSize)
+	})
+
+	t.Run("Malformed body", func(t *testing.T) {
+		client := vct.New(endpoint,
+			vct.WithHTTPClient(respond(t, `{`)),
+			vct.WithDetectErrorInSuccessBody(),
+		)
+
+		_, err := client.GetSTH(context.Background())
+		require.Error(t, err)
+	})
+}
+
+type staticCertPool struct {
+	pool *x509.CertPool
+	err  error
+}
+
+func (p staticCertPool) Get() (*x509.CertPool, error) {
+	return p.pool, p.err
+}
+
+func TestClient_WithTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(`{"tree_size":1}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	certPool, err := tlsutil.NewCertPool(false)
+	require.NoError(t, err)
+
+	certPool.Add(server.Certificate())
+
+	t.Run("Cert pool", func(t *testing.T) {
+		resp, err := vct.New(server.URL+"/maple2020", vct.WithTLSCertPool(certPool)).GetSTH(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), resp.TreeSize)
+	})
+
+	t.Run("TLS config", func(t *testing.T) {
+		rootCAs, err := certPool.Get()
+		require.NoError(t, err)
+
+		resp, err := vct.New(server.URL+"/maple2020", vct.WithTLSConfig(&tls.Config{
+			RootCAs:    rootCAs,
+			MinVersion: tls.VersionTLS12,
+		})).GetSTH(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), resp.TreeSize)
+	})
+
+	t.Run("Cert pool of the caller", func(t *testing.T) {
+		rootCAs := x509.NewCertPool()
+		rootCAs.AddCert(server.Certificate())
+
+		resp, err := vct.New(server.URL+"/maple2020", vct.WithTLSCertPool(staticCertPool{pool: rootCAs})).
+			GetSTH(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), resp.TreeSize)
+	})
+
+	t.Run("Cert pool error", func(t *testing.T) {
+		_, err := vct.New(server.URL+"/maple2020", vct.WithTLSCertPool(staticCertPool{err: errors.New("error")})).
+			GetSTH(context.Background())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get TLS cert pool: error")
+	})
+
+	t.Run("Unknown authority", func(t *testing.T) {
+		emptyPool, err := tlsutil.NewCertPool(false)
+		require.NoError(t, err)
+
+		_, err = vct.New(server.URL+"/maple2020", vct.WithTLSCertPool(emptyPool)).GetSTH(context.Background())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "certificate")
+	})
+
+	t.Run("Ignored with HTTP client", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"tree_size":2}`)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		resp, err := vct.New(server.URL+"/maple2020", vct.WithTLSCertPool(certPool),
+			vct.WithHTTPClient(httpClient)).GetSTH(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), resp.TreeSize)
+	})
+
+	t.Run("Insecure skip verify", func(t *testing.T) {
+		resp, err := vct.New(server.URL+"/maple2020", vct.WithInsecureSkipVerify()).GetSTH(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), resp.TreeSize)
+
+		// the certificate is verified by the HTTP client provided
+		_, err = vct.New(server.URL+"/maple2020", vct.WithInsecureSkipVerify(),
+			vct.WithHTTPClient(&http.Client{})).GetSTH(context.Background())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "certificate")
+	})
+}
+
+func TestClient_WithCompression(t *testing.T) {
+	compress := func(t *testing.T, data string) []byte {
+		t.Helper()
+
+		var buf bytes.Buffer
+
+		writer := gzip.NewWriter(&buf)
+
+		_, err := writer.Write([]byte(data))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		return buf.Bytes()
+	}
+
+	respond := func(code int, encoding string, body []byte) func(*http.Request) (*http.Response, error) {
+		return func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, "gzip", req.Header.Get("Accept-Encoding"))
+
+			header := http.Header{}
+			if encoding != "" {
+				header.Set("Content-Encoding", encoding)
+			}
+
+			return &http.Response{
+				Header:     header,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(body)),
+				StatusCode: code,
+			}, nil
+		}
+	}
+
+	t.Run("Compressed response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(
+			respond(http.StatusOK, "gzip", compress(t, `{"tree_size":3}`)),
+		)
+
+		resp, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithCompression()).
+			GetSTH(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), resp.TreeSize)
+	})
+
+	t.Run("Compressed error response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(
+			respond(http.StatusInternalServerError, "gzip", compress(t, `{"message":"internal error"}`)),
+		)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithCompression()).
+			GetSTH(context.Background())
+		require.EqualError(t, err, "get STH: internal error")
+	})
+
+	t.Run("Identity response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(respond(http.StatusOK, "", []byte(`{"tree_size":3}`)))
+
+		resp, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithCompression()).
+			GetSTH(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), resp.TreeSize)
+	})
+
+	t.Run("Malformed compressed response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(respond(http.StatusOK, "gzip", []byte(`{"tree_size":3}`)))
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithCompression()).
+			GetSTH(context.Background())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get STH: decompress body")
+	})
+
+	t.Run("Compressed request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, "gzip", req.Header.Get("Content-Encoding"))
+			require.Empty(t, req.Header.Get("Accept-Encoding"))
+
+			reader, err := gzip.NewReader(req.Body)
+			require.NoError(t, err)
+
+			body, err := ioutil.ReadAll(reader)
+			require.NoError(t, err)
+			require.Equal(t, vcBachelorDegree, body)
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"timestamp":1}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		})
+
+		resp, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRequestCompression()).
+			AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), resp.Timestamp)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Empty(t, req.Header.Get("Accept-Encoding"))
+			require.Empty(t, req.Header.Get("Content-Encoding"))
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"timestamp":1}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		})
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+	})
+}
+
+func TestClient_WithMaxResponseBytes(t *testing.T) {
+	respond := func(code int, body string) func(*http.Request) (*http.Response, error) {
+		return func(*http.Request) (*http.Response, error) {
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+				StatusCode: code,
+			}, nil
+		}
+	}
+
+	t.Run("Within limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		body := `{"tree_size":3}`
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(respond(http.StatusOK, body))
+
+		resp, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithMaxResponseBytes(int64(len(body)))).
+			GetSTH(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), resp.TreeSize)
+	})
+
+	t.Run("Too large", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(
+			respond(http.StatusOK, `{"tree_size":3,"sha256_root_hash":"`+strings.Repeat("A", 100)+`"}`),
+		)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithMaxResponseBytes(64)).
+			GetSTH(context.Background())
+		require.True(t, errors.Is(err, vct.ErrResponseTooLarge))
+		require.EqualError(t, err, "get STH: response too large: exceeds 64 bytes")
+	})
+
+	t.Run("Too large error response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(
+			respond(http.StatusInternalServerError, strings.Repeat("A", 100)),
+		)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithMaxResponseBytes(64)).
+			GetSTH(context.Background())
+		require.True(t, errors.Is(err, vct.ErrResponseTooLarge))
+	})
+
+	t.Run("Too large health response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(
+			respond(http.StatusOK, `{"treeSize":3,"version":"`+strings.Repeat("A", 100)+`"}`),
+		)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithMaxResponseBytes(64)).
+			HealthStatus(context.Background())
+		require.True(t, errors.Is(err, vct.ErrResponseTooLarge))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(
+			respond(http.StatusOK, `{"tree_size":3,"sha256_root_hash":"`+strings.Repeat("A", 100)+`"}`),
+		)
+
+		resp, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithMaxResponseBytes(0)).
+			GetSTH(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), resp.TreeSize)
+	})
+}
+
+func TestClient_WithVerifySCT(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		log := newFakeLog(t)
+		httpClient := &countingHTTPClient{}
+
+		client := log.client(vct.WithHTTPClient(httpClient), vct.WithVerifySCT(testutil.GetLoader(t)))
+
+		_, err := client.AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+		require.Equal(t, 2, httpClient.count())
+
+		// The public key of the log is retrieved once.
+		_, err = client.AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+		require.Equal(t, 3, httpClient.count())
+	})
+
+	t.Run("Forged timestamp", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		log := newFakeLog(t)
+
+		resp, err := log.client().AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+
+		resp.Timestamp++
+
+		forged, err := json.Marshal(resp)
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodPost {
+				return &http.Response{
+					Body:       ioutil.NopCloser(bytes.NewBuffer(forged)),
+					StatusCode: http.StatusOK,
+				}, nil
+			}
+
+			return http.DefaultClient.Do(req)
+		}).Times(2)
+
+		_, err = log.client(vct.WithHTTPClient(httpClient), vct.WithVerifySCT(testutil.GetLoader(t))).
+			AddVC(context.Background(), vcBachelorDegree)
+
+		var verificationErr *vct.VerificationError
+		require.True(t, errors.As(err, &verificationErr))
+		require.Equal(t, vct.CheckSCTSignature, verificationErr.Check)
+		require.Contains(t, err.Error(), "add VC: sct_signature check failed")
+	})
+
+	t.Run("Public key error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		gomock.InOrder(
+			httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"timestamp":1}`)),
+				StatusCode: http.StatusOK,
+			}, nil),
+			httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"properties":{}}`)),
+				StatusCode: http.StatusOK,
+			}, nil),
+		)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithVerifySCT(testutil.GetLoader(t))).
+			AddVC(context.Background(), vcBachelorDegree)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "add VC: get public key: webfinger response has no")
+	})
+}
+
+func TestClient_WithH2C(t *testing.T) {
+	log := newFakeLog(t)
+
+	var protos sync.Map
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		protos.Store(r.Proto, true)
+		log.server.Config.Handler.ServeHTTP(w, r)
+	})
+
+	server := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	defer server.Close()
+
+	client := vct.New(server.URL+"/"+fakeLogAlias, vct.WithH2C())
+	defer client.Close() // nolint: errcheck
+
+	_, err := client.AddVC(context.Background(), vcBachelorDegree)
+	require.NoError(t, err)
+
+	sth, err := client.GetSTH(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), sth.TreeSize)
+
+	_, usedHTTP2 := protos.Load("HTTP/2.0")
+	_, usedHTTP1 := protos.Load("HTTP/1.1")
+	require.True(t, usedHTTP2)
+	require.False(t, usedHTTP1)
+}
+
+func TestClient_Close(t *testing.T) {
+	t.Run("Default HTTP client", func(t *testing.T) {
+		log := newFakeLog(t)
+
+		var closed int32
+
+		server := httptest.NewUnstartedServer(log.server.Config.Handler)
+		server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+			if state == http.StateClosed {
+				atomic.AddInt32(&closed, 1)
+			}
+		}
+
+		server.Start()
+		defer server.Close()
+
+		client := vct.New(server.URL + "/" + fakeLogAlias)
+
+		_, err := client.GetSTH(context.Background())
+		require.NoError(t, err)
+		require.Zero(t, atomic.LoadInt32(&closed))
+
+		require.NoError(t, client.Close())
+
+		// the idle connection is closed
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt32(&closed) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		_, err = client.GetSTH(context.Background())
+		require.ErrorIs(t, err, vct.ErrClientClosed)
+		require.ErrorIs(t, client.HealthCheck(context.Background()), vct.ErrClientClosed)
+
+		require.NoError(t, client.Close())
+	})
+
+	t.Run("Own HTTP client", func(t *testing.T) {
+		// no request is sent after Close
+		client := vct.New(endpoint, vct.WithHTTPClient(NewMockHTTPClient(gomock.NewController(t))))
+
+		require.NoError(t, client.Close())
+
+		_, err := client.AddVC(context.Background(), vcBachelorDegree)
+		require.ErrorIs(t, err, vct.ErrClientClosed)
+		require.EqualError(t, err, "add VC: client closed")
+	})
+}
+
+func TestClient_Concurrent(t *testing.T) {
+	const (
+		workers  = 16
+		requests = 20
+	)
+
+	log := newFakeLog(t)
+
+	var connections int32
+
+	server := httptest.NewUnstartedServer(log.server.Config.Handler)
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+
+	server.Start()
+	defer server.Close()
+
+	client := vct.New(server.URL+"/"+fakeLogAlias, vct.WithMaxIdleConnsPerHost(workers))
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 2*workers*requests)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < requests; j++ {
+				_, err := client.AddVC(context.Background(), vcBachelorDegree)
+				errs <- err
+
+				_, err = client.GetSTH(context.Background())
+				errs <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	sth, err := client.GetSTH(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(workers*requests), sth.TreeSize)
+
+	// Connections are reused rather than opened per request. The number of connections is not bounded by the
+	// number of workers, as a worker may open a new connection while its previous one is being returned to the
+	// idle pool, but it stays well below the number of requests.
+	require.Less(t, int(atomic.LoadInt32(&connections)), 2*workers*requests/4)
+}
+
+func TestClient_WithHeader(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, "vct-client/1.0", req.UserAgent())
+			require.Equal(t, "tenant-1", req.Header.Get("X-Tenant-ID"))
+			require.Equal(t, []string{"a", "b"}, req.Header.Values("X-Trace-Tag"))
+			require.Equal(t, []string{"Bearer write-token"}, req.Header.Values("Authorization"))
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"timestamp":1}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		}).Times(2)
+
+		client := vct.New(endpoint,
+			vct.WithHTTPClient(httpClient),
+			vct.WithUserAgent("vct-client/1.0"),
+			vct.WithHeader("X-Tenant-ID", "tenant-1"),
+			vct.WithHeader("X-Trace-Tag", "a"),
+			vct.WithHeader("X-Trace-Tag", "b"),
+			vct.WithHeader("Authorization", "Bearer static"),
+			vct.WithAuthWriteToken("write-token"),
+		)
+
+		// Every request gets the headers.
+		for i := 0; i < 2; i++ {
+			_, err := client.AddVC(context.Background(), vcBachelorDegree)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("No token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, "Bearer static", req.Header.Get("Authorization"))
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"tree_size":1}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		})
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithHeader("Authorization", "Bearer static")).
+			GetSTH(context.Background())
+		require.NoError(t, err)
+	})
+
+	t.Run("Health check", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, "/healthcheck", req.URL.Path)
+			require.Equal(t, "vct-client/1.0", req.UserAgent())
+			require.Equal(t, "tenant-1", req.Header.Get("X-Tenant-ID"))
+			require.Equal(t, "request-1", req.Header.Get("X-Request-ID"))
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"status":"success"}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		})
+
+		client := vct.New(endpoint,
+			vct.WithHTTPClient(httpClient),
+			vct.WithUserAgent("vct-client/1.0"),
+			vct.WithHeader("X-Tenant-ID", "tenant-1"),
+			vct.WithRequestIDFromContext(func(context.Context) string { return "request-1" }),
+		)
+
+		require.NoError(t, client.HealthCheck(context.Background()))
+	})
+}
+
+type requestIDKey struct{}
+
+func TestClient_WithRequestIDFromContext(t *testing.T) {
+	requestID := func(ctx context.Context) string {
+		id, _ := ctx.Value(requestIDKey{}).(string)
+
+		return id
+	}
+
+	sth := func(expected []string) func(*http.Request) (*http.Response, error) {
+		return func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, expected, req.Header.Values("X-Request-ID"))
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"tree_size":1}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		}
+	}
+
+	t.Run("Request ID in context", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		gomock.InOrder(
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(sth([]string{"req-1"})),
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(sth([]string{"req-2"})),
+		)
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRequestIDFromContext(requestID))
+
+		_, err := client.GetSTH(context.WithValue(context.Background(), requestIDKey{}, "req-1"))
+		require.NoError(t, err)
+
+		_, err = client.GetSTH(context.WithValue(context.Background(), requestIDKey{}, "req-2"))
+		require.NoError(t, err)
+	})
+
+	t.Run("No request ID in context", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(sth(nil))
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRequestIDFromContext(requestID)).
+			GetSTH(context.Background())
+		require.NoError(t, err)
+	})
+
+	t.Run("No extractor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(sth(nil))
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).
+			GetSTH(context.WithValue(context.Background(), requestIDKey{}, "req-1"))
+		require.NoError(t, err)
+	})
+}
+
+func TestClient_WithTokenSource(t *testing.T) {
+	tokens := func(prefix string) vct.TokenSource {
+		var calls int
+
+		return func(ctx context.Context) (string, error) {
+			calls++
+
+			return fmt.Sprintf("%s-%d", prefix, calls), nil
+		}
+	}
+
+	t.Run("Fresh token for every request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var authorization []string
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			authorization = append(authorization, req.Header.Get("Authorization"))
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"tree_size":1}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		}).Times(2)
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithAuthReadToken("static"),
+			vct.WithTokenSource(tokens("token")))
+
+		for i := 0; i < 2; i++ {
+			_, err := client.GetSTH(context.Background())
+			require.NoError(t, err)
+		}
+
+		require.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, authorization)
+	})
+
+	t.Run("Read and write sources", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodPost {
+				require.Equal(t, "Bearer write-1", req.Header.Get("Authorization"))
+
+				return &http.Response{
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"timestamp":1}`)),
+					StatusCode: http.StatusOK,
+				}, nil
+			}
+
+			require.Equal(t, "Bearer read-1", req.Header.Get("Authorization"))
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"tree_size":1}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		}).Times(2)
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient),
+			vct.WithReadTokenSource(tokens("read")),
+			vct.WithWriteTokenSource(tokens("write")),
+		)
+
+		_, err := client.AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+
+		_, err = client.GetSTH(context.Background())
+		require.NoError(t, err)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Times(0)
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRetry(3, time.Millisecond),
+			vct.WithTokenSource(func(ctx context.Context) (string, error) {
+				return "", errors.New("token expired")
+			}))
+
+		_, err := client.GetSTH(context.Background())
+		require.EqualError(t, err, "get STH: get token: token expired")
+
+		_, err = client.AddVC(context.Background(), vcBachelorDegree)
+		require.EqualError(t, err, "add VC: get token: token expired")
+	})
+}
+
+func TestClient_WithTokenSelector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authorization := map[string]string{}
+
+	httpClient := NewMockHTTPClient(ctrl)
+	httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		authorization[req.URL.Path] = req.Header.Get("Authorization")
+
+		body := `{"tree_size":1}`
+
+		switch {
+		case req.Method == http.MethodPost:
+			body = `{"timestamp":1}`
+		case strings.HasSuffix(req.URL.Path, "webfinger"):
+			body = `{}`
+		}
+
+		return &http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+			StatusCode: http.StatusOK,
+		}, nil
+	}).Times(3)
+
+	var methods []string
+
+	client := vct.New(endpoint, vct.WithHTTPClient(httpClient),
+		vct.WithAuthReadToken("read"),
+		vct.WithWriteTokenSource(func(ctx context.Context) (string, error) {
+			return "", errors.New("unexpected call")
+		}),
+		vct.WithTokenSelector(func(method string) string {
+			methods = append(methods, method)
+
+			switch method {
+			case "Webfinger":
+				return "write"
+			case "AddVC":
+				return "read"
+			default:
+				return ""
+			}
+		}),
+	)
+
+	_, err := client.Webfinger(context.Background())
+	require.NoError(t, err)
+
+	_, err = client.AddVC(context.Background(), vcBachelorDegree)
+	require.NoError(t, err)
+
+	_, err = client.GetSTH(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"Webfinger", "AddVC", "GetSTH"}, methods)
+	require.Equal(t, map[string]string{
+		"/.well-known/webfinger": "Bearer write",
+		"/maple2020/v1/add-vc":   "Bearer read",
+		"/maple2020/v1/get-sth":  "",
+	}, authorization)
+}
+
+func TestClient_WithTimeout(t *testing.T) {
+	slow := func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+
+		return nil, req.Context().Err()
+	}
+
+	t.Run("Slow request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(slow)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithTimeout(10*time.Millisecond)).
+			GetSTH(context.Background())
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		require.Contains(t, err.Error(), "context deadline exceeded")
+	})
+
+	t.Run("Earlier deadline of the caller", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		expected, _ := ctx.Deadline()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			deadline, ok := req.Context().Deadline()
+			require.True(t, ok)
+			require.Equal(t, expected, deadline)
+
+			return slow(req)
+		})
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithTimeout(time.Hour)).GetSTH(ctx)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("Later deadline of the caller", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			deadline, ok := req.Context().Deadline()
+			require.True(t, ok)
+			require.WithinDuration(t, time.Now().Add(time.Minute), deadline, 10*time.Second)
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"tree_size":1}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		})
+
+		resp, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithTimeout(time.Minute)).GetSTH(ctx)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), resp.TreeSize)
+	})
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClient_WithRetry(t *testing.T) {
+	respond := func(code int, body string) func(*http.Request) (*http.Response, error) {
+		return func(*http.Request) (*http.Response, error) {
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+				StatusCode: code,
+			}, nil
+		}
+	}
+
+	sth := `{"tree_size":1,"timestamp":1234567889}`
+
+	t.Run("Success after 5xx", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		gomock.InOrder(
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(respond(http.StatusBadGateway, "bad gateway")),
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(respond(http.StatusServiceUnavailable, "unavailable")),
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(respond(http.StatusOK, sth)),
+		)
+
+		resp, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRetry(3, time.Millisecond)).
+			GetSTH(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), resp.TreeSize)
+	})
+
+	t.Run("Success after timeout", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		gomock.InOrder(
+			httpClient.EXPECT().Do(gomock.Any()).Return(nil, timeoutError{}),
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(respond(http.StatusOK, sth)),
+		)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRetry(3, time.Millisecond)).
+			GetSTH(context.Background())
+		require.NoError(t, err)
+	})
+
+	t.Run("AddVC resends body", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var bodies []string
+
+		readBody := func(code int, body string) func(*http.Request) (*http.Response, error) {
+			return func(req *http.Request) (*http.Response, error) {
+				b, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+
+				bodies = append(bodies, string(b))
+
+				return respond(code, body)(req)
+			}
+		}
+
+		httpClient := NewMockHTTPClient(ctrl)
+		gomock.InOrder(
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(readBody(http.StatusGatewayTimeout, "timeout")),
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(readBody(http.StatusOK, `{"timestamp":1}`)),
+		)
+
+		resp, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRetry(3, time.Millisecond)).
+			AddVC(context.Background(), []byte(`{"id":"vc"}`))
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), resp.Timestamp)
+		require.Equal(t, []string{`{"id":"vc"}`, `{"id":"vc"}`}, bodies)
+	})
+
+	t.Run("Attempts exhausted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(respond(http.StatusServiceUnavailable, "unavailable")).
+			Times(3)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRetry(3, time.Millisecond)).
+			GetSTH(context.Background())
+		require.EqualError(t, err,
+			"get STH: after 3 attempts: unexpected response with status 503 Service Unavailable: unavailable")
+
+		var retryErr *vct.RetryError
+		require.True(t, errors.As(err, &retryErr))
+		require.Equal(t, 3, retryErr.Attempts)
+	})
+
+	t.Run("No retry on 4xx", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(respond(http.StatusBadRequest, `{"message":"bad"}`))
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRetry(3, time.Millisecond)).
+			AddVC(context.Background(), []byte(`{}`))
+		require.EqualError(t, err, "add VC: bad")
+	})
+
+	t.Run("Context canceled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			cancel()
+
+			return respond(http.StatusServiceUnavailable, "unavailable")(req)
+		})
+
+		start := time.Now()
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRetry(3, time.Hour)).GetSTH(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("Retry-After", func(t *testing.T) {
+		tooManyRequests := func(retryAfter func() string) func(*http.Request) (*http.Response, error) {
+			return func(*http.Request) (*http.Response, error) {
+				return &http.Response{
+					Header:     http.Header{"Retry-After": []string{retryAfter()}},
+					Body:       ioutil.NopCloser(bytes.NewBufferString("too many requests")),
+					StatusCode: http.StatusTooManyRequests,
+				}, nil
+			}
+		}
+
+		for name, retryAfter := range map[string]func() string{
+			"Seconds": func() string { return "1" },
+			// the HTTP date has a resolution of a second
+			"HTTP date": func() string { return time.Now().Add(2 * time.Second).UTC().Format(http.TimeFormat) },
+		} {
+			retryAfter := retryAfter
+
+			t.Run(name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				httpClient := NewMockHTTPClient(ctrl)
+				gomock.InOrder(
+					httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(tooManyRequests(retryAfter)),
+					httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(respond(http.StatusOK, sth)),
+				)
+
+				start := time.Now()
+
+				_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRetry(3, time.Millisecond)).
+					GetSTH(context.Background())
+				require.NoError(t, err)
+				require.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+			})
+		}
+
+		t.Run("Longer than a minute", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			httpClient := NewMockHTTPClient(ctrl)
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(tooManyRequests(func() string { return "3600" }))
+
+			_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRetry(3, time.Millisecond)).
+				GetSTH(context.Background())
+			require.EqualError(t, err, "get STH: unexpected response with status 429 Too Many Requests: too many requests")
+
+			var vctErr *vct.Error
+			require.True(t, errors.As(err, &vctErr))
+			require.Equal(t, time.Hour, vctErr.RetryAfter)
+		})
+	})
+
+	t.Run("Deadline before next attempt", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(respond(http.StatusServiceUnavailable, "unavailable"))
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRetry(3, time.Hour)).GetSTH(ctx)
+		require.EqualError(t, err, "get STH: unexpected response with status 503 Service Unavailable: unavailable")
+	})
+}
+
+// backoffStub returns the delays in order and records the attempts it is called with.
+type backoffStub struct {
+	delays   []time.Duration
+	attempts []int
+}
+
+func (b *backoffStub) Next(attempt int) time.Duration {
+	b.attempts = append(b.attempts, attempt)
+
+	return b.delays[attempt-1]
+}
+
+func TestClient_WithBackoff(t *testing.T) {
+	respond := func(code int, body string) func(*http.Request) (*http.Response, error) {
+		return func(*http.Request) (*http.Response, error) {
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+				StatusCode: code,
+			}, nil
+		}
+	}
+
+	t.Run("Delays", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var sent []time.Time
+
+		record := func(code int, body string) func(*http.Request) (*http.Response, error) {
+			return func(req *http.Request) (*http.Response, error) {
+				sent = append(sent, time.Now())
+
+				return respond(code, body)(req)
+			}
+		}
+
+		httpClient := NewMockHTTPClient(ctrl)
+		gomock.InOrder(
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(record(http.StatusServiceUnavailable, "unavailable")),
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(record(http.StatusServiceUnavailable, "unavailable")),
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(record(http.StatusOK, `{"tree_size":1}`)),
+		)
+
+		backoff := &backoffStub{delays: []time.Duration{20 * time.Millisecond, 40 * time.Millisecond}}
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithBackoff(backoff),
+			vct.WithRetry(3, time.Hour)).GetSTH(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []int{1, 2}, backoff.attempts)
+
+		require.Len(t, sent, 3)
+		require.GreaterOrEqual(t, sent[1].Sub(sent[0]), 20*time.Millisecond)
+		require.GreaterOrEqual(t, sent[2].Sub(sent[1]), 40*time.Millisecond)
+	})
+
+	t.Run("Context canceled during sleep", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			time.AfterFunc(20*time.Millisecond, cancel)
+
+			return respond(http.StatusServiceUnavailable, "unavailable")(req)
+		})
+
+		start := time.Now()
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRetry(3, time.Millisecond),
+			vct.WithBackoff(vct.ConstantBackoff{Delay: time.Hour})).GetSTH(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Less(t, time.Since(start), time.Minute)
+	})
+
+	t.Run("Built-in", func(t *testing.T) {
+		require.Equal(t, time.Second, vct.ConstantBackoff{Delay: time.Second}.Next(5))
+
+		backoff := vct.ExponentialBackoff{Base: 100 * time.Millisecond, Max: time.Second}
+
+		for attempt, maxDelay := range map[int]time.Duration{
+			1: 100 * time.Millisecond,
+			2: 200 * time.Millisecond,
+			3: 400 * time.Millisecond,
+			5: time.Second,
+		} {
+			delay := backoff.Next(attempt)
+			require.GreaterOrEqual(t, delay, maxDelay/2)
+			require.Less(t, delay, maxDelay)
+		}
+	})
+}
+
+func TestClient_WithRateLimit(t *testing.T) {
+	sth := func(*http.Request) (*http.Response, error) {
+		return &http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"tree_size":1}`)),
+			StatusCode: http.StatusOK,
+		}, nil
+	}
+
+	t.Run("Requests are spaced out", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var sent []time.Time
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			sent = append(sent, time.Now())
 
-		client := vct.New("https://vct:22/maple2020", vct.WithHTTPClient(httpClient), vct.WithAuthReadToken("tk1"))
-		err := client.HealthCheck(context.Background())
+			return sth(req)
+		}).Times(2)
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRateLimit(1, 1))
+
+		_, err := client.GetSTH(context.Background())
 		require.NoError(t, err)
+
+		_, err = client.GetSTH(context.Background())
+		require.NoError(t, err)
+
+		require.Len(t, sent, 2)
+		require.GreaterOrEqual(t, sent[1].Sub(sent[0]), 900*time.Millisecond)
 	})
 
-	t.Run("Error", func(t *testing.T) {
+	t.Run("Context canceled while waiting", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		httpClient := NewMockHTTPClient(ctrl)
-		httpClient.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
-		}).Return(&http.Response{
-			Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("{}"))),
-			StatusCode: http.StatusInternalServerError,
-		}, nil)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(sth)
 
-		client := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithAuthReadToken("tk1"))
-		err := client.HealthCheck(context.Background())
-		require.Error(t, err)
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithRateLimit(0.001, 1))
+
+		_, err := client.GetSTH(context.Background())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		start := time.Now()
+
+		_, err = client.GetSTH(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Contains(t, err.Error(), "rate limit")
+		require.Less(t, time.Since(start), time.Second)
 	})
 }
 
-func TestClient_GetIssuers(t *testing.T) {
+func TestClient_GetSTH(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
-		expected := []string{"issuer_1", "issuer_2"}
+		expected := command.GetSTHResponse{
+			TreeSize:          1,
+			Timestamp:         1234567889,
+			SHA256RootHash:    []byte(`SHA256RootHash`),
+			TreeHeadSignature: []byte(`TreeHeadSignature`),
+		}
 
 		fakeResp, err := json.Marshal(expected)
 		require.NoError(t, err)
@@ -148,7 +2541,7 @@ func TestClient_GetIssuers(t *testing.T) {
 		}, nil)
 
 		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
-		resp, err := client.GetIssuers(context.Background())
+		resp, err := client.GetSTH(context.Background())
 		require.NoError(t, err)
 
 		bytesResp, err := json.Marshal(resp)
@@ -173,119 +2566,207 @@ func TestClient_GetIssuers(t *testing.T) {
 		}, nil)
 
 		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
-		_, err = client.GetIssuers(context.Background())
-		require.EqualError(t, err, "get issuers: error")
+		_, err = client.GetSTH(context.Background())
+		require.EqualError(t, err, "get STH: error")
 	})
 }
 
-func TestClient_Webfinger(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
+func TestClient_GetSTHIfChanged(t *testing.T) {
+	t.Run("Not modified", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
-		expected := command.WebFingerResponse{
-			Subject: "https://vct.com/maple2021",
-			Properties: map[string]interface{}{
-				"https://trustbloc.dev/ns/public-key": "cHVibGljIGtleQ==",
-			},
-			Links: []command.WebFingerLink{{
-				Rel:  "self",
-				Href: "https://vct.com/maple2021",
-			}},
-		}
-
-		fakeResp, err := json.Marshal(expected)
-		require.NoError(t, err)
-
 		httpClient := NewMockHTTPClient(ctrl)
-		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
-			Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
-			StatusCode: http.StatusOK,
-		}, nil)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, `"3"`, req.Header.Get("If-None-Match"))
 
-		client := vct.New(endpoint,
-			vct.WithHTTPClient(httpClient),
-			vct.WithLedgerURI("https://vct.com/maple2021"),
-		)
-		resp, err := client.Webfinger(context.Background())
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBuffer(nil)),
+				StatusCode: http.StatusNotModified,
+			}, nil
+		})
+
+		sth, changed, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).
+			GetSTHIfChanged(context.Background(), 3)
 		require.NoError(t, err)
+		require.False(t, changed)
+		require.Nil(t, sth)
+	})
 
-		bytesResp, err := json.Marshal(resp)
+	t.Run("Changed", func(t *testing.T) {
+		sth, changed, err := newSampledLog(t, 3).client().GetSTHIfChanged(context.Background(), 2)
 		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, uint64(3), sth.TreeSize)
+	})
 
-		require.Equal(t, fakeResp, bytesResp)
+	t.Run("Unchanged without conditional support", func(t *testing.T) {
+		sth, changed, err := newSampledLog(t, 3).client().GetSTHIfChanged(context.Background(), 3)
+		require.NoError(t, err)
+		require.False(t, changed)
+		require.Nil(t, sth)
 	})
 
 	t.Run("Error", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
-		expected := rest.ErrorResponse{Message: "error"}
-
-		fakeResp, err := json.Marshal(expected)
-		require.NoError(t, err)
-
 		httpClient := NewMockHTTPClient(ctrl)
 		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
-			Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"error"}`)),
 			StatusCode: http.StatusInternalServerError,
 		}, nil)
 
-		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
-		_, err = client.Webfinger(context.Background())
-		require.EqualError(t, err, "webfinger: error")
+		_, _, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).GetSTHIfChanged(context.Background(), 3)
+		require.EqualError(t, err, "get STH if changed: error")
 	})
 }
 
-func TestClient_GetSTH(t *testing.T) {
+func TestClient_TreeSize(t *testing.T) {
+	// sthResponder responds to get-sth requests with the given tree size, and to any other request with no entries.
+	sthResponder := func(t *testing.T, treeSize *uint64, sthRequests *int) func(*http.Request) (*http.Response, error) {
+		t.Helper()
+
+		return func(req *http.Request) (*http.Response, error) {
+			body := []byte(`{}`)
+
+			if strings.HasSuffix(req.URL.Path, "/get-sth") {
+				*sthRequests++
+
+				var err error
+
+				body, err = json.Marshal(command.GetSTHResponse{TreeSize: *treeSize})
+				require.NoError(t, err)
+			}
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBuffer(body)),
+				StatusCode: http.StatusOK,
+			}, nil
+		}
+	}
+
 	t.Run("Success", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
-		defer ctrl.Finish()
+		treeSize, sthRequests := uint64(5), 0
 
-		expected := command.GetSTHResponse{
-			TreeSize:          1,
-			Timestamp:         1234567889,
-			SHA256RootHash:    []byte(`SHA256RootHash`),
-			TreeHeadSignature: []byte(`TreeHeadSignature`),
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(sthResponder(t, &treeSize, &sthRequests)).AnyTimes()
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
+
+		size, err := client.TreeSize(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(5), size)
+
+		// without cache, the tree size is retrieved every time and arguments are not checked
+		_, err = client.TreeSize(context.Background())
+		require.NoError(t, err)
+
+		_, err = client.GetEntries(context.Background(), 10, 20)
+		require.NoError(t, err)
+		require.Equal(t, 2, sthRequests)
+	})
+
+	t.Run("Cached", func(t *testing.T) {
+		treeSize, sthRequests := uint64(5), 0
+
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(sthResponder(t, &treeSize, &sthRequests)).AnyTimes()
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithSTHCacheTTL(time.Hour))
+
+		for i := 0; i < 3; i++ {
+			size, err := client.TreeSize(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, uint64(5), size)
 		}
 
-		fakeResp, err := json.Marshal(expected)
+		require.Equal(t, 1, sthRequests)
+
+		treeSize = 8
+
+		size, err := client.TreeSize(context.Background())
 		require.NoError(t, err)
+		require.Equal(t, uint64(5), size)
 
-		httpClient := NewMockHTTPClient(ctrl)
-		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
-			Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
-			StatusCode: http.StatusOK,
-		}, nil)
+		// the log has grown since the tree size was cached
+		_, err = client.GetEntries(context.Background(), 7, 7)
+		require.NoError(t, err)
+		require.Equal(t, 2, sthRequests)
 
-		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
-		resp, err := client.GetSTH(context.Background())
+		size, err = client.TreeSize(context.Background())
 		require.NoError(t, err)
+		require.Equal(t, uint64(8), size)
+	})
 
-		bytesResp, err := json.Marshal(resp)
+	t.Run("Expired", func(t *testing.T) {
+		treeSize, sthRequests := uint64(5), 0
+
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(sthResponder(t, &treeSize, &sthRequests)).AnyTimes()
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithSTHCacheTTL(time.Millisecond))
+
+		_, err := client.TreeSize(context.Background())
 		require.NoError(t, err)
 
-		require.Equal(t, fakeResp, bytesResp)
+		time.Sleep(2 * time.Millisecond)
+
+		_, err = client.TreeSize(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 2, sthRequests)
+	})
+
+	t.Run("Out of range", func(t *testing.T) {
+		treeSize, sthRequests := uint64(5), 0
+
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(sthResponder(t, &treeSize, &sthRequests)).AnyTimes()
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithSTHCacheTTL(time.Hour))
+
+		_, err := client.GetEntries(context.Background(), 5, 10)
+		require.True(t, errors.Is(err, vct.ErrOutOfRange))
+		require.EqualError(t, err, "get entries: out of range: tree size 6 exceeds tree size 5 of the log")
+
+		// the tree size was retrieved for the request, so it is not retrieved again
+		require.Equal(t, 1, sthRequests)
+
+		_, err = client.GetProofByHash(context.Background(), "hash", 6)
+		require.True(t, errors.Is(err, vct.ErrOutOfRange))
+		require.EqualError(t, err, "get proof by hash: out of range: tree size 6 exceeds tree size 5 of the log")
+
+		// the cached tree size is exceeded, so the tree size is retrieved again
+		require.Equal(t, 2, sthRequests)
+
+		_, err = client.GetEntryAndProof(context.Background(), 5, 5)
+		require.True(t, errors.Is(err, vct.ErrOutOfRange))
+		require.EqualError(t, err, "get entry and proof: out of range: leaf index 5 is not in tree of size 5")
+
+		_, err = client.GetEntryAndProof(context.Background(), 4, 5)
+		require.NoError(t, err)
+		require.Equal(t, 2, sthRequests)
 	})
 
 	t.Run("Error", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
-		expected := rest.ErrorResponse{Message: "error"}
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(*http.Request) (*http.Response, error) {
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"error"}`)),
+				StatusCode: http.StatusInternalServerError,
+			}, nil
+		}).Times(2)
 
-		fakeResp, err := json.Marshal(expected)
-		require.NoError(t, err)
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithSTHCacheTTL(time.Hour))
 
-		httpClient := NewMockHTTPClient(ctrl)
-		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
-			Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
-			StatusCode: http.StatusInternalServerError,
-		}, nil)
+		_, err := client.TreeSize(context.Background())
+		require.EqualError(t, err, "tree size: get STH: error")
 
-		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
-		_, err = client.GetSTH(context.Background())
-		require.EqualError(t, err, "get STH: error")
+		_, err = client.GetProofByHash(context.Background(), "hash", 1)
+		require.EqualError(t, err, "get proof by hash: check tree size: get STH: error")
 	})
 }
 
@@ -339,6 +2820,86 @@ func TestClient_GetSTHConsistency(t *testing.T) {
 		_, err = client.GetSTHConsistency(context.Background(), 1, 2)
 		require.EqualError(t, err, "get STH consistency: error")
 	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := vct.New(endpoint, vct.WithHTTPClient(NewMockHTTPClient(ctrl)))
+
+		_, err := client.GetSTHConsistency(context.Background(), 0, 2)
+		require.True(t, errors.Is(err, vct.ErrInvalidRange))
+		require.EqualError(t, err, "get STH consistency: invalid range: first tree size 0 is not in range [1, 2]")
+
+		_, err = client.GetSTHConsistency(context.Background(), 3, 2)
+		require.True(t, errors.Is(err, vct.ErrInvalidRange))
+	})
+
+	t.Run("Without client validation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+			require.Equal(t, "3", req.URL.Query().Get("first"))
+			require.Equal(t, "2", req.URL.Query().Get("second"))
+		}).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"invalid range"}`)),
+			StatusCode: http.StatusBadRequest,
+		}, nil)
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithoutClientValidation())
+
+		_, err := client.GetSTHConsistency(context.Background(), 3, 2)
+		require.True(t, errors.Is(err, vct.ErrBadRequest))
+	})
+}
+
+func TestClient_GetProofByCredential(t *testing.T) {
+	log := newFakeLog(t)
+	log.addCredential(fakeLogTimestamp, []byte(`{"id":"http://example.edu/credentials/1"}`))
+	log.addCredential(fakeLogTimestamp+1, vcBachelorDegree)
+
+	t.Run("Success", func(t *testing.T) {
+		resp, err := log.client().GetProofByCredential(context.Background(), fakeLogTimestamp+1, vcBachelorDegree,
+			testutil.GetLoader(t), 2)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), resp.LeafIndex)
+		require.Equal(t, [][]byte{log.leafHashes(2)[0]}, resp.AuditPath)
+	})
+
+	t.Run("Wrong timestamp", func(t *testing.T) {
+		_, err := log.client().GetProofByCredential(context.Background(), fakeLogTimestamp, vcBachelorDegree,
+			testutil.GetLoader(t), 2)
+		require.EqualError(t, err, "get proof by hash: leaf not found")
+	})
+
+	t.Run("Malformed credential", func(t *testing.T) {
+		_, err := log.client().GetProofByCredential(context.Background(), fakeLogTimestamp, []byte(`[]`),
+			testutil.GetLoader(t), 2)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get proof by credential: create leaf")
+	})
+
+	t.Run("Leaf hasher", func(t *testing.T) {
+		expected, err := vct.CalculateLeafHashWith(sha384LeafHasher{}, fakeLogTimestamp, vcBachelorDegree,
+			testutil.GetLoader(t))
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, expected, req.URL.Query().Get("hash"))
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"leaf_index":0,"audit_path":[]}`)),
+				StatusCode: http.StatusOK,
+			}, nil
+		})
+
+		_, err = vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithLeafHasher(sha384LeafHasher{})).
+			GetProofByCredential(context.Background(), fakeLogTimestamp, vcBachelorDegree, testutil.GetLoader(t), 1)
+		require.NoError(t, err)
+	})
 }
 
 func TestClient_GetProofByHash(t *testing.T) {
@@ -388,9 +2949,109 @@ func TestClient_GetProofByHash(t *testing.T) {
 			StatusCode: http.StatusInternalServerError,
 		}, nil)
 
-		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
-		_, err = client.GetProofByHash(context.Background(), "hash", 2)
-		require.EqualError(t, err, "get proof by hash: error")
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
+		_, err = client.GetProofByHash(context.Background(), "hash", 2)
+		require.EqualError(t, err, "get proof by hash: error")
+	})
+
+	t.Run("Audit path too long", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fakeResp, err := json.Marshal(command.GetProofByHashResponse{
+			LeafIndex: 1,
+			AuditPath: make([][]byte, 1000),
+		})
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
+		_, err = client.GetProofByHash(context.Background(), "hash", 2)
+		require.EqualError(t, err, "get proof by hash: decode audit_path: length 1000 exceeds maximum 64")
+
+		var decodeErr *vct.DecodeError
+		require.True(t, errors.As(err, &decodeErr))
+		require.Equal(t, "audit_path", decodeErr.Field)
+	})
+
+	t.Run("Empty response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString("null")),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
+		_, err := client.GetProofByHash(context.Background(), "hash", 2)
+		require.EqualError(t, err, "get proof by hash: decode body: empty response")
+	})
+}
+
+func TestClient_GetProofsByHashes(t *testing.T) {
+	log := newFakeLog(t)
+
+	for i := 0; i < 20; i++ {
+		log.addLeaf([]byte("leaf " + strconv.Itoa(i)))
+	}
+
+	leafHashes := log.leafHashes(20)
+
+	var hashes []string
+
+	for i := 19; i >= 0; i-- {
+		hashes = append(hashes, base64.StdEncoding.EncodeToString(leafHashes[i]))
+
+		if i == 10 {
+			hashes = append(hashes, base64.StdEncoding.EncodeToString([]byte("missing")))
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		proofs, errs, err := log.client().GetProofsByHashes(context.Background(), hashes, 20)
+		require.NoError(t, err)
+		require.Len(t, proofs, len(hashes))
+		require.Len(t, errs, len(hashes))
+
+		for i := range hashes {
+			if i == 10 {
+				require.True(t, errors.Is(errs[i], vct.ErrNotFound))
+				require.Equal(t, command.GetProofByHashResponse{}, proofs[i])
+
+				continue
+			}
+
+			index := 19 - i
+			if i > 10 {
+				index++
+			}
+
+			require.NoError(t, errs[i])
+			require.Equal(t, int64(index), proofs[i].LeafIndex)
+			require.NotEmpty(t, proofs[i].AuditPath)
+		}
+	})
+
+	t.Run("No hashes", func(t *testing.T) {
+		proofs, errs, err := log.client().GetProofsByHashes(context.Background(), nil, 20)
+		require.NoError(t, err)
+		require.Empty(t, proofs)
+		require.Empty(t, errs)
+	})
+
+	t.Run("Context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, _, err := log.client().GetProofsByHashes(ctx, hashes, 20)
+		require.EqualError(t, err, "get proofs by hashes: context canceled")
 	})
 }
 
@@ -444,6 +3105,103 @@ func TestClient_GetEntries(t *testing.T) {
 		_, err = client.GetEntries(context.Background(), 1, 2)
 		require.EqualError(t, err, "get entries: error")
 	})
+
+	t.Run("Too many entries", func(t *testing.T) {
+		fakeResp, err := json.Marshal(command.GetEntriesResponse{
+			Entries: []command.LeafEntry{{LeafInput: []byte(`1`)}, {LeafInput: []byte(`2`)}, {LeafInput: []byte(`3`)}},
+		})
+		require.NoError(t, err)
+
+		respond := func(t *testing.T) *MockHTTPClient {
+			t.Helper()
+
+			httpClient := NewMockHTTPClient(gomock.NewController(t))
+			httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+				StatusCode: http.StatusOK,
+			}, nil)
+
+			return httpClient
+		}
+
+		_, err = vct.New(endpoint, vct.WithHTTPClient(respond(t))).GetEntries(context.Background(), 1, 2)
+		require.ErrorIs(t, err, vct.ErrInvalidResponse)
+		require.EqualError(t, err, "get entries: invalid response: 3 entries for range [1, 2]")
+
+		resp, err := vct.New(endpoint, vct.WithHTTPClient(respond(t)), vct.WithoutClientValidation()).
+			GetEntries(context.Background(), 1, 2)
+		require.NoError(t, err)
+		require.Len(t, resp.Entries, 3)
+	})
+
+	t.Run("Range served", func(t *testing.T) {
+		entries := []command.LeafEntry{{LeafInput: []byte(`1`)}, {LeafInput: []byte(`2`)}}
+
+		respond := func(t *testing.T, resp command.GetEntriesResponse) *MockHTTPClient {
+			t.Helper()
+
+			fakeResp, err := json.Marshal(resp)
+			require.NoError(t, err)
+
+			httpClient := NewMockHTTPClient(gomock.NewController(t))
+			httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+				StatusCode: http.StatusOK,
+			}, nil)
+
+			return httpClient
+		}
+
+		index := func(i int64) *int64 { return &i }
+
+		resp, err := vct.New(endpoint, vct.WithHTTPClient(respond(t, command.GetEntriesResponse{
+			Entries: entries, Start: index(1), End: index(2), TreeSize: 3,
+		}))).GetEntries(context.Background(), 1, 5)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), *resp.End)
+		require.Equal(t, uint64(3), resp.TreeSize)
+
+		resp, err = vct.New(endpoint, vct.WithHTTPClient(respond(t, command.GetEntriesResponse{
+			Entries: entries[:1], Start: index(0), End: index(0), TreeSize: 3,
+		}))).GetEntries(context.Background(), 0, 5)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), *resp.Start)
+
+		resp, err = vct.New(endpoint, vct.WithHTTPClient(respond(t, command.GetEntriesResponse{
+			TreeSize: 3,
+		}))).GetEntries(context.Background(), 1, 5)
+		require.NoError(t, err)
+		require.Empty(t, resp.Entries)
+
+		_, err = vct.New(endpoint, vct.WithHTTPClient(respond(t, command.GetEntriesResponse{
+			Entries: entries, Start: index(0), End: index(1), TreeSize: 3,
+		}))).GetEntries(context.Background(), 1, 5)
+		require.ErrorIs(t, err, vct.ErrInvalidResponse)
+		require.EqualError(t, err, "get entries: invalid response: range [0, 1] served for 2 entries from 1")
+
+		_, err = vct.New(endpoint, vct.WithHTTPClient(respond(t, command.GetEntriesResponse{
+			Entries: entries, End: index(2), TreeSize: 3,
+		}))).GetEntries(context.Background(), 1, 5)
+		require.ErrorIs(t, err, vct.ErrInvalidResponse)
+		require.EqualError(t, err, "get entries: invalid response: range [unset, 2] served for 2 entries from 1")
+
+		_, err = vct.New(endpoint, vct.WithHTTPClient(respond(t, command.GetEntriesResponse{
+			Start: index(1), End: index(0), TreeSize: 3,
+		}))).GetEntries(context.Background(), 1, 5)
+		require.ErrorIs(t, err, vct.ErrInvalidResponse)
+		require.EqualError(t, err, "get entries: invalid response: range served for no entries from 1")
+
+		_, err = vct.New(endpoint, vct.WithHTTPClient(respond(t, command.GetEntriesResponse{
+			Entries: entries, Start: index(1), End: index(2), TreeSize: 2,
+		}))).GetEntries(context.Background(), 1, 5)
+		require.ErrorIs(t, err, vct.ErrInvalidResponse)
+		require.EqualError(t, err, "get entries: invalid response: range [1, 2] served from tree size 2")
+
+		_, err = vct.New(endpoint, vct.WithHTTPClient(respond(t, command.GetEntriesResponse{
+			Entries: entries, Start: index(1), End: index(2), TreeSize: 2,
+		})), vct.WithoutClientValidation()).GetEntries(context.Background(), 1, 5)
+		require.NoError(t, err)
+	})
 }
 
 func TestClient_GetEntryAndProof(t *testing.T) {
@@ -498,6 +3256,141 @@ func TestClient_GetEntryAndProof(t *testing.T) {
 		_, err = client.GetEntryAndProof(context.Background(), 1, 2)
 		require.EqualError(t, err, "get entry and proof: error")
 	})
+
+	t.Run("Audit path too long", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fakeResp, err := json.Marshal(command.GetEntryAndProofResponse{
+			LeafInput: []byte(`leaf input`),
+			AuditPath: make([][]byte, 1000),
+		})
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
+		_, err = client.GetEntryAndProof(context.Background(), 1, 2)
+		require.EqualError(t, err, "get entry and proof: decode audit_path: length 1000 exceeds maximum 64")
+
+		var decodeErr *vct.DecodeError
+		require.True(t, errors.As(err, &decodeErr))
+		require.Equal(t, "audit_path", decodeErr.Field)
+	})
+
+	t.Run("Audit path exceeds configured maximum", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fakeResp, err := json.Marshal(command.GetEntryAndProofResponse{
+			LeafInput: []byte(`leaf input`),
+			AuditPath: make([][]byte, 3),
+		})
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(*http.Request) (*http.Response, error) {
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+				StatusCode: http.StatusOK,
+			}, nil
+		}).Times(2)
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithMaxAuditPathLength(2))
+		_, err = client.GetEntryAndProof(context.Background(), 1, 8)
+		require.EqualError(t, err, "get entry and proof: decode audit_path: length 3 exceeds maximum 2")
+
+		client = vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithMaxAuditPathLength(3))
+		_, err = client.GetEntryAndProof(context.Background(), 1, 8)
+		require.NoError(t, err)
+	})
+}
+
+func TestClient_GetVerifiedEntry(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		log := newSampledLog(t, 5)
+		root := rfc6962Root(log.leafHashes(5))
+
+		for i := uint64(0); i < 5; i++ {
+			entry, err := log.client().GetVerifiedEntry(context.Background(), i, 5, root)
+			require.NoError(t, err)
+			require.Equal(t, log.leaves[i], entry.LeafInput)
+		}
+	})
+
+	t.Run("Wrong root", func(t *testing.T) {
+		log := newSampledLog(t, 5)
+
+		_, err := log.client().GetVerifiedEntry(context.Background(), 2, 5, rfc6962Root(log.leafHashes(4)))
+
+		var verificationErr *vct.VerificationError
+		require.True(t, errors.As(err, &verificationErr))
+		require.Equal(t, vct.CheckInclusion, verificationErr.Check)
+		require.Contains(t, err.Error(), "does not match expected root")
+	})
+
+	t.Run("Tampered entry", func(t *testing.T) {
+		log := newSampledLog(t, 5)
+		log.corrupt(2, newLeafEntry(t, fakeLogTimestamp, "forged").LeafInput)
+
+		_, err := log.client().GetVerifiedEntry(context.Background(), 2, 5, rfc6962Root(log.leafHashes(5)))
+
+		var verificationErr *vct.VerificationError
+		require.True(t, errors.As(err, &verificationErr))
+		require.Equal(t, vct.CheckInclusion, verificationErr.Check)
+	})
+
+	t.Run("Leaf hasher", func(t *testing.T) {
+		leaves := [][]byte{
+			newLeafEntry(t, fakeLogTimestamp, "vc-0").LeafInput,
+			newLeafEntry(t, fakeLogTimestamp+1, "vc-1").LeafInput,
+		}
+
+		verifier := vct.NewProofVerifier(vct.WithProofLeafHasher(prefixLeafHasher{}))
+
+		var hashes [][]byte
+
+		for _, leaf := range leaves {
+			hash, err := verifier.LeafHash(leaf)
+			require.NoError(t, err)
+
+			hashes = append(hashes, hash)
+		}
+
+		fakeResp, err := json.Marshal(command.GetEntryAndProofResponse{
+			LeafInput: leaves[0],
+			AuditPath: [][]byte{hashes[1]},
+		})
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(*http.Request) (*http.Response, error) {
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+				StatusCode: http.StatusOK,
+			}, nil
+		}).Times(2)
+
+		entry, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithLeafHasher(prefixLeafHasher{})).
+			GetVerifiedEntry(context.Background(), 0, 2, rfc6962Root(hashes))
+		require.NoError(t, err)
+		require.Equal(t, leaves[0], entry.LeafInput)
+
+		_, err = vct.New(endpoint, vct.WithHTTPClient(httpClient)).
+			GetVerifiedEntry(context.Background(), 0, 2, rfc6962Root(hashes))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match expected root")
+	})
+
+	t.Run("Log unreachable", func(t *testing.T) {
+		_, err := vct.New("http://127.0.0.1:0/maple2020").GetVerifiedEntry(context.Background(), 0, 1, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get verified entry: get entry and proof")
+	})
 }
 
 var simpleVC = &verifiable.Credential{ // nolint: gochecknoglobals // global vc
@@ -550,6 +3443,176 @@ func TestCalculateLeafHash(t *testing.T) {
 	})
 }
 
+// sha384LeafHasher hashes leaves like a log configured with SHA-384 and the RFC 6962 leaf prefix.
+type sha384LeafHasher struct{}
+
+func (sha384LeafHasher) HashLeaf(leafData []byte) []byte {
+	hash := sha512.Sum384(append([]byte{0}, leafData...))
+
+	return hash[:]
+}
+
+// prefixLeafHasher hashes leaves like a log configured with SHA-256 and another leaf domain separation prefix.
+type prefixLeafHasher struct{}
+
+func (prefixLeafHasher) HashLeaf(leafData []byte) []byte {
+	hash := sha256.Sum256(append([]byte{0x02}, leafData...))
+
+	return hash[:]
+}
+
+func TestCalculateLeafHashWith(t *testing.T) {
+	vcBytes, err := json.Marshal(simpleVC)
+	require.NoError(t, err)
+
+	expected, err := vct.CalculateLeafHash(12345, vcBytes, testutil.GetLoader(t))
+	require.NoError(t, err)
+
+	hash, err := vct.CalculateLeafHashWith(vct.RFC6962LeafHasher{}, 12345, vcBytes, testutil.GetLoader(t))
+	require.NoError(t, err)
+	require.Equal(t, expected, hash)
+
+	_, canonical, err := vct.CalculateLeafHashDebug(12345, vcBytes, testutil.GetLoader(t))
+	require.NoError(t, err)
+
+	hash, err = vct.CalculateLeafHashWith(sha384LeafHasher{}, 12345, vcBytes, testutil.GetLoader(t))
+	require.NoError(t, err)
+	require.Equal(t, base64.StdEncoding.EncodeToString(sha384LeafHasher{}.HashLeaf(canonical)), hash)
+
+	_, err = vct.CalculateLeafHashWith(sha384LeafHasher{}, 12345, []byte(`[]`), testutil.GetLoader(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "create leaf")
+}
+
+func TestCalculateVPLeafHash(t *testing.T) {
+	vp, err := json.Marshal(map[string]interface{}{
+		"@context":             []string{"https://www.w3.org/2018/credentials/v1"},
+		"type":                 []string{"VerifiablePresentation"},
+		"verifiableCredential": []interface{}{simpleVC},
+		"proof":                map[string]interface{}{"type": "Ed25519Signature2018"},
+	})
+	require.NoError(t, err)
+
+	hash, err := vct.CalculateVPLeafHash(12345, vp, testutil.GetLoader(t))
+	require.NoError(t, err)
+
+	// The pre-image is reconstructed from the canonical presentation without its proof.
+	canonical, err := vct.CanonicalizeForLog(vp, testutil.GetLoader(t))
+	require.NoError(t, err)
+
+	leafData, err := canonicalizer.MarshalCanonical(command.NewVPLeaf(12345, canonical))
+	require.NoError(t, err)
+	require.Contains(t, string(leafData), `"entry_type":101`)
+
+	expected := sha256.Sum256(append([]byte{0}, leafData...))
+	require.Equal(t, base64.StdEncoding.EncodeToString(expected[:]), hash)
+
+	// The entry type tells the leaf apart from a credential leaf of the same document.
+	vcHash, err := vct.CalculateLeafHash(12345, vp, testutil.GetLoader(t))
+	require.NoError(t, err)
+	require.NotEqual(t, vcHash, hash)
+
+	_, err = vct.CalculateVPLeafHash(12345, []byte(`[]`), testutil.GetLoader(t))
+	require.Error(t, err)
+}
+
+// blockingLoader blocks every document load until it is released.
+type blockingLoader struct {
+	loading chan struct{}
+	release chan struct{}
+}
+
+func (l *blockingLoader) LoadDocument(string) (*ld.RemoteDocument, error) {
+	l.loading <- struct{}{}
+	<-l.release
+
+	return nil, errors.New("released")
+}
+
+func TestCalculateLeafHashContext(t *testing.T) {
+	vcBytes := []byte(`{"@context":["https://www.w3.org/2018/credentials/v1"],"id":"http://example.edu/credentials/1"}`)
+
+	t.Run("Success", func(t *testing.T) {
+		expected, err := vct.CalculateLeafHash(12345, vcBytes, testutil.GetLoader(t))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		hash, err := vct.CalculateLeafHashContext(ctx, 12345, vcBytes, testutil.GetLoader(t))
+		require.NoError(t, err)
+		require.Equal(t, expected, hash)
+	})
+
+	t.Run("Canceled during document load", func(t *testing.T) {
+		loader := &blockingLoader{loading: make(chan struct{}, 1), release: make(chan struct{})}
+		defer close(loader.release)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		go func() {
+			<-loader.loading
+			cancel()
+		}()
+
+		_, err := vct.CalculateLeafHashContext(ctx, 12345, vcBytes, loader)
+		require.ErrorIs(t, err, context.Canceled)
+		require.EqualError(t, err, "calculate leaf hash: context canceled")
+	})
+
+	t.Run("Context done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := vct.CalculateLeafHashContext(ctx, 12345, vcBytes, testutil.GetLoader(t))
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestCalculateLeafHashFromCanonical(t *testing.T) {
+	vcBytes, err := json.Marshal(simpleVC)
+	require.NoError(t, err)
+
+	canonical, err := vct.CanonicalizeForLog(vcBytes, testutil.GetLoader(t))
+	require.NoError(t, err)
+
+	expected, err := vct.CalculateLeafHash(12345, vcBytes, testutil.GetLoader(t))
+	require.NoError(t, err)
+
+	hash, err := vct.CalculateLeafHashFromCanonical(12345, canonical)
+	require.NoError(t, err)
+	require.Equal(t, expected, hash)
+
+	hash, err = vct.CalculateLeafHashFromCanonical(12346, canonical)
+	require.NoError(t, err)
+	require.NotEqual(t, expected, hash)
+}
+
+func TestCalculateLeafHashDebug(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		vcBytes, err := json.Marshal(simpleVC)
+		require.NoError(t, err)
+
+		hash, canonical, err := vct.CalculateLeafHashDebug(12345, vcBytes, testutil.GetLoader(t))
+		require.NoError(t, err)
+		require.Equal(t, rfc6962LeafHash(canonical), hash)
+
+		expected, err := vct.CalculateLeafHash(12345, vcBytes, testutil.GetLoader(t))
+		require.NoError(t, err)
+		require.Equal(t, expected, base64.StdEncoding.EncodeToString(hash))
+
+		var leaf command.MerkleTreeLeaf
+		require.NoError(t, json.Unmarshal(canonical, &leaf))
+		require.Equal(t, uint64(12345), leaf.TimestampedEntry.Timestamp)
+	})
+
+	t.Run("Invalid VC", func(t *testing.T) {
+		_, _, err := vct.CalculateLeafHashDebug(12345, []byte(`[]`), testutil.GetLoader(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create leaf")
+	})
+}
+
 func TestVerifyVCTimestampSignature(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		const signature = `{
@@ -569,6 +3632,30 @@ func TestVerifyVCTimestampSignature(t *testing.T) {
 		))
 	})
 
+	t.Run("Success (ED25519)", func(t *testing.T) {
+		pubKey, signature := signED25519Timestamp(t, 1662067083140, vcBachelorDegree)
+
+		require.NoError(t, vct.VerifyVCTimestampSignature(
+			signature, pubKey, 1662067083140, vcBachelorDegree, testutil.GetLoader(t),
+		))
+	})
+
+	t.Run("Wrong timestamp (ED25519)", func(t *testing.T) {
+		pubKey, signature := signED25519Timestamp(t, 1662067083140, vcBachelorDegree)
+
+		require.EqualError(t, vct.VerifyVCTimestampSignature(
+			signature, pubKey, 1662067083141, vcBachelorDegree, testutil.GetLoader(t),
+		), "ED25519 signature verification failed")
+	})
+
+	t.Run("Invalid public key size (ED25519)", func(t *testing.T) {
+		pubKey, signature := signED25519Timestamp(t, 1662067083140, vcBachelorDegree)
+
+		require.EqualError(t, vct.VerifyVCTimestampSignature(
+			signature, pubKey[1:], 1662067083140, vcBachelorDegree, testutil.GetLoader(t),
+		), "invalid ED25519 public key size 31, expected 32")
+	})
+
 	t.Run("Unmarshal signature error", func(t *testing.T) {
 		require.Contains(t, vct.VerifyVCTimestampSignature(
 			[]byte(`[]`), []byte(`[]`), 1617977793917, vcBachelorDegree, testutil.GetLoader(t),
@@ -576,8 +3663,206 @@ func TestVerifyVCTimestampSignature(t *testing.T) {
 	})
 
 	t.Run("Wrong public key", func(t *testing.T) {
-		require.Contains(t, vct.VerifyVCTimestampSignature(
-			[]byte(`{}`), []byte(`[]`), 1617977793917, vcBachelorDegree, testutil.GetLoader(t),
-		).Error(), "pub key to handle: error")
+		require.EqualError(t, vct.VerifyVCTimestampSignature(
+			[]byte(`{"algorithm":{"type":"ECDSAP256DER"}}`), []byte(`[]`), 1617977793917, vcBachelorDegree,
+			testutil.GetLoader(t),
+		), "invalid ECDSA public key: neither a PKIX public key nor an uncompressed point")
+	})
+
+	t.Run("Wrong public key (secp256k1)", func(t *testing.T) {
+		require.EqualError(t, vct.VerifyVCTimestampSignature(
+			[]byte(`{"algorithm":{"type":"ECDSASecp256k1DER"}}`), []byte(`[]`), 1617977793917, vcBachelorDegree,
+			testutil.GetLoader(t),
+		), "invalid secp256k1 public key: unexpected point encoding of 2 bytes")
+	})
+
+	t.Run("Unsupported algorithm", func(t *testing.T) {
+		require.EqualError(t, vct.VerifyVCTimestampSignature(
+			[]byte(`{"algorithm":{"signature":"BLS","type":"BLS12381G2"}}`), []byte(`[]`), 1617977793917,
+			vcBachelorDegree, testutil.GetLoader(t),
+		), `unsupported signature algorithm "BLS" with key type "BLS12381G2"`)
+	})
+}
+
+// Signature of the tree head of an empty tree with the given timestamp, made with a fixed secp256k1 key, and the
+// public key in uncompressed and compressed form.
+const (
+	secp256k1STHTimestamp = 1662067083140
+	secp256k1STHSignature = "MEYCIQDWaJVx4YyzYyv9t9i2qR8G0UoQbdoA1sm6C8HvQkOlZAIhAM14r1J++GnT2je51qEIxCWcdLrKLoUjN/6jQ8T3/CVk"
+	secp256k1PubKey       = "MFYwEAYHKoZIzj0CAQYFK4EEAAoDQgAE6eJ926v6dkpzPVa5XzxiWoFdhT9llt85oYPajHiLxjQbqQrg55c6BY9g5raJ" +
+		"KSPL/MeyxcMRIWr/Og2Ni6YHWQ=="
+	secp256k1CompressedPubKey = "MDYwEAYHKoZIzj0CAQYFK4EEAAoDIgAD6eJ926v6dkpzPVa5XzxiWoFdhT9llt85oYPajHiLxjQ="
+)
+
+func TestVerifySignature_Secp256k1(t *testing.T) {
+	der, err := base64.StdEncoding.DecodeString(secp256k1STHSignature)
+	require.NoError(t, err)
+
+	var rs struct {
+		R, S *big.Int
+	}
+
+	_, err = asn1.Unmarshal(der, &rs)
+	require.NoError(t, err)
+
+	ieeeP1363 := make([]byte, 64)
+	rs.R.FillBytes(ieeeP1363[:32])
+	rs.S.FillBytes(ieeeP1363[32:])
+
+	// reconstruct verifies the signature of the tree head of an empty log.
+	reconstruct := func(t *testing.T, keyType kms.KeyType, signature []byte, timestamp uint64,
+		pubKey string) error {
+		t.Helper()
+
+		treeHeadSignature, err := json.Marshal(command.DigitallySigned{
+			Algorithm: command.SignatureAndHashAlgorithm{Signature: command.ECDSASignature, Type: keyType},
+			Signature: signature,
+		})
+		require.NoError(t, err)
+
+		root := sha256.Sum256(nil)
+
+		sth, err := json.Marshal(command.GetSTHResponse{
+			Timestamp:         timestamp,
+			SHA256RootHash:    root[:],
+			TreeHeadSignature: treeHeadSignature,
+		})
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBuffer(sth)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		key, err := base64.StdEncoding.DecodeString(pubKey)
+		require.NoError(t, err)
+
+		_, err = vct.ReconstructAndCompareSTH(context.Background(), vct.New(endpoint, vct.WithHTTPClient(httpClient)),
+			key)
+
+		return err
+	}
+
+	t.Run("Success (DER)", func(t *testing.T) {
+		require.NoError(t, reconstruct(t, kms.ECDSASecp256k1DER, der, secp256k1STHTimestamp, secp256k1PubKey))
+	})
+
+	t.Run("Success (IEEE P1363)", func(t *testing.T) {
+		require.NoError(t, reconstruct(t, kms.ECDSASecp256k1IEEEP1363, ieeeP1363, secp256k1STHTimestamp,
+			secp256k1PubKey))
+	})
+
+	t.Run("Success (compressed public key)", func(t *testing.T) {
+		require.NoError(t, reconstruct(t, kms.ECDSASecp256k1DER, der, secp256k1STHTimestamp,
+			secp256k1CompressedPubKey))
+	})
+
+	t.Run("Wrong timestamp", func(t *testing.T) {
+		err := reconstruct(t, kms.ECDSASecp256k1DER, der, secp256k1STHTimestamp+1, secp256k1PubKey)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "secp256k1 signature verification failed")
+	})
+
+	t.Run("Wrong curve", func(t *testing.T) {
+		key, err := x509.MarshalPKIXPublicKey(newFakeLog(t).key.Public())
+		require.NoError(t, err)
+
+		err = reconstruct(t, kms.ECDSASecp256k1DER, der, secp256k1STHTimestamp, base64.StdEncoding.EncodeToString(key))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid secp256k1 public key: unexpected curve 1.2.840.10045.3.1.7")
+	})
+
+	t.Run("Invalid signature", func(t *testing.T) {
+		err := reconstruct(t, kms.ECDSASecp256k1IEEEP1363, der, secp256k1STHTimestamp, secp256k1PubKey)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid secp256k1 signature: unexpected size 72, expected 64")
+	})
+}
+
+// signED25519Timestamp signs the timestamp of the credential with a fixed ED25519 key and returns the public key
+// and the signature envelope.
+func signED25519Timestamp(t *testing.T, timestamp uint64, vc []byte) ([]byte, []byte) {
+	t.Helper()
+
+	privKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+
+	leaf, err := command.CreateLeaf(timestamp, vc, testutil.GetLoader(t))
+	require.NoError(t, err)
+
+	data, err := canonicalizer.MarshalCanonical(command.CreateVCTimestampSignature(leaf))
+	require.NoError(t, err)
+
+	signature, err := json.Marshal(command.DigitallySigned{
+		Algorithm: command.SignatureAndHashAlgorithm{
+			Signature: command.EDDSASignature,
+			Type:      kms.ED25519,
+		},
+		Signature: ed25519.Sign(privKey, data),
+	})
+	require.NoError(t, err)
+
+	return privKey.Public().(ed25519.PublicKey), signature
+}
+
+func TestVerifyVCTimestampSignatureWithBytes(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		log := newFakeLog(t)
+
+		resp, err := log.client().AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+
+		signed, err := vct.VerifyVCTimestampSignatureWithBytes(
+			resp.Signature, log.pubKey, resp.Timestamp, vcBachelorDegree, testutil.GetLoader(t),
+		)
+		require.NoError(t, err)
+
+		expected, err := vct.CanonicalizeForLog(vcBachelorDegree, testutil.GetLoader(t))
+		require.NoError(t, err)
+		require.Equal(t, expected, signed)
+	})
+
+	t.Run("Substituted credential", func(t *testing.T) {
+		log := newFakeLog(t)
+
+		resp, err := log.client().AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+
+		var vc map[string]interface{}
+		require.NoError(t, json.Unmarshal(vcBachelorDegree, &vc))
+
+		vc["id"] = "http://example.edu/credentials/substituted"
+
+		substituted, err := json.Marshal(vc)
+		require.NoError(t, err)
+
+		signed, err := vct.VerifyVCTimestampSignatureWithBytes(
+			resp.Signature, log.pubKey, resp.Timestamp, substituted, testutil.GetLoader(t),
+		)
+		require.Error(t, err)
+		require.Nil(t, signed)
+	})
+
+	t.Run("Unmarshal signature error", func(t *testing.T) {
+		_, err := vct.VerifyVCTimestampSignatureWithBytes(
+			[]byte(`[]`), []byte(`[]`), 1617977793917, vcBachelorDegree, testutil.GetLoader(t),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal signature")
+	})
+
+	t.Run("Empty signature", func(t *testing.T) {
+		_, err := vct.VerifyVCTimestampSignatureWithBytes(
+			[]byte(`null`), []byte(`[]`), 1617977793917, vcBachelorDegree, testutil.GetLoader(t),
+		)
+		require.EqualError(t, err, "unmarshal signature: empty signature")
+	})
+}
+
+func TestCanonicalizeForLog(t *testing.T) {
+	t.Run("Error", func(t *testing.T) {
+		_, err := vct.CanonicalizeForLog([]byte(`[]`), testutil.GetLoader(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create leaf")
 	})
 }
@@ -0,0 +1,54 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	jsonld "github.com/piprate/json-gold/ld"
+)
+
+// DIDLinkedResource is a logged credential stored as a DID-linked resource.
+type DIDLinkedResource struct {
+	// Credential is the credential.
+	Credential []byte
+	// Timestamp is the timestamp at which the credential was logged (the SCT timestamp).
+	Timestamp uint64
+}
+
+// DIDResourceResolver resolves DID URLs to DID-linked resources.
+type DIDResourceResolver interface {
+	Resolve(ctx context.Context, didURL string) (*DIDLinkedResource, error)
+}
+
+// VerifyDIDLinkedResource resolves the DID-linked resource referenced by the DID URL to a credential
+// and verifies end-to-end that the credential is included in the log (see VerifyCredential).
+// A ResolutionError is returned if the resource cannot be resolved and a VerificationError is returned
+// if the credential fails verification.
+func (c *Client) VerifyDIDLinkedResource(ctx context.Context, didURL string, pubKey []byte,
+	loader jsonld.DocumentLoader) error {
+	if c.didResourceResolver == nil {
+		return &ResolutionError{DIDURL: didURL, Err: errors.New("DID resource resolver is not configured")}
+	}
+
+	resource, err := c.didResourceResolver.Resolve(ctx, didURL)
+	if err != nil {
+		return &ResolutionError{DIDURL: didURL, Err: err}
+	}
+
+	if resource == nil || len(resource.Credential) == 0 {
+		return &ResolutionError{DIDURL: didURL, Err: errors.New("resource has no credential")}
+	}
+
+	if _, err = c.VerifyCredential(ctx, pubKey, resource.Timestamp, resource.Credential, loader); err != nil {
+		return fmt.Errorf("verify DID-linked resource: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/testutil"
+)
+
+const loggedAt = 1662067083140
+
+type stubResolver map[string]*vct.DIDLinkedResource
+
+func (r stubResolver) Resolve(_ context.Context, didURL string) (*vct.DIDLinkedResource, error) {
+	resource, ok := r[didURL]
+	if !ok {
+		return nil, fmt.Errorf("resource %s not found", didURL)
+	}
+
+	return resource, nil
+}
+
+func TestClient_VerifyDIDLinkedResource(t *testing.T) {
+	const (
+		loggedURL   = "did:example:123/resources/logged"
+		unloggedURL = "did:example:123/resources/unlogged"
+	)
+
+	unloggedVC, err := json.Marshal(simpleVC)
+	require.NoError(t, err)
+
+	log := newFakeLog(t)
+	log.addCredential(loggedAt-1, unloggedVC)
+	log.addCredential(loggedAt, vcBachelorDegree)
+	log.addCredential(loggedAt+1, unloggedVC)
+
+	resolver := stubResolver{
+		loggedURL:   {Credential: vcBachelorDegree, Timestamp: loggedAt},
+		unloggedURL: {Credential: unloggedVC, Timestamp: loggedAt},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		client := log.client(vct.WithDIDResourceResolver(resolver))
+
+		require.NoError(t, client.VerifyDIDLinkedResource(context.Background(), loggedURL, log.pubKey,
+			testutil.GetLoader(t)))
+	})
+
+	t.Run("Not logged", func(t *testing.T) {
+		client := log.client(vct.WithDIDResourceResolver(resolver))
+
+		err := client.VerifyDIDLinkedResource(context.Background(), unloggedURL, log.pubKey, testutil.GetLoader(t))
+		require.Error(t, err)
+
+		var verificationErr *vct.VerificationError
+		require.True(t, errors.As(err, &verificationErr))
+		require.Equal(t, vct.CheckInclusion, verificationErr.Check)
+
+		var resolutionErr *vct.ResolutionError
+		require.False(t, errors.As(err, &resolutionErr))
+	})
+
+	t.Run("Wrong public key", func(t *testing.T) {
+		client := log.client(vct.WithDIDResourceResolver(resolver))
+
+		err := client.VerifyDIDLinkedResource(context.Background(), loggedURL, newFakeLog(t).pubKey,
+			testutil.GetLoader(t))
+		require.Error(t, err)
+
+		var verificationErr *vct.VerificationError
+		require.True(t, errors.As(err, &verificationErr))
+		require.Equal(t, vct.CheckSTHSignature, verificationErr.Check)
+	})
+
+	t.Run("Resolution error", func(t *testing.T) {
+		client := log.client(vct.WithDIDResourceResolver(resolver))
+
+		err := client.VerifyDIDLinkedResource(context.Background(), "did:example:123/resources/unknown",
+			log.pubKey, testutil.GetLoader(t))
+		require.EqualError(t, err, "resolve did:example:123/resources/unknown: "+
+			"resource did:example:123/resources/unknown not found")
+
+		var resolutionErr *vct.ResolutionError
+		require.True(t, errors.As(err, &resolutionErr))
+		require.Equal(t, "did:example:123/resources/unknown", resolutionErr.DIDURL)
+
+		var verificationErr *vct.VerificationError
+		require.False(t, errors.As(err, &verificationErr))
+	})
+
+	t.Run("Empty resource", func(t *testing.T) {
+		client := log.client(vct.WithDIDResourceResolver(stubResolver{loggedURL: {}}))
+
+		err := client.VerifyDIDLinkedResource(context.Background(), loggedURL, log.pubKey, testutil.GetLoader(t))
+
+		var resolutionErr *vct.ResolutionError
+		require.True(t, errors.As(err, &resolutionErr))
+	})
+
+	t.Run("No resolver", func(t *testing.T) {
+		err := log.client().VerifyDIDLinkedResource(context.Background(), loggedURL, log.pubKey,
+			testutil.GetLoader(t))
+		require.EqualError(t, err, "resolve did:example:123/resources/logged: "+
+			"DID resource resolver is not configured")
+	})
+}
@@ -0,0 +1,169 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/trustbloc/vct/pkg/controller/command"
+)
+
+// DuplicatesOpt represents FindDuplicates option func.
+type DuplicatesOpt func(*duplicatesOptions)
+
+type duplicatesOptions struct {
+	bloomFilter *bloomFilter
+}
+
+// WithBloomFilter bounds the memory used by FindDuplicates for huge ranges. Entries are streamed twice:
+// the first pass only inserts fingerprints into a bloom filter sized for the expected number of entries and
+// false-positive rate, remembering the fingerprints that might have been seen before; the second pass
+// collects indices for those candidates only. The result is the same as in the default mode.
+func WithBloomFilter(expectedEntries uint64, falsePositiveRate float64) DuplicatesOpt {
+	return func(o *duplicatesOptions) {
+		o.bloomFilter = newBloomFilter(expectedEntries, falsePositiveRate)
+	}
+}
+
+// FindDuplicates streams entries in the range [start, end] and returns the content fingerprints that appear
+// at multiple indices together with those indices. The fingerprint is the base64 encoded SHA-256 hash of
+// the canonical credential stored in the leaf (the leaf identity hash), so the same credential logged at
+// different times is reported as a duplicate.
+func FindDuplicates(ctx context.Context, client *Client, start, end uint64,
+	opts ...DuplicatesOpt) (map[string][]uint64, error) {
+	options := &duplicatesOptions{}
+	for _, fn := range opts {
+		fn(options)
+	}
+
+	var candidates map[string]struct{}
+
+	if options.bloomFilter != nil {
+		candidates = map[string]struct{}{}
+
+		err := client.forEachEntry(ctx, start, end, func(index uint64, entry command.LeafEntry) error {
+			fingerprint, err := entryFingerprint(entry)
+			if err != nil {
+				return fmt.Errorf("entry %d: %w", index, err)
+			}
+
+			if options.bloomFilter.addIfAbsent(fingerprint[:]) {
+				candidates[encodeFingerprint(fingerprint)] = struct{}{}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("find duplicates: %w", err)
+		}
+	}
+
+	indices := map[string][]uint64{}
+
+	err := client.forEachEntry(ctx, start, end, func(index uint64, entry command.LeafEntry) error {
+		fingerprint, err := entryFingerprint(entry)
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", index, err)
+		}
+
+		key := encodeFingerprint(fingerprint)
+
+		if candidates != nil {
+			if _, ok := candidates[key]; !ok {
+				return nil
+			}
+		}
+
+		indices[key] = append(indices[key], index)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find duplicates: %w", err)
+	}
+
+	for key, val := range indices {
+		if len(val) < 2 {
+			delete(indices, key)
+		}
+	}
+
+	return indices, nil
+}
+
+func entryFingerprint(entry command.LeafEntry) ([sha256.Size]byte, error) {
+	leaf, err := decodeLeaf(entry.LeafInput)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+
+	if len(leaf.TimestampedEntry.VCEntry) == 0 {
+		return [sha256.Size]byte{}, errors.New("leaf has no VC entry")
+	}
+
+	return sha256.Sum256(leaf.TimestampedEntry.VCEntry), nil
+}
+
+func encodeFingerprint(fingerprint [sha256.Size]byte) string {
+	return base64.StdEncoding.EncodeToString(fingerprint[:])
+}
+
+// bloomFilter is a bloom filter over SHA-256 fingerprints. Since fingerprints are uniformly distributed,
+// the bit positions are derived from the fingerprint itself using double hashing.
+type bloomFilter struct {
+	bits   []uint64
+	size   uint64
+	hashes uint64
+}
+
+func newBloomFilter(expectedEntries uint64, falsePositiveRate float64) *bloomFilter {
+	if expectedEntries == 0 {
+		expectedEntries = 1
+	}
+
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+
+	size := uint64(math.Ceil(-float64(expectedEntries) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashes := uint64(math.Round(float64(size) / float64(expectedEntries) * math.Ln2))
+
+	if hashes == 0 {
+		hashes = 1
+	}
+
+	return &bloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+// addIfAbsent adds the fingerprint to the filter and returns true if it might have been added before.
+func (f *bloomFilter) addIfAbsent(fingerprint []byte) bool {
+	h1 := binary.BigEndian.Uint64(fingerprint[0:8])
+	h2 := binary.BigEndian.Uint64(fingerprint[8:16]) | 1
+
+	present := true
+
+	for i := uint64(0); i < f.hashes; i++ {
+		pos := (h1 + i*h2) % f.size
+
+		if f.bits[pos/64]&(1<<(pos%64)) == 0 {
+			present = false
+			f.bits[pos/64] |= 1 << (pos % 64)
+		}
+	}
+
+	return present
+}
@@ -0,0 +1,192 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/canonicalizer"
+	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/controller/command"
+)
+
+func newLeafEntry(t *testing.T, timestamp uint64, vcEntry string) command.LeafEntry {
+	t.Helper()
+
+	leafInput, err := canonicalizer.MarshalCanonical(command.MerkleTreeLeaf{
+		Version:  command.V1,
+		LeafType: command.TimestampedEntryLeafType,
+		TimestampedEntry: &command.TimestampedEntry{
+			EntryType: command.VCLogEntryType,
+			Timestamp: timestamp,
+			VCEntry:   []byte(vcEntry),
+		},
+	})
+	require.NoError(t, err)
+
+	return command.LeafEntry{LeafInput: leafInput}
+}
+
+// entriesHTTPClient returns HTTP client that serves get-entries requests from the given entries,
+// returning at most pageSize entries per request.
+func entriesHTTPClient(t *testing.T, ctrl *gomock.Controller, entries []command.LeafEntry,
+	pageSize int) *MockHTTPClient {
+	t.Helper()
+
+	httpClient := NewMockHTTPClient(ctrl)
+	httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		start, err := strconv.Atoi(req.URL.Query().Get("start"))
+		require.NoError(t, err)
+
+		end, err := strconv.Atoi(req.URL.Query().Get("end"))
+		require.NoError(t, err)
+
+		if end >= len(entries) {
+			end = len(entries) - 1
+		}
+
+		if end-start+1 > pageSize {
+			end = start + pageSize - 1
+		}
+
+		fakeResp, err := json.Marshal(command.GetEntriesResponse{Entries: entries[start : end+1]})
+		require.NoError(t, err)
+
+		return &http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+			StatusCode: http.StatusOK,
+		}, nil
+	}).AnyTimes()
+
+	return httpClient
+}
+
+func fingerprint(vcEntry string) string {
+	hash := sha256.Sum256([]byte(vcEntry))
+
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+func TestFindDuplicates(t *testing.T) {
+	entries := []command.LeafEntry{
+		newLeafEntry(t, 1, "vc-1"),
+		newLeafEntry(t, 2, "vc-2"),
+		newLeafEntry(t, 3, "vc-3"),
+		newLeafEntry(t, 4, "vc-2"),
+		newLeafEntry(t, 5, "vc-4"),
+		newLeafEntry(t, 6, "vc-2"),
+		newLeafEntry(t, 7, "vc-4"),
+	}
+
+	expected := map[string][]uint64{
+		fingerprint("vc-2"): {1, 3, 5},
+		fingerprint("vc-4"): {4, 6},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := vct.New(endpoint, vct.WithHTTPClient(entriesHTTPClient(t, ctrl, entries, 2)))
+
+		duplicates, err := vct.FindDuplicates(context.Background(), client, 0, uint64(len(entries)-1))
+		require.NoError(t, err)
+		require.Equal(t, expected, duplicates)
+	})
+
+	t.Run("Success (sub-range)", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := vct.New(endpoint, vct.WithHTTPClient(entriesHTTPClient(t, ctrl, entries, 2)))
+
+		duplicates, err := vct.FindDuplicates(context.Background(), client, 2, 5)
+		require.NoError(t, err)
+		require.Equal(t, map[string][]uint64{fingerprint("vc-2"): {3, 5}}, duplicates)
+	})
+
+	t.Run("Success (bloom filter)", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := vct.New(endpoint, vct.WithHTTPClient(entriesHTTPClient(t, ctrl, entries, 3)))
+
+		duplicates, err := vct.FindDuplicates(context.Background(), client, 0, uint64(len(entries)-1),
+			vct.WithBloomFilter(uint64(len(entries)), 0.01),
+		)
+		require.NoError(t, err)
+		require.Equal(t, expected, duplicates)
+	})
+
+	t.Run("Success (saturated bloom filter)", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := vct.New(endpoint, vct.WithHTTPClient(entriesHTTPClient(t, ctrl, entries, 3)))
+
+		// A tiny filter reports false positives, but those are filtered out by the second pass.
+		duplicates, err := vct.FindDuplicates(context.Background(), client, 0, uint64(len(entries)-1),
+			vct.WithBloomFilter(1, 0.9),
+		)
+		require.NoError(t, err)
+		require.Equal(t, expected, duplicates)
+	})
+
+	t.Run("No duplicates", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := vct.New(endpoint, vct.WithHTTPClient(entriesHTTPClient(t, ctrl, entries, 2)))
+
+		duplicates, err := vct.FindDuplicates(context.Background(), client, 0, 2)
+		require.NoError(t, err)
+		require.Empty(t, duplicates)
+	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		_, err := vct.FindDuplicates(context.Background(), vct.New(endpoint), 5, 2)
+		require.EqualError(t, err, "find duplicates: start 5 and end 2 values is not a valid range")
+	})
+
+	t.Run("Malformed leaf", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		malformed := []command.LeafEntry{entries[0], {LeafInput: []byte(`{}`)}}
+
+		client := vct.New(endpoint, vct.WithHTTPClient(entriesHTTPClient(t, ctrl, malformed, 2)))
+
+		_, err := vct.FindDuplicates(context.Background(), client, 0, 1)
+		require.EqualError(t, err, "find duplicates: entry 1: leaf has no timestamped entry")
+	})
+
+	t.Run("Get entries error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(nil, errors.New("error"))
+
+		client := vct.New(endpoint, vct.WithHTTPClient(httpClient))
+
+		_, err := vct.FindDuplicates(context.Background(), client, 0, 1)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "find duplicates: get entries")
+	})
+}
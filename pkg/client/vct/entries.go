@@ -0,0 +1,267 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/trustbloc/vct/pkg/controller/command"
+)
+
+// EntryIterator iterates over the entries of a range of the log. The log may return fewer entries than
+// requested, so the range is retrieved page by page as the iteration advances.
+//
+//	it := client.EntriesIterator(ctx, start, end)
+//	for it.Next() {
+//		entry := it.Entry()
+//		...
+//	}
+//
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type EntryIterator struct {
+	client *Client
+	ctx    context.Context
+	next   uint64
+	end    uint64
+	done   bool
+	page   []command.LeafEntry
+	index  uint64
+	entry  command.LeafEntry
+	err    error
+}
+
+// EntriesIterator returns an iterator over the entries in the range [start, end].
+func (c *Client) EntriesIterator(ctx context.Context, start, end uint64) *EntryIterator {
+	it := &EntryIterator{client: c, ctx: ctx, next: start, end: end}
+
+	if start > end {
+		it.err = fmt.Errorf("start %d and end %d values is not a valid range", start, end)
+	}
+
+	return it
+}
+
+// Next advances the iterator to the next entry, retrieving the next page of entries if needed. It returns false
+// when the end of the range is reached, or when the iteration failed, in which case Err returns the error.
+func (it *EntryIterator) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+
+	if len(it.page) == 0 {
+		if it.err = it.fetch(); it.err != nil {
+			return false
+		}
+	}
+
+	it.index, it.entry, it.page = it.next, it.page[0], it.page[1:]
+
+	if it.next == it.end {
+		it.done, it.page = true, nil
+	} else {
+		it.next++
+	}
+
+	return true
+}
+
+// Entry returns the current entry.
+func (it *EntryIterator) Entry() command.LeafEntry {
+	return it.entry
+}
+
+// Index returns the leaf index of the current entry.
+func (it *EntryIterator) Index() uint64 {
+	return it.index
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *EntryIterator) Err() error {
+	return it.err
+}
+
+func (it *EntryIterator) fetch() error {
+	if err := it.ctx.Err(); err != nil {
+		return err // nolint: wrapcheck
+	}
+
+	resp, err := it.client.GetEntries(it.ctx, it.next, it.end)
+	if err != nil {
+		return err
+	}
+
+	if resp == nil || len(resp.Entries) == 0 {
+		return fmt.Errorf("no entries returned for range [%d, %d]", it.next, it.end)
+	}
+
+	it.page = resp.Entries
+
+	return nil
+}
+
+// forEachEntry retrieves entries in the range [start, end] and calls fn for each of them.
+func (c *Client) forEachEntry(ctx context.Context, start, end uint64,
+	fn func(index uint64, entry command.LeafEntry) error) error {
+	it := c.EntriesIterator(ctx, start, end)
+
+	for it.Next() {
+		if err := fn(it.Index(), it.Entry()); err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// GetEntriesStrided returns the entries in the range [start, end] whose leaf index is start plus a multiple of the
+// stride, e.g. the entries of a shard of an indexer that shards the log by leaf index modulo the stride. With
+// stride 1 it returns every entry of the range, retrieved page by page like by EntriesIterator.
+//
+// The log cannot skip entries, so the stride is applied by the client: each request retrieves the contiguous
+// range from the next selected entry, and the entries in between are discarded. Only gaps of more than a page of
+// entries are not downloaded, so the bandwidth used for a small stride is about the bandwidth of retrieving the
+// whole range.
+func (c *Client) GetEntriesStrided(ctx context.Context, start, end, stride uint64) ([]command.LeafEntry, error) {
+	if stride == 0 {
+		return nil, errors.New("get entries strided: stride must be positive")
+	}
+
+	if start > end {
+		return nil, fmt.Errorf("get entries strided: start %d and end %d values is not a valid range", start, end)
+	}
+
+	// The last selected entry.
+	end = start + (end-start)/stride*stride
+
+	var entries []command.LeafEntry
+
+	for next := start; ; {
+		resp, err := c.GetEntries(ctx, next, end)
+		if err != nil {
+			return nil, fmt.Errorf("get entries strided: %w", err)
+		}
+
+		if resp == nil || len(resp.Entries) == 0 {
+			return nil, fmt.Errorf("get entries strided: no entries returned for range [%d, %d]", next, end)
+		}
+
+		last := next + uint64(len(resp.Entries)) - 1
+
+		for i := next; i <= last && i <= end; i++ {
+			if (i-start)%stride == 0 {
+				entries = append(entries, resp.Entries[i-next])
+			}
+		}
+
+		if last >= end {
+			return entries, nil
+		}
+
+		next = start + ((last-start)/stride+1)*stride
+	}
+}
+
+// decodeLeaf decodes the Merkle tree leaf from the leaf input.
+func decodeLeaf(leafInput []byte) (*command.MerkleTreeLeaf, error) {
+	var leaf *command.MerkleTreeLeaf
+
+	if err := json.Unmarshal(leafInput, &leaf); err != nil {
+		return nil, fmt.Errorf("unmarshal leaf: %w", err)
+	}
+
+	if leaf == nil || leaf.TimestampedEntry == nil {
+		return nil, errors.New("leaf has no timestamped entry")
+	}
+
+	return leaf, nil
+}
+
+// VerifyEntries verifies the VC timestamp signatures of the entries, e.g. a page returned by GetEntries, with
+// the log public key. The signatures are aligned by index with the entries; they are the signatures returned by
+// AddVC, as the log does not return them with the entries. The signed data is recalculated from the leaf input,
+// so no document loader is needed.
+//
+// The entries are verified concurrently by up to GOMAXPROCS workers. The returned errors are aligned by index
+// with the entries and are nil for the valid ones; an invalid signature is a VerificationError. An error is
+// returned instead if the context is done before all the entries are handed to the workers.
+func VerifyEntries(ctx context.Context, entries []command.LeafEntry, signatures [][]byte,
+	pubKey []byte) ([]error, error) {
+	if len(signatures) != len(entries) {
+		return nil, fmt.Errorf("verify entries: %d signatures for %d entries", len(signatures), len(entries))
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(entries) {
+		workers = len(entries)
+	}
+
+	errs := make([]error, len(entries))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for index := range indexes {
+				errs[index] = verifyEntry(entries[index], signatures[index], pubKey)
+			}
+		}()
+	}
+
+	var err error
+
+	for i := 0; i < len(entries) && err == nil; i++ {
+		// A ready worker may be selected over a done context.
+		if err = ctx.Err(); err != nil {
+			break
+		}
+
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	if err != nil {
+		return nil, fmt.Errorf("verify entries: %w", err)
+	}
+
+	return errs, nil
+}
+
+func verifyEntry(entry command.LeafEntry, signature, pubKey []byte) error {
+	leaf, err := decodeLeaf(entry.LeafInput)
+	if err != nil {
+		return err
+	}
+
+	sig, err := unmarshalSignature(signature)
+	if err != nil {
+		return &VerificationError{Check: CheckSCTSignature, Err: err}
+	}
+
+	if err = verifyLeafSignature(sig, pubKey, leaf); err != nil {
+		return &VerificationError{Check: CheckSCTSignature, Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,387 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/canonicalizer"
+	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/controller/command"
+)
+
+func TestClient_EntriesIterator(t *testing.T) {
+	entries := []command.LeafEntry{
+		newLeafEntry(t, 1, "vc-1"),
+		newLeafEntry(t, 2, "vc-2"),
+		newLeafEntry(t, 3, "vc-3"),
+		newLeafEntry(t, 4, "vc-4"),
+		newLeafEntry(t, 5, "vc-5"),
+	}
+
+	collect := func(it *vct.EntryIterator) ([]command.LeafEntry, []uint64) {
+		var (
+			result  []command.LeafEntry
+			indexes []uint64
+		)
+
+		for it.Next() {
+			result = append(result, it.Entry())
+			indexes = append(indexes, it.Index())
+		}
+
+		return result, indexes
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		for pageSize := 1; pageSize <= len(entries); pageSize++ {
+			ctrl := gomock.NewController(t)
+
+			client := vct.New(endpoint, vct.WithHTTPClient(entriesHTTPClient(t, ctrl, entries, pageSize)))
+
+			it := client.EntriesIterator(context.Background(), 1, 4)
+
+			result, indexes := collect(it)
+			require.NoError(t, it.Err())
+			require.Equal(t, entries[1:5], result, "page size %d", pageSize)
+			require.Equal(t, []uint64{1, 2, 3, 4}, indexes)
+			require.False(t, it.Next())
+
+			ctrl.Finish()
+		}
+	})
+
+	t.Run("Single entry", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := vct.New(endpoint, vct.WithHTTPClient(entriesHTTPClient(t, ctrl, entries, len(entries))))
+
+		it := client.EntriesIterator(context.Background(), 2, 2)
+
+		result, _ := collect(it)
+		require.NoError(t, it.Err())
+		require.Equal(t, entries[2:3], result)
+	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		it := vct.New(endpoint).EntriesIterator(context.Background(), 2, 1)
+
+		require.False(t, it.Next())
+		require.EqualError(t, it.Err(), "start 2 and end 1 values is not a valid range")
+	})
+
+	t.Run("No entries returned", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// The log returns the first two entries and nothing after them.
+		client := vct.New(endpoint, vct.WithHTTPClient(entriesHTTPClient(t, ctrl, entries[:2], len(entries))))
+
+		it := client.EntriesIterator(context.Background(), 0, 4)
+
+		result, _ := collect(it)
+		require.Equal(t, entries[:2], result)
+		require.EqualError(t, it.Err(), "no entries returned for range [2, 4]")
+		require.False(t, it.Next())
+	})
+
+	t.Run("Empty response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`null`)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		it := vct.New(endpoint, vct.WithHTTPClient(httpClient)).EntriesIterator(context.Background(), 0, 4)
+
+		require.False(t, it.Next())
+		require.EqualError(t, it.Err(), "no entries returned for range [0, 4]")
+	})
+
+	t.Run("Context cancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := vct.New(endpoint, vct.WithHTTPClient(entriesHTTPClient(t, ctrl, entries, 2)))
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		it := client.EntriesIterator(ctx, 0, 4)
+
+		require.True(t, it.Next())
+		require.True(t, it.Next())
+
+		cancel()
+
+		require.False(t, it.Next())
+		require.True(t, errors.Is(it.Err(), context.Canceled))
+	})
+
+	t.Run("Get entries error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fakeResp, err := json.Marshal(map[string]string{"message": "error"})
+		require.NoError(t, err)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBuffer(fakeResp)),
+			StatusCode: http.StatusInternalServerError,
+		}, nil)
+
+		it := vct.New(endpoint, vct.WithHTTPClient(httpClient)).EntriesIterator(context.Background(), 0, 4)
+
+		require.False(t, it.Next())
+		require.Error(t, it.Err())
+		require.Contains(t, it.Err().Error(), "get entries")
+	})
+}
+
+func TestClient_GetEntriesStrided(t *testing.T) {
+	entries := make([]command.LeafEntry, 10)
+	for i := range entries {
+		entries[i] = newLeafEntry(t, uint64(i), fmt.Sprintf("vc-%d", i))
+	}
+
+	// requests returns an HTTP client of a log with the given page size, which records the ranges requested.
+	requests := func(t *testing.T, pageSize int) (*MockHTTPClient, *[]string) {
+		t.Helper()
+
+		var ranges []string
+
+		ctrl := gomock.NewController(t)
+		log := entriesHTTPClient(t, ctrl, entries, pageSize)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			ranges = append(ranges, req.URL.Query().Get("start")+"-"+req.URL.Query().Get("end"))
+
+			return log.Do(req)
+		}).AnyTimes()
+
+		return httpClient, &ranges
+	}
+
+	t.Run("Stride 1", func(t *testing.T) {
+		for pageSize := 1; pageSize <= len(entries); pageSize++ {
+			httpClient, _ := requests(t, pageSize)
+
+			result, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).
+				GetEntriesStrided(context.Background(), 2, 8, 1)
+			require.NoError(t, err)
+			require.Equal(t, entries[2:9], result, "page size %d", pageSize)
+		}
+
+		httpClient, _ := requests(t, len(entries))
+
+		resp, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).GetEntries(context.Background(), 2, 8)
+		require.NoError(t, err)
+		require.Equal(t, entries[2:9], resp.Entries)
+	})
+
+	t.Run("Stride 3", func(t *testing.T) {
+		for pageSize := 1; pageSize <= len(entries); pageSize++ {
+			httpClient, _ := requests(t, pageSize)
+
+			result, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).
+				GetEntriesStrided(context.Background(), 1, 9, 3)
+			require.NoError(t, err)
+			require.Equal(t, []command.LeafEntry{entries[1], entries[4], entries[7]}, result, "page size %d", pageSize)
+		}
+	})
+
+	t.Run("Gaps are skipped", func(t *testing.T) {
+		httpClient, ranges := requests(t, 2)
+
+		result, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).
+			GetEntriesStrided(context.Background(), 0, 9, 4)
+		require.NoError(t, err)
+		require.Equal(t, []command.LeafEntry{entries[0], entries[4], entries[8]}, result)
+		require.Equal(t, []string{"0-8", "4-8", "8-8"}, *ranges)
+	})
+
+	t.Run("Stride beyond range", func(t *testing.T) {
+		httpClient, ranges := requests(t, len(entries))
+
+		result, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).
+			GetEntriesStrided(context.Background(), 5, 9, 100)
+		require.NoError(t, err)
+		require.Equal(t, entries[5:6], result)
+		require.Equal(t, []string{"5-5"}, *ranges)
+	})
+
+	t.Run("Invalid arguments", func(t *testing.T) {
+		_, err := vct.New(endpoint).GetEntriesStrided(context.Background(), 0, 9, 0)
+		require.EqualError(t, err, "get entries strided: stride must be positive")
+
+		_, err = vct.New(endpoint).GetEntriesStrided(context.Background(), 2, 1, 1)
+		require.EqualError(t, err, "get entries strided: start 2 and end 1 values is not a valid range")
+	})
+
+	t.Run("No entries returned", func(t *testing.T) {
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"entries":[]}`)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).
+			GetEntriesStrided(context.Background(), 0, 9, 2)
+		require.EqualError(t, err, "get entries strided: no entries returned for range [0, 8]")
+	})
+}
+
+// signedEntries returns entries, their VC timestamp signatures and the public key of the signing key.
+func signedEntries(tb testing.TB, n int) ([]command.LeafEntry, [][]byte, []byte) {
+	tb.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+
+	pubKey, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(tb, err)
+
+	entries := make([]command.LeafEntry, n)
+	signatures := make([][]byte, n)
+
+	for i := range entries {
+		leaf := &command.MerkleTreeLeaf{
+			Version:  command.V1,
+			LeafType: command.TimestampedEntryLeafType,
+			TimestampedEntry: &command.TimestampedEntry{
+				EntryType: command.VCLogEntryType,
+				Timestamp: uint64(fakeLogTimestamp + i),
+				VCEntry:   []byte(fmt.Sprintf("vc-%d", i)),
+			},
+		}
+
+		entries[i].LeafInput, err = canonicalizer.MarshalCanonical(leaf)
+		require.NoError(tb, err)
+
+		data, err := canonicalizer.MarshalCanonical(command.CreateVCTimestampSignature(leaf))
+		require.NoError(tb, err)
+
+		digest := sha256.Sum256(data)
+
+		sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		require.NoError(tb, err)
+
+		signatures[i], err = json.Marshal(command.DigitallySigned{
+			Algorithm: command.SignatureAndHashAlgorithm{Signature: command.ECDSASignature, Type: kms.ECDSAP256DER},
+			Signature: sig,
+		})
+		require.NoError(tb, err)
+	}
+
+	return entries, signatures, pubKey
+}
+
+func TestVerifyEntries(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		entries, signatures, pubKey := signedEntries(t, 50)
+
+		errs, err := vct.VerifyEntries(context.Background(), entries, signatures, pubKey)
+		require.NoError(t, err)
+		require.Len(t, errs, len(entries))
+
+		for _, entryErr := range errs {
+			require.NoError(t, entryErr)
+		}
+	})
+
+	t.Run("Invalid entries", func(t *testing.T) {
+		entries, signatures, pubKey := signedEntries(t, 6)
+
+		signatures[1] = signatures[2]
+		signatures[3] = []byte(`[]`)
+		signatures[5] = []byte(`null`)
+		entries[4].LeafInput = []byte(`leaf`)
+
+		errs, err := vct.VerifyEntries(context.Background(), entries, signatures, pubKey)
+		require.NoError(t, err)
+
+		require.NoError(t, errs[0])
+		require.NoError(t, errs[2])
+
+		for _, i := range []int{1, 3, 5} {
+			var verificationErr *vct.VerificationError
+			require.True(t, errors.As(errs[i], &verificationErr), "entry %d", i)
+			require.Equal(t, vct.CheckSCTSignature, verificationErr.Check)
+		}
+
+		require.Error(t, errs[4])
+		require.Contains(t, errs[4].Error(), "unmarshal leaf")
+		require.EqualError(t, errs[5], "sct_signature check failed: unmarshal signature: empty signature")
+	})
+
+	t.Run("No entries", func(t *testing.T) {
+		errs, err := vct.VerifyEntries(context.Background(), nil, nil, nil)
+		require.NoError(t, err)
+		require.Empty(t, errs)
+	})
+
+	t.Run("Signatures do not match entries", func(t *testing.T) {
+		entries, signatures, pubKey := signedEntries(t, 2)
+
+		_, err := vct.VerifyEntries(context.Background(), entries, signatures[:1], pubKey)
+		require.EqualError(t, err, "verify entries: 1 signatures for 2 entries")
+	})
+
+	t.Run("Context canceled", func(t *testing.T) {
+		entries, signatures, pubKey := signedEntries(t, 5)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := vct.VerifyEntries(ctx, entries, signatures, pubKey)
+		require.True(t, errors.Is(err, context.Canceled))
+	})
+}
+
+func BenchmarkVerifyEntries(b *testing.B) {
+	entries, signatures, pubKey := signedEntries(b, 1000)
+
+	b.Run("Serial", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for i := range entries {
+				if _, err := vct.VerifyEntries(context.Background(), entries[i:i+1], signatures[i:i+1],
+					pubKey); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("Concurrent", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			if _, err := vct.VerifyEntries(context.Background(), entries, signatures, pubKey); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
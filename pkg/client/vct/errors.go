@@ -0,0 +1,159 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrBadRequest matches an Error with the 400 Bad Request status code, e.g. a malformed credential.
+	ErrBadRequest = errors.New("bad request")
+	// ErrUnauthorized matches an Error with the 401 Unauthorized status code.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden matches an Error with the 403 Forbidden status code.
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound matches an Error with the 404 Not Found status code.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict matches an Error with the 409 Conflict status code, e.g. a duplicate credential.
+	ErrConflict = errors.New("conflict")
+	// ErrTooManyRequests matches an Error with the 429 Too Many Requests status code, see Error.RetryAfter.
+	ErrTooManyRequests = errors.New("too many requests")
+	// ErrServerError matches an Error with a 5xx status code.
+	ErrServerError = errors.New("server error")
+	// ErrOutOfRange is returned when a tree size or leaf index is beyond the tree of the log, see WithSTHCacheTTL.
+	ErrOutOfRange = errors.New("out of range")
+	// ErrInvalidRange is returned without sending the request when the arguments of a call do not form a valid
+	// range, e.g. the tree sizes of GetSTHConsistency, see WithoutClientValidation.
+	ErrInvalidRange = errors.New("invalid range")
+	// ErrResponseTooLarge is returned when a response body exceeds the maximum size, see WithMaxResponseBytes.
+	ErrResponseTooLarge = errors.New("response too large")
+	// ErrInvalidResponse is returned when a response of the log does not match the request, e.g. GetEntries
+	// returns more entries than requested, see WithoutClientValidation.
+	ErrInvalidResponse = errors.New("invalid response")
+	// ErrNoProof is returned without sending the request when a credential to add is not signed, see
+	// WithRequireProof.
+	ErrNoProof = errors.New("credential has no proof")
+	// ErrClientClosed is returned by requests of a client after Close.
+	ErrClientClosed = errors.New("client closed")
+)
+
+// errNotModified is returned when the log responds to a conditional request with 304 Not Modified.
+var errNotModified = errors.New("not modified")
+
+// Error is returned when the log responds with an error. The sentinel errors match it by status code, e.g.
+// errors.Is(err, ErrUnauthorized).
+type Error struct {
+	// StatusCode is the HTTP status code of the response. An error message in a response with a success status
+	// code is returned with that status code.
+	StatusCode int
+	// Op is the name of the client method that sent the request, e.g. AddVC.
+	Op string
+	// Message is the error message of the log. If the response has none, e.g. an HTML error page of a proxy, it
+	// describes the response with its status code and the beginning of its body.
+	Message string
+	// RetryAfter is the delay the log asked to wait for before sending the request again with the Retry-After
+	// header, e.g. of a 429 Too Many Requests response. It is zero if the response has no such header.
+	RetryAfter time.Duration
+}
+
+// Error returns error message.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is reports whether the error matches the target sentinel error.
+func (e *Error) Is(target error) bool {
+	switch target { // nolint: errorlint
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrTooManyRequests:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServerError:
+		return e.StatusCode >= http.StatusInternalServerError
+	default:
+		return false
+	}
+}
+
+// DecodeError is returned when a log response is malformed.
+type DecodeError struct {
+	// Field is the JSON name of the malformed field.
+	Field string
+	Err   error
+}
+
+// Error returns error message.
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decode %s: %v", e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// VerificationError is returned when a verification check fails.
+type VerificationError struct {
+	// Check is the name of the failed check.
+	Check string
+	Err   error
+}
+
+// Error returns error message.
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("%s check failed: %v", e.Check, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// ResolutionError is returned when a DID-linked resource cannot be resolved.
+type ResolutionError struct {
+	DIDURL string
+	Err    error
+}
+
+// Error returns error message.
+func (e *ResolutionError) Error() string {
+	return fmt.Sprintf("resolve %s: %v", e.DIDURL, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ResolutionError) Unwrap() error {
+	return e.Err
+}
+
+// RetryError is returned when a retried request fails.
+type RetryError struct {
+	// Attempts is the number of attempts made.
+	Attempts int
+	Err      error
+}
+
+// Error returns error message.
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("after %d attempts: %v", e.Attempts, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,65 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	jsonld "github.com/piprate/json-gold/ld"
+
+	"github.com/trustbloc/vct/pkg/controller/command"
+)
+
+// ErrExpiredAtLog is returned when a credential had already expired when it was logged.
+var ErrExpiredAtLog = errors.New("credential expired at log time")
+
+const (
+	defaultGraph            = "@default"
+	expirationDatePredicate = "https://www.w3.org/2018/credentials#expirationDate"
+	validUntilPredicate     = "https://www.w3.org/2018/credentials#validUntil"
+)
+
+// VerifyNotExpiredAtLog verifies that the credential of the given entry had not expired when it was logged.
+// The expirationDate (or validUntil) of the credential is read from the canonical (N-Quads) VC entry
+// of the leaf and compared against the leaf timestamp, which is the timestamp of the SCT.
+// Credentials without an expiration date pass the check.
+func VerifyNotExpiredAtLog(entry command.LeafEntry) error {
+	leaf, err := decodeLeaf(entry.LeafInput)
+	if err != nil {
+		return fmt.Errorf("decode leaf: %w", err)
+	}
+
+	dataset, err := jsonld.ParseNQuads(string(leaf.TimestampedEntry.VCEntry))
+	if err != nil {
+		return fmt.Errorf("parse VC entry: %w", err)
+	}
+
+	loggedAt := time.UnixMilli(int64(leaf.TimestampedEntry.Timestamp)).UTC()
+
+	for _, quad := range dataset.Graphs[defaultGraph] {
+		predicate := quad.Predicate.GetValue()
+		if predicate != expirationDatePredicate && predicate != validUntilPredicate {
+			continue
+		}
+
+		var expiresAt time.Time
+
+		expiresAt, err = time.Parse(time.RFC3339Nano, quad.Object.GetValue())
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", predicate, err)
+		}
+
+		if expiresAt.Before(loggedAt) {
+			return fmt.Errorf("%w: expired at %s, logged at %s", ErrExpiredAtLog,
+				expiresAt.UTC().Format(time.RFC3339Nano), loggedAt.Format(time.RFC3339Nano))
+		}
+	}
+
+	return nil
+}
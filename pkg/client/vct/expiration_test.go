@@ -0,0 +1,83 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/controller/command"
+)
+
+const (
+	vcTypeQuad = `<http://example.gov/credentials/789012> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> ` +
+		`<https://www.w3.org/2018/credentials#VerifiableCredential> .
+`
+	vcExpirationQuad = `<http://example.gov/credentials/789012> <https://www.w3.org/2018/credentials#expirationDate> ` +
+		`"2021-01-01T00:00:00Z"^^<http://www.w3.org/2001/XMLSchema#dateTime> .
+`
+	vcValidUntilQuad = `<http://example.gov/credentials/789012> <https://www.w3.org/2018/credentials#validUntil> ` +
+		`"2021-01-01T00:00:00Z"^^<http://www.w3.org/2001/XMLSchema#dateTime> .
+`
+	vcInvalidExpirationQuad = `<http://example.gov/credentials/789012> ` +
+		`<https://www.w3.org/2018/credentials#expirationDate> "tomorrow" .
+`
+)
+
+func TestVerifyNotExpiredAtLog(t *testing.T) {
+	expiresAt := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	before := uint64(expiresAt.Add(-time.Hour).UnixMilli())
+	after := uint64(expiresAt.Add(time.Hour).UnixMilli())
+
+	t.Run("Success", func(t *testing.T) {
+		require.NoError(t, vct.VerifyNotExpiredAtLog(newLeafEntry(t, before, vcTypeQuad+vcExpirationQuad)))
+		require.NoError(t, vct.VerifyNotExpiredAtLog(newLeafEntry(t, before, vcTypeQuad+vcValidUntilQuad)))
+	})
+
+	t.Run("Success (expires when logged)", func(t *testing.T) {
+		entry := newLeafEntry(t, uint64(expiresAt.UnixMilli()), vcTypeQuad+vcExpirationQuad)
+		require.NoError(t, vct.VerifyNotExpiredAtLog(entry))
+	})
+
+	t.Run("Success (no expiration)", func(t *testing.T) {
+		require.NoError(t, vct.VerifyNotExpiredAtLog(newLeafEntry(t, after, vcTypeQuad)))
+	})
+
+	t.Run("Expired at log (expirationDate)", func(t *testing.T) {
+		err := vct.VerifyNotExpiredAtLog(newLeafEntry(t, after, vcTypeQuad+vcExpirationQuad))
+		require.True(t, errors.Is(err, vct.ErrExpiredAtLog))
+		require.EqualError(t, err, "credential expired at log time: "+
+			"expired at 2021-01-01T00:00:00Z, logged at 2021-01-01T01:00:00Z")
+	})
+
+	t.Run("Expired at log (validUntil)", func(t *testing.T) {
+		err := vct.VerifyNotExpiredAtLog(newLeafEntry(t, after, vcTypeQuad+vcValidUntilQuad))
+		require.True(t, errors.Is(err, vct.ErrExpiredAtLog))
+	})
+
+	t.Run("Invalid expiration date", func(t *testing.T) {
+		err := vct.VerifyNotExpiredAtLog(newLeafEntry(t, before, vcTypeQuad+vcInvalidExpirationQuad))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse https://www.w3.org/2018/credentials#expirationDate")
+	})
+
+	t.Run("Malformed VC entry", func(t *testing.T) {
+		err := vct.VerifyNotExpiredAtLog(newLeafEntry(t, before, "not N-Quads"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse VC entry")
+	})
+
+	t.Run("Malformed leaf", func(t *testing.T) {
+		err := vct.VerifyNotExpiredAtLog(command.LeafEntry{LeafInput: []byte(`[]`)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decode leaf: unmarshal leaf")
+	})
+}
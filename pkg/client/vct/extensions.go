@@ -0,0 +1,111 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	jsonld "github.com/piprate/json-gold/ld"
+
+	"github.com/trustbloc/vct/pkg/canonicalizer"
+	"github.com/trustbloc/vct/pkg/controller/command"
+)
+
+const extensionsParamName = "extensions"
+
+// AddVCWithExtensions adds verifiable credential to log like AddVC, with extensions the log records in the
+// timestamped entry of the credential, e.g. a correlation key of the submitter. The extensions are sent as a JSON
+// object in the query of the request, and the log records them as the JCS (RFC 8785) canonical JSON object, see
+// command.MarshalExtensions; the response returns them base64 encoded in AddVCResponse.Extensions.
+//
+// The extensions are part of the leaf: both the signed timestamp and the leaf hash of the entry cover them, so
+// verifiers reproduce them with VerifyVCTimestampSignatureWithExtensions and CalculateLeafHashWithExtensions and
+// the extensions of the response; CalculateLeafHash and the methods built on it only match entries without
+// extensions. Logs that support extensions report command.FeatureAddVCExtensions, see GetLogInfo; older logs
+// ignore them. The log does not log a credential twice: if it was logged before, the response has the timestamp
+// and the extensions of the entry logged first, which may differ from the given ones.
+func (c *Client) AddVCWithExtensions(ctx context.Context, credential []byte,
+	extensions map[string]string) (*command.AddVCResponse, error) {
+	if len(extensions) == 0 {
+		return c.addVC(ctx, credential)
+	}
+
+	if _, err := command.MarshalExtensions(extensions); err != nil {
+		return nil, fmt.Errorf("add VC: %w", err)
+	}
+
+	param, err := json.Marshal(extensions)
+	if err != nil {
+		return nil, fmt.Errorf("add VC: marshal extensions: %w", err)
+	}
+
+	return c.addVC(ctx, credential, withValueAdd(extensionsParamName, string(param)))
+}
+
+// CalculateLeafHashWithExtensions calculates hash for given credentials like CalculateLeafHash, for an entry with
+// the given extensions, i.e. the base64 decoded AddVCResponse.Extensions. Without extensions, it returns the hash
+// of CalculateLeafHash.
+func CalculateLeafHashWithExtensions(timestamp uint64, vcBytes, extensions []byte,
+	loader jsonld.DocumentLoader) (string, error) {
+	leaf, err := createLeafWithExtensions(timestamp, vcBytes, extensions, loader)
+	if err != nil {
+		return "", err
+	}
+
+	leafData, err := canonicalizer.MarshalCanonical(leaf)
+	if err != nil {
+		return "", fmt.Errorf("marshal leaf: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(RFC6962LeafHasher{}.HashLeaf(leafData)), nil
+}
+
+// VerifyVCTimestampSignatureWithExtensions verifies VC timestamp signature like VerifyVCTimestampSignature, for an
+// entry with the given extensions, i.e. the base64 decoded AddVCResponse.Extensions.
+func VerifyVCTimestampSignatureWithExtensions(signature, pubKey []byte, timestamp uint64, vcBytes,
+	extensions []byte, loader jsonld.DocumentLoader) error {
+	sig, err := unmarshalSignature(signature)
+	if err != nil {
+		return err
+	}
+
+	leaf, err := createLeafWithExtensions(timestamp, vcBytes, extensions, loader)
+	if err != nil {
+		return err
+	}
+
+	return verifyLeafSignature(sig, pubKey, leaf)
+}
+
+// createLeafWithExtensions creates the leaf of the credential with the extensions. Empty extensions are not set,
+// as the log records no extensions.
+func createLeafWithExtensions(timestamp uint64, vcBytes, extensions []byte,
+	loader jsonld.DocumentLoader) (*command.MerkleTreeLeaf, error) {
+	leaf, err := command.CreateLeaf(timestamp, vcBytes, loader)
+	if err != nil {
+		return nil, fmt.Errorf("create leaf: %w", err)
+	}
+
+	if len(extensions) > 0 {
+		leaf.TimestampedEntry.Extensions = extensions
+	}
+
+	return leaf, nil
+}
+
+// decodeExtensions decodes the base64 encoded extensions of the response of AddVC.
+func decodeExtensions(extensions string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(extensions)
+	if err != nil {
+		return nil, fmt.Errorf("decode extensions: %w", err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"context"
+	"encoding/base64"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/testutil"
+)
+
+func TestClient_AddVCWithExtensions(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		log := newFakeLog(t)
+
+		resp, err := log.client(vct.WithVerifySCT(testutil.GetLoader(t))).AddVCWithExtensions(context.Background(),
+			vcBachelorDegree, map[string]string{"correlation": "abc", "batch": "1"})
+		require.NoError(t, err)
+
+		extensions, err := base64.StdEncoding.DecodeString(resp.Extensions)
+		require.NoError(t, err)
+		require.Equal(t, `{"batch":"1","correlation":"abc"}`, string(extensions))
+
+		require.NoError(t, vct.VerifyVCTimestampSignatureWithExtensions(resp.Signature, log.pubKey, resp.Timestamp,
+			vcBachelorDegree, extensions, testutil.GetLoader(t)))
+
+		require.Error(t, vct.VerifyVCTimestampSignature(resp.Signature, log.pubKey, resp.Timestamp,
+			vcBachelorDegree, testutil.GetLoader(t)))
+
+		hash, err := vct.CalculateLeafHashWithExtensions(resp.Timestamp, vcBachelorDegree, extensions,
+			testutil.GetLoader(t))
+		require.NoError(t, err)
+		require.Equal(t, base64.StdEncoding.EncodeToString(log.leafHashes(1)[0]), hash)
+
+		hashWithout, err := vct.CalculateLeafHash(resp.Timestamp, vcBachelorDegree, testutil.GetLoader(t))
+		require.NoError(t, err)
+		require.NotEqual(t, hash, hashWithout)
+	})
+
+	t.Run("No extensions", func(t *testing.T) {
+		log := newFakeLog(t)
+
+		resp, err := log.client(vct.WithVerifySCT(testutil.GetLoader(t))).AddVCWithExtensions(context.Background(),
+			vcBachelorDegree, nil)
+		require.NoError(t, err)
+		require.Empty(t, resp.Extensions)
+
+		hash, err := vct.CalculateLeafHashWithExtensions(resp.Timestamp, vcBachelorDegree, []byte{},
+			testutil.GetLoader(t))
+		require.NoError(t, err)
+
+		hashWithout, err := vct.CalculateLeafHash(resp.Timestamp, vcBachelorDegree, testutil.GetLoader(t))
+		require.NoError(t, err)
+		require.Equal(t, hashWithout, hash)
+
+		require.NoError(t, vct.VerifyVCTimestampSignatureWithExtensions(resp.Signature, log.pubKey, resp.Timestamp,
+			vcBachelorDegree, nil, testutil.GetLoader(t)))
+	})
+
+	t.Run("Extensions too long", func(t *testing.T) {
+		_, err := vct.New(endpoint).AddVCWithExtensions(context.Background(), vcBachelorDegree,
+			map[string]string{"key": strings.Repeat("a", math.MaxUint16)})
+		require.EqualError(t, err, "add VC: extensions exceed the maximum length of 65535 bytes")
+	})
+
+	t.Run("Empty signature", func(t *testing.T) {
+		require.EqualError(t, vct.VerifyVCTimestampSignatureWithExtensions([]byte(`null`), nil, 0, vcBachelorDegree,
+			nil, testutil.GetLoader(t)), "unmarshal signature: empty signature")
+	})
+}
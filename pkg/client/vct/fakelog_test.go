@@ -0,0 +1,500 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/canonicalizer"
+	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/controller/command"
+	"github.com/trustbloc/vct/pkg/controller/rest"
+	"github.com/trustbloc/vct/pkg/testutil"
+)
+
+const (
+	fakeLogAlias     = "maple2020"
+	fakeLogTimestamp = 1624000000000
+)
+
+// fakeLog is an in-memory log that serves the VCT REST API. Tree heads and timestamps are signed
+// with an ECDSA P-256 key. Merkle tree hashes are calculated with the RFC 6962 reference definitions.
+type fakeLog struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *ecdsa.PrivateKey
+	pubKey []byte
+
+	mu        sync.Mutex
+	leaves    [][]byte
+	extraData [][]byte
+	corrupted map[uint64][]byte
+	issuers   []string
+	// forgedRoot, if set, is served and signed as the root hash of the tree instead of the actual one.
+	forgedRoot []byte
+	// lagTreeSize, if set, is the size of the tree of the served tree head instead of the actual one.
+	lagTreeSize uint64
+	// leafHasher, if set, hashes the leaves instead of the RFC 6962 leaf hash.
+	leafHasher vct.LeafHasher
+}
+
+func newFakeLog(t *testing.T) *fakeLog {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	pubKey, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+
+	l := &fakeLog{t: t, key: key, pubKey: pubKey, corrupted: map[uint64][]byte{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(l.path(rest.AddVCPath), l.addVC)
+	mux.HandleFunc(l.path(rest.GetSTHPath), l.getSTH)
+	mux.HandleFunc(l.path(rest.GetSTHConsistencyPath), l.getSTHConsistency)
+	mux.HandleFunc(l.path(rest.GetProofByHashPath), l.getProofByHash)
+	mux.HandleFunc(l.path(rest.GetEntriesPath), l.getEntries)
+	mux.HandleFunc(l.path(rest.GetEntryAndProofPath), l.getEntryAndProof)
+	mux.HandleFunc(l.path(rest.GetIssuersPath), l.getIssuers)
+	mux.HandleFunc(rest.WebfingerPath, l.webfinger)
+
+	l.server = httptest.NewServer(mux)
+	t.Cleanup(l.server.Close)
+
+	return l
+}
+
+func (l *fakeLog) path(p string) string {
+	return "/" + fakeLogAlias + p[len(rest.AliasPath):]
+}
+
+func (l *fakeLog) endpoint() string {
+	return l.server.URL + "/" + fakeLogAlias
+}
+
+func (l *fakeLog) client(opts ...vct.ClientOpt) *vct.Client {
+	return vct.New(l.endpoint(), opts...)
+}
+
+// addLeaf appends the leaf input to the log and returns its index.
+func (l *fakeLog) addLeaf(leafInput []byte) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.leaves = append(l.leaves, leafInput)
+	l.extraData = append(l.extraData, nil)
+
+	return uint64(len(l.leaves) - 1)
+}
+
+// addCredential logs the credential with the given timestamp and returns its leaf index.
+func (l *fakeLog) addCredential(timestamp uint64, vc []byte) uint64 {
+	leaf, err := command.CreateLeaf(timestamp, vc, testutil.GetLoader(l.t))
+	require.NoError(l.t, err)
+
+	leafInput, err := canonicalizer.MarshalCanonical(leaf)
+	require.NoError(l.t, err)
+
+	return l.addLeaf(leafInput)
+}
+
+// corrupt makes the log serve the given leaf input at the index instead of the logged one.
+func (l *fakeLog) corrupt(index uint64, leafInput []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.corrupted[index] = leafInput
+}
+
+// forgeRoot makes the log sign the given root hash instead of the root hash of its tree.
+func (l *fakeLog) forgeRoot(root []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.forgedRoot = root
+}
+
+// lag makes the log serve the tree head of the tree with the given size, as a lagging replica would.
+func (l *fakeLog) lag(treeSize uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lagTreeSize = treeSize
+}
+
+// hashLeavesWith makes the log hash its leaves with the leaf hasher, as a log configured with another leaf
+// encoding would.
+func (l *fakeLog) hashLeavesWith(leafHasher vct.LeafHasher) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.leafHasher = leafHasher
+}
+
+func (l *fakeLog) servedLeaf(index uint64) []byte {
+	if leafInput, ok := l.corrupted[index]; ok {
+		return leafInput
+	}
+
+	return l.leaves[index]
+}
+
+func (l *fakeLog) leafHashes(treeSize uint64) [][]byte {
+	hashes := make([][]byte, treeSize)
+	for i := range hashes {
+		if l.leafHasher != nil {
+			hashes[i] = l.leafHasher.HashLeaf(l.leaves[i])
+		} else {
+			hashes[i] = rfc6962LeafHash(l.leaves[i])
+		}
+	}
+
+	return hashes
+}
+
+func (l *fakeLog) sign(v interface{}) []byte {
+	data, err := canonicalizer.MarshalCanonical(v)
+	require.NoError(l.t, err)
+
+	digest := sha256.Sum256(data)
+
+	sig, err := ecdsa.SignASN1(rand.Reader, l.key, digest[:])
+	require.NoError(l.t, err)
+
+	signature, err := json.Marshal(command.DigitallySigned{
+		Algorithm: command.SignatureAndHashAlgorithm{
+			Signature: command.ECDSASignature,
+			Type:      kms.ECDSAP256DER,
+		},
+		Signature: sig,
+	})
+	require.NoError(l.t, err)
+
+	return signature
+}
+
+func (l *fakeLog) addVC(w http.ResponseWriter, r *http.Request) {
+	vc, err := ioutil.ReadAll(r.Body)
+	require.NoError(l.t, err)
+
+	l.mu.Lock()
+	timestamp := uint64(fakeLogTimestamp + len(l.leaves))
+	l.mu.Unlock()
+
+	leaf, err := command.CreateLeaf(timestamp, vc, testutil.GetLoader(l.t))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if param := r.URL.Query().Get("extensions"); param != "" {
+		var extensions map[string]string
+		require.NoError(l.t, json.Unmarshal([]byte(param), &extensions))
+
+		leaf.TimestampedEntry.Extensions, err = command.MarshalExtensions(extensions)
+		require.NoError(l.t, err)
+	}
+
+	leafInput, err := canonicalizer.MarshalCanonical(leaf)
+	require.NoError(l.t, err)
+
+	l.addLeaf(leafInput)
+
+	writeResponse(w, command.AddVCResponse{
+		SVCTVersion: command.V1,
+		Timestamp:   timestamp,
+		Extensions:  base64.StdEncoding.EncodeToString(leaf.TimestampedEntry.Extensions),
+		Signature:   l.sign(command.CreateVCTimestampSignature(leaf)),
+	})
+}
+
+func (l *fakeLog) getSTH(w http.ResponseWriter, _ *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	treeSize := uint64(len(l.leaves))
+	if l.lagTreeSize != 0 {
+		treeSize = l.lagTreeSize
+	}
+
+	timestamp := uint64(fakeLogTimestamp) + treeSize
+
+	root := rfc6962Root(l.leafHashes(treeSize))
+	if l.forgedRoot != nil {
+		root = l.forgedRoot
+	}
+
+	writeResponse(w, command.GetSTHResponse{
+		TreeSize:       treeSize,
+		Timestamp:      timestamp,
+		SHA256RootHash: root,
+		TreeHeadSignature: l.sign(command.TreeHeadSignature{
+			Version:        command.V1,
+			SignatureType:  command.TreeHeadSignatureType,
+			Timestamp:      timestamp,
+			TreeSize:       treeSize,
+			SHA256RootHash: root,
+		}),
+	})
+}
+
+func (l *fakeLog) getSTHConsistency(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	first, second, ok := parseRange(w, r, "first", "second")
+	if !ok {
+		return
+	}
+
+	if first == 0 || first > second || second > uint64(len(l.leaves)) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid range [%d, %d]", first, second))
+
+		return
+	}
+
+	writeResponse(w, command.GetSTHConsistencyResponse{
+		Consistency: rfc6962ConsistencyProof(first, l.leafHashes(second)),
+	})
+}
+
+func (l *fakeLog) getProofByHash(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hash, err := base64.StdEncoding.DecodeString(r.URL.Query().Get("hash"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	treeSize, err := strconv.ParseUint(r.URL.Query().Get("tree_size"), 10, 64)
+	if err != nil || treeSize > uint64(len(l.leaves)) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid tree size"))
+
+		return
+	}
+
+	hashes := l.leafHashes(treeSize)
+
+	for i, leafHash := range hashes {
+		if bytes.Equal(leafHash, hash) {
+			writeResponse(w, command.GetProofByHashResponse{
+				LeafIndex: int64(i),
+				AuditPath: rfc6962AuditPath(uint64(i), hashes),
+			})
+
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, fmt.Errorf("leaf not found"))
+}
+
+func (l *fakeLog) getEntries(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	start, end, ok := parseRange(w, r, "start", "end")
+	if !ok {
+		return
+	}
+
+	if start > end || start >= uint64(len(l.leaves)) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid range [%d, %d]", start, end))
+
+		return
+	}
+
+	if end >= uint64(len(l.leaves)) {
+		end = uint64(len(l.leaves)) - 1
+	}
+
+	var entries []command.LeafEntry
+
+	for i := start; i <= end; i++ {
+		entries = append(entries, command.LeafEntry{LeafInput: l.servedLeaf(i), ExtraData: l.extraData[i]})
+	}
+
+	writeResponse(w, command.GetEntriesResponse{Entries: entries})
+}
+
+func (l *fakeLog) getEntryAndProof(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	leafIndex, treeSize, ok := parseRange(w, r, "leaf_index", "tree_size")
+	if !ok {
+		return
+	}
+
+	if leafIndex >= treeSize || treeSize > uint64(len(l.leaves)) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid leaf index %d or tree size %d", leafIndex, treeSize))
+
+		return
+	}
+
+	writeResponse(w, command.GetEntryAndProofResponse{
+		LeafInput: l.servedLeaf(leafIndex),
+		ExtraData: l.extraData[leafIndex],
+		AuditPath: rfc6962AuditPath(leafIndex, l.leafHashes(treeSize)),
+	})
+}
+
+// setIssuers sets the issuers accepted by the log.
+func (l *fakeLog) setIssuers(issuers ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.issuers = issuers
+}
+
+func (l *fakeLog) getIssuers(w http.ResponseWriter, _ *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	writeResponse(w, l.issuers)
+}
+
+func (l *fakeLog) webfinger(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, command.WebFingerResponse{
+		Subject: r.URL.Query().Get("resource"),
+		Properties: map[string]interface{}{
+			command.PublicKeyType: l.pubKey,
+		},
+		Links: []command.WebFingerLink{{Rel: "self", Href: r.URL.Query().Get("resource")}},
+	})
+}
+
+func parseRange(w http.ResponseWriter, r *http.Request, first, second string) (uint64, uint64, bool) {
+	a, err := strconv.ParseUint(r.URL.Query().Get(first), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return 0, 0, false
+	}
+
+	b, err := strconv.ParseUint(r.URL.Query().Get(second), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return 0, 0, false
+	}
+
+	return a, b, true
+}
+
+func writeResponse(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(v) // nolint: errcheck,gosec
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(rest.ErrorResponse{Message: err.Error()}) // nolint: errcheck,gosec
+}
+
+func rfc6962LeafHash(leaf []byte) []byte {
+	hash := sha256.Sum256(append([]byte{0x00}, leaf...))
+
+	return hash[:]
+}
+
+func rfc6962NodeHash(left, right []byte) []byte {
+	hash := sha256.Sum256(append(append([]byte{0x01}, left...), right...))
+
+	return hash[:]
+}
+
+// rfc6962Split returns the largest power of two smaller than n.
+func rfc6962Split(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+
+	return k
+}
+
+// rfc6962Root returns MTH(D[n]) as defined in RFC 6962, section 2.1.
+func rfc6962Root(hashes [][]byte) []byte {
+	switch len(hashes) {
+	case 0:
+		hash := sha256.Sum256(nil)
+
+		return hash[:]
+	case 1:
+		return hashes[0]
+	}
+
+	k := rfc6962Split(len(hashes))
+
+	return rfc6962NodeHash(rfc6962Root(hashes[:k]), rfc6962Root(hashes[k:]))
+}
+
+// rfc6962AuditPath returns PATH(m, D[n]) as defined in RFC 6962, section 2.1.1.
+func rfc6962AuditPath(m uint64, hashes [][]byte) [][]byte {
+	if len(hashes) <= 1 {
+		return [][]byte{}
+	}
+
+	k := uint64(rfc6962Split(len(hashes)))
+
+	if m < k {
+		return append(rfc6962AuditPath(m, hashes[:k]), rfc6962Root(hashes[k:]))
+	}
+
+	return append(rfc6962AuditPath(m-k, hashes[k:]), rfc6962Root(hashes[:k]))
+}
+
+// rfc6962ConsistencyProof returns PROOF(m, D[n]) as defined in RFC 6962, section 2.1.2.
+func rfc6962ConsistencyProof(m uint64, hashes [][]byte) [][]byte {
+	return rfc6962SubProof(m, hashes, true)
+}
+
+func rfc6962SubProof(m uint64, hashes [][]byte, complete bool) [][]byte {
+	n := uint64(len(hashes))
+
+	if m == n {
+		if complete {
+			return [][]byte{}
+		}
+
+		return [][]byte{rfc6962Root(hashes)}
+	}
+
+	k := uint64(rfc6962Split(len(hashes)))
+
+	if m <= k {
+		return append(rfc6962SubProof(m, hashes[:k], complete), rfc6962Root(hashes[k:]))
+	}
+
+	return append(rfc6962SubProof(m-k, hashes[k:], false), rfc6962Root(hashes[:k]))
+}
@@ -0,0 +1,59 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+const h2cDialTimeout = 30 * time.Second
+
+// idleConnectionsCloser is implemented by the transports of the default HTTP client, see Close.
+type idleConnectionsCloser interface {
+	CloseIdleConnections()
+}
+
+// h2cTransport sends requests to http:// URLs with HTTP/2 over cleartext TCP, and other requests with the
+// default transport, see WithH2C.
+type h2cTransport struct {
+	h2c      *http2.Transport
+	fallback http.RoundTripper
+}
+
+func newH2CTransport(fallback http.RoundTripper) *h2cTransport {
+	return &h2cTransport{
+		h2c: &http2.Transport{
+			AllowHTTP: true,
+			// The transport dials TLS for every URL, which is only used for http:// URLs here.
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return (&net.Dialer{Timeout: h2cDialTimeout}).Dial(network, addr)
+			},
+		},
+		fallback: fallback,
+	}
+}
+
+func (t *h2cTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "http" {
+		return t.h2c.RoundTrip(req) // nolint: wrapcheck
+	}
+
+	return t.fallback.RoundTrip(req) // nolint: wrapcheck
+}
+
+func (t *h2cTransport) CloseIdleConnections() {
+	t.h2c.CloseIdleConnections()
+
+	if closer, ok := t.fallback.(idleConnectionsCloser); ok {
+		closer.CloseIdleConnections()
+	}
+}
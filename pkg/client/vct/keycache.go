@@ -0,0 +1,88 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WithKeyCacheTTL sets how long the public key of the log retrieved by PublicKey is cached, after which it is
+// retrieved again on next use, e.g. to pick up a rotated key. By default, the key is cached for the lifetime of
+// the client.
+func WithKeyCacheTTL(ttl time.Duration) ClientOpt {
+	return func(o *Client) {
+		o.keyCacheTTL = ttl
+	}
+}
+
+// PublicKey returns the public key of the log like GetPublicKey, from the cache the client verifies the
+// signatures of the log with, e.g. with WithVerifySCT and VerifyInclusionByCredential. The key is retrieved on
+// first use and once it has expired, see WithKeyCacheTTL; concurrent callers share a single webfinger request,
+// and failed retrievals are not cached.
+func (c *Client) PublicKey(ctx context.Context) ([]byte, error) {
+	return c.keyCache.get(ctx, c.keyCacheTTL, c.GetPublicKey)
+}
+
+// keyCache caches a public key and lets concurrent callers share the retrieval of the key in flight.
+type keyCache struct {
+	mu        sync.Mutex
+	pubKey    []byte
+	fetchedAt time.Time
+	call      *keyCall
+}
+
+// keyCall is a retrieval of the public key, the result of which is set once done is closed.
+type keyCall struct {
+	done   chan struct{}
+	pubKey []byte
+	err    error
+}
+
+func (k *keyCache) get(ctx context.Context, ttl time.Duration,
+	fetch func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	k.mu.Lock()
+
+	if k.pubKey != nil && (ttl <= 0 || time.Since(k.fetchedAt) < ttl) {
+		pubKey := k.pubKey
+		k.mu.Unlock()
+
+		return pubKey, nil
+	}
+
+	if call := k.call; call != nil {
+		k.mu.Unlock()
+
+		select {
+		case <-call.done:
+			return call.pubKey, call.err
+		case <-ctx.Done():
+			return nil, fmt.Errorf("get public key: %w", ctx.Err())
+		}
+	}
+
+	call := &keyCall{done: make(chan struct{})}
+	k.call = call
+	k.mu.Unlock()
+
+	call.pubKey, call.err = fetch(ctx)
+
+	k.mu.Lock()
+
+	if call.err == nil {
+		k.pubKey, k.fetchedAt = call.pubKey, time.Now()
+	}
+
+	k.call = nil
+	k.mu.Unlock()
+
+	close(call.done)
+
+	return call.pubKey, call.err
+}
@@ -0,0 +1,190 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+)
+
+// blockingHTTPClient holds the requests until released, reporting the first one on entered.
+type blockingHTTPClient struct {
+	countingHTTPClient
+
+	once    sync.Once
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newBlockingHTTPClient() *blockingHTTPClient {
+	return &blockingHTTPClient{entered: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (c *blockingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&c.requests, 1)
+
+	c.once.Do(func() { close(c.entered) })
+	<-c.release
+
+	return http.DefaultClient.Do(req)
+}
+
+type publicKeyResult struct {
+	pubKey []byte
+	err    error
+}
+
+func TestClient_PublicKey(t *testing.T) {
+	log := newFakeLog(t)
+
+	t.Run("Cached", func(t *testing.T) {
+		httpClient := &countingHTTPClient{}
+		client := log.client(vct.WithHTTPClient(httpClient))
+
+		pubKey, err := client.PublicKey(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, log.pubKey, pubKey)
+
+		pubKey, err = client.PublicKey(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, log.pubKey, pubKey)
+		require.Equal(t, 1, httpClient.count())
+	})
+
+	t.Run("Second concurrent call shares the request", func(t *testing.T) {
+		httpClient := newBlockingHTTPClient()
+		client := log.client(vct.WithHTTPClient(httpClient))
+
+		results := make(chan publicKeyResult, 2)
+
+		get := func() {
+			pubKey, err := client.PublicKey(context.Background())
+			results <- publicKeyResult{pubKey: pubKey, err: err}
+		}
+
+		go get()
+		<-httpClient.entered
+		go get()
+
+		select {
+		case <-results:
+			t.Fatal("public key returned while the request is in flight")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(httpClient.release)
+
+		for i := 0; i < 2; i++ {
+			result := <-results
+			require.NoError(t, result.err)
+			require.Equal(t, log.pubKey, result.pubKey)
+		}
+
+		require.Equal(t, 1, httpClient.count())
+	})
+
+	t.Run("Race", func(t *testing.T) {
+		const callers = 50
+
+		httpClient := &countingHTTPClient{}
+		client := log.client(vct.WithHTTPClient(httpClient), vct.WithKeyCacheTTL(time.Hour))
+
+		var wg sync.WaitGroup
+
+		results := make([]publicKeyResult, callers)
+
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				pubKey, err := client.PublicKey(context.Background())
+				results[i] = publicKeyResult{pubKey: pubKey, err: err}
+			}(i)
+		}
+
+		wg.Wait()
+
+		for _, result := range results {
+			require.NoError(t, result.err)
+			require.Equal(t, log.pubKey, result.pubKey)
+		}
+
+		require.Equal(t, 1, httpClient.count())
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		httpClient := &countingHTTPClient{}
+		client := log.client(vct.WithHTTPClient(httpClient), vct.WithKeyCacheTTL(time.Nanosecond))
+
+		_, err := client.PublicKey(context.Background())
+		require.NoError(t, err)
+
+		time.Sleep(time.Millisecond)
+
+		pubKey, err := client.PublicKey(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, log.pubKey, pubKey)
+		require.Equal(t, 2, httpClient.count())
+	})
+
+	t.Run("Waiter context canceled", func(t *testing.T) {
+		httpClient := newBlockingHTTPClient()
+		client := log.client(vct.WithHTTPClient(httpClient))
+
+		results := make(chan publicKeyResult, 1)
+
+		go func() {
+			pubKey, err := client.PublicKey(context.Background())
+			results <- publicKeyResult{pubKey: pubKey, err: err}
+		}()
+
+		<-httpClient.entered
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := client.PublicKey(ctx)
+		require.EqualError(t, err, "get public key: context canceled")
+
+		close(httpClient.release)
+
+		result := <-results
+		require.NoError(t, result.err)
+		require.Equal(t, log.pubKey, result.pubKey)
+		require.Equal(t, 1, httpClient.count())
+	})
+
+	t.Run("Error is not cached", func(t *testing.T) {
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		gomock.InOrder(
+			httpClient.EXPECT().Do(gomock.Any()).Return(nil, errors.New("error")),
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(http.DefaultClient.Do),
+		)
+
+		client := log.client(vct.WithHTTPClient(httpClient))
+
+		_, err := client.PublicKey(context.Background())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get public key")
+
+		pubKey, err := client.PublicKey(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, log.pubKey, pubKey)
+	})
+}
@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultKeyTTL = time.Hour
+
+// KeyDirectoryOpt represents KeyDirectory option func.
+type KeyDirectoryOpt func(*KeyDirectory)
+
+// WithKeyTTL sets how long resolved public keys are cached. Defaults to one hour.
+func WithKeyTTL(ttl time.Duration) KeyDirectoryOpt {
+	return func(d *KeyDirectory) {
+		d.ttl = ttl
+	}
+}
+
+// WithKeyDirectoryClientOpts sets the options of the clients used to resolve public keys.
+func WithKeyDirectoryClientOpts(opts ...ClientOpt) KeyDirectoryOpt {
+	return func(d *KeyDirectory) {
+		d.clientOpts = opts
+	}
+}
+
+// KeyDirectory resolves and caches the public keys of the logs (aliases) served by a VCT instance.
+// Public keys are discovered via Webfinger and verified against the signature of the latest signed tree head
+// of the log before they are cached.
+type KeyDirectory struct {
+	baseEndpoint string
+	ttl          time.Duration
+	clientOpts   []ClientOpt
+
+	mu   sync.Mutex
+	keys map[string]cachedKey
+}
+
+type cachedKey struct {
+	pubKey  []byte
+	expires time.Time
+}
+
+// NewKeyDirectory returns a key directory for the VCT instance at the given base endpoint
+// (e.g. https://vct.example.com).
+func NewKeyDirectory(baseEndpoint string, opts ...KeyDirectoryOpt) *KeyDirectory {
+	d := &KeyDirectory{
+		baseEndpoint: strings.TrimSuffix(baseEndpoint, "/"),
+		ttl:          defaultKeyTTL,
+		keys:         map[string]cachedKey{},
+	}
+
+	for _, fn := range opts {
+		fn(d)
+	}
+
+	return d
+}
+
+// Get returns the public key of the log with the given alias.
+func (d *KeyDirectory) Get(ctx context.Context, alias string) ([]byte, error) {
+	d.mu.Lock()
+	cached, ok := d.keys[alias]
+	d.mu.Unlock()
+
+	if ok && time.Now().Before(cached.expires) {
+		return cached.pubKey, nil
+	}
+
+	pubKey, err := d.resolve(ctx, alias)
+	if err != nil {
+		return nil, fmt.Errorf("resolve public key for %q: %w", alias, err)
+	}
+
+	d.mu.Lock()
+	d.keys[alias] = cachedKey{pubKey: pubKey, expires: time.Now().Add(d.ttl)}
+	d.mu.Unlock()
+
+	return pubKey, nil
+}
+
+// Invalidate removes the cached public key of the log with the given alias.
+func (d *KeyDirectory) Invalidate(alias string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.keys, alias)
+}
+
+func (d *KeyDirectory) resolve(ctx context.Context, alias string) ([]byte, error) {
+	client := New(d.baseEndpoint+"/"+alias, d.clientOpts...)
+
+	pubKey, err := client.GetPublicKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sth, err := client.GetSTH(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if sth == nil {
+		return nil, fmt.Errorf("get STH: %w", &DecodeError{Field: "body", Err: errors.New("empty response")})
+	}
+
+	if err = verifySTHSignature(sth, pubKey); err != nil {
+		return nil, fmt.Errorf("verify STH signature: %w", err)
+	}
+
+	return pubKey, nil
+}
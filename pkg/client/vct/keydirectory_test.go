@@ -0,0 +1,123 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+)
+
+type countingHTTPClient struct {
+	requests int32
+}
+
+func (c *countingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&c.requests, 1)
+
+	return http.DefaultClient.Do(req)
+}
+
+func (c *countingHTTPClient) count() int {
+	return int(atomic.LoadInt32(&c.requests))
+}
+
+func TestKeyDirectory_Get(t *testing.T) {
+	log := newFakeLog(t)
+	log.addLeaf(newLeafEntry(t, fakeLogTimestamp, "vc").LeafInput)
+
+	t.Run("Cached within TTL", func(t *testing.T) {
+		httpClient := &countingHTTPClient{}
+
+		directory := vct.NewKeyDirectory(log.server.URL,
+			vct.WithKeyDirectoryClientOpts(vct.WithHTTPClient(httpClient)),
+		)
+
+		pubKey, err := directory.Get(context.Background(), fakeLogAlias)
+		require.NoError(t, err)
+		require.Equal(t, log.pubKey, pubKey)
+
+		requests := httpClient.count()
+		require.NotZero(t, requests)
+
+		pubKey, err = directory.Get(context.Background(), fakeLogAlias)
+		require.NoError(t, err)
+		require.Equal(t, log.pubKey, pubKey)
+		require.Equal(t, requests, httpClient.count())
+	})
+
+	t.Run("Invalidate", func(t *testing.T) {
+		httpClient := &countingHTTPClient{}
+
+		directory := vct.NewKeyDirectory(log.server.URL+"/",
+			vct.WithKeyDirectoryClientOpts(vct.WithHTTPClient(httpClient)),
+		)
+
+		_, err := directory.Get(context.Background(), fakeLogAlias)
+		require.NoError(t, err)
+
+		requests := httpClient.count()
+
+		directory.Invalidate(fakeLogAlias)
+
+		pubKey, err := directory.Get(context.Background(), fakeLogAlias)
+		require.NoError(t, err)
+		require.Equal(t, log.pubKey, pubKey)
+		require.Equal(t, 2*requests, httpClient.count())
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		httpClient := &countingHTTPClient{}
+
+		directory := vct.NewKeyDirectory(log.server.URL,
+			vct.WithKeyTTL(time.Nanosecond),
+			vct.WithKeyDirectoryClientOpts(vct.WithHTTPClient(httpClient)),
+		)
+
+		_, err := directory.Get(context.Background(), fakeLogAlias)
+		require.NoError(t, err)
+
+		requests := httpClient.count()
+
+		time.Sleep(time.Millisecond)
+
+		_, err = directory.Get(context.Background(), fakeLogAlias)
+		require.NoError(t, err)
+		require.Equal(t, 2*requests, httpClient.count())
+	})
+
+	t.Run("Unknown alias", func(t *testing.T) {
+		_, err := vct.NewKeyDirectory(log.server.URL).Get(context.Background(), "unknown")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `resolve public key for "unknown"`)
+	})
+
+	t.Run("Empty STH", func(t *testing.T) {
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		gomock.InOrder(
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(http.DefaultClient.Do),
+			httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`null`)),
+				StatusCode: http.StatusOK,
+			}, nil),
+		)
+
+		_, err := vct.NewKeyDirectory(log.server.URL,
+			vct.WithKeyDirectoryClientOpts(vct.WithHTTPClient(httpClient)),
+		).Get(context.Background(), fakeLogAlias)
+		require.EqualError(t, err, `resolve public key for "`+fakeLogAlias+`": get STH: decode body: empty response`)
+	})
+}
@@ -0,0 +1,97 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/vdr/fingerprint"
+	jsonld "github.com/piprate/json-gold/ld"
+)
+
+const didKeyPrefix = "did:key:"
+
+// KeyResolver resolves the identifier of a log public key, e.g. a DID, to the public key in the format expected
+// by VerifyVCTimestampSignature, see WithKeyResolver.
+type KeyResolver interface {
+	ResolveKey(ctx context.Context, id string) ([]byte, error)
+}
+
+// DIDKeyResolver resolves did:key DIDs, with or without a key fragment, to Ed25519 public keys and to DER encoded
+// ECDSA P-256, P-384 and P-521 public keys. It does not need the network.
+type DIDKeyResolver struct{}
+
+// ResolveKey returns the public key of the did:key DID.
+func (DIDKeyResolver) ResolveKey(_ context.Context, did string) ([]byte, error) {
+	if !strings.HasPrefix(did, didKeyPrefix) {
+		return nil, fmt.Errorf("%q is not a did:key DID", did)
+	}
+
+	methodID := strings.TrimPrefix(did, didKeyPrefix)
+
+	if i := strings.IndexByte(methodID, '#'); i >= 0 {
+		methodID = methodID[:i]
+	}
+
+	pubKey, code, err := fingerprint.PubKeyFromFingerprint(methodID)
+	if err != nil {
+		return nil, fmt.Errorf("decode did:key: %w", err)
+	}
+
+	switch code {
+	case fingerprint.ED25519PubKeyMultiCodec:
+		return pubKey, nil
+	case fingerprint.P256PubKeyMultiCodec:
+		return ecdsaPublicKeyDER(elliptic.P256(), pubKey)
+	case fingerprint.P384PubKeyMultiCodec:
+		return ecdsaPublicKeyDER(elliptic.P384(), pubKey)
+	case fingerprint.P521PubKeyMultiCodec:
+		return ecdsaPublicKeyDER(elliptic.P521(), pubKey)
+	default:
+		return nil, fmt.Errorf("unsupported did:key multicodec 0x%x", code)
+	}
+}
+
+// ecdsaPublicKeyDER returns the DER encoding of the compressed or uncompressed public key point.
+func ecdsaPublicKeyDER(curve elliptic.Curve, point []byte) ([]byte, error) {
+	var x, y *big.Int
+
+	if len(point) == (curve.Params().BitSize+7)/8+1 {
+		x, y = elliptic.UnmarshalCompressed(curve, point)
+	} else {
+		x, y = elliptic.Unmarshal(curve, point) // nolint: staticcheck
+	}
+
+	if x == nil {
+		return nil, errors.New("invalid public key point")
+	}
+
+	return x509.MarshalPKIXPublicKey(&ecdsa.PublicKey{Curve: curve, X: x, Y: y}) // nolint: wrapcheck
+}
+
+// VerifyVCTimestampSignatureWithDID verifies VC timestamp signature like VerifyVCTimestampSignature, against the
+// public key the DID of the log resolves to. If the resolver is nil, DIDKeyResolver is used.
+func VerifyVCTimestampSignatureWithDID(ctx context.Context, signature []byte, did string, resolver KeyResolver,
+	timestamp uint64, vcBytes []byte, loader jsonld.DocumentLoader) error {
+	if resolver == nil {
+		resolver = DIDKeyResolver{}
+	}
+
+	pubKey, err := resolver.ResolveKey(ctx, did)
+	if err != nil {
+		return fmt.Errorf("resolve key: %w", err)
+	}
+
+	return VerifyVCTimestampSignature(signature, pubKey, timestamp, vcBytes, loader)
+}
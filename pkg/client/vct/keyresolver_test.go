@@ -0,0 +1,145 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	didkey "github.com/hyperledger/aries-framework-go/pkg/vdr/fingerprint"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/controller/command"
+	"github.com/trustbloc/vct/pkg/testutil"
+)
+
+func TestDIDKeyResolver(t *testing.T) {
+	resolver := vct.DIDKeyResolver{}
+
+	t.Run("Ed25519", func(t *testing.T) {
+		pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		did, keyID := didkey.CreateDIDKeyByCode(didkey.ED25519PubKeyMultiCodec, pubKey)
+
+		resolved, err := resolver.ResolveKey(context.Background(), did)
+		require.NoError(t, err)
+		require.Equal(t, []byte(pubKey), resolved)
+
+		resolved, err = resolver.ResolveKey(context.Background(), keyID)
+		require.NoError(t, err)
+		require.Equal(t, []byte(pubKey), resolved)
+	})
+
+	t.Run("P-256", func(t *testing.T) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		expected, err := x509.MarshalPKIXPublicKey(key.Public())
+		require.NoError(t, err)
+
+		did, _ := didkey.CreateDIDKeyByCode(didkey.P256PubKeyMultiCodec,
+			elliptic.MarshalCompressed(elliptic.P256(), key.X, key.Y))
+
+		resolved, err := resolver.ResolveKey(context.Background(), did)
+		require.NoError(t, err)
+		require.Equal(t, expected, resolved)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := resolver.ResolveKey(context.Background(), "did:example:123")
+		require.EqualError(t, err, `"did:example:123" is not a did:key DID`)
+
+		did, _ := didkey.CreateDIDKeyByCode(didkey.X25519PubKeyMultiCodec, make([]byte, 32))
+
+		_, err = resolver.ResolveKey(context.Background(), did)
+		require.EqualError(t, err, "unsupported did:key multicodec 0xec")
+
+		did, _ = didkey.CreateDIDKeyByCode(didkey.P256PubKeyMultiCodec, []byte{2, 1, 2, 3})
+
+		_, err = resolver.ResolveKey(context.Background(), did)
+		require.EqualError(t, err, "invalid public key point")
+
+		_, err = resolver.ResolveKey(context.Background(), "did:key:abc")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decode did:key")
+	})
+}
+
+func TestVerifyVCTimestampSignatureWithDID(t *testing.T) {
+	log := newFakeLog(t)
+
+	resp, err := log.client().AddVC(context.Background(), vcBachelorDegree)
+	require.NoError(t, err)
+
+	did, _ := didkey.CreateDIDKeyByCode(didkey.P256PubKeyMultiCodec,
+		elliptic.MarshalCompressed(elliptic.P256(), log.key.X, log.key.Y))
+
+	require.NoError(t, vct.VerifyVCTimestampSignatureWithDID(context.Background(), resp.Signature, did, nil,
+		resp.Timestamp, vcBachelorDegree, testutil.GetLoader(t)))
+
+	other := newFakeLog(t)
+	otherDID, _ := didkey.CreateDIDKeyByCode(didkey.P256PubKeyMultiCodec,
+		elliptic.MarshalCompressed(elliptic.P256(), other.key.X, other.key.Y))
+
+	require.Error(t, vct.VerifyVCTimestampSignatureWithDID(context.Background(), resp.Signature, otherDID, nil,
+		resp.Timestamp, vcBachelorDegree, testutil.GetLoader(t)))
+
+	err = vct.VerifyVCTimestampSignatureWithDID(context.Background(), resp.Signature, "did:web:example.com", nil,
+		resp.Timestamp, vcBachelorDegree, testutil.GetLoader(t))
+	require.EqualError(t, err, `resolve key: "did:web:example.com" is not a did:key DID`)
+}
+
+func TestClient_WithKeyResolver(t *testing.T) {
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	did, _ := didkey.CreateDIDKeyByCode(didkey.ED25519PubKeyMultiCodec, pubKey)
+
+	webfinger, err := json.Marshal(command.WebFingerResponse{
+		Properties: map[string]interface{}{command.PublicKeyType: did},
+	})
+	require.NoError(t, err)
+
+	respond := func(t *testing.T) *MockHTTPClient {
+		t.Helper()
+
+		ctrl := gomock.NewController(t)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBuffer(webfinger)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		return httpClient
+	}
+
+	t.Run("DID", func(t *testing.T) {
+		resolved, err := vct.New(endpoint, vct.WithHTTPClient(respond(t)), vct.WithKeyResolver(vct.DIDKeyResolver{})).
+			GetPublicKey(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []byte(pubKey), resolved)
+	})
+
+	t.Run("No resolver", func(t *testing.T) {
+		_, err := vct.New(endpoint, vct.WithHTTPClient(respond(t))).GetPublicKey(context.Background())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get public key: decode public key")
+	})
+}
@@ -0,0 +1,35 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+// Logger is the logging backend of the client, e.g. an adapter of the structured logger of the caller.
+// Logger must be safe for concurrent use.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// WithLogger sets the logger of the client. The requests sent to the log and their responses are logged at debug
+// level, and retried attempts and insecure settings at warning level. By default, nothing is logged.
+func WithLogger(l Logger) ClientOpt {
+	return func(o *Client) {
+		if l == nil {
+			l = noopLogger{}
+		}
+
+		o.logger = l
+	}
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Debugf(string, ...interface{}) {}
+
+func (noopLogger) Warnf(string, ...interface{}) {}
+
+func (noopLogger) Errorf(string, ...interface{}) {}
@@ -0,0 +1,105 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+)
+
+type recordingLogger struct {
+	mu     sync.Mutex
+	debugs []string
+	warns  []string
+	errors []string
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.debugs = append(l.debugs, fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Warnf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.warns = append(l.warns, fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Errorf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.errors = append(l.errors, fmt.Sprintf(format, args...))
+}
+
+func TestWithLogger(t *testing.T) {
+	t.Run("Requests", func(t *testing.T) {
+		log := newFakeLog(t)
+		logger := &recordingLogger{}
+
+		client := log.client(vct.WithLogger(logger), vct.WithRequestIDFromContext(func(ctx context.Context) string {
+			id, _ := ctx.Value(requestIDKey{}).(string)
+
+			return id
+		}))
+
+		_, err := client.GetSTH(context.WithValue(context.Background(), requestIDKey{}, "req-1"))
+		require.NoError(t, err)
+
+		_, err = client.GetEntries(context.Background(), 5, 6)
+		require.Error(t, err)
+
+		require.Len(t, logger.debugs, 2)
+		require.Contains(t, logger.debugs[0], "GetSTH: GET ")
+		require.Contains(t, logger.debugs[0], "responded with status 200 (request ID req-1)")
+		require.Contains(t, logger.debugs[1], "GetEntries: GET ")
+		require.NotContains(t, logger.debugs[1], "request ID")
+		require.Empty(t, logger.warns)
+	})
+
+	t.Run("Retries", func(t *testing.T) {
+		log := newFakeLog(t)
+		log.server.Close()
+
+		logger := &recordingLogger{}
+
+		_, err := log.client(vct.WithLogger(logger), vct.WithRetry(2, time.Millisecond),
+			vct.WithTimeout(time.Nanosecond)).GetSTH(context.Background())
+		require.Error(t, err)
+
+		require.Len(t, logger.debugs, 2)
+		require.Contains(t, logger.debugs[0], "GetSTH: GET ")
+		require.Contains(t, logger.debugs[0], "failed")
+		require.Len(t, logger.warns, 1)
+		require.Contains(t, logger.warns[0], "GetSTH: attempt 1 failed, retrying in")
+	})
+
+	t.Run("Insecure skip verify", func(t *testing.T) {
+		logger := &recordingLogger{}
+
+		vct.New(endpoint, vct.WithLogger(logger), vct.WithInsecureSkipVerify())
+
+		require.Equal(t, []string{
+			"TLS certificate verification of the log " + endpoint + " is disabled, do not use in production",
+		}, logger.warns)
+	})
+
+	t.Run("Nil logger", func(t *testing.T) {
+		_, err := newSampledLog(t, 1).client(vct.WithLogger(nil)).GetSTH(context.Background())
+		require.NoError(t, err)
+	})
+}
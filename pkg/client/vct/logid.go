@@ -0,0 +1,44 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+)
+
+// LogID returns the ID of the log with the given public key, which is the ID of the SCTs returned by the log,
+// e.g. AddVCResponse.ID. As in Certificate Transparency, the ID is the SHA-256 hash of the public key: a DER
+// encoded SubjectPublicKeyInfo, as advertised by logs with ECDSA keys. Logs with ED25519 keys advertise the raw
+// public key, which is hashed as is so that the ID matches the ID of their SCTs.
+func LogID(pubKey []byte) ([sha256.Size]byte, error) {
+	if err := checkPublicKey(pubKey); err != nil {
+		return [sha256.Size]byte{}, fmt.Errorf("log ID: %w", err)
+	}
+
+	return sha256.Sum256(pubKey), nil
+}
+
+func checkPublicKey(pubKey []byte) error {
+	if len(pubKey) == ed25519.PublicKeySize {
+		return nil
+	}
+
+	_, err := x509.ParsePKIXPublicKey(pubKey)
+	if err == nil {
+		return nil
+	}
+
+	// x509 does not support the secp256k1 curve.
+	if _, secp256k1Err := parseSecp256k1PublicKey(pubKey); secp256k1Err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("invalid public key: %w", err)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+)
+
+func TestLogID(t *testing.T) {
+	t.Run("ECDSA", func(t *testing.T) {
+		log := newFakeLog(t)
+
+		id, err := vct.LogID(log.pubKey)
+		require.NoError(t, err)
+		require.Equal(t, sha256.Sum256(log.pubKey), id)
+
+		// the public key the log advertises
+		pubKey, err := log.client().GetPublicKey(context.Background())
+		require.NoError(t, err)
+
+		advertisedID, err := vct.LogID(pubKey)
+		require.NoError(t, err)
+		require.Equal(t, id, advertisedID)
+	})
+
+	t.Run("ED25519", func(t *testing.T) {
+		pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		id, err := vct.LogID(pubKey)
+		require.NoError(t, err)
+		require.Equal(t, sha256.Sum256(pubKey), id)
+	})
+
+	t.Run("secp256k1", func(t *testing.T) {
+		pubKey, err := base64.StdEncoding.DecodeString(secp256k1PubKey)
+		require.NoError(t, err)
+
+		id, err := vct.LogID(pubKey)
+		require.NoError(t, err)
+		require.Equal(t, sha256.Sum256(pubKey), id)
+	})
+
+	t.Run("Invalid public key", func(t *testing.T) {
+		_, err := vct.LogID([]byte("public key"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "log ID: invalid public key")
+
+		_, err = vct.LogID(nil)
+		require.Error(t, err)
+	})
+}
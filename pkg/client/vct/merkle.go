@@ -0,0 +1,302 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/google/trillian/merkle/rfc6962/hasher"
+
+	"github.com/trustbloc/vct/pkg/canonicalizer"
+)
+
+// LeafHasher calculates the Merkle leaf hash of leaf data, i.e. of the JCS (RFC 8785) canonical JSON of a
+// MerkleTreeLeaf, the way the log is configured to, see WithLeafHasher.
+type LeafHasher interface {
+	HashLeaf(leafData []byte) []byte
+}
+
+// RFC6962LeafHasher is the default LeafHasher, which matches the VCT server: the leaf hash is the SHA-256 hash
+// of the byte 0x00, the RFC 6962 domain separation prefix of leaves, followed by the leaf data. The resulting
+// hash is 32 bytes long.
+type RFC6962LeafHasher struct{}
+
+// HashLeaf returns SHA-256(0x00 || leafData).
+func (RFC6962LeafHasher) HashLeaf(leafData []byte) []byte {
+	return hasher.DefaultHasher.HashLeaf(leafData)
+}
+
+// ProofVerifierOpt represents ProofVerifier option func.
+type ProofVerifierOpt func(*ProofVerifier)
+
+// WithTrillianProofEncoding adapts leaf hash calculation and proof verification to the conventions of
+// a Trillian-backed log (the VCT server is a Trillian personality). The differences handled are:
+//
+//   - Trillian computes the Merkle leaf hash over the stored LeafValue bytes as they are, so the leaf hash is
+//     calculated over the leaf input verbatim. By default, the leaf input is decoded and re-encoded in its
+//     canonical (JCS) form first, which tolerates re-encoding by intermediaries but fails for leaves that
+//     were not stored canonically.
+//   - Trillian leaf indices and tree sizes are signed 64-bit integers, so larger values are rejected.
+//   - Trillian also stores a leaf identity hash (the SHA-256 hash of the canonical VC entry) used for
+//     de-duplication. It is not part of the Merkle tree and is never used in place of the leaf hash.
+//
+// Both modes use the RFC 6962 hasher: SHA-256 with 0x00 and 0x01 prefixes for leaves and nodes.
+func WithTrillianProofEncoding() ProofVerifierOpt {
+	return func(v *ProofVerifier) {
+		v.trillian = true
+	}
+}
+
+// WithProofLeafHasher sets the hasher of Merkle leaves, for logs that are configured with another leaf encoding
+// than the VCT server, see WithLeafHasher. Nodes are still hashed with the RFC 6962 hasher, so the leaf hash must
+// be 32 bytes long. By default, RFC6962LeafHasher is used.
+func WithProofLeafHasher(leafHasher LeafHasher) ProofVerifierOpt {
+	return func(v *ProofVerifier) {
+		v.leafHasher = leafHasher
+	}
+}
+
+// ProofVerifier verifies Merkle proofs returned by the log.
+type ProofVerifier struct {
+	trillian   bool
+	leafHasher LeafHasher
+}
+
+// NewProofVerifier returns proof verifier.
+func NewProofVerifier(opts ...ProofVerifierOpt) *ProofVerifier {
+	v := &ProofVerifier{leafHasher: RFC6962LeafHasher{}}
+
+	for _, fn := range opts {
+		fn(v)
+	}
+
+	return v
+}
+
+// LeafHash returns the Merkle leaf hash of the given leaf input.
+func (v *ProofVerifier) LeafHash(leafInput []byte) ([]byte, error) {
+	if v.trillian {
+		return v.leafHasher.HashLeaf(leafInput), nil
+	}
+
+	leaf, err := decodeLeaf(leafInput)
+	if err != nil {
+		return nil, err
+	}
+
+	leafData, err := canonicalizer.MarshalCanonical(leaf)
+	if err != nil {
+		return nil, fmt.Errorf("marshal leaf: %w", err)
+	}
+
+	return v.leafHasher.HashLeaf(leafData), nil
+}
+
+// VerifyInclusion verifies that the leaf input is included at the given index of the tree with the given
+// size and root hash.
+func (v *ProofVerifier) VerifyInclusion(leafInput []byte, leafIndex, treeSize uint64, auditPath [][]byte,
+	rootHash []byte) error {
+	leafHash, err := v.inclusionLeafHash(leafInput, leafIndex, treeSize)
+	if err != nil {
+		return err
+	}
+
+	return VerifyInclusionProof(leafHash, leafIndex, treeSize, auditPath, rootHash)
+}
+
+func (v *ProofVerifier) inclusionLeafHash(leafInput []byte, leafIndex, treeSize uint64) ([]byte, error) {
+	if v.trillian && (leafIndex > math.MaxInt64 || treeSize > math.MaxInt64) {
+		return nil, fmt.Errorf("leaf index %d or tree size %d exceeds the maximum supported by Trillian",
+			leafIndex, treeSize)
+	}
+
+	leafHash, err := v.LeafHash(leafInput)
+	if err != nil {
+		return nil, fmt.Errorf("leaf hash: %w", err)
+	}
+
+	return leafHash, nil
+}
+
+// VerifyInclusionProof verifies the RFC 6962 inclusion proof of the leaf with the given Merkle leaf hash,
+// e.g. the audit path returned by GetProofByHash, against the root hash of the tree with the given size.
+// Nodes are hashed with the RFC 6962 domain separation: 0x00 prefix for leaves and 0x01 prefix for nodes.
+// In a tree with a single entry, the audit path is empty and the leaf hash is the root hash.
+func VerifyInclusionProof(leafHash []byte, leafIndex, treeSize uint64, auditPath [][]byte, rootHash []byte) error {
+	calculated, err := rootFromInclusionProof(leafHash, leafIndex, treeSize, auditPath)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(calculated, rootHash) {
+		return fmt.Errorf("calculated root %x does not match expected root %x", calculated, rootHash)
+	}
+
+	return nil
+}
+
+// VerifyConsistencyProof verifies the RFC 6962 consistency proof between the tree with the first size and
+// root hash and the tree with the second size and root hash, e.g. the proof returned by GetSTHConsistency.
+// It returns an error unless the first tree is a prefix of the second. An empty tree is consistent with any
+// tree, and a tree is consistent with itself; in both cases the proof must be empty.
+func VerifyConsistencyProof(first, second uint64, firstRoot, secondRoot []byte, proof [][]byte) error {
+	switch {
+	case first > second:
+		return fmt.Errorf("first tree size %d is greater than second tree size %d", first, second)
+	case first == second:
+		if len(proof) != 0 {
+			return fmt.Errorf("consistency proof has %d hashes, expected none for trees of the same size", len(proof))
+		}
+
+		if !bytes.Equal(firstRoot, secondRoot) {
+			return fmt.Errorf("first root %x does not match second root %x of the same tree size", firstRoot, secondRoot)
+		}
+
+		return nil
+	case first == 0:
+		if len(proof) != 0 {
+			return fmt.Errorf("consistency proof has %d hashes, expected none for an empty first tree", len(proof))
+		}
+
+		return nil
+	case len(proof) == 0:
+		return errors.New("consistency proof is empty")
+	}
+
+	for i, hash := range proof {
+		if len(hash) != sha256.Size {
+			return fmt.Errorf("consistency proof hash %d has %d bytes, expected %d", i, len(hash), sha256.Size)
+		}
+	}
+
+	return verifyConsistency(first, second, firstRoot, secondRoot, proof)
+}
+
+// verifyConsistency implements the consistency proof verification of RFC 9162, section 2.1.4.2.
+func verifyConsistency(first, second uint64, firstRoot, secondRoot []byte, proof [][]byte) error {
+	// If the first tree is a perfect subtree of the second one, its root is the first node of the proof.
+	if first&(first-1) == 0 {
+		proof = append([][]byte{firstRoot}, proof...)
+	}
+
+	fn, sn := first-1, second-1
+
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+
+	fr, sr := proof[0], proof[0]
+
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return fmt.Errorf("consistency proof has too many hashes for tree sizes %d and %d", first, second)
+		}
+
+		if fn&1 == 1 || fn == sn {
+			fr = hasher.DefaultHasher.HashChildren(c, fr)
+			sr = hasher.DefaultHasher.HashChildren(c, sr)
+
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = hasher.DefaultHasher.HashChildren(sr, c)
+		}
+
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if sn != 0 {
+		return fmt.Errorf("consistency proof has too few hashes for tree sizes %d and %d", first, second)
+	}
+
+	if !bytes.Equal(fr, firstRoot) {
+		return fmt.Errorf("calculated first root %x does not match expected root %x", fr, firstRoot)
+	}
+
+	if !bytes.Equal(sr, secondRoot) {
+		return fmt.Errorf("calculated second root %x does not match expected root %x", sr, secondRoot)
+	}
+
+	return nil
+}
+
+// rootFromInclusionProof calculates the root hash of the tree from the leaf hash and its audit path
+// (RFC 9162, section 2.1.3.2).
+func rootFromInclusionProof(leafHash []byte, leafIndex, treeSize uint64, auditPath [][]byte) ([]byte, error) {
+	inner, err := checkInclusionProof(leafHash, leafIndex, treeSize, auditPath)
+	if err != nil {
+		return nil, err
+	}
+
+	res := chainInner(leafHash, auditPath[:inner], leafIndex)
+
+	return chainBorderRight(res, auditPath[inner:]), nil
+}
+
+// checkInclusionProof checks the shape of the inclusion proof and returns the length of its inner part.
+func checkInclusionProof(leafHash []byte, leafIndex, treeSize uint64, auditPath [][]byte) (int, error) {
+	if leafIndex >= treeSize {
+		return 0, fmt.Errorf("leaf index %d is out of range for tree size %d", leafIndex, treeSize)
+	}
+
+	if len(leafHash) != sha256.Size {
+		return 0, fmt.Errorf("leaf hash has %d bytes, expected %d", len(leafHash), sha256.Size)
+	}
+
+	inner, border := decompInclusionProof(leafIndex, treeSize)
+
+	if len(auditPath) != inner+border {
+		return 0, fmt.Errorf("audit path has %d hashes, expected %d", len(auditPath), inner+border)
+	}
+
+	for i, hash := range auditPath {
+		if len(hash) != sha256.Size {
+			return 0, fmt.Errorf("audit path hash %d has %d bytes, expected %d", i, len(hash), sha256.Size)
+		}
+	}
+
+	return inner, nil
+}
+
+// decompInclusionProof breaks the audit path down into the inner part, which is the path within the
+// perfect subtree containing the leaf, and the border part, which consists of left siblings on the right
+// border of the tree.
+func decompInclusionProof(leafIndex, treeSize uint64) (int, int) {
+	inner := bits.Len64(leafIndex ^ (treeSize - 1))
+	border := bits.OnesCount64(leafIndex >> uint(inner))
+
+	return inner, border
+}
+
+func chainInner(seed []byte, proof [][]byte, leafIndex uint64) []byte {
+	for i, hash := range proof {
+		if (leafIndex>>uint(i))&1 == 0 {
+			seed = hasher.DefaultHasher.HashChildren(seed, hash)
+		} else {
+			seed = hasher.DefaultHasher.HashChildren(hash, seed)
+		}
+	}
+
+	return seed
+}
+
+func chainBorderRight(seed []byte, proof [][]byte) []byte {
+	for _, hash := range proof {
+		seed = hasher.DefaultHasher.HashChildren(hash, seed)
+	}
+
+	return seed
+}
@@ -0,0 +1,304 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	_ "embed"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/controller/command"
+)
+
+// trillianProofs contains a tree head and get-entry-and-proof responses in the format returned by a
+// Trillian-backed log. The root hash and the audit paths were produced with the Merkle tree code of Trillian
+// (github.com/transparency-dev/merkle v0.0.2): the root with the compact range Trillian integrates leaves with,
+// and the audit paths with its inclusion proof code, over the leaf values as stored. The tree head is not
+// signed. The leaf at index 3 is stored as non-canonical JSON.
+//
+//go:embed testdata/trillian_proofs.json
+var trillianProofs []byte // nolint: gochecknoglobals
+
+const nonCanonicalLeafIndex = 3
+
+type proofFixture struct {
+	LeafIndex uint64 `json:"leaf_index"`
+	command.GetEntryAndProofResponse
+}
+
+func loadTrillianProofs(t *testing.T) (*command.GetSTHResponse, []proofFixture) {
+	t.Helper()
+
+	var fixtures struct {
+		STH    *command.GetSTHResponse `json:"sth"`
+		Proofs []proofFixture          `json:"proofs"`
+	}
+
+	require.NoError(t, json.Unmarshal(trillianProofs, &fixtures))
+
+	return fixtures.STH, fixtures.Proofs
+}
+
+func TestProofVerifier_VerifyInclusion(t *testing.T) {
+	sth, proofs := loadTrillianProofs(t)
+
+	t.Run("Success (Trillian proof encoding)", func(t *testing.T) {
+		verifier := vct.NewProofVerifier(vct.WithTrillianProofEncoding())
+
+		for _, p := range proofs {
+			require.NoError(t, verifier.VerifyInclusion(p.LeafInput, p.LeafIndex, sth.TreeSize, p.AuditPath,
+				sth.SHA256RootHash))
+		}
+	})
+
+	t.Run("Success (canonical leaves)", func(t *testing.T) {
+		verifier := vct.NewProofVerifier()
+
+		for _, p := range proofs {
+			err := verifier.VerifyInclusion(p.LeafInput, p.LeafIndex, sth.TreeSize, p.AuditPath, sth.SHA256RootHash)
+
+			if p.LeafIndex == nonCanonicalLeafIndex {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "does not match expected root")
+
+				continue
+			}
+
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("Leaf index out of range", func(t *testing.T) {
+		p := proofs[0]
+
+		err := vct.NewProofVerifier().VerifyInclusion(p.LeafInput, sth.TreeSize, sth.TreeSize, p.AuditPath,
+			sth.SHA256RootHash)
+		require.EqualError(t, err, "leaf index 7 is out of range for tree size 7")
+	})
+
+	t.Run("Leaf index exceeds Trillian maximum", func(t *testing.T) {
+		p := proofs[0]
+
+		err := vct.NewProofVerifier(vct.WithTrillianProofEncoding()).VerifyInclusion(p.LeafInput,
+			math.MaxInt64+1, math.MaxUint64, p.AuditPath, sth.SHA256RootHash)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "exceeds the maximum supported by Trillian")
+	})
+
+	t.Run("Wrong audit path length", func(t *testing.T) {
+		p := proofs[0]
+
+		err := vct.NewProofVerifier().VerifyInclusion(p.LeafInput, p.LeafIndex, sth.TreeSize, p.AuditPath[1:],
+			sth.SHA256RootHash)
+		require.EqualError(t, err, "audit path has 2 hashes, expected 3")
+	})
+
+	t.Run("Wrong audit path hash size", func(t *testing.T) {
+		p := proofs[0]
+
+		err := vct.NewProofVerifier().VerifyInclusion(p.LeafInput, p.LeafIndex, sth.TreeSize,
+			[][]byte{p.AuditPath[0], {0x01}, p.AuditPath[2]}, sth.SHA256RootHash)
+		require.EqualError(t, err, "audit path hash 1 has 1 bytes, expected 32")
+	})
+
+	t.Run("Wrong leaf index", func(t *testing.T) {
+		p := proofs[0]
+
+		err := vct.NewProofVerifier().VerifyInclusion(p.LeafInput, 1, sth.TreeSize, p.AuditPath,
+			sth.SHA256RootHash)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match expected root")
+	})
+
+	t.Run("Tampered leaf", func(t *testing.T) {
+		p := proofs[1]
+
+		err := vct.NewProofVerifier(vct.WithTrillianProofEncoding()).VerifyInclusion(proofs[0].LeafInput,
+			p.LeafIndex, sth.TreeSize, p.AuditPath, sth.SHA256RootHash)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match expected root")
+	})
+
+	t.Run("Malformed leaf", func(t *testing.T) {
+		p := proofs[0]
+
+		err := vct.NewProofVerifier().VerifyInclusion([]byte(`leaf`), p.LeafIndex, sth.TreeSize, p.AuditPath,
+			sth.SHA256RootHash)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "leaf hash: unmarshal leaf")
+	})
+}
+
+func TestProofVerifier_LeafHash(t *testing.T) {
+	_, proofs := loadTrillianProofs(t)
+
+	canonical := proofs[nonCanonicalLeafIndex-1].LeafInput
+	nonCanonical := proofs[nonCanonicalLeafIndex].LeafInput
+
+	t.Run("Canonical leaf", func(t *testing.T) {
+		expected, err := vct.NewProofVerifier().LeafHash(canonical)
+		require.NoError(t, err)
+
+		actual, err := vct.NewProofVerifier(vct.WithTrillianProofEncoding()).LeafHash(canonical)
+		require.NoError(t, err)
+
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("Non-canonical leaf", func(t *testing.T) {
+		expected, err := vct.NewProofVerifier().LeafHash(nonCanonical)
+		require.NoError(t, err)
+
+		actual, err := vct.NewProofVerifier(vct.WithTrillianProofEncoding()).LeafHash(nonCanonical)
+		require.NoError(t, err)
+
+		require.NotEqual(t, expected, actual)
+	})
+}
+
+func TestVerifyInclusionProof(t *testing.T) {
+	t.Run("Every leaf of every tree size", func(t *testing.T) {
+		for size := 1; size <= 17; size++ {
+			tree := newTestTree(size)
+
+			for i, leaf := range tree.leaves {
+				require.NoError(t, vct.VerifyInclusionProof(rfc6962LeafHash(leaf), uint64(i), uint64(size),
+					tree.auditPaths[i], tree.root), "size %d, leaf %d", size, i)
+			}
+		}
+	})
+
+	t.Run("Single entry tree", func(t *testing.T) {
+		leafHash := rfc6962LeafHash([]byte("leaf"))
+
+		require.NoError(t, vct.VerifyInclusionProof(leafHash, 0, 1, nil, leafHash))
+
+		err := vct.VerifyInclusionProof(leafHash, 0, 1, nil, rfc6962LeafHash([]byte("other")))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match expected root")
+
+		require.EqualError(t, vct.VerifyInclusionProof(leafHash, 0, 1, [][]byte{leafHash}, leafHash),
+			"audit path has 1 hashes, expected 0")
+	})
+
+	t.Run("Rightmost leaf", func(t *testing.T) {
+		tree := newTestTree(7)
+		leafHash := rfc6962LeafHash(tree.leaves[6])
+
+		require.Len(t, tree.auditPaths[6], 2)
+		require.NoError(t, vct.VerifyInclusionProof(leafHash, 6, 7, tree.auditPaths[6], tree.root))
+
+		err := vct.VerifyInclusionProof(leafHash, 6, 7, [][]byte{tree.auditPaths[6][1], tree.auditPaths[6][0]},
+			tree.root)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match expected root")
+	})
+
+	t.Run("Root mismatch", func(t *testing.T) {
+		tree := newTestTree(5)
+
+		err := vct.VerifyInclusionProof(rfc6962LeafHash(tree.leaves[1]), 1, 5, tree.auditPaths[1],
+			newTestTree(6).root)
+		require.Error(t, err)
+		require.Regexp(t, "^calculated root [0-9a-f]{64} does not match expected root [0-9a-f]{64}$", err.Error())
+	})
+
+	t.Run("Invalid leaf hash", func(t *testing.T) {
+		tree := newTestTree(5)
+
+		require.EqualError(t, vct.VerifyInclusionProof([]byte("leaf"), 1, 5, tree.auditPaths[1], tree.root),
+			"leaf hash has 4 bytes, expected 32")
+	})
+}
+
+func TestVerifyConsistencyProof(t *testing.T) {
+	hashes := newTestTree(20).leafHashes()
+
+	root := func(size uint64) []byte {
+		return rfc6962Root(hashes[:size])
+	}
+
+	proof := func(first, second uint64) [][]byte {
+		return rfc6962ConsistencyProof(first, hashes[:second])
+	}
+
+	t.Run("Every pair of tree sizes", func(t *testing.T) {
+		for second := uint64(1); second <= uint64(len(hashes)); second++ {
+			for first := uint64(1); first < second; first++ {
+				require.NoError(t, vct.VerifyConsistencyProof(first, second, root(first), root(second),
+					proof(first, second)), "first %d, second %d", first, second)
+			}
+		}
+	})
+
+	t.Run("First is a power of two", func(t *testing.T) {
+		for _, first := range []uint64{1, 2, 4, 8, 16} {
+			p := proof(first, 20)
+
+			require.NoError(t, vct.VerifyConsistencyProof(first, 20, root(first), root(20), p))
+
+			err := vct.VerifyConsistencyProof(first, 20, root(first+1), root(20), p)
+			require.Error(t, err, "first %d", first)
+		}
+	})
+
+	t.Run("Empty first tree", func(t *testing.T) {
+		require.NoError(t, vct.VerifyConsistencyProof(0, 5, nil, root(5), nil))
+		require.EqualError(t, vct.VerifyConsistencyProof(0, 5, nil, root(5), [][]byte{root(5)}),
+			"consistency proof has 1 hashes, expected none for an empty first tree")
+	})
+
+	t.Run("Same tree size", func(t *testing.T) {
+		require.NoError(t, vct.VerifyConsistencyProof(5, 5, root(5), root(5), nil))
+
+		err := vct.VerifyConsistencyProof(5, 5, root(5), root(6), nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "of the same tree size")
+
+		require.EqualError(t, vct.VerifyConsistencyProof(5, 5, root(5), root(5), [][]byte{root(5)}),
+			"consistency proof has 1 hashes, expected none for trees of the same size")
+	})
+
+	t.Run("First tree is not a prefix", func(t *testing.T) {
+		forged := newTestTree(7).leafHashes()
+		forged[2] = rfc6962LeafHash([]byte("forged"))
+
+		err := vct.VerifyConsistencyProof(3, 7, rfc6962Root(forged[:3]), root(7), proof(3, 7))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "calculated first root")
+
+		err = vct.VerifyConsistencyProof(3, 7, rfc6962Root(forged[:3]), rfc6962Root(forged),
+			rfc6962ConsistencyProof(3, forged))
+		require.NoError(t, err)
+
+		err = vct.VerifyConsistencyProof(3, 7, root(3), rfc6962Root(forged), proof(3, 7))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "calculated second root")
+	})
+
+	t.Run("Wrong proof length", func(t *testing.T) {
+		p := proof(6, 13)
+
+		require.EqualError(t, vct.VerifyConsistencyProof(6, 13, root(6), root(13), p[:len(p)-1]),
+			"consistency proof has too few hashes for tree sizes 6 and 13")
+		require.EqualError(t, vct.VerifyConsistencyProof(6, 13, root(6), root(13), append(p, root(1))),
+			"consistency proof has too many hashes for tree sizes 6 and 13")
+		require.EqualError(t, vct.VerifyConsistencyProof(6, 13, root(6), root(13), nil),
+			"consistency proof is empty")
+	})
+
+	t.Run("Invalid arguments", func(t *testing.T) {
+		require.EqualError(t, vct.VerifyConsistencyProof(13, 6, root(13), root(6), proof(6, 13)),
+			"first tree size 13 is greater than second tree size 6")
+		require.EqualError(t, vct.VerifyConsistencyProof(6, 13, root(6), root(13), [][]byte{{0x01}}),
+			"consistency proof hash 0 has 1 bytes, expected 32")
+	})
+}
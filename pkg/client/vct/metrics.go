@@ -0,0 +1,76 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"time"
+
+	"github.com/trustbloc/vct/pkg/controller/rest"
+)
+
+// MetricsRecorder records metrics of the requests the client sends to the log.
+type MetricsRecorder interface {
+	// ObserveRequest is called after every HTTP round trip with the name of the client method that sent the
+	// request, e.g. AddVC or GetSTH, the HTTP status code, which is zero if no response was received, and the
+	// duration of the round trip. Retried requests are observed once per attempt.
+	ObserveRequest(method string, statusCode int, d time.Duration)
+}
+
+// WithMetrics sets the recorder of request metrics. By default, no metrics are recorded.
+func WithMetrics(r MetricsRecorder) ClientOpt {
+	return func(o *Client) {
+		if r == nil {
+			r = noopMetricsRecorder{}
+		}
+
+		o.metrics = r
+	}
+}
+
+type noopMetricsRecorder struct{}
+
+func (noopMetricsRecorder) ObserveRequest(string, int, time.Duration) {}
+
+// operationName returns the name of the client method that sends requests to the given path.
+func operationName(path string) string {
+	switch path {
+	case rest.AddVCPath:
+		return "AddVC"
+	case rest.AddVPPath:
+		return "AddVP"
+	case rest.AddVCBatchPath:
+		return "AddVCBatch"
+	case rest.ValidateVCPath:
+		return "ValidateVC"
+	case rest.GetSTHPath:
+		return "GetSTH"
+	case rest.GetSTHConsistencyPath:
+		return "GetSTHConsistency"
+	case rest.GetProofByHashPath:
+		return "GetProofByHash"
+	case rest.GetEntriesPath:
+		return "GetEntries"
+	case rest.GetIssuersPath:
+		return "GetIssuers"
+	case rest.GetRootsPath:
+		return "GetRoots"
+	case rest.GetAcceptedContextsPath:
+		return "GetAcceptedContexts"
+	case rest.GetLogInfoPath:
+		return "GetLogInfo"
+	case rest.GetEntryAndProofPath:
+		return "GetEntryAndProof"
+	case rest.WebfingerPath:
+		return "Webfinger"
+	case rest.HealthCheckPath:
+		return "HealthCheck"
+	case rest.LogHealthCheckPath:
+		return "HealthStatus"
+	default:
+		return path
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+)
+
+type observation struct {
+	method     string
+	statusCode int
+}
+
+type recordingMetrics struct {
+	mu           sync.Mutex
+	observations []observation
+}
+
+func (m *recordingMetrics) ObserveRequest(method string, statusCode int, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.observations = append(m.observations, observation{method: method, statusCode: statusCode})
+}
+
+func TestWithMetrics(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		log := newFakeLog(t)
+		metrics := &recordingMetrics{}
+
+		client := log.client(vct.WithMetrics(metrics))
+
+		_, err := client.AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+
+		_, err = client.GetSTH(context.Background())
+		require.NoError(t, err)
+
+		_, err = client.GetEntries(context.Background(), 5, 6)
+		require.Error(t, err)
+
+		_, err = client.Webfinger(context.Background())
+		require.NoError(t, err)
+
+		require.Equal(t, []observation{
+			{method: "AddVC", statusCode: http.StatusOK},
+			{method: "GetSTH", statusCode: http.StatusOK},
+			{method: "GetEntries", statusCode: http.StatusBadRequest},
+			{method: "Webfinger", statusCode: http.StatusOK},
+		}, metrics.observations)
+	})
+
+	t.Run("No response", func(t *testing.T) {
+		metrics := &recordingMetrics{}
+
+		_, err := vct.New("http://127.0.0.1:0/maple2020", vct.WithMetrics(metrics)).GetSTH(context.Background())
+		require.Error(t, err)
+
+		require.Equal(t, []observation{{method: "GetSTH"}}, metrics.observations)
+	})
+
+	t.Run("Nil recorder", func(t *testing.T) {
+		_, err := newFakeLog(t).client(vct.WithMetrics(nil)).GetSTH(context.Background())
+		require.NoError(t, err)
+	})
+}
+
+// prometheusMetrics records the request metrics of the client with Prometheus.
+type prometheusMetrics struct {
+	latency *prometheus.HistogramVec
+}
+
+func (m *prometheusMetrics) ObserveRequest(method string, statusCode int, d time.Duration) {
+	m.latency.WithLabelValues(method, strconv.Itoa(statusCode)).Observe(d.Seconds())
+}
+
+func ExampleWithMetrics() {
+	metrics := &prometheusMetrics{
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "vct_client_request_duration_seconds",
+			Help: "Latency of the requests sent to the VCT log.",
+		}, []string{"method", "code"}),
+	}
+
+	prometheus.MustRegister(metrics.latency)
+
+	client := vct.New("https://vct.example.com/maple2020", vct.WithMetrics(metrics))
+
+	_, _ = client.GetSTH(context.Background())
+}
@@ -0,0 +1,432 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/trustbloc/vct/pkg/canonicalizer"
+	"github.com/trustbloc/vct/pkg/controller/command"
+)
+
+// defaultMonitorInterval is the interval of Run if a non-positive one is given.
+const defaultMonitorInterval = time.Minute
+
+// MonitorEventType is the type of a MonitorEvent.
+type MonitorEventType string
+
+const (
+	// NewSTH is emitted when the log publishes a signed tree head of a larger tree that is consistent with the last
+	// verified one. The first signed tree head seen by a monitor without a stored one is trusted on first use.
+	NewSTH MonitorEventType = "new_sth"
+	// ConsistencyFailure is emitted when a signed tree head is not consistent with the last verified one, e.g. the
+	// log presents a split view.
+	ConsistencyFailure MonitorEventType = "consistency_failure"
+	// SignatureFailure is emitted when the signature of a signed tree head does not verify with the log public key.
+	SignatureFailure MonitorEventType = "signature_failure"
+	// EntryFailure is emitted when an entry served by the log while catching up is not included at its position
+	// in the tree of the new signed tree head, see WithEntryHandler.
+	EntryFailure MonitorEventType = "entry_failure"
+)
+
+// MonitorEvent is an event emitted by a Monitor.
+type MonitorEvent struct {
+	Type MonitorEventType
+	// STH is the signed tree head published by the log.
+	STH *command.GetSTHResponse
+	// Previous is the last verified signed tree head, if any.
+	Previous *command.GetSTHResponse
+	// Err is the verification error of a failure event.
+	Err error
+	// LeafIndex is the index of the entry of an EntryFailure.
+	LeafIndex uint64
+}
+
+// MonitorEntry is a new entry of the log, passed to the entry handler of a Monitor.
+type MonitorEntry struct {
+	// Index is the leaf index of the entry, at which its inclusion in the tree of STH is verified.
+	Index uint64
+	// Entry is the entry served by the log.
+	Entry command.LeafEntry
+	// STH is the signed tree head the entry is verified against.
+	STH *command.GetSTHResponse
+}
+
+// CheckpointStore persists the catch-up progress of a Monitor, so that a monitor restarted in the middle of a
+// catch-up resumes after the last entry handled instead of handling the new entries again. CheckpointStore must
+// be safe for concurrent use.
+type CheckpointStore interface {
+	// Get returns the leaf index of the next entry to handle, or 0 if none was stored yet.
+	Get(ctx context.Context) (uint64, error)
+	// Put stores the leaf index of the next entry to handle.
+	Put(ctx context.Context, next uint64) error
+}
+
+// STHStore persists the last verified signed tree head of a Monitor, so that a restarted monitor resumes from it
+// instead of trusting the signed tree head the log publishes next. STHStore must be safe for concurrent use.
+type STHStore interface {
+	// Get returns the stored signed tree head, or nil if none was stored yet.
+	Get(ctx context.Context) (*command.GetSTHResponse, error)
+	// Put stores the signed tree head.
+	Put(ctx context.Context, sth *command.GetSTHResponse) error
+}
+
+// MonitorOption represents Monitor option func.
+type MonitorOption func(*Monitor)
+
+// WithMonitorPublicKey sets the log public key the signed tree heads are verified with. By default, the public
+// key the log advertises is retrieved once.
+func WithMonitorPublicKey(pubKey []byte) MonitorOption {
+	return func(m *Monitor) {
+		m.pubKey = pubKey
+	}
+}
+
+// WithSTHStore sets the store of the last verified signed tree head. By default, it is kept in memory only.
+func WithSTHStore(store STHStore) MonitorOption {
+	return func(m *Monitor) {
+		m.store = store
+	}
+}
+
+// WithEntryHandler makes the monitor catch up with the new entries of the log: once a signed tree head of a larger
+// tree is verified to be consistent with the last verified one, the entries between the two tree sizes are
+// retrieved with GetEntries, the inclusion of every entry at its position in the new tree is verified with an
+// inclusion proof, and the handler is invoked with each of them in order. The new signed tree head replaces the
+// last verified one only once every new entry is handled, so that the monitor follows the tail of the log
+// without missing an entry. The entries of the first signed tree head trusted on first use are not handled.
+//
+// An entry that fails verification is reported with an EntryFailure event and stops the catch-up; so does an
+// error of the handler, which is returned by Check. The progress is stored in the checkpoint store after every
+// handled entry, see WithCheckpointStore, and the next check resumes from it. An entry may be handled again if
+// the monitor stops between handling it and storing the progress, so the handler should be idempotent.
+//
+// One inclusion proof is retrieved per entry, so catching up with many entries takes as many requests.
+func WithEntryHandler(handler func(ctx context.Context, entry MonitorEntry) error) MonitorOption {
+	return func(m *Monitor) {
+		m.onEntry = handler
+	}
+}
+
+// WithCheckpointStore sets the store of the catch-up progress, see WithEntryHandler. It must be persisted along
+// with the STH store. By default, it is kept in memory only.
+func WithCheckpointStore(store CheckpointStore) MonitorOption {
+	return func(m *Monitor) {
+		m.checkpoints = store
+	}
+}
+
+// WithMonitorErrorHandler sets the callback invoked when a round of Run could not be completed, e.g. the log is
+// unreachable.
+func WithMonitorErrorHandler(handler func(error)) MonitorOption {
+	return func(m *Monitor) {
+		m.onError = handler
+	}
+}
+
+// Monitor watches the signed tree heads published by the log and verifies that every signed tree head is
+// consistent with the last verified one, so that a log presenting a split view or rewriting its history is
+// detected.
+type Monitor struct {
+	client      *Client
+	pubKey      []byte
+	store       STHStore
+	checkpoints CheckpointStore
+	onEntry     func(ctx context.Context, entry MonitorEntry) error
+	onError     func(error)
+}
+
+// NewMonitor returns a monitor of the log of the given client.
+func NewMonitor(client *Client, opts ...MonitorOption) *Monitor {
+	m := &Monitor{
+		client:      client,
+		store:       &memorySTHStore{},
+		checkpoints: &memoryCheckpointStore{},
+		onError:     func(error) {},
+	}
+
+	for _, fn := range opts {
+		fn(m)
+	}
+
+	return m
+}
+
+// Run checks the signed tree head of the log on every interval until the context is done, and invokes the
+// handler with every event. A non-positive interval is replaced with one minute.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration, handler func(MonitorEvent)) {
+	if interval <= 0 {
+		interval = defaultMonitorInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		event, err := m.Check(ctx)
+		if err != nil {
+			m.onError(err)
+		} else if event != nil {
+			handler(*event)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check retrieves the latest signed tree head and verifies its signature and its consistency with the last
+// verified signed tree head, which it replaces if the tree has grown. It returns the resulting event, or nil if
+// the log published no new tree. An error is returned if the check could not be completed.
+//
+// A signed tree head of a smaller tree, e.g. served by a lagging replica of the log, is verified to be
+// consistent with the last verified one but does not replace it. With WithEntryHandler, the new entries of a
+// larger tree are verified and handled before its signed tree head replaces the last verified one.
+func (m *Monitor) Check(ctx context.Context) (*MonitorEvent, error) {
+	pubKey, err := m.publicKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("monitor: %w", err)
+	}
+
+	sth, err := m.client.GetSTH(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("monitor: %w", err)
+	}
+
+	if sth == nil {
+		return nil, fmt.Errorf("monitor: get STH: %w", &DecodeError{Field: "body", Err: errors.New("empty response")})
+	}
+
+	last, err := m.store.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("monitor: get stored STH: %w", err)
+	}
+
+	if err = verifySTHSignature(sth, pubKey); err != nil {
+		return &MonitorEvent{
+			Type:     SignatureFailure,
+			STH:      sth,
+			Previous: last,
+			Err:      &VerificationError{Check: CheckSTHSignature, Err: err},
+		}, nil
+	}
+
+	if last != nil {
+		first, second := last, sth
+		if first.TreeSize > second.TreeSize {
+			first, second = second, first
+		}
+
+		proof, proofErr := m.consistencyProof(ctx, first.TreeSize, second.TreeSize)
+		if proofErr != nil {
+			return nil, fmt.Errorf("monitor: %w", proofErr)
+		}
+
+		err = VerifyConsistencyProof(first.TreeSize, second.TreeSize, first.SHA256RootHash, second.SHA256RootHash,
+			proof)
+		if err != nil {
+			return &MonitorEvent{
+				Type:     ConsistencyFailure,
+				STH:      sth,
+				Previous: last,
+				Err:      &VerificationError{Check: CheckConsistency, Err: err},
+			}, nil
+		}
+
+		if sth.TreeSize <= last.TreeSize {
+			return nil, nil
+		}
+
+		if m.onEntry != nil {
+			if event, catchUpErr := m.catchUp(ctx, last, sth); event != nil || catchUpErr != nil {
+				return event, catchUpErr
+			}
+		}
+	}
+
+	if err = m.store.Put(ctx, sth); err != nil {
+		return nil, fmt.Errorf("monitor: store STH: %w", err)
+	}
+
+	return &MonitorEvent{Type: NewSTH, STH: sth, Previous: last}, nil
+}
+
+// consistencyProof retrieves the consistency proof between the trees of the given sizes. No proof is needed if
+// the first tree is empty or the trees have the same size.
+func (m *Monitor) consistencyProof(ctx context.Context, first, second uint64) ([][]byte, error) {
+	if first == 0 || first == second {
+		return nil, nil
+	}
+
+	resp, err := m.client.GetSTHConsistency(ctx, first, second)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp == nil {
+		return nil, fmt.Errorf("get STH consistency: %w", &DecodeError{Field: "body", Err: errors.New("empty response")})
+	}
+
+	return resp.Consistency, nil
+}
+
+// catchUp verifies and handles the entries of the tree of the new signed tree head that are not in the tree of
+// the last verified one, from the stored checkpoint. It returns an EntryFailure event if an entry fails
+// verification.
+func (m *Monitor) catchUp(ctx context.Context, last, sth *command.GetSTHResponse) (*MonitorEvent, error) {
+	next, err := m.checkpoints.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("monitor: get checkpoint: %w", err)
+	}
+
+	// The checkpoint is behind the last verified tree if the last catch-up was completed.
+	if next < last.TreeSize {
+		next = last.TreeSize
+	}
+
+	for next < sth.TreeSize {
+		var resp *command.GetEntriesResponse
+
+		resp, err = m.client.GetEntries(ctx, next, sth.TreeSize-1)
+		if err != nil {
+			return nil, fmt.Errorf("monitor: %w", err)
+		}
+
+		if resp == nil || len(resp.Entries) == 0 {
+			return nil, fmt.Errorf("monitor: no entries returned for range [%d, %d]", next, sth.TreeSize-1)
+		}
+
+		for _, entry := range resp.Entries {
+			if next == sth.TreeSize {
+				break
+			}
+
+			if err = m.verifyEntry(ctx, entry, next, sth); err != nil {
+				var verificationErr *VerificationError
+				if errors.As(err, &verificationErr) {
+					return &MonitorEvent{Type: EntryFailure, STH: sth, Previous: last, Err: err, LeafIndex: next}, nil
+				}
+
+				return nil, fmt.Errorf("monitor: %w", err)
+			}
+
+			if err = m.onEntry(ctx, MonitorEntry{Index: next, Entry: entry, STH: sth}); err != nil {
+				return nil, fmt.Errorf("monitor: entry handler: %w", err)
+			}
+
+			next++
+
+			if err = m.checkpoints.Put(ctx, next); err != nil {
+				return nil, fmt.Errorf("monitor: store checkpoint: %w", err)
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// verifyEntry verifies that the entry is included at the given index of the tree of the signed tree head. A
+// VerificationError is returned if it is not.
+func (m *Monitor) verifyEntry(ctx context.Context, entry command.LeafEntry, index uint64,
+	sth *command.GetSTHResponse) error {
+	leaf, err := decodeLeaf(entry.LeafInput)
+	if err != nil {
+		return &VerificationError{Check: CheckInclusion, Err: err}
+	}
+
+	leafData, err := canonicalizer.MarshalCanonical(leaf)
+	if err != nil {
+		return &VerificationError{Check: CheckInclusion, Err: fmt.Errorf("marshal leaf: %w", err)}
+	}
+
+	leafHash := m.client.leafHasher.HashLeaf(leafData)
+
+	resp, err := m.client.GetProofByHash(ctx, base64.StdEncoding.EncodeToString(leafHash), sth.TreeSize)
+	if errors.Is(err, ErrNotFound) {
+		return &VerificationError{Check: CheckInclusion, Err: fmt.Errorf("entry %d is not in the tree", index)}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if resp == nil {
+		return fmt.Errorf("get proof by hash: %w", &DecodeError{Field: "body", Err: errors.New("empty response")})
+	}
+
+	if resp.LeafIndex < 0 || uint64(resp.LeafIndex) != index {
+		return &VerificationError{
+			Check: CheckInclusion,
+			Err:   fmt.Errorf("entry %d is included at index %d", index, resp.LeafIndex),
+		}
+	}
+
+	err = VerifyInclusionProof(leafHash, index, sth.TreeSize, resp.AuditPath, sth.SHA256RootHash)
+	if err != nil {
+		return &VerificationError{Check: CheckInclusion, Err: err}
+	}
+
+	return nil
+}
+
+func (m *Monitor) publicKey(ctx context.Context) ([]byte, error) {
+	if m.pubKey != nil {
+		return m.pubKey, nil
+	}
+
+	return m.client.PublicKey(ctx)
+}
+
+type memorySTHStore struct {
+	mu  sync.Mutex
+	sth *command.GetSTHResponse
+}
+
+func (s *memorySTHStore) Get(context.Context) (*command.GetSTHResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sth, nil
+}
+
+func (s *memorySTHStore) Put(_ context.Context, sth *command.GetSTHResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sth = sth
+
+	return nil
+}
+
+type memoryCheckpointStore struct {
+	mu   sync.Mutex
+	next uint64
+}
+
+func (s *memoryCheckpointStore) Get(context.Context) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.next, nil
+}
+
+func (s *memoryCheckpointStore) Put(_ context.Context, next uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.next = next
+
+	return nil
+}
@@ -0,0 +1,448 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/controller/command"
+)
+
+// sthStore is an STHStore that counts the stored signed tree heads.
+type sthStore struct {
+	sth    *command.GetSTHResponse
+	puts   int
+	getErr error
+}
+
+func (s *sthStore) Get(context.Context) (*command.GetSTHResponse, error) {
+	return s.sth, s.getErr
+}
+
+func (s *sthStore) Put(_ context.Context, sth *command.GetSTHResponse) error {
+	s.sth = sth
+	s.puts++
+
+	return nil
+}
+
+func TestMonitor_Check(t *testing.T) {
+	t.Run("New STH", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+		store := &sthStore{}
+
+		monitor := vct.NewMonitor(log.client(), vct.WithSTHStore(store))
+
+		event, err := monitor.Check(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, vct.NewSTH, event.Type)
+		require.Equal(t, uint64(3), event.STH.TreeSize)
+		require.Nil(t, event.Previous)
+		require.Equal(t, event.STH, store.sth)
+
+		// the tree has not grown
+		event, err = monitor.Check(context.Background())
+		require.NoError(t, err)
+		require.Nil(t, event)
+
+		log.addLeaf(newLeafEntry(t, fakeLogTimestamp+3, "vc-3").LeafInput)
+		log.addLeaf(newLeafEntry(t, fakeLogTimestamp+4, "vc-4").LeafInput)
+
+		event, err = monitor.Check(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, vct.NewSTH, event.Type)
+		require.Equal(t, uint64(5), event.STH.TreeSize)
+		require.Equal(t, uint64(3), event.Previous.TreeSize)
+		require.Equal(t, 2, store.puts)
+	})
+
+	t.Run("Empty tree", func(t *testing.T) {
+		log := newFakeLog(t)
+		monitor := vct.NewMonitor(log.client(), vct.WithMonitorPublicKey(log.pubKey))
+
+		event, err := monitor.Check(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), event.STH.TreeSize)
+
+		log.addLeaf(newLeafEntry(t, fakeLogTimestamp, "vc-0").LeafInput)
+
+		event, err = monitor.Check(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, vct.NewSTH, event.Type)
+		require.Equal(t, uint64(1), event.STH.TreeSize)
+	})
+
+	t.Run("Split view", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+		store := &sthStore{}
+
+		monitor := vct.NewMonitor(log.client(), vct.WithSTHStore(store))
+
+		_, err := monitor.Check(context.Background())
+		require.NoError(t, err)
+
+		forgedRoot := sha256.Sum256([]byte("forged"))
+
+		log.addLeaf(newLeafEntry(t, fakeLogTimestamp+3, "vc-3").LeafInput)
+		log.forgeRoot(forgedRoot[:])
+
+		event, err := monitor.Check(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, vct.ConsistencyFailure, event.Type)
+		require.Equal(t, uint64(4), event.STH.TreeSize)
+		require.Equal(t, uint64(3), event.Previous.TreeSize)
+
+		var verificationErr *vct.VerificationError
+		require.True(t, errors.As(event.Err, &verificationErr))
+		require.Equal(t, vct.CheckConsistency, verificationErr.Check)
+
+		// the inconsistent STH is not stored
+		require.Equal(t, uint64(3), store.sth.TreeSize)
+		require.Equal(t, 1, store.puts)
+	})
+
+	t.Run("Same tree size with different root", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+		monitor := vct.NewMonitor(log.client())
+
+		_, err := monitor.Check(context.Background())
+		require.NoError(t, err)
+
+		forgedRoot := sha256.Sum256([]byte("forged"))
+		log.forgeRoot(forgedRoot[:])
+
+		event, err := monitor.Check(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, vct.ConsistencyFailure, event.Type)
+	})
+
+	t.Run("Resume from store", func(t *testing.T) {
+		log := newSampledLog(t, 5)
+
+		// the leaves of the smaller log are the first leaves of the log
+		sth, err := newSampledLog(t, 3).client().GetSTH(context.Background())
+		require.NoError(t, err)
+
+		store := &sthStore{sth: sth}
+
+		event, err := vct.NewMonitor(log.client(), vct.WithSTHStore(store)).Check(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, vct.NewSTH, event.Type)
+		require.Equal(t, sth, event.Previous)
+		require.Equal(t, uint64(5), store.sth.TreeSize)
+
+		// a fork of the log
+		forked := newFakeLog(t)
+		for i := 0; i < 5; i++ {
+			forked.addLeaf(newLeafEntry(t, uint64(fakeLogTimestamp+i), fmt.Sprintf("forked-%d", i)).LeafInput)
+		}
+
+		event, err = vct.NewMonitor(forked.client(), vct.WithSTHStore(store)).Check(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, vct.ConsistencyFailure, event.Type)
+	})
+
+	t.Run("Lagging replica", func(t *testing.T) {
+		log := newSampledLog(t, 5)
+		store := &sthStore{}
+
+		monitor := vct.NewMonitor(log.client(), vct.WithSTHStore(store))
+
+		_, err := monitor.Check(context.Background())
+		require.NoError(t, err)
+
+		log.lag(3)
+
+		event, err := monitor.Check(context.Background())
+		require.NoError(t, err)
+		require.Nil(t, event)
+		require.Equal(t, uint64(5), store.sth.TreeSize)
+
+		forgedRoot := sha256.Sum256([]byte("forged"))
+		log.forgeRoot(forgedRoot[:])
+
+		event, err = monitor.Check(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, vct.ConsistencyFailure, event.Type)
+		require.Equal(t, uint64(3), event.STH.TreeSize)
+	})
+
+	t.Run("Signature failure", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+		store := &sthStore{}
+
+		event, err := vct.NewMonitor(log.client(), vct.WithSTHStore(store),
+			vct.WithMonitorPublicKey(newFakeLog(t).pubKey)).Check(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, vct.SignatureFailure, event.Type)
+
+		var verificationErr *vct.VerificationError
+		require.True(t, errors.As(event.Err, &verificationErr))
+		require.Equal(t, vct.CheckSTHSignature, verificationErr.Check)
+		require.Nil(t, store.sth)
+	})
+
+	t.Run("Store error", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+
+		_, err := vct.NewMonitor(log.client(), vct.WithSTHStore(&sthStore{getErr: errors.New("unavailable")})).
+			Check(context.Background())
+		require.EqualError(t, err, "monitor: get stored STH: unavailable")
+	})
+
+	t.Run("Log unreachable", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+		log.server.Close()
+
+		_, err := vct.NewMonitor(log.client(), vct.WithMonitorPublicKey(log.pubKey)).Check(context.Background())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "monitor: get STH")
+	})
+}
+
+// checkpointStore is a CheckpointStore that can fail.
+type checkpointStore struct {
+	next   uint64
+	putErr error
+}
+
+func (s *checkpointStore) Get(context.Context) (uint64, error) {
+	return s.next, nil
+}
+
+func (s *checkpointStore) Put(_ context.Context, next uint64) error {
+	if s.putErr != nil {
+		return s.putErr
+	}
+
+	s.next = next
+
+	return nil
+}
+
+func TestMonitor_CatchUp(t *testing.T) {
+	// handler returns an entry handler that records the handled entries and fails for the given indexes.
+	handler := func(handled *[]vct.MonitorEntry, failing ...uint64) func(context.Context, vct.MonitorEntry) error {
+		return func(_ context.Context, entry vct.MonitorEntry) error {
+			for _, index := range failing {
+				if entry.Index == index {
+					return fmt.Errorf("handle entry %d", index)
+				}
+			}
+
+			*handled = append(*handled, entry)
+
+			return nil
+		}
+	}
+
+	indexes := func(entries []vct.MonitorEntry) []uint64 {
+		result := make([]uint64, len(entries))
+		for i, entry := range entries {
+			result[i] = entry.Index
+		}
+
+		return result
+	}
+
+	t.Run("New entries", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+		store := &sthStore{}
+		checkpoints := &checkpointStore{}
+
+		var handled []vct.MonitorEntry
+
+		monitor := vct.NewMonitor(log.client(), vct.WithSTHStore(store), vct.WithCheckpointStore(checkpoints),
+			vct.WithEntryHandler(handler(&handled)))
+
+		// the entries of the tree trusted on first use are not handled
+		event, err := monitor.Check(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, vct.NewSTH, event.Type)
+		require.Empty(t, handled)
+
+		for i := 3; i < 6; i++ {
+			log.addLeaf(newLeafEntry(t, uint64(fakeLogTimestamp+i), fmt.Sprintf("vc-%d", i)).LeafInput)
+		}
+
+		event, err = monitor.Check(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, vct.NewSTH, event.Type)
+		require.Equal(t, uint64(6), store.sth.TreeSize)
+		require.Equal(t, []uint64{3, 4, 5}, indexes(handled))
+		require.Equal(t, uint64(6), checkpoints.next)
+
+		for _, entry := range handled {
+			require.Equal(t, log.leaves[entry.Index], entry.Entry.LeafInput)
+			require.Equal(t, event.STH, entry.STH)
+		}
+
+		// the tree has not grown
+		event, err = monitor.Check(context.Background())
+		require.NoError(t, err)
+		require.Nil(t, event)
+		require.Len(t, handled, 3)
+	})
+
+	t.Run("Resume after handler error", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+		store := &sthStore{}
+		checkpoints := &checkpointStore{}
+
+		var handled []vct.MonitorEntry
+
+		_, err := vct.NewMonitor(log.client(), vct.WithSTHStore(store)).Check(context.Background())
+		require.NoError(t, err)
+
+		for i := 3; i < 6; i++ {
+			log.addLeaf(newLeafEntry(t, uint64(fakeLogTimestamp+i), fmt.Sprintf("vc-%d", i)).LeafInput)
+		}
+
+		_, err = vct.NewMonitor(log.client(), vct.WithSTHStore(store), vct.WithCheckpointStore(checkpoints),
+			vct.WithEntryHandler(handler(&handled, 4))).Check(context.Background())
+		require.EqualError(t, err, "monitor: entry handler: handle entry 4")
+		require.Equal(t, []uint64{3}, indexes(handled))
+		require.Equal(t, uint64(4), checkpoints.next)
+
+		// the new STH is not stored until every new entry is handled
+		require.Equal(t, uint64(3), store.sth.TreeSize)
+
+		// a restarted monitor resumes from the checkpoint, up to the latest tree
+		log.addLeaf(newLeafEntry(t, fakeLogTimestamp+6, "vc-6").LeafInput)
+
+		event, err := vct.NewMonitor(log.client(), vct.WithSTHStore(store), vct.WithCheckpointStore(checkpoints),
+			vct.WithEntryHandler(handler(&handled))).Check(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, vct.NewSTH, event.Type)
+		require.Equal(t, []uint64{3, 4, 5, 6}, indexes(handled))
+		require.Equal(t, uint64(7), store.sth.TreeSize)
+	})
+
+	t.Run("Corrupted entry", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+		store := &sthStore{}
+
+		var handled []vct.MonitorEntry
+
+		monitor := vct.NewMonitor(log.client(), vct.WithSTHStore(store), vct.WithEntryHandler(handler(&handled)))
+
+		_, err := monitor.Check(context.Background())
+		require.NoError(t, err)
+
+		log.addLeaf(newLeafEntry(t, fakeLogTimestamp+3, "vc-3").LeafInput)
+		log.addLeaf(newLeafEntry(t, fakeLogTimestamp+4, "vc-4").LeafInput)
+		log.corrupt(4, newLeafEntry(t, fakeLogTimestamp+4, "forged").LeafInput)
+
+		event, err := monitor.Check(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, vct.EntryFailure, event.Type)
+		require.Equal(t, uint64(4), event.LeafIndex)
+		require.Equal(t, uint64(5), event.STH.TreeSize)
+
+		var verificationErr *vct.VerificationError
+		require.True(t, errors.As(event.Err, &verificationErr))
+		require.Equal(t, vct.CheckInclusion, verificationErr.Check)
+
+		require.Equal(t, []uint64{3}, indexes(handled))
+		require.Equal(t, uint64(3), store.sth.TreeSize)
+	})
+
+	t.Run("Checkpoint store error", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+
+		var handled []vct.MonitorEntry
+
+		monitor := vct.NewMonitor(log.client(), vct.WithEntryHandler(handler(&handled)),
+			vct.WithCheckpointStore(&checkpointStore{putErr: errors.New("unavailable")}))
+
+		_, err := monitor.Check(context.Background())
+		require.NoError(t, err)
+
+		log.addLeaf(newLeafEntry(t, fakeLogTimestamp+3, "vc-3").LeafInput)
+
+		_, err = monitor.Check(context.Background())
+		require.EqualError(t, err, "monitor: store checkpoint: unavailable")
+	})
+}
+
+func TestMonitor_Run(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+
+		events := make(chan vct.MonitorEvent, 100)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		done := make(chan struct{})
+
+		go func() {
+			vct.NewMonitor(log.client()).Run(ctx, time.Millisecond, func(event vct.MonitorEvent) {
+				events <- event
+			})
+			close(done)
+		}()
+
+		next := func() vct.MonitorEvent {
+			select {
+			case event := <-events:
+				return event
+			case <-ctx.Done():
+				t.Fatal("no event")
+			}
+
+			return vct.MonitorEvent{}
+		}
+
+		require.Equal(t, uint64(3), next().STH.TreeSize)
+
+		log.addLeaf(newLeafEntry(t, fakeLogTimestamp+3, "vc-3").LeafInput)
+
+		event := next()
+		require.Equal(t, vct.NewSTH, event.Type)
+		require.Equal(t, uint64(4), event.STH.TreeSize)
+
+		cancel()
+		<-done
+	})
+
+	t.Run("Non-positive interval", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+
+		events := make(chan vct.MonitorEvent, 1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		done := make(chan struct{})
+
+		go func() {
+			vct.NewMonitor(log.client()).Run(ctx, 0, func(event vct.MonitorEvent) {
+				events <- event
+			})
+			close(done)
+		}()
+
+		select {
+		case event := <-events:
+			require.Equal(t, uint64(3), event.STH.TreeSize)
+		case <-ctx.Done():
+			t.Fatal("no event")
+		}
+
+		cancel()
+		<-done
+	})
+}
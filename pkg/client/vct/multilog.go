@@ -0,0 +1,69 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/trustbloc/vct/pkg/controller/command"
+)
+
+// MultiResult is the result of adding a credential to one of the logs of a MultiLog.
+type MultiResult struct {
+	// Endpoint is the endpoint of the log.
+	Endpoint string
+	// Response is the response of the log, which holds its signed timestamp. It is nil if Err is set.
+	Response *command.AddVCResponse
+	// Err is the error of the log, e.g. the log is unreachable.
+	Err error
+}
+
+// MultiLog adds credentials to several logs, e.g. independent logs a credential is submitted to for redundancy.
+type MultiLog struct {
+	clients []*Client
+}
+
+// NewMultiLog returns a MultiLog of the logs of the given clients.
+func NewMultiLog(clients ...*Client) *MultiLog {
+	return &MultiLog{clients: clients}
+}
+
+// AddVC adds the credential to every log concurrently and returns once all of them responded. The results are
+// aligned by index with the clients; a log that fails does not fail the call, its error is returned in its
+// result instead. An error is returned together with the results only if the context is done before every log
+// responded.
+func (m *MultiLog) AddVC(ctx context.Context, credential []byte) ([]MultiResult, error) {
+	results := make([]MultiResult, len(m.clients))
+
+	var wg sync.WaitGroup
+
+	for i, client := range m.clients {
+		wg.Add(1)
+
+		go func(i int, client *Client) {
+			defer wg.Done()
+
+			resp, err := client.AddVC(ctx, credential)
+
+			results[i] = MultiResult{Endpoint: client.endpoint, Response: resp, Err: err}
+		}(i, client)
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		for _, result := range results {
+			if result.Err != nil {
+				return results, fmt.Errorf("multi log: add VC: %w", err)
+			}
+		}
+	}
+
+	return results, nil
+}
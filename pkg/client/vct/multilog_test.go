@@ -0,0 +1,127 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+)
+
+func TestMultiLog_AddVC(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		logs := []*fakeLog{newFakeLog(t), newFakeLog(t), newFakeLog(t)}
+
+		results, err := vct.NewMultiLog(logs[0].client(), logs[1].client(), logs[2].client()).
+			AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+
+		for i, result := range results {
+			require.NoError(t, result.Err)
+			require.Equal(t, logs[i].endpoint(), result.Endpoint)
+			require.NotEmpty(t, result.Response.Signature)
+		}
+	})
+
+	t.Run("One log down", func(t *testing.T) {
+		up, down := newFakeLog(t), newFakeLog(t)
+		down.server.Close()
+
+		results, err := vct.NewMultiLog(down.client(), up.client()).AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+
+		require.Error(t, results[0].Err)
+		require.Nil(t, results[0].Response)
+		require.Equal(t, down.endpoint(), results[0].Endpoint)
+
+		require.NoError(t, results[1].Err)
+		require.NotNil(t, results[1].Response)
+	})
+
+	t.Run("Submitted concurrently", func(t *testing.T) {
+		reached, release := make(chan struct{}), make(chan struct{})
+
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+
+			_, _ = w.Write([]byte(`{"timestamp":1}`)) // nolint: errcheck
+		}))
+		defer slow.Close()
+
+		fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(reached)
+
+			_, _ = w.Write([]byte(`{"timestamp":2}`)) // nolint: errcheck
+		}))
+		defer fast.Close()
+
+		done := make(chan struct{})
+
+		var (
+			results []vct.MultiResult
+			err     error
+		)
+
+		go func() {
+			defer close(done)
+
+			results, err = vct.NewMultiLog(vct.New(slow.URL+"/maple2020"), vct.New(fast.URL+"/maple2020")).
+				AddVC(context.Background(), vcBachelorDegree)
+		}()
+
+		// the credential reaches the fast log while the slow log has not responded yet
+		select {
+		case <-reached:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the credential did not reach the fast log")
+		}
+
+		close(release)
+		<-done
+
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), results[0].Response.Timestamp)
+		require.Equal(t, uint64(2), results[1].Response.Timestamp)
+	})
+
+	t.Run("Context canceled", func(t *testing.T) {
+		hang := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the closed connection is detected once the body is read
+			_, _ = io.Copy(io.Discard, r.Body) // nolint: errcheck
+
+			<-r.Context().Done()
+		}))
+		defer hang.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		results, err := vct.NewMultiLog(vct.New(hang.URL+"/maple2020"), newFakeLog(t).client()).
+			AddVC(ctx, vcBachelorDegree)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		require.Len(t, results, 2)
+		require.Error(t, results[0].Err)
+		require.NoError(t, results[1].Err)
+	})
+
+	t.Run("No logs", func(t *testing.T) {
+		results, err := vct.NewMultiLog().AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+		require.Empty(t, results)
+	})
+}
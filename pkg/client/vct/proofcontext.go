@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/google/trillian/merkle/rfc6962/hasher"
+)
+
+// nodeRange identifies a node of the tree by the range of leaves [start, end) it covers. Within a tree of
+// a given size, the range identifies the node unambiguously.
+type nodeRange struct {
+	start uint64
+	end   uint64
+}
+
+type nodeHash struct {
+	node nodeRange
+	hash []byte
+}
+
+// ProofVerifierContext verifies inclusion proofs against a fixed tree size and root hash, e.g. many entries
+// against the same signed tree head. The hashes of the nodes on the path of a proof, and of their siblings, are
+// cached once the proof has been verified up to the root. As the root commits to each of them, a later proof
+// that reaches a cached node with the same hash is verified without hashing the rest of its path, and a proof
+// that reaches a cached node with a different hash is rejected. Proofs that fail verification are never cached.
+//
+// ProofVerifierContext is safe for concurrent use.
+type ProofVerifierContext struct {
+	verifier *ProofVerifier
+	treeSize uint64
+	rootHash []byte
+
+	mu    sync.RWMutex
+	nodes map[nodeRange][]byte
+}
+
+// NewContext returns a context to verify inclusion proofs against the tree with the given size and root hash.
+func (v *ProofVerifier) NewContext(treeSize uint64, rootHash []byte) *ProofVerifierContext {
+	return &ProofVerifierContext{
+		verifier: v,
+		treeSize: treeSize,
+		rootHash: rootHash,
+		nodes:    map[nodeRange][]byte{},
+	}
+}
+
+// VerifyInclusion verifies that the leaf input is included at the given index of the tree of the context.
+func (c *ProofVerifierContext) VerifyInclusion(leafInput []byte, leafIndex uint64, auditPath [][]byte) error {
+	leafHash, err := c.verifier.inclusionLeafHash(leafInput, leafIndex, c.treeSize)
+	if err != nil {
+		return err
+	}
+
+	return c.verifyInclusion(leafHash, leafIndex, auditPath)
+}
+
+func (c *ProofVerifierContext) verifyInclusion(leafHash []byte, leafIndex uint64, auditPath [][]byte) error {
+	inner, err := checkInclusionProof(leafHash, leafIndex, c.treeSize, auditPath)
+	if err != nil {
+		return err
+	}
+
+	node, hash := nodeRange{start: leafIndex, end: leafIndex + 1}, leafHash
+	visited := make([]nodeHash, 0, 2*len(auditPath))
+
+	for i, siblingHash := range auditPath {
+		if verified, ok := c.node(node); ok {
+			return c.settle(node, hash, verified, visited)
+		}
+
+		var sibling nodeRange
+
+		if i < inner && (leafIndex>>uint(i))&1 == 0 {
+			// The node is a left child; its sibling is the next subtree, which may be incomplete.
+			sibling = nodeRange{start: node.end, end: subtreeEnd(node.end, uint64(1)<<uint(i), c.treeSize)}
+			visited = append(visited, nodeHash{node, hash}, nodeHash{sibling, siblingHash})
+			node, hash = nodeRange{start: node.start, end: sibling.end},
+				hasher.DefaultHasher.HashChildren(hash, siblingHash)
+
+			continue
+		}
+
+		// The node is a right child; its sibling is the perfect subtree on its left.
+		sibling = nodeRange{start: node.start - node.start&-node.start, end: node.start}
+		visited = append(visited, nodeHash{node, hash}, nodeHash{sibling, siblingHash})
+		node, hash = nodeRange{start: sibling.start, end: node.end},
+			hasher.DefaultHasher.HashChildren(siblingHash, hash)
+	}
+
+	if !bytes.Equal(hash, c.rootHash) {
+		return fmt.Errorf("calculated root %x does not match expected root %x", hash, c.rootHash)
+	}
+
+	c.store(visited)
+
+	return nil
+}
+
+// settle completes the verification of a proof that reached a node whose hash has already been verified.
+func (c *ProofVerifierContext) settle(node nodeRange, hash, verified []byte, visited []nodeHash) error {
+	if !bytes.Equal(hash, verified) {
+		return fmt.Errorf("calculated hash %x of node [%d, %d) does not match verified hash %x",
+			hash, node.start, node.end, verified)
+	}
+
+	c.store(visited)
+
+	return nil
+}
+
+func (c *ProofVerifierContext) node(node nodeRange) ([]byte, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	hash, ok := c.nodes[node]
+
+	return hash, ok
+}
+
+func (c *ProofVerifierContext) store(nodes []nodeHash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, n := range nodes {
+		c.nodes[n.node] = n.hash
+	}
+}
+
+// subtreeEnd returns the end of the subtree with the given start and width, which is cut at the tree size.
+func subtreeEnd(start, width, treeSize uint64) uint64 {
+	if treeSize-start < width {
+		return treeSize
+	}
+
+	return start + width
+}
@@ -0,0 +1,210 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+)
+
+// testTree contains the leaves of a tree, its root hash and the audit path of every leaf.
+type testTree struct {
+	leaves     [][]byte
+	root       []byte
+	auditPaths [][][]byte
+}
+
+func newTestTree(size int) *testTree {
+	tree := &testTree{}
+
+	hashes := make([][]byte, size)
+
+	for i := range hashes {
+		tree.leaves = append(tree.leaves, []byte(fmt.Sprintf("leaf-%d", i)))
+		hashes[i] = rfc6962LeafHash(tree.leaves[i])
+	}
+
+	for i := range hashes {
+		tree.auditPaths = append(tree.auditPaths, rfc6962AuditPath(uint64(i), hashes))
+	}
+
+	tree.root = rfc6962Root(hashes)
+
+	return tree
+}
+
+func (tree *testTree) leafHashes() [][]byte {
+	hashes := make([][]byte, len(tree.leaves))
+	for i, leaf := range tree.leaves {
+		hashes[i] = rfc6962LeafHash(leaf)
+	}
+
+	return hashes
+}
+
+func (tree *testTree) context() *vct.ProofVerifierContext {
+	return vct.NewProofVerifier(vct.WithTrillianProofEncoding()).NewContext(uint64(len(tree.leaves)), tree.root)
+}
+
+func TestProofVerifierContext_VerifyInclusion(t *testing.T) {
+	t.Run("Every leaf of every tree size", func(t *testing.T) {
+		for size := 1; size <= 33; size++ {
+			tree := newTestTree(size)
+			verifier := tree.context()
+
+			// Verify every proof twice so that the second round is verified against cached nodes only.
+			for round := 0; round < 2; round++ {
+				for i := len(tree.leaves) - 1; i >= 0; i-- {
+					require.NoError(t, verifier.VerifyInclusion(tree.leaves[i], uint64(i), tree.auditPaths[i]),
+						"size %d, leaf %d", size, i)
+				}
+			}
+		}
+	})
+
+	t.Run("Trillian proofs", func(t *testing.T) {
+		sth, proofs := loadTrillianProofs(t)
+
+		verifier := vct.NewProofVerifier(vct.WithTrillianProofEncoding()).NewContext(sth.TreeSize,
+			sth.SHA256RootHash)
+
+		for _, p := range proofs {
+			require.NoError(t, verifier.VerifyInclusion(p.LeafInput, p.LeafIndex, p.AuditPath))
+		}
+	})
+
+	t.Run("Tampered leaf against cached nodes", func(t *testing.T) {
+		tree := newTestTree(8)
+		verifier := tree.context()
+
+		require.NoError(t, verifier.VerifyInclusion(tree.leaves[0], 0, tree.auditPaths[0]))
+
+		// The hash of leaf 1 is cached as the sibling of leaf 0.
+		err := verifier.VerifyInclusion([]byte("forged"), 1, tree.auditPaths[1])
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "of node [1, 2) does not match verified hash")
+	})
+
+	t.Run("Tampered audit path against cached nodes", func(t *testing.T) {
+		tree := newTestTree(8)
+		verifier := tree.context()
+
+		require.NoError(t, verifier.VerifyInclusion(tree.leaves[0], 0, tree.auditPaths[0]))
+
+		auditPath := append([][]byte{}, tree.auditPaths[5]...)
+		auditPath[0] = rfc6962LeafHash([]byte("forged"))
+
+		err := verifier.VerifyInclusion(tree.leaves[5], 5, auditPath)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "of node [4, 8) does not match verified hash")
+	})
+
+	t.Run("Failed proofs are not cached", func(t *testing.T) {
+		tree := newTestTree(8)
+		verifier := tree.context()
+
+		auditPath := append([][]byte{}, tree.auditPaths[3]...)
+		auditPath[2] = rfc6962LeafHash([]byte("forged"))
+
+		err := verifier.VerifyInclusion(tree.leaves[3], 3, auditPath)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match expected root")
+
+		for i := range tree.leaves {
+			require.NoError(t, verifier.VerifyInclusion(tree.leaves[i], uint64(i), tree.auditPaths[i]))
+		}
+	})
+
+	t.Run("Wrong root", func(t *testing.T) {
+		tree := newTestTree(5)
+
+		err := vct.NewProofVerifier(vct.WithTrillianProofEncoding()).NewContext(5, newTestTree(6).root).
+			VerifyInclusion(tree.leaves[2], 2, tree.auditPaths[2])
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match expected root")
+	})
+
+	t.Run("Invalid proof", func(t *testing.T) {
+		tree := newTestTree(5)
+		verifier := tree.context()
+
+		require.EqualError(t, verifier.VerifyInclusion(tree.leaves[0], 5, tree.auditPaths[0]),
+			"leaf index 5 is out of range for tree size 5")
+		require.EqualError(t, verifier.VerifyInclusion(tree.leaves[0], 0, tree.auditPaths[0][1:]),
+			"audit path has 2 hashes, expected 3")
+	})
+
+	t.Run("Malformed leaf", func(t *testing.T) {
+		tree := newTestTree(5)
+
+		err := vct.NewProofVerifier().NewContext(5, tree.root).VerifyInclusion([]byte(`leaf`), 0,
+			tree.auditPaths[0])
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "leaf hash: unmarshal leaf")
+	})
+
+	t.Run("Concurrent verification", func(t *testing.T) {
+		tree := newTestTree(100)
+		verifier := tree.context()
+
+		var wg sync.WaitGroup
+
+		errs := make(chan error, 4*len(tree.leaves))
+
+		for worker := 0; worker < 4; worker++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for i := range tree.leaves {
+					errs <- verifier.VerifyInclusion(tree.leaves[i], uint64(i), tree.auditPaths[i])
+				}
+			}()
+		}
+
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			require.NoError(t, err)
+		}
+	})
+}
+
+func BenchmarkProofVerifierContext_VerifyInclusion(b *testing.B) {
+	tree := newTestTree(1024)
+	verifier := vct.NewProofVerifier(vct.WithTrillianProofEncoding())
+
+	b.Run("Independent", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for i := range tree.leaves {
+				if err := verifier.VerifyInclusion(tree.leaves[i], uint64(i), uint64(len(tree.leaves)),
+					tree.auditPaths[i], tree.root); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("Context", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			ctx := verifier.NewContext(uint64(len(tree.leaves)), tree.root)
+
+			for i := range tree.leaves {
+				if err := ctx.VerifyInclusion(tree.leaves[i], uint64(i), tree.auditPaths[i]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,218 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	jsonld "github.com/piprate/json-gold/ld"
+
+	"github.com/trustbloc/vct/pkg/controller/command"
+)
+
+// VerifyVCTimestampSignatureWithKey verifies VC timestamp signature like VerifyVCTimestampSignature, against a
+// parsed public key, e.g. to verify many entries without parsing the key for each of them. The key is an
+// ed25519.PublicKey, an *ecdsa.PublicKey on the P-256, P-384 or P-521 curve, or an *rsa.PublicKey; it must
+// match the algorithm of the signature.
+func VerifyVCTimestampSignatureWithKey(signature []byte, key crypto.PublicKey, timestamp uint64, vcBytes []byte,
+	loader jsonld.DocumentLoader) error {
+	sig, err := unmarshalSignature(signature)
+	if err != nil {
+		return err
+	}
+
+	_, err = verifyVCTimestampSignatureWithKey(sig, key, timestamp, vcBytes, loader)
+
+	return err
+}
+
+// verifyVCTimestampSignatureWithKey verifies the signature of the leaf of the credential and returns the leaf.
+func verifyVCTimestampSignatureWithKey(sig *command.DigitallySigned, key crypto.PublicKey, timestamp uint64,
+	vcBytes []byte, loader jsonld.DocumentLoader) (*command.MerkleTreeLeaf, error) {
+	leaf, err := command.CreateLeaf(timestamp, vcBytes, loader)
+	if err != nil {
+		return nil, fmt.Errorf("create leaf: %w", err)
+	}
+
+	if err = verifyLeafSignatureWithKey(sig, key, leaf); err != nil {
+		return nil, err
+	}
+
+	return leaf, nil
+}
+
+// parsePublicKey parses the public key of the log as the key type of the signature requires: a raw key for
+// Ed25519, a DER encoded SubjectPublicKeyInfo or an uncompressed point for ECDSA, and a DER encoded
+// SubjectPublicKeyInfo or PKCS #1 RSAPublicKey for RSA. The key is verified with verifySignatureWithKey.
+func parsePublicKey(sig *command.DigitallySigned, pubKey []byte) (crypto.PublicKey, error) {
+	switch sig.Algorithm.Type {
+	case kms.ED25519:
+		if len(pubKey) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid ED25519 public key size %d, expected %d", len(pubKey),
+				ed25519.PublicKeySize)
+		}
+
+		return ed25519.PublicKey(pubKey), nil
+	case kms.ECDSASecp256k1DER, kms.ECDSASecp256k1IEEEP1363:
+		key, err := parseSecp256k1PublicKey(pubKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secp256k1 public key: %w", err)
+		}
+
+		return key, nil
+	case kms.ECDSAP256DER, kms.ECDSAP256IEEEP1363, kms.ECDSAP384DER, kms.ECDSAP384IEEEP1363,
+		kms.ECDSAP521DER, kms.ECDSAP521IEEEP1363:
+		key, err := parseECDSAPublicKey(pubKey, ecdsaCurve(sig.Algorithm.Type))
+		if err != nil {
+			return nil, fmt.Errorf("invalid ECDSA public key: %w", err)
+		}
+
+		return key, nil
+	case kms.RSARS256, kms.RSAPS256:
+		key, err := parseRSAPublicKey(pubKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA public key: %w", err)
+		}
+
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported signature algorithm %q with key type %q", sig.Algorithm.Signature,
+			sig.Algorithm.Type)
+	}
+}
+
+func parseECDSAPublicKey(pubKey []byte, curve elliptic.Curve) (*ecdsa.PublicKey, error) {
+	if key, err := x509.ParsePKIXPublicKey(pubKey); err == nil {
+		ecdsaKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("unexpected key type %T", key)
+		}
+
+		return ecdsaKey, nil
+	}
+
+	x, y := elliptic.Unmarshal(curve, pubKey)
+	if x == nil {
+		return nil, errors.New("neither a PKIX public key nor an uncompressed point")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// verifySignatureWithKey verifies the signature of the data with the public key, which must match the key type of
+// the signature. Signatures are verified here whether the key is given parsed or as bytes, see parsePublicKey.
+func verifySignatureWithKey(sig *command.DigitallySigned, key crypto.PublicKey, data []byte) error {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		if sig.Algorithm.Type != kms.ED25519 {
+			break
+		}
+
+		return verifyED25519Signature(sig.Signature, k, data)
+	case *ecdsa.PublicKey:
+		return verifyECDSASignature(sig, k, data)
+	case secp256k1Point:
+		switch sig.Algorithm.Type {
+		case kms.ECDSASecp256k1DER:
+			return verifySecp256k1Signature(sig.Signature, k, data, false)
+		case kms.ECDSASecp256k1IEEEP1363:
+			return verifySecp256k1Signature(sig.Signature, k, data, true)
+		}
+	case *rsa.PublicKey:
+		switch sig.Algorithm.Type {
+		case kms.RSARS256:
+			return verifyRSAKeySignature(sig.Signature, k, data, false)
+		case kms.RSAPS256:
+			return verifyRSAKeySignature(sig.Signature, k, data, true)
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+
+	return fmt.Errorf("key type %q of the signature does not match public key type %T", sig.Algorithm.Type, key)
+}
+
+// verifyECDSASignature verifies the ECDSA signature of the digest of the data, with the hash function of the
+// curve of the key as the key manager of the log uses it, i.e. SHA-256 for P-256, SHA-384 for P-384 and SHA-512
+// for P-521. The signature is either DER encoded or the concatenation of r and s (IEEE P1363).
+func verifyECDSASignature(sig *command.DigitallySigned, key *ecdsa.PublicKey, data []byte) error {
+	curve := ecdsaCurve(sig.Algorithm.Type)
+
+	ieeeP1363 := sig.Algorithm.Type == kms.ECDSAP256IEEEP1363 || sig.Algorithm.Type == kms.ECDSAP384IEEEP1363 ||
+		sig.Algorithm.Type == kms.ECDSAP521IEEEP1363
+
+	if curve == nil || key.Curve != curve {
+		return fmt.Errorf("key type %q of the signature does not match public key type %T", sig.Algorithm.Type, key)
+	}
+
+	digest := ecdsaDigest(curve, data)
+
+	if !ieeeP1363 {
+		if !ecdsa.VerifyASN1(key, digest, sig.Signature) {
+			return errors.New("ECDSA signature verification failed")
+		}
+
+		return nil
+	}
+
+	size := (curve.Params().BitSize + 7) / 8
+
+	if len(sig.Signature) != 2*size {
+		return fmt.Errorf("invalid ECDSA signature size %d, expected %d", len(sig.Signature), 2*size)
+	}
+
+	r := new(big.Int).SetBytes(sig.Signature[:size])
+	s := new(big.Int).SetBytes(sig.Signature[size:])
+
+	if !ecdsa.Verify(key, digest, r, s) {
+		return errors.New("ECDSA signature verification failed")
+	}
+
+	return nil
+}
+
+// ecdsaCurve returns the curve of the ECDSA key type, nil for other key types.
+func ecdsaCurve(keyType kms.KeyType) elliptic.Curve {
+	switch keyType {
+	case kms.ECDSAP256DER, kms.ECDSAP256IEEEP1363:
+		return elliptic.P256()
+	case kms.ECDSAP384DER, kms.ECDSAP384IEEEP1363:
+		return elliptic.P384()
+	case kms.ECDSAP521DER, kms.ECDSAP521IEEEP1363:
+		return elliptic.P521()
+	default:
+		return nil
+	}
+}
+
+func ecdsaDigest(curve elliptic.Curve, data []byte) []byte {
+	switch curve {
+	case elliptic.P384():
+		digest := sha512.Sum384(data)
+
+		return digest[:]
+	case elliptic.P521():
+		digest := sha512.Sum512(data)
+
+		return digest[:]
+	default:
+		digest := sha256.Sum256(data)
+
+		return digest[:]
+	}
+}
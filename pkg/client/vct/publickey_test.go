@@ -0,0 +1,158 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/canonicalizer"
+	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/controller/command"
+	"github.com/trustbloc/vct/pkg/testutil"
+)
+
+// signTimestamp signs the timestamp of the credential with the given func and returns the signature envelope.
+func signTimestamp(t *testing.T, keyType kms.KeyType, timestamp uint64, vc []byte,
+	sign func(data []byte) []byte) []byte {
+	t.Helper()
+
+	leaf, err := command.CreateLeaf(timestamp, vc, testutil.GetLoader(t))
+	require.NoError(t, err)
+
+	data, err := canonicalizer.MarshalCanonical(command.CreateVCTimestampSignature(leaf))
+	require.NoError(t, err)
+
+	signature, err := json.Marshal(command.DigitallySigned{
+		Algorithm: command.SignatureAndHashAlgorithm{Type: keyType},
+		Signature: sign(data),
+	})
+	require.NoError(t, err)
+
+	return signature
+}
+
+func TestVerifyVCTimestampSignatureWithKey(t *testing.T) {
+	const timestamp = 1662067083140
+
+	t.Run("ECDSA P-256 (DER)", func(t *testing.T) {
+		log := newFakeLog(t)
+
+		resp, err := log.client().AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+
+		require.NoError(t, vct.VerifyVCTimestampSignatureWithKey(resp.Signature, &log.key.PublicKey,
+			resp.Timestamp, vcBachelorDegree, testutil.GetLoader(t)))
+
+		require.EqualError(t, vct.VerifyVCTimestampSignatureWithKey(resp.Signature, &log.key.PublicKey,
+			resp.Timestamp+1, vcBachelorDegree, testutil.GetLoader(t)), "ECDSA signature verification failed")
+
+		require.EqualError(t, vct.VerifyVCTimestampSignatureWithKey(resp.Signature, &newFakeLog(t).key.PublicKey,
+			resp.Timestamp, vcBachelorDegree, testutil.GetLoader(t)), "ECDSA signature verification failed")
+	})
+
+	t.Run("ECDSA P-384 (IEEE P1363)", func(t *testing.T) {
+		key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		signature := signTimestamp(t, kms.ECDSAP384IEEEP1363, timestamp, vcBachelorDegree,
+			func(data []byte) []byte {
+				digest := sha512.Sum384(data)
+
+				r, s, errSign := ecdsa.Sign(rand.Reader, key, digest[:])
+				require.NoError(t, errSign)
+
+				return append(r.FillBytes(make([]byte, 48)), s.FillBytes(make([]byte, 48))...)
+			})
+
+		require.NoError(t, vct.VerifyVCTimestampSignatureWithKey(signature, &key.PublicKey, timestamp,
+			vcBachelorDegree, testutil.GetLoader(t)))
+
+		// the public key bytes are verified the same way, either as a PKIX public key or as a point
+		pubKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+
+		for _, pubKey := range [][]byte{pubKey, elliptic.Marshal(elliptic.P384(), key.X, key.Y)} {
+			require.NoError(t, vct.VerifyVCTimestampSignature(signature, pubKey, timestamp, vcBachelorDegree,
+				testutil.GetLoader(t)))
+
+			require.EqualError(t, vct.VerifyVCTimestampSignature(signature, pubKey, timestamp+1, vcBachelorDegree,
+				testutil.GetLoader(t)), "ECDSA signature verification failed")
+		}
+
+		other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		require.EqualError(t, vct.VerifyVCTimestampSignatureWithKey(signature, &other.PublicKey, timestamp,
+			vcBachelorDegree, testutil.GetLoader(t)),
+			`key type "ECDSAP384IEEEP1363" of the signature does not match public key type *ecdsa.PublicKey`)
+	})
+
+	t.Run("Ed25519", func(t *testing.T) {
+		pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		signature := signTimestamp(t, kms.ED25519, timestamp, vcBachelorDegree, func(data []byte) []byte {
+			return ed25519.Sign(privKey, data)
+		})
+
+		require.NoError(t, vct.VerifyVCTimestampSignatureWithKey(signature, pubKey, timestamp, vcBachelorDegree,
+			testutil.GetLoader(t)))
+
+		// the raw public key is verified the same way
+		require.NoError(t, vct.VerifyVCTimestampSignature(signature, pubKey, timestamp, vcBachelorDegree,
+			testutil.GetLoader(t)))
+
+		require.EqualError(t, vct.VerifyVCTimestampSignatureWithKey(signature, pubKey, timestamp+1,
+			vcBachelorDegree, testutil.GetLoader(t)), "ED25519 signature verification failed")
+	})
+
+	t.Run("RSA", func(t *testing.T) {
+		block, _ := pem.Decode(rsaKeyPEM)
+		require.NotNil(t, block)
+
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		require.NoError(t, err)
+
+		for _, keyType := range []kms.KeyType{kms.RSARS256, kms.RSAPS256} {
+			signature := signRSATimestamp(t, key, keyType, timestamp, vcBachelorDegree)
+
+			require.NoError(t, vct.VerifyVCTimestampSignatureWithKey(signature, &key.PublicKey, timestamp,
+				vcBachelorDegree, testutil.GetLoader(t)))
+		}
+
+		signature := signRSATimestamp(t, key, kms.RSARS256, timestamp, vcBachelorDegree)
+
+		require.EqualError(t, vct.VerifyVCTimestampSignatureWithKey(signature, ed25519.PublicKey{}, timestamp,
+			vcBachelorDegree, testutil.GetLoader(t)),
+			`key type "RSARS256" of the signature does not match public key type ed25519.PublicKey`)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		require.EqualError(t, vct.VerifyVCTimestampSignatureWithKey([]byte(`{}`), "key", timestamp,
+			vcBachelorDegree, testutil.GetLoader(t)), "unsupported public key type string")
+
+		require.EqualError(t, vct.VerifyVCTimestampSignatureWithKey([]byte(`null`), ed25519.PublicKey{}, timestamp,
+			vcBachelorDegree, testutil.GetLoader(t)), "unmarshal signature: empty signature")
+
+		err := vct.VerifyVCTimestampSignatureWithKey([]byte(`[]`), ed25519.PublicKey{}, timestamp,
+			vcBachelorDegree, testutil.GetLoader(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal signature")
+	})
+}
@@ -0,0 +1,136 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/google/trillian/merkle/rfc6962/hasher"
+
+	"github.com/trustbloc/vct/pkg/controller/command"
+)
+
+// ReconstructOpt represents ReconstructAndCompareSTH option func.
+type ReconstructOpt func(*reconstructOptions)
+
+type reconstructOptions struct {
+	verifier *ProofVerifier
+}
+
+// WithReconstructionProofVerifier sets the verifier used to calculate the leaf hashes of the entries. By default,
+// the leaf hashes are calculated with the leaf hasher of the client, see WithLeafHasher.
+func WithReconstructionProofVerifier(verifier *ProofVerifier) ReconstructOpt {
+	return func(o *reconstructOptions) {
+		o.verifier = verifier
+	}
+}
+
+// ReconstructionReport represents the outcome of reconstructing the tree of a log.
+type ReconstructionReport struct {
+	// TreeSize is the size of the signed tree head, which is the number of entries the root was computed from.
+	TreeSize uint64
+	// ComputedRoot is the root hash computed from the downloaded entries.
+	ComputedRoot []byte
+	// SignedRoot is the root hash of the signed tree head.
+	SignedRoot []byte
+	// Match is true if the computed root matches the signed root.
+	Match bool
+}
+
+// ReconstructAndCompareSTH downloads every entry of the tree of the latest signed tree head, recomputes its root
+// hash and compares it with the signed root. The signature of the signed tree head is verified with the log
+// public key first; a VerificationError is returned if it is invalid. A root mismatch is not an error, it is
+// reported in the result.
+func ReconstructAndCompareSTH(ctx context.Context, client *Client, pubKey []byte,
+	opts ...ReconstructOpt) (*ReconstructionReport, error) {
+	options := &reconstructOptions{verifier: NewProofVerifier(WithProofLeafHasher(client.leafHasher))}
+	for _, fn := range opts {
+		fn(options)
+	}
+
+	sth, err := client.GetSTH(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reconstruct STH: %w", err)
+	}
+
+	if sth == nil {
+		return nil, fmt.Errorf("reconstruct STH: get STH: %w", &DecodeError{
+			Field: "body",
+			Err:   errors.New("empty response"),
+		})
+	}
+
+	if err = verifySTHSignature(sth, pubKey); err != nil {
+		return nil, fmt.Errorf("reconstruct STH: %w", &VerificationError{Check: CheckSTHSignature, Err: err})
+	}
+
+	tree := &treeHasher{}
+
+	if sth.TreeSize > 0 {
+		err = client.forEachEntry(ctx, 0, sth.TreeSize-1, func(index uint64, entry command.LeafEntry) error {
+			leafHash, hashErr := options.verifier.LeafHash(entry.LeafInput)
+			if hashErr != nil {
+				return fmt.Errorf("entry %d: %w", index, hashErr)
+			}
+
+			tree.append(leafHash)
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("reconstruct STH: %w", err)
+		}
+	}
+
+	root := tree.root()
+
+	return &ReconstructionReport{
+		TreeSize:     sth.TreeSize,
+		ComputedRoot: root,
+		SignedRoot:   sth.SHA256RootHash,
+		Match:        bytes.Equal(root, sth.SHA256RootHash),
+	}, nil
+}
+
+// treeHasher calculates the root hash of a tree from its leaf hashes appended one by one. Only the roots of
+// the perfect subtrees the tree decomposes into are kept, so memory is logarithmic in the tree size.
+type treeHasher struct {
+	size  uint64
+	nodes [][]byte
+}
+
+func (t *treeHasher) append(leafHash []byte) {
+	t.nodes = append(t.nodes, leafHash)
+
+	// Every trailing one bit of the previous size is a perfect subtree of the same size to merge with.
+	for size := t.size; size&1 == 1; size >>= 1 {
+		n := len(t.nodes)
+		t.nodes = append(t.nodes[:n-2], hasher.DefaultHasher.HashChildren(t.nodes[n-2], t.nodes[n-1]))
+	}
+
+	t.size++
+}
+
+func (t *treeHasher) root() []byte {
+	if len(t.nodes) == 0 {
+		hash := sha256.Sum256(nil)
+
+		return hash[:]
+	}
+
+	root := t.nodes[len(t.nodes)-1]
+
+	for i := len(t.nodes) - 2; i >= 0; i-- {
+		root = hasher.DefaultHasher.HashChildren(t.nodes[i], root)
+	}
+
+	return root
+}
@@ -0,0 +1,124 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+)
+
+func TestReconstructAndCompareSTH(t *testing.T) {
+	t.Run("Match", func(t *testing.T) {
+		for _, size := range []int{1, 2, 3, 7, 8, 13} {
+			log := newSampledLog(t, size)
+
+			report, err := vct.ReconstructAndCompareSTH(context.Background(), log.client(), log.pubKey)
+			require.NoError(t, err)
+			require.True(t, report.Match, "size %d", size)
+			require.Equal(t, uint64(size), report.TreeSize)
+			require.Equal(t, rfc6962Root(log.leafHashes(uint64(size))), report.ComputedRoot)
+			require.Equal(t, report.SignedRoot, report.ComputedRoot)
+		}
+	})
+
+	t.Run("Leaf hasher of the client", func(t *testing.T) {
+		log := newSampledLog(t, 5)
+		log.hashLeavesWith(prefixLeafHasher{})
+
+		report, err := vct.ReconstructAndCompareSTH(context.Background(),
+			log.client(vct.WithLeafHasher(prefixLeafHasher{})), log.pubKey)
+		require.NoError(t, err)
+		require.True(t, report.Match)
+
+		report, err = vct.ReconstructAndCompareSTH(context.Background(), log.client(), log.pubKey)
+		require.NoError(t, err)
+		require.False(t, report.Match)
+	})
+
+	t.Run("Empty log", func(t *testing.T) {
+		log := newFakeLog(t)
+
+		report, err := vct.ReconstructAndCompareSTH(context.Background(), log.client(), log.pubKey)
+		require.NoError(t, err)
+		require.True(t, report.Match)
+
+		empty := sha256.Sum256(nil)
+		require.Equal(t, empty[:], report.ComputedRoot)
+	})
+
+	t.Run("Forged root", func(t *testing.T) {
+		log := newSampledLog(t, 5)
+		log.forgeRoot(rfc6962Root(newSampledLog(t, 6).leafHashes(6)))
+
+		report, err := vct.ReconstructAndCompareSTH(context.Background(), log.client(), log.pubKey)
+		require.NoError(t, err)
+		require.False(t, report.Match)
+		require.Equal(t, rfc6962Root(log.leafHashes(5)), report.ComputedRoot)
+		require.NotEqual(t, report.SignedRoot, report.ComputedRoot)
+	})
+
+	t.Run("Tampered entry", func(t *testing.T) {
+		log := newSampledLog(t, 5)
+		log.corrupt(2, newLeafEntry(t, fakeLogTimestamp, "forged").LeafInput)
+
+		report, err := vct.ReconstructAndCompareSTH(context.Background(), log.client(), log.pubKey)
+		require.NoError(t, err)
+		require.False(t, report.Match)
+	})
+
+	t.Run("Wrong public key", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+
+		_, err := vct.ReconstructAndCompareSTH(context.Background(), log.client(), newFakeLog(t).pubKey)
+
+		var verificationErr *vct.VerificationError
+		require.True(t, errors.As(err, &verificationErr))
+		require.Equal(t, vct.CheckSTHSignature, verificationErr.Check)
+	})
+
+	t.Run("Malformed entry", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+		log.corrupt(1, []byte(`leaf`))
+
+		_, err := vct.ReconstructAndCompareSTH(context.Background(), log.client(), log.pubKey)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "reconstruct STH: entry 1: unmarshal leaf")
+
+		report, err := vct.ReconstructAndCompareSTH(context.Background(), log.client(), log.pubKey,
+			vct.WithReconstructionProofVerifier(vct.NewProofVerifier(vct.WithTrillianProofEncoding())))
+		require.NoError(t, err)
+		require.False(t, report.Match)
+	})
+
+	t.Run("Log unreachable", func(t *testing.T) {
+		_, err := vct.ReconstructAndCompareSTH(context.Background(), vct.New("http://127.0.0.1:0/maple2020"), nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "reconstruct STH: get STH")
+	})
+
+	t.Run("Empty STH", func(t *testing.T) {
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`null`)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		_, err := vct.ReconstructAndCompareSTH(context.Background(), vct.New(endpoint, vct.WithHTTPClient(httpClient)),
+			nil)
+		require.EqualError(t, err, "reconstruct STH: get STH: decode body: empty response")
+	})
+}
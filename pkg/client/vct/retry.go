@@ -0,0 +1,169 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const maxRetryDelay = time.Minute
+
+// Backoff returns the delay to wait for before the next attempt of a retried request, see WithBackoff.
+type Backoff interface {
+	// Next returns the delay after the given failed attempt, starting at 1.
+	Next(attempt int) time.Duration
+}
+
+// ExponentialBackoff doubles the Base delay for every failed attempt, up to Max, of which a random half is
+// waited for. It is the backoff of WithRetry.
+type ExponentialBackoff struct {
+	Base time.Duration
+	// Max is the maximum delay. It defaults to one minute.
+	Max time.Duration
+}
+
+// Next returns the jittered delay after the given failed attempt.
+func (b ExponentialBackoff) Next(attempt int) time.Duration {
+	maxDelay := b.Max
+	if maxDelay <= 0 {
+		maxDelay = maxRetryDelay
+	}
+
+	delay := b.Base
+
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	if delay <= 1 {
+		return delay
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2))) // nolint: gosec
+}
+
+// ConstantBackoff waits for the same Delay after every failed attempt.
+type ConstantBackoff struct {
+	Delay time.Duration
+}
+
+// Next returns the constant delay.
+func (b ConstantBackoff) Next(int) time.Duration {
+	return b.Delay
+}
+
+type retryPolicy struct {
+	maxAttempts int
+	backoff     Backoff
+}
+
+// do calls send until it succeeds, fails with an error that is not transient, or the attempts are exhausted.
+// onRetry is called with the failed attempt and the delay before the next one.
+func (p *retryPolicy) do(ctx context.Context, send func() (bool, error),
+	onRetry func(attempt int, delay time.Duration, err error)) error {
+	for attempt := 1; ; attempt++ {
+		retryable, err := send()
+		if err == nil {
+			return nil
+		}
+
+		if !retryable || attempt >= p.maxAttempts {
+			return retryError(attempt, err)
+		}
+
+		delay := p.backoff.Next(attempt)
+		if delay < 0 {
+			delay = 0
+		}
+
+		var vctErr *Error
+		if errors.As(err, &vctErr) && vctErr.RetryAfter > 0 {
+			if vctErr.RetryAfter > maxRetryDelay {
+				return retryError(attempt, err)
+			}
+
+			delay = vctErr.RetryAfter
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return retryError(attempt, err)
+		}
+
+		onRetry(attempt, delay, err)
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return retryError(attempt, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func retryError(attempts int, err error) error {
+	if attempts == 1 {
+		return err
+	}
+
+	return &RetryError{Attempts: attempts, Err: err}
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter parses the value of a Retry-After header, either a number of seconds or an HTTP date, into the
+// delay from now. It returns zero if the value is missing or malformed, or the date has passed.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+
+		if seconds > int64(math.MaxInt64/time.Second) {
+			return math.MaxInt64
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil || !date.After(now) {
+		return 0
+	}
+
+	return date.Sub(now)
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,63 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
+// minRSAKeySize is the minimum size of the RSA public keys signatures are verified with, in bits.
+const minRSAKeySize = 2048
+
+func verifyRSAKeySignature(signature []byte, key *rsa.PublicKey, data []byte, pss bool) error {
+	if key.N.BitLen() < minRSAKeySize {
+		return fmt.Errorf("invalid RSA public key: key size %d is less than %d bits", key.N.BitLen(), minRSAKeySize)
+	}
+
+	digest := sha256.Sum256(data)
+
+	var err error
+
+	if pss {
+		err = rsa.VerifyPSS(key, crypto.SHA256, digest[:], signature, &rsa.PSSOptions{
+			SaltLength: rsa.PSSSaltLengthAuto,
+		})
+	} else {
+		err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+	}
+
+	if err != nil {
+		return errors.New("RSA signature verification failed")
+	}
+
+	return nil
+}
+
+// parseRSAPublicKey parses the public key, either a DER encoded SubjectPublicKeyInfo or a DER encoded PKCS #1
+// RSAPublicKey.
+func parseRSAPublicKey(pubKey []byte) (*rsa.PublicKey, error) {
+	key, err := x509.ParsePKCS1PublicKey(pubKey)
+	if err != nil {
+		pkixKey, pkixErr := x509.ParsePKIXPublicKey(pubKey)
+		if pkixErr != nil {
+			return nil, errors.New("neither a PKIX nor a PKCS #1 public key")
+		}
+
+		var ok bool
+
+		if key, ok = pkixKey.(*rsa.PublicKey); !ok {
+			return nil, fmt.Errorf("unexpected key type %T", pkixKey)
+		}
+	}
+
+	return key, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	_ "embed"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/canonicalizer"
+	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/controller/command"
+	"github.com/trustbloc/vct/pkg/testutil"
+)
+
+//go:embed testdata/rsa_key.pem
+var rsaKeyPEM []byte // nolint: gochecknoglobals
+
+// signRSATimestamp signs the timestamp of the credential with a fixed RSA key and returns the signature
+// envelope.
+func signRSATimestamp(t *testing.T, key *rsa.PrivateKey, keyType kms.KeyType, timestamp uint64, vc []byte) []byte {
+	t.Helper()
+
+	leaf, err := command.CreateLeaf(timestamp, vc, testutil.GetLoader(t))
+	require.NoError(t, err)
+
+	data, err := canonicalizer.MarshalCanonical(command.CreateVCTimestampSignature(leaf))
+	require.NoError(t, err)
+
+	digest := sha256.Sum256(data)
+
+	var sig []byte
+
+	if keyType == kms.RSAPS256 {
+		sig, err = rsa.SignPSS(rand.Reader, key, crypto.SHA256, digest[:], nil)
+	} else {
+		sig, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	}
+
+	require.NoError(t, err)
+
+	signature, err := json.Marshal(command.DigitallySigned{
+		Algorithm: command.SignatureAndHashAlgorithm{Signature: command.RSASignature, Type: keyType},
+		Signature: sig,
+	})
+	require.NoError(t, err)
+
+	return signature
+}
+
+func TestVerifyVCTimestampSignature_RSA(t *testing.T) {
+	const timestamp = 1662067083140
+
+	block, _ := pem.Decode(rsaKeyPEM)
+	require.NotNil(t, block)
+
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	require.NoError(t, err)
+
+	pkixPubKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	pkcs1PubKey := x509.MarshalPKCS1PublicKey(&key.PublicKey)
+
+	for _, keyType := range []kms.KeyType{kms.RSARS256, kms.RSAPS256} {
+		keyType := keyType
+
+		t.Run(string(keyType), func(t *testing.T) {
+			signature := signRSATimestamp(t, key, keyType, timestamp, vcBachelorDegree)
+
+			t.Run("Success (PKIX public key)", func(t *testing.T) {
+				require.NoError(t, vct.VerifyVCTimestampSignature(signature, pkixPubKey, timestamp, vcBachelorDegree,
+					testutil.GetLoader(t)))
+			})
+
+			t.Run("Success (PKCS #1 public key)", func(t *testing.T) {
+				require.NoError(t, vct.VerifyVCTimestampSignature(signature, pkcs1PubKey, timestamp,
+					vcBachelorDegree, testutil.GetLoader(t)))
+			})
+
+			t.Run("Wrong timestamp", func(t *testing.T) {
+				require.EqualError(t, vct.VerifyVCTimestampSignature(signature, pkixPubKey, timestamp+1,
+					vcBachelorDegree, testutil.GetLoader(t)), "RSA signature verification failed")
+			})
+		})
+	}
+
+	t.Run("Wrong padding", func(t *testing.T) {
+		signature := signRSATimestamp(t, key, kms.RSAPS256, timestamp, vcBachelorDegree)
+
+		var envelope command.DigitallySigned
+		require.NoError(t, json.Unmarshal(signature, &envelope))
+
+		envelope.Algorithm.Type = kms.RSARS256
+
+		signature, err = json.Marshal(envelope)
+		require.NoError(t, err)
+
+		require.EqualError(t, vct.VerifyVCTimestampSignature(signature, pkixPubKey, timestamp, vcBachelorDegree,
+			testutil.GetLoader(t)), "RSA signature verification failed")
+	})
+
+	t.Run("Not an RSA public key", func(t *testing.T) {
+		signature := signRSATimestamp(t, key, kms.RSARS256, timestamp, vcBachelorDegree)
+
+		require.EqualError(t, vct.VerifyVCTimestampSignature(signature, newFakeLog(t).pubKey, timestamp,
+			vcBachelorDegree, testutil.GetLoader(t)), "invalid RSA public key: unexpected key type *ecdsa.PublicKey")
+
+		require.EqualError(t, vct.VerifyVCTimestampSignature(signature, []byte(`[]`), timestamp,
+			vcBachelorDegree, testutil.GetLoader(t)), "invalid RSA public key: neither a PKIX nor a PKCS #1 public key")
+	})
+
+	t.Run("Key too small", func(t *testing.T) {
+		small, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err)
+
+		pubKey, err := x509.MarshalPKIXPublicKey(&small.PublicKey)
+		require.NoError(t, err)
+
+		signature := signRSATimestamp(t, small, kms.RSARS256, timestamp, vcBachelorDegree)
+
+		require.EqualError(t, vct.VerifyVCTimestampSignature(signature, pubKey, timestamp, vcBachelorDegree,
+			testutil.GetLoader(t)), "invalid RSA public key: key size 1024 is less than 2048 bits")
+	})
+}
@@ -0,0 +1,209 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/trillian/monitoring"
+)
+
+const (
+	defaultSampleInterval = time.Minute
+	defaultSampleSize     = 10
+)
+
+// SamplerOpt represents ContinuousSampler option func.
+type SamplerOpt func(*ContinuousSampler)
+
+// WithSampleInterval sets the interval between sampling rounds. Defaults to one minute, which a non-positive
+// interval keeps.
+func WithSampleInterval(interval time.Duration) SamplerOpt {
+	return func(s *ContinuousSampler) {
+		if interval > 0 {
+			s.interval = interval
+		}
+	}
+}
+
+// WithSampleSize sets the number of entries verified in each sampling round. Defaults to 10.
+func WithSampleSize(size int) SamplerOpt {
+	return func(s *ContinuousSampler) {
+		s.sampleSize = size
+	}
+}
+
+// WithSampleFailureHandler sets the callback invoked for every verification failure.
+func WithSampleFailureHandler(handler func(SampleFailure)) SamplerOpt {
+	return func(s *ContinuousSampler) {
+		s.onFailure = handler
+	}
+}
+
+// WithSamplerMetrics sets the metric factory used to create the sampler metrics. By default, metrics
+// are not exported.
+func WithSamplerMetrics(mf monitoring.MetricFactory) SamplerOpt {
+	return func(s *ContinuousSampler) {
+		s.mf = mf
+	}
+}
+
+// WithSamplerProofVerifier sets the verifier used to verify inclusion of the sampled entries. By default, the
+// leaf hashes are calculated with the leaf hasher of the client, see WithLeafHasher.
+func WithSamplerProofVerifier(verifier *ProofVerifier) SamplerOpt {
+	return func(s *ContinuousSampler) {
+		s.verifier = verifier
+	}
+}
+
+// SampleFailure describes a failed verification of a sampling round.
+type SampleFailure struct {
+	// Check is the name of the failed check.
+	Check string
+	// LeafIndex is the index of the sampled entry. It is not set if the signed tree head failed verification.
+	LeafIndex uint64
+	// TreeSize is the size of the tree the entry was verified against.
+	TreeSize uint64
+	Err      error
+}
+
+// ContinuousSampler provides lightweight ongoing assurance that the log serves the entries it committed to.
+// On every round, it verifies the latest signed tree head and the inclusion of randomly picked entries under it.
+// Indices are picked with a cryptographically secure random generator so the log cannot predict them.
+type ContinuousSampler struct {
+	client     *Client
+	pubKey     []byte
+	verifier   *ProofVerifier
+	interval   time.Duration
+	sampleSize int
+	onFailure  func(SampleFailure)
+	mf         monitoring.MetricFactory
+
+	sampledCounter monitoring.Counter
+	failureCounter monitoring.Counter
+	errorCounter   monitoring.Counter
+}
+
+// NewContinuousSampler returns a sampler that verifies entries of the log with the given public key.
+func NewContinuousSampler(client *Client, pubKey []byte, opts ...SamplerOpt) *ContinuousSampler {
+	s := &ContinuousSampler{
+		client:     client,
+		pubKey:     pubKey,
+		verifier:   NewProofVerifier(WithProofLeafHasher(client.leafHasher)),
+		interval:   defaultSampleInterval,
+		sampleSize: defaultSampleSize,
+		onFailure:  func(SampleFailure) {},
+		mf:         monitoring.InertMetricFactory{},
+	}
+
+	for _, fn := range opts {
+		fn(s)
+	}
+
+	s.sampledCounter = s.mf.NewCounter("sampled_entries", "Number of log entries sampled for verification")
+	s.failureCounter = s.mf.NewCounter("sample_failures", "Number of failed sample verifications", "check")
+	s.errorCounter = s.mf.NewCounter("sample_errors", "Number of sampling rounds that could not be completed")
+
+	return s
+}
+
+// Run samples the log on every interval until the context is done.
+func (s *ContinuousSampler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Sample(ctx); err != nil {
+			s.errorCounter.Inc()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sample performs a single sampling round and returns the verification failures, which are also reported to
+// the failure handler. An error is returned if the round could not be completed, e.g. the log is unreachable.
+func (s *ContinuousSampler) Sample(ctx context.Context) ([]SampleFailure, error) {
+	sth, err := s.client.GetSTH(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sample: %w", err)
+	}
+
+	if sth == nil {
+		return nil, fmt.Errorf("sample: get STH: %w", &DecodeError{Field: "body", Err: errors.New("empty response")})
+	}
+
+	var failures []SampleFailure
+
+	fail := func(failure SampleFailure) {
+		s.failureCounter.Inc(failure.Check)
+		s.onFailure(failure)
+
+		failures = append(failures, failure)
+	}
+
+	if err = verifySTHSignature(sth, s.pubKey); err != nil {
+		fail(SampleFailure{Check: CheckSTHSignature, TreeSize: sth.TreeSize, Err: err})
+
+		return failures, nil
+	}
+
+	if sth.TreeSize == 0 {
+		return nil, nil
+	}
+
+	verifier := s.verifier.NewContext(sth.TreeSize, sth.SHA256RootHash)
+
+	for i := 0; i < s.sampleSize; i++ {
+		var index uint64
+
+		index, err = randIndex(sth.TreeSize)
+		if err != nil {
+			return failures, fmt.Errorf("sample: %w", err)
+		}
+
+		if err = s.verifyEntry(ctx, verifier, index, sth.TreeSize); err != nil {
+			if ctx.Err() != nil {
+				return failures, fmt.Errorf("sample: %w", ctx.Err())
+			}
+
+			fail(SampleFailure{Check: CheckInclusion, LeafIndex: index, TreeSize: sth.TreeSize, Err: err})
+		}
+
+		s.sampledCounter.Inc()
+	}
+
+	return failures, nil
+}
+
+func (s *ContinuousSampler) verifyEntry(ctx context.Context, verifier *ProofVerifierContext, index,
+	treeSize uint64) error {
+	entry, err := s.client.GetEntryAndProof(ctx, index, treeSize)
+	if err != nil {
+		return err
+	}
+
+	return verifier.VerifyInclusion(entry.LeafInput, index, entry.AuditPath)
+}
+
+func randIndex(n uint64) (uint64, error) {
+	index, err := rand.Int(rand.Reader, new(big.Int).SetUint64(n))
+	if err != nil {
+		return 0, fmt.Errorf("random index: %w", err)
+	}
+
+	return index.Uint64(), nil
+}
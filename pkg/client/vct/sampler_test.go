@@ -0,0 +1,169 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+)
+
+func newSampledLog(t *testing.T, size int) *fakeLog {
+	t.Helper()
+
+	log := newFakeLog(t)
+
+	for i := 0; i < size; i++ {
+		log.addLeaf(newLeafEntry(t, uint64(fakeLogTimestamp+i), fmt.Sprintf("vc-%d", i)).LeafInput)
+	}
+
+	return log
+}
+
+func TestContinuousSampler_Run(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		const corruptedIndex = 5
+
+		log := newSampledLog(t, 8)
+		log.corrupt(corruptedIndex, newLeafEntry(t, fakeLogTimestamp, "forged").LeafInput)
+
+		failures := make(chan vct.SampleFailure, 100)
+
+		sampler := vct.NewContinuousSampler(log.client(), log.pubKey,
+			vct.WithSampleInterval(time.Millisecond),
+			vct.WithSampleSize(2),
+			vct.WithSampleFailureHandler(func(failure vct.SampleFailure) {
+				failures <- failure
+			}),
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		done := make(chan struct{})
+
+		go func() {
+			sampler.Run(ctx)
+			close(done)
+		}()
+
+		select {
+		case failure := <-failures:
+			require.Equal(t, vct.CheckInclusion, failure.Check)
+			require.Equal(t, uint64(corruptedIndex), failure.LeafIndex)
+			require.Equal(t, uint64(8), failure.TreeSize)
+			require.Contains(t, failure.Err.Error(), "does not match")
+		case <-ctx.Done():
+			t.Fatal("corrupted entry was not sampled")
+		}
+
+		cancel()
+		<-done
+	})
+
+	t.Run("Non-positive interval", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.NotPanics(t, func() {
+			vct.NewContinuousSampler(log.client(), log.pubKey, vct.WithSampleInterval(0)).Run(ctx)
+		})
+	})
+}
+
+func TestContinuousSampler_Sample(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		log := newSampledLog(t, 5)
+
+		failures, err := vct.NewContinuousSampler(log.client(), log.pubKey, vct.WithSampleSize(20)).
+			Sample(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, failures)
+	})
+
+	t.Run("Leaf hasher of the client", func(t *testing.T) {
+		log := newSampledLog(t, 5)
+		log.hashLeavesWith(prefixLeafHasher{})
+
+		failures, err := vct.NewContinuousSampler(log.client(vct.WithLeafHasher(prefixLeafHasher{})), log.pubKey,
+			vct.WithSampleSize(20)).Sample(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, failures)
+
+		failures, err = vct.NewContinuousSampler(log.client(), log.pubKey, vct.WithSampleSize(20)).
+			Sample(context.Background())
+		require.NoError(t, err)
+		require.NotEmpty(t, failures)
+	})
+
+	t.Run("Empty log", func(t *testing.T) {
+		log := newFakeLog(t)
+
+		failures, err := vct.NewContinuousSampler(log.client(), log.pubKey).Sample(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, failures)
+	})
+
+	t.Run("Every entry corrupted", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+
+		for i := uint64(0); i < 3; i++ {
+			log.corrupt(i, newLeafEntry(t, fakeLogTimestamp, "forged").LeafInput)
+		}
+
+		var reported []vct.SampleFailure
+
+		failures, err := vct.NewContinuousSampler(log.client(), log.pubKey,
+			vct.WithSampleSize(4),
+			vct.WithSampleFailureHandler(func(failure vct.SampleFailure) {
+				reported = append(reported, failure)
+			}),
+		).Sample(context.Background())
+		require.NoError(t, err)
+		require.Len(t, failures, 4)
+		require.Equal(t, failures, reported)
+	})
+
+	t.Run("Wrong public key", func(t *testing.T) {
+		log := newSampledLog(t, 3)
+
+		failures, err := vct.NewContinuousSampler(log.client(), newFakeLog(t).pubKey).Sample(context.Background())
+		require.NoError(t, err)
+		require.Len(t, failures, 1)
+		require.Equal(t, vct.CheckSTHSignature, failures[0].Check)
+	})
+
+	t.Run("Log unreachable", func(t *testing.T) {
+		_, err := vct.NewContinuousSampler(vct.New("http://127.0.0.1:0/maple2020"), nil).
+			Sample(context.Background())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "sample: get STH")
+	})
+
+	t.Run("Empty STH", func(t *testing.T) {
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`null`)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		_, err := vct.NewContinuousSampler(vct.New(endpoint, vct.WithHTTPClient(httpClient)), nil).
+			Sample(context.Background())
+		require.EqualError(t, err, "sample: get STH: decode body: empty response")
+	})
+}
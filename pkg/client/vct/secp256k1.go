@@ -0,0 +1,225 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// secp256k1 curve parameters (SEC 2, section 2.4.1). The curve is y² = x³ + 7; crypto/elliptic only supports
+// curves with a = -3, so points are added in affine coordinates here. This is not constant time, which is fine
+// to verify signatures against public keys.
+var (
+	secp256k1P, _  = new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F", 16) // nolint: gochecknoglobals,lll
+	secp256k1N, _  = new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 16) // nolint: gochecknoglobals,lll
+	secp256k1Gx, _ = new(big.Int).SetString("79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798", 16) // nolint: gochecknoglobals,lll
+	secp256k1Gy, _ = new(big.Int).SetString("483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8", 16) // nolint: gochecknoglobals,lll
+	secp256k1B     = big.NewInt(7)                                                                                  // nolint: gochecknoglobals
+
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1} // nolint: gochecknoglobals
+	oidSecp256k1      = asn1.ObjectIdentifier{1, 3, 132, 0, 10}       // nolint: gochecknoglobals
+)
+
+const secp256k1CoordinateSize = 32
+
+// secp256k1Point is a point of the curve in affine coordinates; the point at infinity has nil coordinates.
+type secp256k1Point struct {
+	x, y *big.Int
+}
+
+func (p secp256k1Point) isInfinity() bool {
+	return p.x == nil
+}
+
+// verifySecp256k1Signature verifies the ECDSA signature of the SHA-256 digest of the data with the public key,
+// see parseSecp256k1PublicKey. The signature is either DER encoded or the concatenation of r and s (IEEE P1363).
+func verifySecp256k1Signature(signature []byte, q secp256k1Point, data []byte, ieeeP1363 bool) error {
+	r, s, err := parseSecp256k1Signature(signature, ieeeP1363)
+	if err != nil {
+		return fmt.Errorf("invalid secp256k1 signature: %w", err)
+	}
+
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(secp256k1N) >= 0 || s.Cmp(secp256k1N) >= 0 {
+		return errors.New("secp256k1 signature verification failed")
+	}
+
+	digest := sha256.Sum256(data)
+	e := new(big.Int).SetBytes(digest[:])
+
+	w := new(big.Int).ModInverse(s, secp256k1N)
+
+	u1 := new(big.Int).Mul(e, w)
+	u1.Mod(u1, secp256k1N)
+
+	u2 := new(big.Int).Mul(r, w)
+	u2.Mod(u2, secp256k1N)
+
+	point := secp256k1Add(
+		secp256k1ScalarMult(secp256k1Point{x: secp256k1Gx, y: secp256k1Gy}, u1),
+		secp256k1ScalarMult(q, u2),
+	)
+
+	if point.isInfinity() || new(big.Int).Mod(point.x, secp256k1N).Cmp(r) != 0 {
+		return errors.New("secp256k1 signature verification failed")
+	}
+
+	return nil
+}
+
+// parseSecp256k1PublicKey parses the public key, either a DER encoded SubjectPublicKeyInfo or a SEC 1 encoded
+// point.
+func parseSecp256k1PublicKey(pubKey []byte) (secp256k1Point, error) {
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+
+	if rest, err := asn1.Unmarshal(pubKey, &spki); err == nil && len(rest) == 0 {
+		var curve asn1.ObjectIdentifier
+
+		if !spki.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) {
+			return secp256k1Point{}, fmt.Errorf("unexpected public key algorithm %s", spki.Algorithm.Algorithm)
+		}
+
+		if _, err = asn1.Unmarshal(spki.Algorithm.Parameters.FullBytes, &curve); err != nil {
+			return secp256k1Point{}, fmt.Errorf("unmarshal curve: %w", err)
+		}
+
+		if !curve.Equal(oidSecp256k1) {
+			return secp256k1Point{}, fmt.Errorf("unexpected curve %s", curve)
+		}
+
+		pubKey = spki.PublicKey.RightAlign()
+	}
+
+	return decodeSecp256k1Point(pubKey)
+}
+
+func decodeSecp256k1Point(data []byte) (secp256k1Point, error) {
+	switch {
+	case len(data) == 1+2*secp256k1CoordinateSize && data[0] == 4:
+		p := secp256k1Point{
+			x: new(big.Int).SetBytes(data[1 : 1+secp256k1CoordinateSize]),
+			y: new(big.Int).SetBytes(data[1+secp256k1CoordinateSize:]),
+		}
+
+		if p.x.Cmp(secp256k1P) >= 0 || p.y.Cmp(secp256k1P) >= 0 ||
+			new(big.Int).Exp(p.y, big.NewInt(2), secp256k1P).Cmp(secp256k1Rhs(p.x)) != 0 {
+			return secp256k1Point{}, errors.New("point is not on the curve")
+		}
+
+		return p, nil
+	case len(data) == 1+secp256k1CoordinateSize && (data[0] == 2 || data[0] == 3):
+		x := new(big.Int).SetBytes(data[1:])
+		if x.Cmp(secp256k1P) >= 0 {
+			return secp256k1Point{}, errors.New("point is not on the curve")
+		}
+
+		y := new(big.Int).ModSqrt(secp256k1Rhs(x), secp256k1P)
+		if y == nil {
+			return secp256k1Point{}, errors.New("point is not on the curve")
+		}
+
+		if y.Bit(0) != uint(data[0]&1) {
+			y.Sub(secp256k1P, y)
+		}
+
+		return secp256k1Point{x: x, y: y}, nil
+	default:
+		return secp256k1Point{}, fmt.Errorf("unexpected point encoding of %d bytes", len(data))
+	}
+}
+
+func parseSecp256k1Signature(signature []byte, ieeeP1363 bool) (*big.Int, *big.Int, error) {
+	if ieeeP1363 {
+		if len(signature) != 2*secp256k1CoordinateSize {
+			return nil, nil, fmt.Errorf("unexpected size %d, expected %d", len(signature), 2*secp256k1CoordinateSize)
+		}
+
+		return new(big.Int).SetBytes(signature[:secp256k1CoordinateSize]),
+			new(big.Int).SetBytes(signature[secp256k1CoordinateSize:]), nil
+	}
+
+	var sig struct {
+		R, S *big.Int
+	}
+
+	rest, err := asn1.Unmarshal(signature, &sig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	if len(rest) != 0 {
+		return nil, nil, errors.New("trailing data")
+	}
+
+	return sig.R, sig.S, nil
+}
+
+// secp256k1Rhs returns x³ + 7 mod p.
+func secp256k1Rhs(x *big.Int) *big.Int {
+	rhs := new(big.Int).Exp(x, big.NewInt(3), secp256k1P)
+	rhs.Add(rhs, secp256k1B)
+
+	return rhs.Mod(rhs, secp256k1P)
+}
+
+func secp256k1Add(a, b secp256k1Point) secp256k1Point {
+	switch {
+	case a.isInfinity():
+		return b
+	case b.isInfinity():
+		return a
+	case a.x.Cmp(b.x) == 0:
+		if a.y.Cmp(b.y) != 0 || a.y.Sign() == 0 {
+			return secp256k1Point{}
+		}
+
+		// λ = 3x² / 2y
+		num := new(big.Int).Mul(a.x, a.x)
+		num.Mul(num, big.NewInt(3))
+
+		return secp256k1Chord(a, b, num, new(big.Int).Lsh(a.y, 1))
+	default:
+		// λ = (y2 - y1) / (x2 - x1)
+		return secp256k1Chord(a, b, new(big.Int).Sub(b.y, a.y), new(big.Int).Sub(b.x, a.x))
+	}
+}
+
+// secp256k1Chord returns the sum of the points given the slope num/den of the line through them.
+func secp256k1Chord(a, b secp256k1Point, num, den *big.Int) secp256k1Point {
+	den.Mod(den, secp256k1P)
+	lambda := num.Mul(num, den.ModInverse(den, secp256k1P))
+	lambda.Mod(lambda, secp256k1P)
+
+	x := new(big.Int).Mul(lambda, lambda)
+	x.Sub(x, a.x).Sub(x, b.x).Mod(x, secp256k1P)
+
+	y := new(big.Int).Sub(a.x, x)
+	y.Mul(y, lambda).Sub(y, a.y).Mod(y, secp256k1P)
+
+	return secp256k1Point{x: x, y: y}
+}
+
+func secp256k1ScalarMult(p secp256k1Point, k *big.Int) secp256k1Point {
+	result := secp256k1Point{}
+
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		result = secp256k1Add(result, result)
+
+		if k.Bit(i) == 1 {
+			result = secp256k1Add(result, p)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,61 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/trustbloc/vct/pkg/client/vct"
+
+// WithTracing makes the client trace the requests it sends to the log with OpenTelemetry. Every client method
+// call is a client span named after the method, e.g. AddVC, which is started with the tracer provider registered
+// globally and is a child of the span in the context passed by the caller. The span context is propagated to
+// the log in the W3C traceparent header of every attempt of the request.
+func WithTracing() ClientOpt {
+	return func(o *Client) {
+		o.tracing = true
+	}
+}
+
+// traced calls send within a client span of the operation.
+func (c *Client) traced(ctx context.Context, op *options, p string, send func(context.Context) error) error {
+	ctx, span := otel.GetTracerProvider().Tracer(tracerName).Start(ctx, op.operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPMethodKey.String(op.method), semconv.HTTPURLKey.String(p)),
+	)
+	defer span.End()
+
+	err := send(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+// injectTraceContext adds the span context to the headers of the request and returns a function that records
+// the status code of the response in the span.
+func (c *Client) injectTraceContext(ctx context.Context, req *http.Request) func(statusCode int) {
+	if !c.tracing {
+		return func(int) {}
+	}
+
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	return func(statusCode int) {
+		trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPStatusCodeKey.Int(statusCode))
+	}
+}
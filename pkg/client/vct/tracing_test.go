@@ -0,0 +1,174 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+)
+
+// recordSpans registers a tracer provider which records the ended spans for the duration of the test.
+func recordSpans(t *testing.T) (*tracetest.SpanRecorder, trace.Tracer) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+
+	return recorder, provider.Tracer("test")
+}
+
+func TestWithTracing(t *testing.T) {
+	respond := func(code int, body string) func(*http.Request) (*http.Response, error) {
+		return func(*http.Request) (*http.Response, error) {
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+				StatusCode: code,
+			}, nil
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder, tracer := recordSpans(t)
+
+		ctx, parent := tracer.Start(context.Background(), "parent")
+
+		var propagated trace.SpanContext
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.NotEmpty(t, req.Header.Get("traceparent"))
+
+			propagated = trace.SpanContextFromContext(
+				propagation.TraceContext{}.Extract(context.Background(), propagation.HeaderCarrier(req.Header)),
+			)
+
+			return respond(http.StatusOK, `{"tree_size":1}`)(req)
+		})
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithTracing()).GetSTH(ctx)
+		require.NoError(t, err)
+
+		parent.End()
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+
+		span := spans[0]
+		require.Equal(t, "GetSTH", span.Name())
+		require.Equal(t, trace.SpanKindClient, span.SpanKind())
+		require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		require.Equal(t, span.SpanContext().TraceID(), propagated.TraceID())
+		require.Equal(t, span.SpanContext().SpanID(), propagated.SpanID())
+		require.Contains(t, span.Attributes(), semconv.HTTPStatusCodeKey.Int(http.StatusOK))
+		require.Contains(t, span.Attributes(), semconv.HTTPMethodKey.String(http.MethodGet))
+		require.Equal(t, codes.Unset, span.Status().Code)
+	})
+
+	t.Run("Retries", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder, _ := recordSpans(t)
+
+		var traceparents []string
+
+		record := func(code int, body string) func(*http.Request) (*http.Response, error) {
+			return func(req *http.Request) (*http.Response, error) {
+				traceparents = append(traceparents, req.Header.Get("traceparent"))
+
+				return respond(code, body)(req)
+			}
+		}
+
+		httpClient := NewMockHTTPClient(ctrl)
+		gomock.InOrder(
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(record(http.StatusServiceUnavailable, "unavailable")),
+			httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(record(http.StatusOK, `{"tree_size":1}`)),
+		)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithTracing(),
+			vct.WithRetry(2, time.Millisecond)).GetSTH(context.Background())
+		require.NoError(t, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		require.Len(t, traceparents, 2)
+		require.NotEmpty(t, traceparents[0])
+		require.Equal(t, traceparents[0], traceparents[1])
+		require.Contains(t, spans[0].Attributes(), semconv.HTTPStatusCodeKey.Int(http.StatusOK))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder, _ := recordSpans(t)
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(
+			respond(http.StatusInternalServerError, `{"message":"internal error"}`),
+		)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient), vct.WithTracing()).
+			AddVC(context.Background(), vcBachelorDegree)
+		require.Error(t, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		require.Equal(t, "AddVC", spans[0].Name())
+		require.Equal(t, codes.Error, spans[0].Status().Code)
+		require.Equal(t, "internal error", spans[0].Status().Description)
+		require.Contains(t, spans[0].Attributes(), semconv.HTTPStatusCodeKey.Int(http.StatusInternalServerError))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder, tracer := recordSpans(t)
+
+		ctx, parent := tracer.Start(context.Background(), "parent")
+		defer parent.End()
+
+		httpClient := NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			require.Empty(t, req.Header.Get("traceparent"))
+
+			return respond(http.StatusOK, `{"tree_size":1}`)(req)
+		})
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).GetSTH(ctx)
+		require.NoError(t, err)
+		require.Empty(t, recorder.Ended())
+	})
+}
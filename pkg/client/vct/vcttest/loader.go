@@ -0,0 +1,47 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vcttest
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/ld"
+	mockldstore "github.com/hyperledger/aries-framework-go/pkg/mock/ld"
+	ldstore "github.com/hyperledger/aries-framework-go/pkg/store/ld"
+	jsonld "github.com/piprate/json-gold/ld"
+
+	vctldcontext "github.com/trustbloc/vct/internal/pkg/ldcontext"
+)
+
+// defaultDocumentLoader returns a document loader of the contexts embedded in the log, which lists them like the
+// document loader of the log.
+func defaultDocumentLoader() (jsonld.DocumentLoader, error) {
+	p := &ldProvider{
+		ContextStore:        vctldcontext.NewContextStore(mockldstore.NewMockContextStore()),
+		RemoteProviderStore: mockldstore.NewMockRemoteProviderStore(),
+	}
+
+	loader, err := ld.NewDocumentLoader(p, ld.WithExtraContexts(vctldcontext.MustGetAll()...))
+	if err != nil {
+		return nil, fmt.Errorf("new document loader: %w", err)
+	}
+
+	return vctldcontext.NewDocumentLoader(loader, p.ContextStore), nil
+}
+
+type ldProvider struct {
+	ContextStore        *vctldcontext.ContextStore
+	RemoteProviderStore ldstore.RemoteProviderStore
+}
+
+func (p *ldProvider) JSONLDContextStore() ldstore.ContextStore {
+	return p.ContextStore
+}
+
+func (p *ldProvider) JSONLDRemoteProviderStore() ldstore.RemoteProviderStore {
+	return p.RemoteProviderStore
+}
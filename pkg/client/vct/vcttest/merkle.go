@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vcttest
+
+import (
+	"crypto/sha256"
+)
+
+// The Merkle tree hashes are calculated with the reference definitions of RFC 6962, section 2.1, which are simple
+// rather than efficient, as the tree of the server is small.
+
+func leafHash(leafInput []byte) []byte {
+	hash := sha256.Sum256(append([]byte{0x00}, leafInput...))
+
+	return hash[:]
+}
+
+func nodeHash(left, right []byte) []byte {
+	hash := sha256.Sum256(append(append([]byte{0x01}, left...), right...))
+
+	return hash[:]
+}
+
+// split returns the largest power of two smaller than n.
+func split(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+
+	return k
+}
+
+// rootHash returns MTH(D[n]) of the leaf hashes.
+func rootHash(hashes [][]byte) []byte {
+	switch len(hashes) {
+	case 0:
+		hash := sha256.Sum256(nil)
+
+		return hash[:]
+	case 1:
+		return hashes[0]
+	}
+
+	k := split(len(hashes))
+
+	return nodeHash(rootHash(hashes[:k]), rootHash(hashes[k:]))
+}
+
+// auditPath returns PATH(m, D[n]) of the leaf hashes.
+func auditPath(m uint64, hashes [][]byte) [][]byte {
+	if len(hashes) <= 1 {
+		return [][]byte{}
+	}
+
+	k := uint64(split(len(hashes)))
+
+	if m < k {
+		return append(auditPath(m, hashes[:k]), rootHash(hashes[k:]))
+	}
+
+	return append(auditPath(m-k, hashes[k:]), rootHash(hashes[:k]))
+}
+
+// consistencyProof returns PROOF(m, D[n]) of the leaf hashes.
+func consistencyProof(m uint64, hashes [][]byte) [][]byte {
+	return subProof(m, hashes, true)
+}
+
+func subProof(m uint64, hashes [][]byte, complete bool) [][]byte {
+	n := uint64(len(hashes))
+
+	if m == n {
+		if complete {
+			return [][]byte{}
+		}
+
+		return [][]byte{rootHash(hashes)}
+	}
+
+	k := uint64(split(len(hashes)))
+
+	if m <= k {
+		return append(subProof(m, hashes[:k], complete), rootHash(hashes[k:]))
+	}
+
+	return append(subProof(m-k, hashes[k:], false), rootHash(hashes[:k]))
+}
@@ -0,0 +1,742 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package vcttest provides an in-memory log for integration tests of the users of the VCT client.
+package vcttest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	jsonld "github.com/piprate/json-gold/ld"
+
+	"github.com/trustbloc/vct/pkg/canonicalizer"
+	"github.com/trustbloc/vct/pkg/controller/command"
+	"github.com/trustbloc/vct/pkg/controller/rest"
+)
+
+// DefaultAlias is the alias of the log served by default.
+const DefaultAlias = "maple2020"
+
+// Option configures the Server.
+type Option func(*Server)
+
+// WithAlias sets the alias of the log. The default is DefaultAlias.
+func WithAlias(alias string) Option {
+	return func(s *Server) {
+		s.alias = alias
+	}
+}
+
+// WithDocumentLoader sets the JSON-LD document loader the credentials are canonicalized with. By default, the
+// contexts embedded in the log are loaded.
+func WithDocumentLoader(loader jsonld.DocumentLoader) Option {
+	return func(s *Server) {
+		s.loader = loader
+	}
+}
+
+// WithIssuers sets the issuers accepted by the log. By default, the credentials of any issuer are accepted.
+func WithIssuers(issuers ...string) Option {
+	return func(s *Server) {
+		s.issuers = issuers
+	}
+}
+
+// WithRoots sets the trust anchors served by the log.
+func WithRoots(roots ...[]byte) Option {
+	return func(s *Server) {
+		s.roots = roots
+	}
+}
+
+// Server is an in-memory log that serves the VCT REST API, so that a vct.Client can be pointed at it with
+// Endpoint. Credentials and presentations are logged as soon as they are added and the signed tree heads and
+// timestamps are signed with an ECDSA P-256 key generated for the server, which is advertised with Webfinger. The
+// proofs of credentials and presentations are not verified.
+type Server struct {
+	*httptest.Server
+
+	// PublicKey is the DER encoded public key of the log.
+	PublicKey []byte
+
+	alias   string
+	loader  jsonld.DocumentLoader
+	issuers []string
+	roots   [][]byte
+	key     *ecdsa.PrivateKey
+	logID   [32]byte
+
+	mu        sync.Mutex
+	leaves    [][]byte
+	extraData [][]byte
+	hashes    [][]byte
+	// indexes are the leaf indexes by the hash of the logged credential, which dedups the credentials.
+	indexes map[[32]byte]int
+}
+
+// NewServer starts and returns a new Server. The caller should call Close when finished, to shut it down.
+// It panics if the key of the log cannot be generated, as httptest.NewServer does if it cannot listen.
+func NewServer(opts ...Option) *Server {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(fmt.Sprintf("vcttest: generate key: %v", err))
+	}
+
+	pubKey, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		panic(fmt.Sprintf("vcttest: marshal public key: %v", err))
+	}
+
+	s := &Server{
+		PublicKey: pubKey,
+		alias:     DefaultAlias,
+		key:       key,
+		logID:     sha256.Sum256(pubKey),
+		indexes:   map[[32]byte]int{},
+	}
+
+	for _, fn := range opts {
+		fn(s)
+	}
+
+	if s.loader == nil {
+		if s.loader, err = defaultDocumentLoader(); err != nil {
+			panic(fmt.Sprintf("vcttest: create document loader: %v", err))
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(s.path(rest.AddVCPath), s.addVC)
+	mux.HandleFunc(s.path(rest.AddVCBatchPath), s.addVCBatch)
+	mux.HandleFunc(s.path(rest.AddVPPath), s.addVP)
+	mux.HandleFunc(s.path(rest.ValidateVCPath), s.validateVC)
+	mux.HandleFunc(s.path(rest.GetSTHPath), s.getSTH)
+	mux.HandleFunc(s.path(rest.GetSTHConsistencyPath), s.getSTHConsistency)
+	mux.HandleFunc(s.path(rest.GetProofByHashPath), s.getProofByHash)
+	mux.HandleFunc(s.path(rest.GetEntriesPath), s.getEntries)
+	mux.HandleFunc(s.path(rest.GetEntryAndProofPath), s.getEntryAndProof)
+	mux.HandleFunc(s.path(rest.GetIssuersPath), s.getIssuers)
+	mux.HandleFunc(s.path(rest.GetRootsPath), s.getRoots)
+	mux.HandleFunc(s.path(rest.GetAcceptedContextsPath), s.getAcceptedContexts)
+	mux.HandleFunc(s.path(rest.GetLogInfoPath), s.getLogInfo)
+	mux.HandleFunc(s.path(rest.LogHealthCheckPath), s.logHealthCheck)
+	mux.HandleFunc(rest.WebfingerPath, s.webfinger)
+	mux.HandleFunc(rest.HealthCheckPath, s.healthCheck)
+
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// Endpoint returns the endpoint of the log, e.g. for vct.New.
+func (s *Server) Endpoint() string {
+	return s.URL + "/" + s.alias
+}
+
+// TreeSize returns the number of credentials logged.
+func (s *Server) TreeSize() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return uint64(len(s.leaves))
+}
+
+func (s *Server) path(p string) string {
+	return "/" + s.alias + strings.TrimPrefix(p, rest.AliasPath)
+}
+
+// add logs the credential with the extensions, unless it was logged before, and returns its signed timestamp.
+func (s *Server) add(vcEntry, extensions []byte) (*command.AddVCResponse, error) {
+	vc, leaf, err := s.validate(vcEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf.TimestampedEntry.Extensions = extensions
+
+	return s.log(leaf, vc.Proofs)
+}
+
+// validate checks that the credential can be logged and returns it with its leaf.
+func (s *Server) validate(vcEntry []byte) (*verifiable.Credential, *command.MerkleTreeLeaf, error) {
+	vc, err := verifiable.ParseCredential(vcEntry,
+		verifiable.WithDisabledProofCheck(),
+		verifiable.WithJSONLDDocumentLoader(s.loader),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse credential: %w", err)
+	}
+
+	if len(s.issuers) > 0 && !contains(s.issuers, vc.Issuer.ID) {
+		return nil, nil, fmt.Errorf("issuer %s is not in a list", vc.Issuer.ID)
+	}
+
+	leaf, err := command.CreateLeaf(uint64(time.Now().UnixNano()/int64(time.Millisecond)), vcEntry, s.loader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create leaf: %w", err)
+	}
+
+	return vc, leaf, nil
+}
+
+// addPresentation logs the presentation, unless it was logged before, and returns its signed timestamp.
+func (s *Server) addPresentation(vpEntry []byte) (*command.AddVCResponse, error) {
+	vp, err := verifiable.ParsePresentation(vpEntry,
+		verifiable.WithPresDisabledProofCheck(),
+		verifiable.WithPresJSONLDDocumentLoader(s.loader),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("parse presentation: %w", err)
+	}
+
+	credentials, err := vp.MarshalledCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("parse presentation credentials: %w", err)
+	}
+
+	for _, vcEntry := range credentials {
+		vc, parseErr := verifiable.ParseCredential(vcEntry,
+			verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(s.loader),
+		)
+		if parseErr != nil {
+			return nil, fmt.Errorf("parse presentation credential: %w", parseErr)
+		}
+
+		if len(s.issuers) > 0 && !contains(s.issuers, vc.Issuer.ID) {
+			return nil, fmt.Errorf("issuer %s is not in a list", vc.Issuer.ID)
+		}
+	}
+
+	leaf, err := command.CreateVPLeaf(uint64(time.Now().UnixNano()/int64(time.Millisecond)), vpEntry, s.loader)
+	if err != nil {
+		return nil, fmt.Errorf("create leaf: %w", err)
+	}
+
+	return s.log(leaf, vp.Proofs)
+}
+
+// log appends the leaf to the log, unless its entry was logged before, and returns its signed timestamp.
+func (s *Server) log(leaf *command.MerkleTreeLeaf, proofs []verifiable.Proof) (*command.AddVCResponse, error) {
+	var (
+		extraData []byte
+		err       error
+	)
+
+	if len(proofs) > 0 {
+		if extraData, err = canonicalizer.MarshalCanonical(proofs); err != nil {
+			return nil, fmt.Errorf("marshal proofs: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := sha256.Sum256(leaf.TimestampedEntry.VCEntry)
+
+	if index, ok := s.indexes[id]; ok {
+		// The entry was logged before, its timestamp is signed again as the log does.
+		leaf = &command.MerkleTreeLeaf{}
+
+		if err = json.Unmarshal(s.leaves[index], leaf); err != nil {
+			return nil, fmt.Errorf("unmarshal MerkleTreeLeaf: %w", err)
+		}
+	} else {
+		leafInput, marshalErr := canonicalizer.MarshalCanonical(leaf)
+		if marshalErr != nil {
+			return nil, fmt.Errorf("marshal MerkleTreeLeaf: %w", marshalErr)
+		}
+
+		s.indexes[id] = len(s.leaves)
+		s.leaves = append(s.leaves, leafInput)
+		s.extraData = append(s.extraData, extraData)
+		s.hashes = append(s.hashes, leafHash(leafInput))
+	}
+
+	signature, err := s.sign(command.CreateVCTimestampSignature(leaf))
+	if err != nil {
+		return nil, err
+	}
+
+	return &command.AddVCResponse{
+		SVCTVersion: command.V1,
+		Timestamp:   leaf.TimestampedEntry.Timestamp,
+		ID:          s.logID[:],
+		Extensions:  base64.StdEncoding.EncodeToString(leaf.TimestampedEntry.Extensions),
+		Signature:   signature,
+	}, nil
+}
+
+// sign signs the canonical form of the data as the log does.
+func (s *Server) sign(v interface{}) ([]byte, error) {
+	data, err := canonicalizer.MarshalCanonical(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal canonical: %w", err)
+	}
+
+	digest := sha256.Sum256(data)
+
+	sig, err := ecdsa.SignASN1(rand.Reader, s.key, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("sign: %w", err)
+	}
+
+	signature, err := json.Marshal(command.DigitallySigned{
+		Algorithm: command.SignatureAndHashAlgorithm{
+			Signature: command.ECDSASignature,
+			Type:      kms.ECDSAP256DER,
+		},
+		Signature: sig,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal DigitallySigned payload: %w", err)
+	}
+
+	return signature, nil
+}
+
+func (s *Server) addVC(w http.ResponseWriter, r *http.Request) {
+	vcEntry, err := readBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	var extensions map[string]string
+
+	if param := r.URL.Query().Get("extensions"); param != "" {
+		if err = json.Unmarshal([]byte(param), &extensions); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("decode extensions: %w", err))
+
+			return
+		}
+	}
+
+	ext, err := command.MarshalExtensions(extensions)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	resp, err := s.add(vcEntry, ext)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func (s *Server) validateVC(w http.ResponseWriter, r *http.Request) {
+	vcEntry, err := readBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if _, _, err = s.validate(vcEntry); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	writeResponse(w, command.ValidateVCResponse{})
+}
+
+func (s *Server) addVP(w http.ResponseWriter, r *http.Request) {
+	vpEntry, err := readBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	resp, err := s.addPresentation(vpEntry)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func (s *Server) addVCBatch(w http.ResponseWriter, r *http.Request) {
+	body, err := readBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	var vcEntries []json.RawMessage
+
+	if err = json.Unmarshal(body, &vcEntries); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("decode credentials: %w", err))
+
+		return
+	}
+
+	if len(vcEntries) == 0 || len(vcEntries) > command.MaxAddVCBatchSize {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("batch size %d is not in range [1, %d]",
+			len(vcEntries), command.MaxAddVCBatchSize))
+
+		return
+	}
+
+	results := make([]*command.AddVCBatchResult, len(vcEntries))
+
+	for i, vcEntry := range vcEntries {
+		resp, addErr := s.add(vcEntry, nil)
+		if addErr != nil {
+			results[i] = &command.AddVCBatchResult{Error: addErr.Error(), StatusCode: http.StatusBadRequest}
+
+			continue
+		}
+
+		results[i] = &command.AddVCBatchResult{Response: resp}
+	}
+
+	writeResponse(w, command.AddVCBatchResponse{Results: results})
+}
+
+func (s *Server) getSTH(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	treeSize := uint64(len(s.hashes))
+	root := rootHash(s.hashes)
+	s.mu.Unlock()
+
+	etag := rest.TreeSizeETag(treeSize)
+
+	w.Header().Set("ETag", etag)
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	timestamp := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+
+	signature, err := s.sign(command.TreeHeadSignature{
+		Version:        command.V1,
+		SignatureType:  command.TreeHeadSignatureType,
+		Timestamp:      timestamp,
+		TreeSize:       treeSize,
+		SHA256RootHash: root,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	writeResponse(w, command.GetSTHResponse{
+		TreeSize:          treeSize,
+		Timestamp:         timestamp,
+		SHA256RootHash:    root,
+		TreeHeadSignature: signature,
+	})
+}
+
+func (s *Server) getSTHConsistency(w http.ResponseWriter, r *http.Request) {
+	first, second, ok := parseRange(w, r, "first", "second")
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if first == 0 || first > second || second > uint64(len(s.hashes)) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid range [%d, %d]", first, second))
+
+		return
+	}
+
+	writeResponse(w, command.GetSTHConsistencyResponse{
+		Consistency: consistencyProof(first, s.hashes[:second]),
+	})
+}
+
+func (s *Server) getProofByHash(w http.ResponseWriter, r *http.Request) {
+	hash, err := base64.StdEncoding.DecodeString(r.URL.Query().Get("hash"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("decode hash: %w", err))
+
+		return
+	}
+
+	treeSize, err := strconv.ParseUint(r.URL.Query().Get("tree_size"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("parse tree_size: %w", err))
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if treeSize > uint64(len(s.hashes)) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("need tree size: %d for proof, got: %d",
+			treeSize, len(s.hashes)))
+
+		return
+	}
+
+	for i, h := range s.hashes[:treeSize] {
+		if bytes.Equal(h, hash) {
+			writeResponse(w, command.GetProofByHashResponse{
+				LeafIndex: int64(i),
+				AuditPath: auditPath(uint64(i), s.hashes[:treeSize]),
+			})
+
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, errors.New("no proof"))
+}
+
+func (s *Server) getEntries(w http.ResponseWriter, r *http.Request) {
+	start, end, ok := parseRange(w, r, "start", "end")
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if start > end || start >= uint64(len(s.leaves)) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid range [%d, %d]", start, end))
+
+		return
+	}
+
+	if end >= uint64(len(s.leaves)) {
+		end = uint64(len(s.leaves)) - 1
+	}
+
+	if end-start+1 > command.MaxGetEntries {
+		end = start + command.MaxGetEntries - 1
+	}
+
+	entries := make([]command.LeafEntry, 0, end+1-start)
+
+	for i := start; i <= end; i++ {
+		entries = append(entries, command.LeafEntry{LeafInput: s.leaves[i], ExtraData: s.extraData[i]})
+	}
+
+	first, last := int64(start), int64(end)
+
+	writeResponse(w, command.GetEntriesResponse{
+		Entries:  entries,
+		Start:    &first,
+		End:      &last,
+		TreeSize: uint64(len(s.leaves)),
+	})
+}
+
+func (s *Server) getEntryAndProof(w http.ResponseWriter, r *http.Request) {
+	leafIndex, treeSize, ok := parseRange(w, r, "leaf_index", "tree_size")
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if leafIndex >= treeSize || treeSize > uint64(len(s.leaves)) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid leaf index %d or tree size %d", leafIndex, treeSize))
+
+		return
+	}
+
+	writeResponse(w, command.GetEntryAndProofResponse{
+		LeafInput: s.leaves[leafIndex],
+		ExtraData: s.extraData[leafIndex],
+		AuditPath: auditPath(leafIndex, s.hashes[:treeSize]),
+	})
+}
+
+func (s *Server) getIssuers(w http.ResponseWriter, _ *http.Request) {
+	issuers := s.issuers
+	if issuers == nil {
+		issuers = []string{}
+	}
+
+	writeResponse(w, issuers)
+}
+
+func (s *Server) getRoots(w http.ResponseWriter, _ *http.Request) {
+	roots := s.roots
+	if roots == nil {
+		roots = [][]byte{}
+	}
+
+	writeResponse(w, command.GetRootsResponse{Certificates: roots})
+}
+
+func (s *Server) getAcceptedContexts(w http.ResponseWriter, _ *http.Request) {
+	lister, ok := s.loader.(command.ContextLister)
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("contexts are not available"))
+
+		return
+	}
+
+	contexts, err := lister.Contexts()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	writeResponse(w, contexts)
+}
+
+func (s *Server) getLogInfo(w http.ResponseWriter, _ *http.Request) {
+	features := []string{
+		command.FeatureAddVCBatch, command.FeatureAddVP, command.FeatureValidateVC, command.FeatureGetRoots,
+	}
+
+	if _, ok := s.loader.(command.ContextLister); ok {
+		features = append(features, command.FeatureGetAcceptedContexts)
+	}
+
+	writeResponse(w, command.LogInfoResponse{
+		HashAlgorithm: command.HashAlgorithm,
+		MaxGetEntries: command.MaxGetEntries,
+		Features:      append(features, command.FeatureGetEntriesRange, command.FeatureAddVCExtensions),
+	})
+}
+
+func (s *Server) webfinger(w http.ResponseWriter, r *http.Request) {
+	resource := r.URL.Query().Get("resource")
+	if resource == "" {
+		writeError(w, http.StatusBadRequest, errors.New("resource is required"))
+
+		return
+	}
+
+	writeResponse(w, command.WebFingerResponse{
+		Subject: resource,
+		Properties: map[string]interface{}{
+			command.PublicKeyType: s.PublicKey,
+			command.LedgerType:    "vct-v1",
+		},
+		Links: []command.WebFingerLink{{Rel: command.SelfRel, Href: resource}},
+	})
+}
+
+func (s *Server) healthCheck(w http.ResponseWriter, _ *http.Request) {
+	now := time.Now()
+
+	writeResponse(w, command.HealthResponse{
+		Status:      command.HealthStatusSuccess,
+		Time:        now,
+		CurrentTime: now.UnixNano() / int64(time.Millisecond),
+	})
+}
+
+func (s *Server) logHealthCheck(w http.ResponseWriter, _ *http.Request) {
+	now := time.Now()
+
+	writeResponse(w, command.HealthResponse{
+		Status:         command.HealthStatusSuccess,
+		TrillianStatus: command.HealthStatusSuccess,
+		Time:           now,
+		CurrentTime:    now.UnixNano() / int64(time.Millisecond),
+		TreeSize:       s.TreeSize(),
+	})
+}
+
+// readBody reads the body of the request, which the client may have gzip compressed.
+func readBody(r *http.Request) ([]byte, error) {
+	var reader io.Reader = r.Body
+
+	if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("gzip reader: %w", err)
+		}
+
+		defer gz.Close() // nolint: errcheck
+
+		reader = gz
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+
+	return body, nil
+}
+
+func parseRange(w http.ResponseWriter, r *http.Request, first, second string) (uint64, uint64, bool) {
+	a, err := strconv.ParseUint(r.URL.Query().Get(first), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("parse %s: %w", first, err))
+
+		return 0, 0, false
+	}
+
+	b, err := strconv.ParseUint(r.URL.Query().Get(second), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("parse %s: %w", second, err))
+
+		return 0, 0, false
+	}
+
+	return a, b, true
+}
+
+func writeResponse(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(v) // nolint: errcheck,gosec
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(rest.ErrorResponse{Message: err.Error()}) // nolint: errcheck,gosec
+}
+
+func contains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,306 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vcttest_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+
+	jsonld "github.com/piprate/json-gold/ld"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/client/vct/vcttest"
+	"github.com/trustbloc/vct/pkg/controller/command"
+	"github.com/trustbloc/vct/pkg/testutil"
+)
+
+const issuer = "did:example:76e12ec712ebc6f1c221ebfeb1f"
+
+type contextLister struct {
+	jsonld.DocumentLoader
+
+	contexts []string
+}
+
+func (l *contextLister) Contexts() ([]string, error) {
+	return l.contexts, nil
+}
+
+func credential(id int, issuerID string) []byte {
+	return []byte(fmt.Sprintf(`{
+  "@context": ["https://www.w3.org/2018/credentials/v1"],
+  "id": "http://example.edu/credentials/%d",
+  "type": ["VerifiableCredential"],
+  "issuer": %q,
+  "issuanceDate": "2010-01-01T19:23:24Z",
+  "credentialSubject": {"id": "did:example:ebfeb1f712ebc6f1c276e12ec21"}
+}`, id, issuerID))
+}
+
+func TestServer(t *testing.T) {
+	t.Run("Add and verify credential", func(t *testing.T) {
+		server := vcttest.NewServer()
+		defer server.Close()
+
+		client := vct.New(server.Endpoint())
+
+		pubKey, err := client.GetPublicKey(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, server.PublicKey, pubKey)
+
+		resp, err := client.AddVC(context.Background(), credential(0, issuer))
+		require.NoError(t, err)
+		require.NoError(t, vct.VerifyVCTimestampSignature(resp.Signature, pubKey, resp.Timestamp,
+			credential(0, issuer), testutil.GetLoader(t)))
+
+		_, err = client.AddVC(context.Background(), credential(1, issuer))
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), server.TreeSize())
+
+		_, err = client.VerifyCredential(context.Background(), pubKey, resp.Timestamp, credential(0, issuer),
+			testutil.GetLoader(t))
+		require.NoError(t, err)
+
+		entries, err := client.GetEntries(context.Background(), 0, 1)
+		require.NoError(t, err)
+		require.Len(t, entries.Entries, 2)
+		require.Equal(t, int64(1), *entries.End)
+		require.Equal(t, uint64(2), entries.TreeSize)
+
+		timestamp, _, err := entries.Entries[0].DecodeTimestampedEntry()
+		require.NoError(t, err)
+		require.Equal(t, resp.Timestamp, timestamp)
+	})
+
+	t.Run("Duplicate credential", func(t *testing.T) {
+		server := vcttest.NewServer()
+		defer server.Close()
+
+		client := vct.New(server.Endpoint())
+
+		first, err := client.AddVC(context.Background(), credential(0, issuer))
+		require.NoError(t, err)
+
+		second, err := client.AddVC(context.Background(), credential(0, issuer))
+		require.NoError(t, err)
+		require.Equal(t, first.Timestamp, second.Timestamp)
+		require.Equal(t, uint64(1), server.TreeSize())
+	})
+
+	t.Run("Consistency", func(t *testing.T) {
+		server := vcttest.NewServer()
+		defer server.Close()
+
+		client := vct.New(server.Endpoint())
+
+		for i := 0; i < 3; i++ {
+			_, err := client.AddVC(context.Background(), credential(i, issuer))
+			require.NoError(t, err)
+		}
+
+		first, err := client.GetSTH(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, vct.VerifySTHSignature(*first, server.PublicKey))
+
+		for i := 3; i < 7; i++ {
+			_, err = client.AddVC(context.Background(), credential(i, issuer))
+			require.NoError(t, err)
+		}
+
+		second, err := client.GetSTH(context.Background())
+		require.NoError(t, err)
+
+		proof, err := client.GetSTHConsistency(context.Background(), first.TreeSize, second.TreeSize)
+		require.NoError(t, err)
+		require.NoError(t, vct.VerifyConsistencyProof(first.TreeSize, second.TreeSize, first.SHA256RootHash,
+			second.SHA256RootHash, proof.Consistency))
+	})
+
+	t.Run("STH if changed", func(t *testing.T) {
+		server := vcttest.NewServer()
+		defer server.Close()
+
+		client := vct.New(server.Endpoint())
+
+		_, err := client.AddVC(context.Background(), credential(0, issuer))
+		require.NoError(t, err)
+
+		sth, changed, err := client.GetSTHIfChanged(context.Background(), 0)
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, uint64(1), sth.TreeSize)
+
+		sth, changed, err = client.GetSTHIfChanged(context.Background(), 1)
+		require.NoError(t, err)
+		require.False(t, changed)
+		require.Nil(t, sth)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		server := vcttest.NewServer()
+		defer server.Close()
+
+		results, err := vct.New(server.Endpoint()).AddVCBatch(context.Background(),
+			[][]byte{credential(0, issuer), []byte(`{}`)})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		require.NotNil(t, results[0].Response)
+		require.NotEmpty(t, results[1].Error)
+		require.ErrorIs(t, vct.BatchResultError(results[1]), vct.ErrBadRequest)
+		require.Equal(t, uint64(1), server.TreeSize())
+	})
+
+	t.Run("Presentation", func(t *testing.T) {
+		server := vcttest.NewServer()
+		defer server.Close()
+
+		client := vct.New(server.Endpoint())
+
+		vp := []byte(fmt.Sprintf(`{
+  "@context": ["https://www.w3.org/2018/credentials/v1"],
+  "type": ["VerifiablePresentation"],
+  "verifiableCredential": [%s]
+}`, credential(0, issuer)))
+
+		resp, err := client.AddVP(context.Background(), vp)
+		require.NoError(t, err)
+
+		_, err = client.AddVC(context.Background(), credential(0, issuer))
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), server.TreeSize())
+
+		entries, err := client.GetEntries(context.Background(), 0, 1)
+		require.NoError(t, err)
+
+		entryType, timestamp, _, err := entries.Entries[0].DecodeTimestampedEntryWithType()
+		require.NoError(t, err)
+		require.Equal(t, command.VPLogEntryType, entryType)
+		require.Equal(t, resp.Timestamp, timestamp)
+
+		entryType, _, _, err = entries.Entries[1].DecodeTimestampedEntryWithType()
+		require.NoError(t, err)
+		require.Equal(t, command.VCLogEntryType, entryType)
+
+		hash, err := vct.CalculateVPLeafHash(resp.Timestamp, vp, testutil.GetLoader(t))
+		require.NoError(t, err)
+
+		sth, err := client.GetSTH(context.Background())
+		require.NoError(t, err)
+
+		_, err = client.GetProofByHash(context.Background(), hash, sth.TreeSize)
+		require.NoError(t, err)
+	})
+
+	t.Run("Issuers", func(t *testing.T) {
+		server := vcttest.NewServer(vcttest.WithAlias("test"), vcttest.WithIssuers(issuer),
+			vcttest.WithRoots([]byte("root")))
+		defer server.Close()
+
+		client := vct.New(server.Endpoint())
+
+		issuers, err := client.GetIssuers(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{issuer}, issuers)
+
+		roots, err := client.GetRoots(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("root")}, roots)
+
+		_, err = client.AddVC(context.Background(), credential(0, "did:example:other"))
+		require.True(t, errors.Is(err, vct.ErrBadRequest))
+	})
+
+	t.Run("Extensions", func(t *testing.T) {
+		server := vcttest.NewServer()
+		defer server.Close()
+
+		client := vct.New(server.Endpoint(), vct.WithVerifySCT(testutil.GetLoader(t)))
+
+		resp, err := client.AddVCWithExtensions(context.Background(), credential(0, issuer),
+			map[string]string{"correlation": "abc"})
+		require.NoError(t, err)
+
+		extensions, err := base64.StdEncoding.DecodeString(resp.Extensions)
+		require.NoError(t, err)
+		require.Equal(t, `{"correlation":"abc"}`, string(extensions))
+
+		hash, err := vct.CalculateLeafHashWithExtensions(resp.Timestamp, credential(0, issuer), extensions,
+			testutil.GetLoader(t))
+		require.NoError(t, err)
+
+		_, err = client.GetProofByHash(context.Background(), hash, server.TreeSize())
+		require.NoError(t, err)
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		server := vcttest.NewServer(vcttest.WithIssuers(issuer))
+		defer server.Close()
+
+		client := vct.New(server.Endpoint())
+
+		require.NoError(t, client.ValidateVC(context.Background(), credential(0, issuer)))
+		require.Equal(t, uint64(0), server.TreeSize())
+
+		err := client.ValidateVC(context.Background(), credential(0, "did:example:other"))
+		require.True(t, errors.Is(err, vct.ErrBadRequest))
+	})
+
+	t.Run("Accepted contexts", func(t *testing.T) {
+		server := vcttest.NewServer(vcttest.WithDocumentLoader(&contextLister{
+			DocumentLoader: testutil.GetLoader(t),
+			contexts:       []string{"https://www.w3.org/2018/credentials/v1"},
+		}))
+		defer server.Close()
+
+		contexts, err := vct.New(server.Endpoint()).GetAcceptedContexts(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{"https://www.w3.org/2018/credentials/v1"}, contexts)
+
+		other := vcttest.NewServer(vcttest.WithDocumentLoader(testutil.GetLoader(t)))
+		defer other.Close()
+
+		_, err = vct.New(other.Endpoint()).GetAcceptedContexts(context.Background())
+		require.True(t, errors.Is(err, vct.ErrNotFound))
+	})
+
+	t.Run("Log info", func(t *testing.T) {
+		server := vcttest.NewServer(vcttest.WithDocumentLoader(testutil.GetLoader(t)))
+		defer server.Close()
+
+		info, err := vct.New(server.Endpoint()).GetLogInfo(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, command.HashAlgorithm, info.HashAlgorithm)
+		require.Equal(t, int64(command.MaxGetEntries), info.MaxGetEntries)
+		require.True(t, info.HasFeature(command.FeatureAddVCBatch))
+		require.False(t, info.HasFeature(command.FeatureGetAcceptedContexts))
+	})
+
+	t.Run("Health", func(t *testing.T) {
+		server := vcttest.NewServer()
+		defer server.Close()
+
+		client := vct.New(server.Endpoint())
+
+		health, err := client.HealthStatus(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), health.TreeSize)
+
+		_, err = client.AddVC(context.Background(), credential(0, issuer))
+		require.NoError(t, err)
+
+		health, err = client.HealthStatus(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, command.HealthStatusSuccess, health.TrillianStatus)
+		require.Equal(t, uint64(1), health.TreeSize)
+
+		require.NoError(t, client.HealthCheck(context.Background()))
+	})
+}
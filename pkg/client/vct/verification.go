@@ -0,0 +1,328 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	jsonld "github.com/piprate/json-gold/ld"
+
+	"github.com/trustbloc/vct/pkg/canonicalizer"
+	"github.com/trustbloc/vct/pkg/controller/command"
+)
+
+// Verification checks.
+const (
+	// CheckSTHSignature checks the signature of the signed tree head.
+	CheckSTHSignature = "sth_signature"
+	// CheckInclusion checks the inclusion of the credential in the tree.
+	CheckInclusion = "inclusion"
+	// CheckIssuer checks that the issuer of the credential is in the issuer allowlist.
+	CheckIssuer = "issuer"
+	// CheckSCTSignature checks the signature of the timestamp returned by the log for an added credential.
+	CheckSCTSignature = "sct_signature"
+	// CheckConsistency checks that a signed tree head is consistent with a previously verified one.
+	CheckConsistency = "consistency"
+)
+
+var (
+	// ErrNotSequenced is returned by VerifyInclusionByCredential if the credential is not included in the tree of
+	// the latest signed tree head, e.g. because the log has not sequenced it yet. Verifying it later may succeed.
+	ErrNotSequenced = errors.New("not yet sequenced")
+	// ErrInvalidProof is returned by VerifyInclusionByCredential if the inclusion proof of the credential does not
+	// verify against the root hash of the signed tree head.
+	ErrInvalidProof = errors.New("invalid inclusion proof")
+)
+
+// VerificationResult represents the outcome of verifying a credential against a log.
+type VerificationResult struct {
+	// CredentialID is the ID of the verified credential.
+	CredentialID string
+	// Endpoint is the log endpoint the credential was verified against.
+	Endpoint string
+	// LeafHash is the Merkle leaf hash of the credential.
+	LeafHash []byte
+	// TreeSize is the size of the tree the credential was verified against.
+	TreeSize uint64
+	// RootHash is the root hash of the signed tree head the credential was verified against.
+	RootHash []byte
+	// Checks contains the individual checks that were performed.
+	Checks []CheckResult
+}
+
+// CheckResult represents the outcome of a single verification check.
+type CheckResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Passed returns true if every check has passed.
+func (r *VerificationResult) Passed() bool {
+	if len(r.Checks) == 0 {
+		return false
+	}
+
+	for _, check := range r.Checks {
+		if !check.Passed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *VerificationResult) check(name string, err error) error {
+	if err != nil {
+		r.Checks = append(r.Checks, CheckResult{Name: name, Error: err.Error()})
+
+		return &VerificationError{Check: name, Err: err}
+	}
+
+	r.Checks = append(r.Checks, CheckResult{Name: name, Passed: true})
+
+	return nil
+}
+
+// VerifyCredential verifies end-to-end that the credential logged at the given timestamp is included in the log:
+// the latest signed tree head is verified with the log public key, and the inclusion proof of the credential
+// leaf is verified against it. If an issuer allowlist is configured, the issuer of the credential is checked
+// against it as well. If a check fails, the result is returned together with a VerificationError.
+func (c *Client) VerifyCredential(ctx context.Context, pubKey []byte, timestamp uint64, vcBytes []byte,
+	loader jsonld.DocumentLoader) (*VerificationResult, error) {
+	leafHash, err := calculateLeafHash(c.leafHasher, timestamp, vcBytes, loader)
+	if err != nil {
+		return nil, fmt.Errorf("calculate leaf hash: %w", err)
+	}
+
+	sth, err := c.GetSTH(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if sth == nil {
+		return nil, fmt.Errorf("get STH: %w", &DecodeError{Field: "body", Err: errors.New("empty response")})
+	}
+
+	result := &VerificationResult{
+		CredentialID: credentialID(vcBytes),
+		Endpoint:     c.endpoint,
+		LeafHash:     leafHash,
+		TreeSize:     sth.TreeSize,
+		RootHash:     sth.SHA256RootHash,
+	}
+
+	if err = result.check(CheckSTHSignature, verifySTHSignature(sth, pubKey)); err != nil {
+		return result, err
+	}
+
+	if err = result.check(CheckInclusion, c.checkInclusion(ctx, leafHash, sth)); err != nil {
+		return result, err
+	}
+
+	if c.issuerAllowlist == nil && !c.issuerAllowlistFromLog {
+		return result, nil
+	}
+
+	allowlist, err := c.allowedIssuers(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	return result, result.check(CheckIssuer, checkIssuer(vcBytes, allowlist))
+}
+
+// VerifyInclusionByCredential verifies that the credential, e.g. just added with AddVC, has been incorporated into
+// the log at the given timestamp, i.e. the timestamp of its SCT: the latest signed tree head is verified with the
+// log public key, see PublicKey, and the inclusion proof of the credential leaf is verified against it.
+// It returns an error wrapping ErrNotSequenced if the log does not include the credential yet, and a
+// VerificationError wrapping ErrInvalidProof if the inclusion proof is invalid.
+func (c *Client) VerifyInclusionByCredential(ctx context.Context, timestamp uint64, credential []byte,
+	loader jsonld.DocumentLoader) error {
+	leafHash, err := calculateLeafHash(c.leafHasher, timestamp, credential, loader)
+	if err != nil {
+		return fmt.Errorf("verify inclusion: calculate leaf hash: %w", err)
+	}
+
+	sth, err := c.GetSTH(ctx)
+	if err != nil {
+		return fmt.Errorf("verify inclusion: %w", err)
+	}
+
+	if sth == nil {
+		return fmt.Errorf("verify inclusion: get STH: %w", &DecodeError{
+			Field: "body",
+			Err:   errors.New("empty response"),
+		})
+	}
+
+	pubKey, err := c.PublicKey(ctx)
+	if err != nil {
+		return fmt.Errorf("verify inclusion: %w", err)
+	}
+
+	if err = verifySTHSignature(sth, pubKey); err != nil {
+		return fmt.Errorf("verify inclusion: %w", &VerificationError{Check: CheckSTHSignature, Err: err})
+	}
+
+	if sth.TreeSize == 0 {
+		return fmt.Errorf("verify inclusion: %w: tree is empty", ErrNotSequenced)
+	}
+
+	proof, err := c.GetProofByHash(ctx, base64.StdEncoding.EncodeToString(leafHash), sth.TreeSize)
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("verify inclusion: %w: %v", ErrNotSequenced, err)
+	}
+
+	if err != nil {
+		return fmt.Errorf("verify inclusion: %w", err)
+	}
+
+	if proof.LeafIndex < 0 {
+		err = fmt.Errorf("%w: invalid leaf index %d", ErrInvalidProof, proof.LeafIndex)
+	} else if err = VerifyInclusionProof(leafHash, uint64(proof.LeafIndex), sth.TreeSize, proof.AuditPath,
+		sth.SHA256RootHash); err != nil {
+		err = fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+
+	if err != nil {
+		return fmt.Errorf("verify inclusion: %w", &VerificationError{Check: CheckInclusion, Err: err})
+	}
+
+	return nil
+}
+
+// allowedIssuers returns the issuer allowlist. A nil allowlist means that any issuer is accepted.
+func (c *Client) allowedIssuers(ctx context.Context) ([]string, error) {
+	if !c.issuerAllowlistFromLog {
+		return c.issuerAllowlist, nil
+	}
+
+	issuers, err := c.GetIssuers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(issuers) == 0 {
+		return c.issuerAllowlist, nil
+	}
+
+	return append(append([]string{}, c.issuerAllowlist...), issuers...), nil
+}
+
+func checkIssuer(vcBytes []byte, allowlist []string) error {
+	if allowlist == nil {
+		return nil
+	}
+
+	issuer, err := credentialIssuer(vcBytes)
+	if err != nil {
+		return err
+	}
+
+	for _, allowed := range allowlist {
+		if allowed == issuer {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("issuer %s is not in the allowlist", issuer)
+}
+
+func (c *Client) checkInclusion(ctx context.Context, leafHash []byte, sth *command.GetSTHResponse) error {
+	if sth.TreeSize == 0 {
+		return errors.New("tree is empty")
+	}
+
+	proof, err := c.GetProofByHash(ctx, base64.StdEncoding.EncodeToString(leafHash), sth.TreeSize)
+	if err != nil {
+		return err
+	}
+
+	if proof.LeafIndex < 0 {
+		return fmt.Errorf("invalid leaf index %d", proof.LeafIndex)
+	}
+
+	return VerifyInclusionProof(leafHash, uint64(proof.LeafIndex), sth.TreeSize, proof.AuditPath, sth.SHA256RootHash)
+}
+
+// VerifySTHSignature verifies the tree head signature of the signed tree head with the log public key, e.g. the
+// key returned by GetPublicKey. The signed data is reconstructed from the version, tree size, timestamp and root
+// hash of the signed tree head the same way the log signs it. A root hash should not be trusted for inclusion or
+// consistency checks before its signed tree head is verified.
+func VerifySTHSignature(sth command.GetSTHResponse, pubKey []byte) error {
+	return verifySTHSignature(&sth, pubKey)
+}
+
+// verifySTHSignature verifies the tree head signature of the STH with the log public key.
+func verifySTHSignature(sth *command.GetSTHResponse, pubKey []byte) error {
+	var sig *command.DigitallySigned
+
+	if err := json.Unmarshal(sth.TreeHeadSignature, &sig); err != nil {
+		return fmt.Errorf("unmarshal tree head signature: %w", err)
+	}
+
+	if sig == nil {
+		return errors.New("tree head signature is empty")
+	}
+
+	data, err := canonicalizer.MarshalCanonical(command.TreeHeadSignature{
+		Version:        command.V1,
+		SignatureType:  command.TreeHeadSignatureType,
+		Timestamp:      sth.Timestamp,
+		TreeSize:       sth.TreeSize,
+		SHA256RootHash: sth.SHA256RootHash,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal tree head signature: %w", err)
+	}
+
+	return verifySignature(sig, pubKey, data)
+}
+
+func credentialID(vcBytes []byte) string {
+	var vc struct {
+		ID string `json:"id"`
+	}
+
+	if err := json.Unmarshal(vcBytes, &vc); err != nil {
+		return ""
+	}
+
+	return vc.ID
+}
+
+// credentialIssuer returns the ID of the credential issuer, which is either a string or an object with an id.
+func credentialIssuer(vcBytes []byte) (string, error) {
+	var vc struct {
+		Issuer json.RawMessage `json:"issuer"`
+	}
+
+	if err := json.Unmarshal(vcBytes, &vc); err != nil {
+		return "", fmt.Errorf("unmarshal credential: %w", err)
+	}
+
+	var id string
+
+	if err := json.Unmarshal(vc.Issuer, &id); err == nil && id != "" {
+		return id, nil
+	}
+
+	var issuer struct {
+		ID string `json:"id"`
+	}
+
+	if err := json.Unmarshal(vc.Issuer, &issuer); err == nil && issuer.ID != "" {
+		return issuer.ID, nil
+	}
+
+	return "", errors.New("credential has no issuer")
+}
@@ -0,0 +1,331 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vct_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/testutil"
+)
+
+func TestClient_VerifyCredential(t *testing.T) {
+	otherVC, err := json.Marshal(simpleVC)
+	require.NoError(t, err)
+
+	log := newFakeLog(t)
+	log.addCredential(loggedAt-1, otherVC)
+	log.addCredential(loggedAt, vcBachelorDegree)
+
+	t.Run("Success", func(t *testing.T) {
+		result, err := log.client().VerifyCredential(context.Background(), log.pubKey, loggedAt, vcBachelorDegree,
+			testutil.GetLoader(t))
+		require.NoError(t, err)
+		require.True(t, result.Passed())
+		require.Equal(t, "http://example.gov/credentials/789012", result.CredentialID)
+		require.Equal(t, log.endpoint(), result.Endpoint)
+		require.Equal(t, uint64(2), result.TreeSize)
+		require.NotEmpty(t, result.RootHash)
+		require.NotEmpty(t, result.LeafHash)
+		require.Equal(t, []vct.CheckResult{
+			{Name: vct.CheckSTHSignature, Passed: true},
+			{Name: vct.CheckInclusion, Passed: true},
+		}, result.Checks)
+	})
+
+	t.Run("Wrong timestamp", func(t *testing.T) {
+		result, err := log.client().VerifyCredential(context.Background(), log.pubKey, loggedAt+1, vcBachelorDegree,
+			testutil.GetLoader(t))
+		require.Error(t, err)
+		require.False(t, result.Passed())
+		require.Len(t, result.Checks, 2)
+		require.True(t, result.Checks[0].Passed)
+		require.False(t, result.Checks[1].Passed)
+		require.Contains(t, result.Checks[1].Error, "leaf not found")
+
+		var verificationErr *vct.VerificationError
+		require.True(t, errors.As(err, &verificationErr))
+		require.Equal(t, vct.CheckInclusion, verificationErr.Check)
+	})
+
+	t.Run("Empty log", func(t *testing.T) {
+		empty := newFakeLog(t)
+
+		result, err := empty.client().VerifyCredential(context.Background(), empty.pubKey, loggedAt,
+			vcBachelorDegree, testutil.GetLoader(t))
+		require.EqualError(t, err, "inclusion check failed: tree is empty")
+		require.False(t, result.Passed())
+	})
+
+	t.Run("Invalid credential", func(t *testing.T) {
+		_, err := log.client().VerifyCredential(context.Background(), log.pubKey, loggedAt, []byte(`[]`),
+			testutil.GetLoader(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "calculate leaf hash: create leaf")
+	})
+
+	t.Run("Get STH error", func(t *testing.T) {
+		_, err := vct.New("http://127.0.0.1:0/maple2020").VerifyCredential(context.Background(), log.pubKey,
+			loggedAt, vcBachelorDegree, testutil.GetLoader(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get STH")
+	})
+
+	t.Run("Empty STH", func(t *testing.T) {
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`null`)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		_, err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).VerifyCredential(context.Background(),
+			log.pubKey, loggedAt, vcBachelorDegree, testutil.GetLoader(t))
+		require.EqualError(t, err, "get STH: decode body: empty response")
+	})
+}
+
+func TestClient_VerifyCredential_IssuerAllowlist(t *testing.T) {
+	const (
+		issuer        = "did:key:zUC724vuGvHpnCGFG1qqpXb81SiBLu3KLSqVzenwEZNPoY35i2Bscb8DLaVwHvRFs6F2NkNNXRcPWvqnPDUd9ukdjLkjZd3u9zzL4wDZDUpkPAatLDGLEYVo8kkAzuAKJQMr7N7" // nolint: lll
+		unknownIssuer = "did:example:unknown"
+	)
+
+	log := newFakeLog(t)
+	log.addCredential(loggedAt, vcBachelorDegree)
+
+	verify := func(t *testing.T, opts ...vct.ClientOpt) (*vct.VerificationResult, error) {
+		t.Helper()
+
+		return log.client(opts...).VerifyCredential(context.Background(), log.pubKey, loggedAt, vcBachelorDegree,
+			testutil.GetLoader(t))
+	}
+
+	t.Run("Allowed issuer", func(t *testing.T) {
+		result, err := verify(t, vct.WithIssuerAllowlist([]string{unknownIssuer, issuer}))
+		require.NoError(t, err)
+		require.True(t, result.Passed())
+		require.Equal(t, vct.CheckResult{Name: vct.CheckIssuer, Passed: true}, result.Checks[2])
+	})
+
+	t.Run("Unknown issuer", func(t *testing.T) {
+		result, err := verify(t, vct.WithIssuerAllowlist([]string{unknownIssuer}))
+		require.EqualError(t, err, "issuer check failed: issuer "+issuer+" is not in the allowlist")
+		require.False(t, result.Passed())
+		require.Len(t, result.Checks, 3)
+		require.Equal(t, vct.CheckIssuer, result.Checks[2].Name)
+	})
+
+	t.Run("Allowlist from log", func(t *testing.T) {
+		log.setIssuers(issuer)
+
+		result, err := verify(t, vct.WithIssuerAllowlistFromLog())
+		require.NoError(t, err)
+		require.True(t, result.Passed())
+		require.Len(t, result.Checks, 3)
+	})
+
+	t.Run("Issuer revoked by log", func(t *testing.T) {
+		log.setIssuers(unknownIssuer)
+
+		_, err := verify(t, vct.WithIssuerAllowlistFromLog())
+
+		var verificationErr *vct.VerificationError
+		require.True(t, errors.As(err, &verificationErr))
+		require.Equal(t, vct.CheckIssuer, verificationErr.Check)
+	})
+
+	t.Run("Log accepts any issuer", func(t *testing.T) {
+		log.setIssuers()
+
+		result, err := verify(t, vct.WithIssuerAllowlistFromLog())
+		require.NoError(t, err)
+		require.True(t, result.Passed())
+
+		_, err = verify(t, vct.WithIssuerAllowlistFromLog(), vct.WithIssuerAllowlist([]string{unknownIssuer}))
+		require.Error(t, err)
+	})
+
+	t.Run("Issuer object", func(t *testing.T) {
+		var vc map[string]interface{}
+		require.NoError(t, json.Unmarshal(vcBachelorDegree, &vc))
+
+		vc["issuer"] = map[string]interface{}{"id": issuer, "name": "Example University"}
+
+		vcBytes, err := json.Marshal(vc)
+		require.NoError(t, err)
+
+		log.addCredential(loggedAt+1, vcBytes)
+
+		result, err := log.client(vct.WithIssuerAllowlist([]string{issuer})).VerifyCredential(context.Background(),
+			log.pubKey, loggedAt+1, vcBytes, testutil.GetLoader(t))
+		require.NoError(t, err)
+		require.True(t, result.Passed())
+	})
+
+	t.Run("No allowlist", func(t *testing.T) {
+		result, err := verify(t)
+		require.NoError(t, err)
+		require.Len(t, result.Checks, 2)
+	})
+
+	t.Run("Get issuers error", func(t *testing.T) {
+		_, err := vct.New(log.endpoint(), vct.WithIssuerAllowlistFromLog(), vct.WithHTTPClient(getIssuersFailure{})).
+			VerifyCredential(context.Background(), log.pubKey, loggedAt, vcBachelorDegree, testutil.GetLoader(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get issuers")
+	})
+}
+
+type getIssuersFailure struct{}
+
+func (getIssuersFailure) Do(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, "/get-issuers") {
+		return nil, errors.New("connection reset")
+	}
+
+	return http.DefaultClient.Do(req)
+}
+
+func TestVerificationResult_Passed(t *testing.T) {
+	require.False(t, (&vct.VerificationResult{}).Passed())
+	require.True(t, (&vct.VerificationResult{Checks: []vct.CheckResult{{Passed: true}}}).Passed())
+	require.False(t, (&vct.VerificationResult{Checks: []vct.CheckResult{{Passed: true}, {}}}).Passed())
+}
+
+func TestVerifySTHSignature(t *testing.T) {
+	log := newSampledLog(t, 3)
+
+	sth, err := log.client().GetSTH(context.Background())
+	require.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		require.NoError(t, vct.VerifySTHSignature(*sth, log.pubKey))
+	})
+
+	t.Run("Tampered root hash", func(t *testing.T) {
+		tampered := *sth
+		tampered.SHA256RootHash = append([]byte{}, sth.SHA256RootHash...)
+		tampered.SHA256RootHash[0] ^= 1
+
+		require.Error(t, vct.VerifySTHSignature(tampered, log.pubKey))
+	})
+
+	t.Run("Tampered tree size", func(t *testing.T) {
+		tampered := *sth
+		tampered.TreeSize++
+
+		require.Error(t, vct.VerifySTHSignature(tampered, log.pubKey))
+	})
+
+	t.Run("Other public key", func(t *testing.T) {
+		require.Error(t, vct.VerifySTHSignature(*sth, newFakeLog(t).pubKey))
+	})
+
+	t.Run("No signature", func(t *testing.T) {
+		noSignature := *sth
+		noSignature.TreeHeadSignature = []byte("null")
+
+		require.EqualError(t, vct.VerifySTHSignature(noSignature, log.pubKey), "tree head signature is empty")
+	})
+
+	t.Run("Malformed signature", func(t *testing.T) {
+		malformed := *sth
+		malformed.TreeHeadSignature = []byte("{")
+
+		err := vct.VerifySTHSignature(malformed, log.pubKey)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal tree head signature")
+	})
+}
+
+func TestClient_VerifyInclusionByCredential(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		log := newFakeLog(t)
+		client := log.client()
+
+		resp, err := client.AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+
+		require.NoError(t, client.VerifyInclusionByCredential(context.Background(), resp.Timestamp, vcBachelorDegree,
+			testutil.GetLoader(t)))
+	})
+
+	t.Run("Not sequenced", func(t *testing.T) {
+		log := newFakeLog(t)
+		client := log.client()
+
+		otherVC, err := json.Marshal(simpleVC)
+		require.NoError(t, err)
+
+		_, err = client.AddVC(context.Background(), otherVC)
+		require.NoError(t, err)
+
+		resp, err := client.AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+
+		log.lag(1)
+
+		err = client.VerifyInclusionByCredential(context.Background(), resp.Timestamp, vcBachelorDegree,
+			testutil.GetLoader(t))
+		require.ErrorIs(t, err, vct.ErrNotSequenced)
+		require.NotErrorIs(t, err, vct.ErrInvalidProof)
+
+		empty := newFakeLog(t)
+
+		err = empty.client().VerifyInclusionByCredential(context.Background(), loggedAt, vcBachelorDegree,
+			testutil.GetLoader(t))
+		require.EqualError(t, err, "verify inclusion: not yet sequenced: tree is empty")
+	})
+
+	t.Run("Invalid proof", func(t *testing.T) {
+		log := newFakeLog(t)
+		client := log.client()
+
+		resp, err := client.AddVC(context.Background(), vcBachelorDegree)
+		require.NoError(t, err)
+
+		log.forgeRoot(make([]byte, 32))
+
+		err = client.VerifyInclusionByCredential(context.Background(), resp.Timestamp, vcBachelorDegree,
+			testutil.GetLoader(t))
+		require.ErrorIs(t, err, vct.ErrInvalidProof)
+		require.NotErrorIs(t, err, vct.ErrNotSequenced)
+
+		var verificationErr *vct.VerificationError
+		require.True(t, errors.As(err, &verificationErr))
+		require.Equal(t, vct.CheckInclusion, verificationErr.Check)
+	})
+
+	t.Run("Invalid credential", func(t *testing.T) {
+		err := newFakeLog(t).client().VerifyInclusionByCredential(context.Background(), loggedAt, []byte(`[]`),
+			testutil.GetLoader(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verify inclusion: calculate leaf hash")
+	})
+
+	t.Run("Empty STH", func(t *testing.T) {
+		httpClient := NewMockHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`null`)),
+			StatusCode: http.StatusOK,
+		}, nil)
+
+		err := vct.New(endpoint, vct.WithHTTPClient(httpClient)).VerifyInclusionByCredential(context.Background(),
+			loggedAt, vcBachelorDegree, testutil.GetLoader(t))
+		require.EqualError(t, err, "verify inclusion: get STH: decode body: empty response")
+	})
+}
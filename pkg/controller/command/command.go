@@ -13,14 +13,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/url"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/types"
-	ariesjsonld "github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
@@ -32,14 +33,47 @@ import (
 
 // Command methods.
 const (
-	GetSTH            = "getSTH"
-	GetSTHConsistency = "getSTHConsistency"
-	GetEntries        = "getEntries"
-	GetProofByHash    = "getProofByHash"
-	GetEntryAndProof  = "getEntryAndProof"
-	GetIssuers        = "getIssuers"
-	Webfinger         = "webfinger"
-	AddVC             = "addVC"
+	GetSTH              = "getSTH"
+	GetSTHConsistency   = "getSTHConsistency"
+	GetEntries          = "getEntries"
+	GetProofByHash      = "getProofByHash"
+	GetEntryAndProof    = "getEntryAndProof"
+	GetIssuers          = "getIssuers"
+	GetRoots            = "getRoots"
+	GetAcceptedContexts = "getAcceptedContexts"
+	GetLogInfo          = "getLogInfo"
+	Webfinger           = "webfinger"
+	AddVC               = "addVC"
+	AddVCBatch          = "addVCBatch"
+	AddVP               = "addVP"
+	ValidateVC          = "validateVC"
+)
+
+// MaxAddVCBatchSize is the maximum number of credentials in an add-vc-batch request.
+const MaxAddVCBatchSize = 1000
+
+// MaxGetEntries is the maximum number of entries returned by a get-entries request, a larger range is truncated.
+const MaxGetEntries = 1000
+
+// HashAlgorithm is the hash algorithm of the Merkle tree of the log (RFC 6962).
+const HashAlgorithm = "SHA-256"
+
+// Features of the log, reported by GetLogInfo.
+const (
+	// FeatureAddVCBatch is set if the log serves add-vc-batch.
+	FeatureAddVCBatch = "add-vc-batch"
+	// FeatureAddVP is set if the log serves add-vp.
+	FeatureAddVP = "add-vp"
+	// FeatureValidateVC is set if the log serves validate-vc.
+	FeatureValidateVC = "validate-vc"
+	// FeatureGetRoots is set if the log serves get-roots.
+	FeatureGetRoots = "get-roots"
+	// FeatureGetAcceptedContexts is set if the log can list the contexts it accepts with get-accepted-contexts.
+	FeatureGetAcceptedContexts = "get-accepted-contexts"
+	// FeatureGetEntriesRange is set if get-entries returns the served range and the tree size.
+	FeatureGetEntriesRange = "get-entries-range"
+	// FeatureAddVCExtensions is set if add-vc records the extensions of the request in the entry.
+	FeatureAddVCExtensions = "add-vc-extensions"
 )
 
 const (
@@ -47,6 +81,8 @@ const (
 	PublicKeyType = "https://trustbloc.dev/ns/public-key"
 	// LedgerType is the ledger type property in the Webfinger document.
 	LedgerType = "https://trustbloc.dev/ns/ledger-type"
+	// SelfRel is the relation of the link to the log itself in the Webfinger document.
+	SelfRel = "self"
 
 	ldProofField = "proof"
 
@@ -73,6 +109,8 @@ type Cmd struct {
 	PubKey  []byte
 	alg     *SignatureAndHashAlgorithm
 	loaders map[string]jsonld.DocumentLoader
+	policy  AdmissionPolicy
+	version string
 }
 
 type permission int32
@@ -90,7 +128,10 @@ type Log struct {
 	Permission string
 	Endpoint   string
 	Issuers    []string
-	Client     TrillianLogClient
+	// Roots are the trust anchors of the issuers the log accepts, e.g. DER encoded certificates or DID
+	// documents. They are advertised to submitters with get-roots; credentials are checked against Issuers.
+	Roots  [][]byte
+	Client TrillianLogClient
 }
 
 // Config for the Cmd.
@@ -102,6 +143,32 @@ type Config struct {
 	DocumentLoaders map[string]jsonld.DocumentLoader // alias -> loader
 	Key             Key
 	BaseURL         string
+	AdmissionPolicy AdmissionPolicy // nil -> AllowAllPolicy
+	Version         string          // version of the software, reported by GetLogInfo
+}
+
+// AdmissionPolicy decides whether a credential is admitted to a log, in addition to the issuers of the log, e.g. to
+// reject expired credentials or to enforce a schema. Admit is called with the credential as submitted, after it is
+// parsed and its proofs checked, by AddVC, AddVCBatch and ValidateVC, and for each credential of a presentation
+// by AddVP. A credential Admit returns an error for is rejected with a bad request error. Admit must be safe for
+// concurrent use.
+type AdmissionPolicy interface {
+	Admit(vc []byte) error
+}
+
+// AllowAllPolicy is the AdmissionPolicy that admits all credentials.
+type AllowAllPolicy struct{}
+
+// Admit admits the credential.
+func (AllowAllPolicy) Admit([]byte) error {
+	return nil
+}
+
+// ContextLister is implemented by the document loaders of the logs that can list the JSON-LD contexts they are
+// provisioned with, see GetAcceptedContexts.
+type ContextLister interface {
+	// Contexts returns the URLs of the JSON-LD contexts.
+	Contexts() ([]string, error)
 }
 
 // KeyManager key manager.
@@ -164,6 +231,11 @@ func New(cfg *Config, mf monitoring.MetricFactory) (*Cmd, error) {
 		logs[log.Alias] = log
 	}
 
+	policy := cfg.AdmissionPolicy
+	if policy == nil {
+		policy = AllowAllPolicy{}
+	}
+
 	return &Cmd{
 		vdr:     cfg.VDR,
 		PubKey:  pubBytes,
@@ -175,6 +247,8 @@ func New(cfg *Config, mf monitoring.MetricFactory) (*Cmd, error) {
 		alg:     alg,
 		baseURL: baseURL,
 		loaders: cfg.DocumentLoaders,
+		policy:  policy,
+		version: cfg.Version,
 	}, nil
 }
 
@@ -187,8 +261,14 @@ func (c *Cmd) GetHandlers() []Handler {
 		NewCmdHandler(GetProofByHash, c.GetProofByHash),
 		NewCmdHandler(GetEntryAndProof, c.GetEntryAndProof),
 		NewCmdHandler(GetIssuers, c.GetIssuers),
+		NewCmdHandler(GetRoots, c.GetRoots),
+		NewCmdHandler(GetAcceptedContexts, c.GetAcceptedContexts),
+		NewCmdHandler(GetLogInfo, c.GetLogInfo),
 		NewCmdHandler(Webfinger, c.Webfinger),
 		NewCmdHandler(AddVC, c.AddVC),
+		NewCmdHandler(AddVCBatch, c.AddVCBatch),
+		NewCmdHandler(AddVP, c.AddVP),
+		NewCmdHandler(ValidateVC, c.ValidateVC),
 	}
 }
 
@@ -207,6 +287,26 @@ func (c *Cmd) GetIssuers(w io.Writer, r io.Reader) error {
 	return json.NewEncoder(w).Encode(c.logs[alias].Issuers) // nolint: wrapcheck
 }
 
+// GetRoots returns the trust anchors of the issuers the log accepts.
+func (c *Cmd) GetRoots(w io.Writer, r io.Reader) error {
+	var alias string
+
+	if err := json.NewDecoder(r).Decode(&alias); err != nil {
+		return fmt.Errorf("%w: decode alias failed", errors.ErrInternal)
+	}
+
+	if err := c.hasPermissions(alias, read); err != nil {
+		return fmt.Errorf("has permissions: %w", err)
+	}
+
+	roots := c.logs[alias].Roots
+	if roots == nil {
+		roots = [][]byte{}
+	}
+
+	return json.NewEncoder(w).Encode(GetRootsResponse{Certificates: roots}) // nolint: wrapcheck
+}
+
 // Webfinger returns discovery info.
 func (c *Cmd) Webfinger(w io.Writer, r io.Reader) error {
 	var resourceID string
@@ -248,37 +348,100 @@ func (c *Cmd) Webfinger(w io.Writer, r io.Reader) error {
 			LedgerType:    vctV1,
 		},
 		Links: []WebFingerLink{
-			{Rel: "self", Href: resourceID},
+			{Rel: SelfRel, Href: resourceID},
 		},
 	}) // nolint: wrapcheck
 }
 
-// CreateLeaf creates MerkleTreeLeaf.
+// CreateLeaf creates MerkleTreeLeaf. A credential with duplicate JSON keys is rejected with an error wrapping
+// canonicalizer.ErrDuplicateKey, since its canonical form would depend on the JSON parser.
 func CreateLeaf(timestamp uint64, vcBytes []byte, loader jsonld.DocumentLoader) (*MerkleTreeLeaf, error) {
-	var vcDoc map[string]interface{}
+	vcEntry, err := canonicalEntry("VC", vcBytes, loader)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLeaf(timestamp, vcEntry), nil
+}
+
+// CreateVPLeaf creates MerkleTreeLeaf of the verifiable presentation. Like the credential of CreateLeaf, the
+// presentation is stored in the URDNA2015 canonical form without its proof. The credentials embedded in the
+// presentation keep their proofs, since they are part of what was presented.
+func CreateVPLeaf(timestamp uint64, vpBytes []byte, loader jsonld.DocumentLoader) (*MerkleTreeLeaf, error) {
+	vpEntry, err := canonicalEntry("VP", vpBytes, loader)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewVPLeaf(timestamp, vpEntry), nil
+}
+
+// canonicalEntry returns the URDNA2015 canonical form of the credential or presentation without its proof.
+func canonicalEntry(kind string, data []byte, loader jsonld.DocumentLoader) ([]byte, error) {
+	if err := canonicalizer.CheckDuplicateKeys(data); err != nil {
+		return nil, fmt.Errorf("check %s: %w", kind, err)
+	}
 
-	err := json.Unmarshal(vcBytes, &vcDoc)
+	var doc map[string]interface{}
+
+	err := json.Unmarshal(data, &doc)
 	if err != nil {
-		return nil, fmt.Errorf("unmarshal VC to document: %w", err)
+		return nil, fmt.Errorf("unmarshal %s to document: %w", kind, err)
 	}
 
-	vcDoc[ldProofField] = nil
+	doc[ldProofField] = nil
 
-	canonicalBytes, err := ariesjsonld.NewProcessor("").GetCanonicalDocument(vcDoc,
-		ariesjsonld.WithDocumentLoader(loader))
+	canonicalBytes, err := canonicalizer.MarshalCanonicalWith(doc, canonicalizer.URDNA2015,
+		canonicalizer.WithDocumentLoader(loader))
 	if err != nil {
 		return nil, fmt.Errorf("marshal canonical: %w", err)
 	}
 
+	return canonicalBytes, nil
+}
+
+// NewLeaf creates MerkleTreeLeaf of the credential in canonical form, as returned by CreateLeaf in the
+// timestamped entry. The canonical credential is used as is.
+func NewLeaf(timestamp uint64, vcEntry []byte) *MerkleTreeLeaf {
+	return newLeaf(VCLogEntryType, timestamp, vcEntry)
+}
+
+// NewVPLeaf creates MerkleTreeLeaf of the presentation in canonical form, as returned by CreateVPLeaf in the
+// timestamped entry. The canonical presentation is used as is.
+func NewVPLeaf(timestamp uint64, vpEntry []byte) *MerkleTreeLeaf {
+	return newLeaf(VPLogEntryType, timestamp, vpEntry)
+}
+
+func newLeaf(entryType LogEntryType, timestamp uint64, entry []byte) *MerkleTreeLeaf {
 	return &MerkleTreeLeaf{
 		Version:  V1,
 		LeafType: TimestampedEntryLeafType,
 		TimestampedEntry: &TimestampedEntry{
-			EntryType: VCLogEntryType,
+			EntryType: entryType,
 			Timestamp: timestamp,
-			VCEntry:   canonicalBytes,
+			VCEntry:   entry,
 		},
-	}, nil
+	}
+}
+
+// MarshalExtensions returns the extensions of a timestamped entry as the log records them: the JCS (RFC 8785)
+// canonical JSON object of the extensions, or nil if there are none. The extensions are part of the leaf, so
+// that they are covered by both the signed timestamp and the leaf hash of the entry.
+func MarshalExtensions(extensions map[string]string) ([]byte, error) {
+	if len(extensions) == 0 {
+		return nil, nil
+	}
+
+	data, err := canonicalizer.MarshalCanonical(extensions)
+	if err != nil {
+		return nil, fmt.Errorf("marshal extensions: %w", err)
+	}
+
+	if len(data) > math.MaxUint16 {
+		return nil, fmt.Errorf("extensions exceed the maximum length of %d bytes", math.MaxUint16)
+	}
+
+	return data, nil
 }
 
 func (c *Cmd) hasPermissions(alias string, perm permission) error {
@@ -295,64 +458,342 @@ func (c *Cmd) hasPermissions(alias string, perm permission) error {
 	return errors.NewUnauthorizedError(fmt.Errorf("action forbidden for %q", alias))
 }
 
-// AddVC adds verifiable credential to log.
-func (c *Cmd) AddVC(w io.Writer, r io.Reader) error { // nolint: funlen,gocyclo,cyclop
+// AddVC adds verifiable credential to log. The extensions of the request, if any, are recorded in the timestamped
+// entry of the credential, see MarshalExtensions. A credential logged before is not logged again: the response
+// has the timestamp and the extensions of the entry logged first.
+func (c *Cmd) AddVC(w io.Writer, r io.Reader) error {
 	var req AddVCRequest
 
 	if err := json.NewDecoder(r).Decode(&req); err != nil {
 		return fmt.Errorf("decode AddVC request: %w", errors.ErrInternal)
 	}
 
-	if err := c.hasPermissions(req.Alias, write); err != nil {
+	loader, err := c.writeLoader(req.Alias)
+	if err != nil {
+		return err
+	}
+
+	extensions, err := MarshalExtensions(req.Extensions)
+	if err != nil {
+		return errors.NewBadRequestError(err)
+	}
+
+	resp, err := c.addVC(req.Alias, loader, req.VCEntry, extensions)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(resp) // nolint: wrapcheck
+}
+
+// ValidateVC runs the checks AddVC runs before the credential is added to the log: the credential is parsed and
+// its proofs checked, its issuer must be accepted by the log, it must be admitted by the admission policy and its
+// contexts must be resolvable. The credential is not added. A credential that passes validation may still be
+// rejected by AddVC later, e.g. if the issuers or contexts of the log change in the meantime.
+func (c *Cmd) ValidateVC(w io.Writer, r io.Reader) error {
+	var req AddVCRequest
+
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		return fmt.Errorf("decode ValidateVC request: %w", errors.ErrInternal)
+	}
+
+	loader, err := c.writeLoader(req.Alias)
+	if err != nil {
+		return err
+	}
+
+	vc, err := c.parseVC(loader, req.VCEntry)
+	if err != nil {
+		return err
+	}
+
+	if err = c.checkIssuer(req.Alias, vc.Issuer.ID); err != nil {
+		return err
+	}
+
+	if err = c.admit(req.VCEntry); err != nil {
+		return err
+	}
+
+	if _, err = CreateLeaf(uint64(time.Now().UnixNano()/int64(time.Millisecond)), req.VCEntry, loader); err != nil {
+		return fmt.Errorf("create leaf: %w", err)
+	}
+
+	return json.NewEncoder(w).Encode(ValidateVCResponse{}) // nolint: wrapcheck
+}
+
+// AddVCBatch adds verifiable credentials to log. A credential that cannot be added does not fail the batch,
+// its error is returned in the result at the same index instead.
+func (c *Cmd) AddVCBatch(w io.Writer, r io.Reader) error {
+	var req AddVCBatchRequest
+
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		return fmt.Errorf("decode AddVCBatch request: %w", errors.ErrInternal)
+	}
+
+	loader, err := c.writeLoader(req.Alias)
+	if err != nil {
+		return err
+	}
+
+	if len(req.VCEntries) == 0 {
+		return errors.NewBadRequestError(fmt.Errorf("batch is empty"))
+	}
+
+	if len(req.VCEntries) > MaxAddVCBatchSize {
+		return errors.NewBadRequestError(fmt.Errorf("batch size %d exceeds maximum %d",
+			len(req.VCEntries), MaxAddVCBatchSize))
+	}
+
+	results := make([]*AddVCBatchResult, len(req.VCEntries))
+
+	for i, vcEntry := range req.VCEntries {
+		resp, addErr := c.addVC(req.Alias, loader, vcEntry, nil)
+		if addErr != nil {
+			results[i] = &AddVCBatchResult{Error: addErr.Error(), StatusCode: errors.StatusCodeFromError(addErr)}
+
+			continue
+		}
+
+		results[i] = &AddVCBatchResult{Response: resp}
+	}
+
+	return json.NewEncoder(w).Encode(AddVCBatchResponse{Results: results}) // nolint: wrapcheck
+}
+
+// AddVP adds verifiable presentation to log.
+func (c *Cmd) AddVP(w io.Writer, r io.Reader) error {
+	var req AddVPRequest
+
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		return fmt.Errorf("decode AddVP request: %w", errors.ErrInternal)
+	}
+
+	loader, err := c.writeLoader(req.Alias)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.addVP(req.Alias, loader, req.VPEntry)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(resp) // nolint: wrapcheck
+}
+
+// GetAcceptedContexts returns the sorted URLs of the JSON-LD contexts the document loader of the log is
+// provisioned with, i.e. the contexts credentials added to the log may use. It fails with a not found error if
+// the document loader does not implement ContextLister.
+func (c *Cmd) GetAcceptedContexts(w io.Writer, r io.Reader) error {
+	var alias string
+
+	if err := json.NewDecoder(r).Decode(&alias); err != nil {
+		return fmt.Errorf("%w: decode alias failed", errors.ErrInternal)
+	}
+
+	if err := c.hasPermissions(alias, read); err != nil {
 		return fmt.Errorf("has permissions: %w", err)
 	}
 
-	loader, ok := c.loaders[req.Alias]
+	lister, ok := c.loaders[alias].(ContextLister)
 	if !ok {
-		return fmt.Errorf("no document loader found for alias %s", req.Alias)
+		return errors.NewNotFoundError(fmt.Errorf("contexts of %q are not available", alias))
 	}
 
+	contexts, err := lister.Contexts()
+	if err != nil {
+		return fmt.Errorf("%w: list contexts: %v", errors.ErrInternal, err)
+	}
+
+	sorted := append([]string{}, contexts...)
+	sort.Strings(sorted)
+
+	return json.NewEncoder(w).Encode(sorted) // nolint: wrapcheck
+}
+
+// GetLogInfo returns the metadata of the log: the version of the software, the hash algorithm of the tree, the
+// maximum number of entries of a get-entries request and the optional features the log supports.
+func (c *Cmd) GetLogInfo(w io.Writer, r io.Reader) error {
+	var alias string
+
+	if err := json.NewDecoder(r).Decode(&alias); err != nil {
+		return fmt.Errorf("%w: decode alias failed", errors.ErrInternal)
+	}
+
+	if err := c.hasPermissions(alias, read); err != nil {
+		return fmt.Errorf("has permissions: %w", err)
+	}
+
+	features := []string{FeatureAddVCBatch, FeatureAddVP, FeatureValidateVC, FeatureGetRoots}
+
+	if _, ok := c.loaders[alias].(ContextLister); ok {
+		features = append(features, FeatureGetAcceptedContexts)
+	}
+
+	features = append(features, FeatureGetEntriesRange, FeatureAddVCExtensions)
+
+	return json.NewEncoder(w).Encode(LogInfoResponse{ // nolint: wrapcheck
+		Version:       c.version,
+		HashAlgorithm: HashAlgorithm,
+		MaxGetEntries: MaxGetEntries,
+		Features:      features,
+	})
+}
+
+// writeLoader checks that the log with the given alias can be written to and returns its document loader.
+func (c *Cmd) writeLoader(alias string) (jsonld.DocumentLoader, error) {
+	if err := c.hasPermissions(alias, write); err != nil {
+		return nil, fmt.Errorf("has permissions: %w", err)
+	}
+
+	loader, ok := c.loaders[alias]
+	if !ok {
+		return nil, fmt.Errorf("no document loader found for alias %s", alias)
+	}
+
+	return loader, nil
+}
+
+func (c *Cmd) addVC(alias string, loader jsonld.DocumentLoader, vcEntry, extensions []byte) (*AddVCResponse,
+	error) {
 	parseCredentialTime := time.Now()
 
-	vc, err := verifiable.ParseCredential(req.VCEntry,
+	vc, err := c.parseVC(loader, vcEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	addVCParseCredentialLatency.Observe(time.Since(parseCredentialTime).Seconds(), alias)
+
+	if err = c.checkIssuer(alias, vc.Issuer.ID); err != nil {
+		return nil, err
+	}
+
+	if err = c.admit(vcEntry); err != nil {
+		return nil, err
+	}
+
+	leaf, err := CreateLeaf(uint64(time.Now().UnixNano()/int64(time.Millisecond)), vcEntry, loader)
+	if err != nil {
+		return nil, fmt.Errorf("create leaf: %w", err)
+	}
+
+	leaf.TimestampedEntry.Extensions = extensions
+
+	return c.logLeaf(alias, leaf, vc.Proofs)
+}
+
+// parseVC parses the credential and checks its proofs.
+func (c *Cmd) parseVC(loader jsonld.DocumentLoader, vcEntry []byte) (*verifiable.Credential, error) {
+	vc, err := verifiable.ParseCredential(vcEntry,
 		verifiable.WithPublicKeyFetcher(
 			verifiable.NewVDRKeyResolver(c.vdr).PublicKeyFetcher(),
 		),
 		verifiable.WithJSONLDDocumentLoader(loader),
 	)
 	if err != nil {
-		return errors.NewBadRequestError(fmt.Errorf("parse credential: %w", err))
+		return nil, errors.NewBadRequestError(fmt.Errorf("parse credential: %w", err))
 	}
 
-	addVCParseCredentialLatency.Observe(time.Since(parseCredentialTime).Seconds(), req.Alias)
+	// The credential is parsed with encoding/json, which silently keeps the last value of a duplicate key.
+	if err = canonicalizer.CheckDuplicateKeys(vcEntry); err != nil {
+		return nil, errors.NewBadRequestError(fmt.Errorf("parse credential: %w", err))
+	}
 
-	if len(c.logs[req.Alias].Issuers) > 0 && !contains(c.logs[req.Alias].Issuers, vc.Issuer.ID) {
-		return fmt.Errorf("%w: issuer %s is not in a list", errors.ErrBadRequest, vc.Issuer.ID)
+	return vc, nil
+}
+
+// checkIssuer checks that the log with the given alias accepts credentials of the issuer.
+func (c *Cmd) checkIssuer(alias, issuer string) error {
+	if len(c.logs[alias].Issuers) > 0 && !contains(c.logs[alias].Issuers, issuer) {
+		return fmt.Errorf("%w: issuer %s is not in a list", errors.ErrBadRequest, issuer)
 	}
 
-	leaf, err := CreateLeaf(uint64(time.Now().UnixNano()/int64(time.Millisecond)), req.VCEntry, loader)
+	return nil
+}
+
+// admit checks that the admission policy admits the credential.
+func (c *Cmd) admit(vcEntry []byte) error {
+	if err := c.policy.Admit(vcEntry); err != nil {
+		return errors.NewBadRequestError(fmt.Errorf("credential is not admitted: %w", err))
+	}
+
+	return nil
+}
+
+// addVP adds the presentation to the log. The credentials of the presentation are parsed, and their proofs
+// checked, like the credentials of addVC; if the log accepts only some issuers, each of them must be issued by
+// one of those, and each of them must be admitted by the admission policy.
+func (c *Cmd) addVP(alias string, loader jsonld.DocumentLoader, vpEntry []byte) (*AddVCResponse, error) {
+	vp, err := verifiable.ParsePresentation(vpEntry,
+		verifiable.WithPresPublicKeyFetcher(
+			verifiable.NewVDRKeyResolver(c.vdr).PublicKeyFetcher(),
+		),
+		verifiable.WithPresJSONLDDocumentLoader(loader),
+	)
 	if err != nil {
-		return fmt.Errorf("create leaf: %w", err)
+		return nil, errors.NewBadRequestError(fmt.Errorf("parse presentation: %w", err))
+	}
+
+	if err = canonicalizer.CheckDuplicateKeys(vpEntry); err != nil {
+		return nil, errors.NewBadRequestError(fmt.Errorf("parse presentation: %w", err))
 	}
 
+	credentials, err := vp.MarshalledCredentials()
+	if err != nil {
+		return nil, errors.NewBadRequestError(fmt.Errorf("parse presentation credentials: %w", err))
+	}
+
+	for _, vcEntry := range credentials {
+		vc, parseErr := verifiable.ParseCredential(vcEntry,
+			verifiable.WithPublicKeyFetcher(
+				verifiable.NewVDRKeyResolver(c.vdr).PublicKeyFetcher(),
+			),
+			verifiable.WithJSONLDDocumentLoader(loader),
+		)
+		if parseErr != nil {
+			return nil, errors.NewBadRequestError(fmt.Errorf("parse presentation credential: %w", parseErr))
+		}
+
+		if err = c.checkIssuer(alias, vc.Issuer.ID); err != nil {
+			return nil, err
+		}
+
+		if err = c.admit(vcEntry); err != nil {
+			return nil, err
+		}
+	}
+
+	leaf, err := CreateVPLeaf(uint64(time.Now().UnixNano()/int64(time.Millisecond)), vpEntry, loader)
+	if err != nil {
+		return nil, fmt.Errorf("create leaf: %w", err)
+	}
+
+	return c.logLeaf(alias, leaf, vp.Proofs)
+}
+
+// logLeaf queues the leaf to the log and signs its timestamp. The proofs of the credential or presentation are
+// kept in the extra data of the leaf.
+func (c *Cmd) logLeaf(alias string, leaf *MerkleTreeLeaf, proofs []verifiable.Proof) (*AddVCResponse, error) {
 	leafData, err := canonicalizer.MarshalCanonical(leaf)
 	if err != nil {
-		return errors.NewStatusInternalServerError(fmt.Errorf("marshal MerkleTreeLeaf: %w", err))
+		return nil, errors.NewStatusInternalServerError(fmt.Errorf("marshal MerkleTreeLeaf: %w", err))
 	}
 
 	var extraData []byte
 
-	if len(vc.Proofs) > 0 {
-		extraData, err = canonicalizer.MarshalCanonical(vc.Proofs)
+	if len(proofs) > 0 {
+		extraData, err = canonicalizer.MarshalCanonical(proofs)
 		if err != nil {
-			return errors.NewStatusInternalServerError(fmt.Errorf("marshal credential proofs: %w", err))
+			return nil, errors.NewStatusInternalServerError(fmt.Errorf("marshal proofs: %w", err))
 		}
 	}
 
 	leafIDHash := sha256.Sum256(leaf.TimestampedEntry.VCEntry)
 
-	resp, err := c.logs[req.Alias].Client.QueueLeaf(context.Background(), &trillian.QueueLeafRequest{
-		LogId: c.logs[req.Alias].ID,
+	resp, err := c.logs[alias].Client.QueueLeaf(context.Background(), &trillian.QueueLeafRequest{
+		LogId: c.logs[alias].ID,
 		Leaf: &trillian.LogLeaf{
 			LeafValue:        leafData,
 			ExtraData:        extraData,
@@ -360,35 +801,35 @@ func (c *Cmd) AddVC(w io.Writer, r io.Reader) error { // nolint: funlen,gocyclo,
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("queue leaf: %w", err)
+		return nil, fmt.Errorf("queue leaf: %w", err)
 	}
 
 	if resp.QueuedLeaf == nil {
-		return fmt.Errorf("%w: no leaf", errors.ErrInternal)
+		return nil, fmt.Errorf("%w: no leaf", errors.ErrInternal)
 	}
 
 	var loggedLeaf MerkleTreeLeaf
 	if err = json.Unmarshal(resp.QueuedLeaf.Leaf.LeafValue, &loggedLeaf); err != nil {
-		return errors.NewStatusInternalServerError(fmt.Errorf("failed to reconstruct MerkleTreeLeaf: %w", err))
+		return nil, errors.NewStatusInternalServerError(fmt.Errorf("failed to reconstruct MerkleTreeLeaf: %w", err))
 	}
 
 	sct, err := c.signV1VCTS(&loggedLeaf)
 	if err != nil {
-		return fmt.Errorf("sign V1 VCTS: %w", err)
+		return nil, fmt.Errorf("sign V1 VCTS: %w", err)
 	}
 
 	signature, err := json.Marshal(sct)
 	if err != nil {
-		return fmt.Errorf("marshal DigitallySigned payload: %w", err)
+		return nil, fmt.Errorf("marshal DigitallySigned payload: %w", err)
 	}
 
-	return json.NewEncoder(w).Encode(AddVCResponse{ // nolint: wrapcheck
+	return &AddVCResponse{
 		SVCTVersion: V1,
 		Timestamp:   loggedLeaf.TimestampedEntry.Timestamp,
 		ID:          c.VCLogID[:],
 		Extensions:  base64.StdEncoding.EncodeToString(loggedLeaf.TimestampedEntry.Extensions),
 		Signature:   signature,
-	})
+	}, nil
 }
 
 // GetSTH retrieves the latest signed tree head.
@@ -439,8 +880,6 @@ func (c *Cmd) GetSTH(w io.Writer, r io.Reader) error {
 
 // GetEntries retrieves entries from log.
 func (c *Cmd) GetEntries(w io.Writer, r io.Reader) error { // nolint: funlen
-	const maxRange = 1000
-
 	var request *GetEntriesRequest
 
 	if err := json.NewDecoder(r).Decode(&request); err != nil {
@@ -455,8 +894,8 @@ func (c *Cmd) GetEntries(w io.Writer, r io.Reader) error { // nolint: funlen
 		return fmt.Errorf("has permissions: %w", err)
 	}
 
-	if request.End-request.Start+1 > maxRange {
-		request.End = request.Start + maxRange - 1
+	if request.End-request.Start+1 > MaxGetEntries {
+		request.End = request.Start + MaxGetEntries - 1
 	}
 
 	req := trillian.GetLeavesByRangeRequest{
@@ -504,7 +943,14 @@ func (c *Cmd) GetEntries(w io.Writer, r io.Reader) error { // nolint: funlen
 		}
 	}
 
-	return json.NewEncoder(w).Encode(GetEntriesResponse{Entries: entries}) // nolint: wrapcheck
+	result := GetEntriesResponse{Entries: entries, TreeSize: currentRoot.TreeSize}
+
+	if len(entries) > 0 {
+		end := request.Start + int64(len(entries)) - 1
+		result.Start, result.End = &request.Start, &end
+	}
+
+	return json.NewEncoder(w).Encode(result) // nolint: wrapcheck
 }
 
 // GetEntryAndProof retrieves entry and merkle audit proof from log.
@@ -730,6 +1176,11 @@ func signatureAndHashAlgorithmByKeyType(keyType kms.KeyType) (*SignatureAndHashA
 			Signature: EDDSASignature,
 			Type:      keyType,
 		}, nil
+	case keyType == kms.RSARS256 || keyType == kms.RSAPS256:
+		return &SignatureAndHashAlgorithm{
+			Signature: RSASignature,
+			Type:      keyType,
+		}, nil
 	default:
 		return nil, fmt.Errorf("%w: key type %v is not supported", errors.ErrInternal, keyType)
 	}
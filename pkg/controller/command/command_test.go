@@ -11,11 +11,16 @@ package command_test
 
 import (
 	"bytes"
+	"context"
 	"embed"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"net/http"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -37,8 +42,10 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/vdr/key"
 	jsonld "github.com/piprate/json-gold/ld"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 
 	vctldcontext "github.com/trustbloc/vct/internal/pkg/ldcontext"
+	"github.com/trustbloc/vct/pkg/canonicalizer"
 	. "github.com/trustbloc/vct/pkg/controller/command"
 	"github.com/trustbloc/vct/pkg/controller/errors"
 	"github.com/trustbloc/vct/pkg/testutil"
@@ -244,6 +251,250 @@ func TestCmd_GetIssuers(t *testing.T) {
 	})
 }
 
+func TestCmd_GetRoots(t *testing.T) {
+	const kid = "kid"
+
+	newCmd := func(t *testing.T, log Log) *Cmd {
+		t.Helper()
+
+		ctrl := gomock.NewController(t)
+
+		km := NewMockKeyManager(ctrl)
+		km.EXPECT().Get(kid).Return(nil, nil)
+		km.EXPECT().ExportPubKeyBytes(kid).Return([]byte(`public key`), kms.ECDSAP256TypeIEEEP1363, nil)
+
+		log.Alias = alias
+
+		cmd, err := New(&Config{KMS: km, Key: Key{ID: kid}, Logs: []Log{log}}, nil)
+		require.NoError(t, err)
+
+		return cmd
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		cmd := newCmd(t, Log{Permission: "r", Roots: [][]byte{[]byte("root_a"), []byte("root_b")}})
+
+		var fr bytes.Buffer
+
+		require.NoError(t, cmd.GetRoots(&fr, bytes.NewBufferString(fmt.Sprintf("%q", alias))))
+
+		var hr bytes.Buffer
+
+		require.NoError(t, lookupHandler(t, cmd, GetRoots)(&hr, bytes.NewBufferString(fmt.Sprintf("%q", alias))))
+
+		require.Equal(t, fr.String(), hr.String())
+
+		var resp GetRootsResponse
+		require.NoError(t, json.Unmarshal(fr.Bytes(), &resp))
+		require.Equal(t, [][]byte{[]byte("root_a"), []byte("root_b")}, resp.Certificates)
+	})
+
+	t.Run("No roots", func(t *testing.T) {
+		cmd := newCmd(t, Log{Permission: "r"})
+
+		var fr bytes.Buffer
+
+		require.NoError(t, cmd.GetRoots(&fr, bytes.NewBufferString(fmt.Sprintf("%q", alias))))
+		require.Equal(t, `{"certificates":[]}`+"\n", fr.String())
+	})
+
+	t.Run("Action forbidden", func(t *testing.T) {
+		cmd := newCmd(t, Log{Permission: "w"})
+
+		require.EqualError(t, cmd.GetRoots(nil, bytes.NewBufferString(fmt.Sprintf("%q", alias))),
+			"has permissions: action forbidden for \"maple2021\"",
+		)
+	})
+
+	t.Run("Decode alias failed", func(t *testing.T) {
+		cmd := newCmd(t, Log{Permission: "r"})
+
+		require.EqualError(t, cmd.GetRoots(nil, bytes.NewBufferString("2021")),
+			"internal error: decode alias failed",
+		)
+	})
+}
+
+type contextLister struct {
+	jsonld.DocumentLoader
+
+	contexts []string
+	err      error
+}
+
+func (l *contextLister) Contexts() ([]string, error) {
+	return l.contexts, l.err
+}
+
+func TestCmd_GetAcceptedContexts(t *testing.T) {
+	const kid = "kid"
+
+	newCmd := func(t *testing.T, permission string, loader jsonld.DocumentLoader) *Cmd {
+		t.Helper()
+
+		ctrl := gomock.NewController(t)
+
+		km := NewMockKeyManager(ctrl)
+		km.EXPECT().Get(kid).Return(nil, nil)
+		km.EXPECT().ExportPubKeyBytes(kid).Return([]byte(`public key`), kms.ECDSAP256TypeIEEEP1363, nil)
+
+		cmd, err := New(&Config{
+			KMS:             km,
+			Key:             Key{ID: kid},
+			Logs:            []Log{{Alias: alias, Permission: permission}},
+			DocumentLoaders: map[string]jsonld.DocumentLoader{alias: loader},
+		}, nil)
+		require.NoError(t, err)
+
+		return cmd
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		cmd := newCmd(t, "r", &contextLister{
+			DocumentLoader: testutil.GetLoader(t),
+			contexts:       []string{"https://w3id.org/security/v2", "https://www.w3.org/2018/credentials/v1"},
+		})
+
+		var fr bytes.Buffer
+
+		require.NoError(t, cmd.GetAcceptedContexts(&fr, bytes.NewBufferString(fmt.Sprintf("%q", alias))))
+
+		var hr bytes.Buffer
+
+		require.NoError(t, lookupHandler(t, cmd, GetAcceptedContexts)(&hr,
+			bytes.NewBufferString(fmt.Sprintf("%q", alias))))
+
+		require.Equal(t, fr.String(), hr.String())
+
+		var contexts []string
+		require.NoError(t, json.Unmarshal(fr.Bytes(), &contexts))
+		require.Equal(t, []string{"https://w3id.org/security/v2", "https://www.w3.org/2018/credentials/v1"}, contexts)
+	})
+
+	t.Run("Sorted", func(t *testing.T) {
+		cmd := newCmd(t, "r", &contextLister{
+			DocumentLoader: testutil.GetLoader(t),
+			contexts:       []string{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/v2"},
+		})
+
+		var fr bytes.Buffer
+
+		require.NoError(t, cmd.GetAcceptedContexts(&fr, bytes.NewBufferString(fmt.Sprintf("%q", alias))))
+		require.Equal(t, `["https://w3id.org/security/v2","https://www.w3.org/2018/credentials/v1"]`+"\n", fr.String())
+	})
+
+	t.Run("Not available", func(t *testing.T) {
+		cmd := newCmd(t, "r", testutil.GetLoader(t))
+
+		err := cmd.GetAcceptedContexts(nil, bytes.NewBufferString(fmt.Sprintf("%q", alias)))
+		require.EqualError(t, err, `contexts of "maple2021" are not available`)
+		require.Equal(t, http.StatusNotFound, errors.StatusCodeFromError(err))
+	})
+
+	t.Run("List contexts failed", func(t *testing.T) {
+		cmd := newCmd(t, "r", &contextLister{DocumentLoader: testutil.GetLoader(t), err: errors.New("error")})
+
+		require.EqualError(t, cmd.GetAcceptedContexts(nil, bytes.NewBufferString(fmt.Sprintf("%q", alias))),
+			"internal error: list contexts: error",
+		)
+	})
+
+	t.Run("Action forbidden", func(t *testing.T) {
+		cmd := newCmd(t, "w", testutil.GetLoader(t))
+
+		require.EqualError(t, cmd.GetAcceptedContexts(nil, bytes.NewBufferString(fmt.Sprintf("%q", alias))),
+			"has permissions: action forbidden for \"maple2021\"",
+		)
+	})
+
+	t.Run("Decode alias failed", func(t *testing.T) {
+		cmd := newCmd(t, "r", testutil.GetLoader(t))
+
+		require.EqualError(t, cmd.GetAcceptedContexts(nil, bytes.NewBufferString("2021")),
+			"internal error: decode alias failed",
+		)
+	})
+}
+
+func TestCmd_GetLogInfo(t *testing.T) {
+	const kid = "kid"
+
+	newCmd := func(t *testing.T, permission string, loader jsonld.DocumentLoader) *Cmd {
+		t.Helper()
+
+		ctrl := gomock.NewController(t)
+
+		km := NewMockKeyManager(ctrl)
+		km.EXPECT().Get(kid).Return(nil, nil)
+		km.EXPECT().ExportPubKeyBytes(kid).Return([]byte(`public key`), kms.ECDSAP256TypeIEEEP1363, nil)
+
+		cmd, err := New(&Config{
+			KMS:             km,
+			Key:             Key{ID: kid},
+			Logs:            []Log{{Alias: alias, Permission: permission}},
+			DocumentLoaders: map[string]jsonld.DocumentLoader{alias: loader},
+			Version:         "v1.0.0",
+		}, nil)
+		require.NoError(t, err)
+
+		return cmd
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		cmd := newCmd(t, "r", testutil.GetLoader(t))
+
+		var fr bytes.Buffer
+
+		require.NoError(t, cmd.GetLogInfo(&fr, bytes.NewBufferString(fmt.Sprintf("%q", alias))))
+
+		var hr bytes.Buffer
+
+		require.NoError(t, lookupHandler(t, cmd, GetLogInfo)(&hr, bytes.NewBufferString(fmt.Sprintf("%q", alias))))
+
+		require.Equal(t, fr.String(), hr.String())
+
+		var info LogInfoResponse
+		require.NoError(t, json.Unmarshal(fr.Bytes(), &info))
+		require.Equal(t, LogInfoResponse{
+			Version:       "v1.0.0",
+			HashAlgorithm: "SHA-256",
+			MaxGetEntries: MaxGetEntries,
+			Features: []string{
+				FeatureAddVCBatch, FeatureAddVP, FeatureValidateVC, FeatureGetRoots, FeatureGetEntriesRange,
+				FeatureAddVCExtensions,
+			},
+		}, info)
+	})
+
+	t.Run("Accepted contexts", func(t *testing.T) {
+		cmd := newCmd(t, "r", &contextLister{DocumentLoader: testutil.GetLoader(t)})
+
+		var fr bytes.Buffer
+
+		require.NoError(t, cmd.GetLogInfo(&fr, bytes.NewBufferString(fmt.Sprintf("%q", alias))))
+
+		var info LogInfoResponse
+		require.NoError(t, json.Unmarshal(fr.Bytes(), &info))
+		require.True(t, info.HasFeature(FeatureGetAcceptedContexts))
+	})
+
+	t.Run("Action forbidden", func(t *testing.T) {
+		cmd := newCmd(t, "w", testutil.GetLoader(t))
+
+		require.EqualError(t, cmd.GetLogInfo(nil, bytes.NewBufferString(fmt.Sprintf("%q", alias))),
+			"has permissions: action forbidden for \"maple2021\"",
+		)
+	})
+
+	t.Run("Decode alias failed", func(t *testing.T) {
+		cmd := newCmd(t, "r", testutil.GetLoader(t))
+
+		require.EqualError(t, cmd.GetLogInfo(nil, bytes.NewBufferString("2021")),
+			"internal error: decode alias failed",
+		)
+	})
+}
+
 func TestCmd_Webfinger(t *testing.T) {
 	const kid = "kid"
 
@@ -336,9 +587,45 @@ func TestCmd_GetEntries(t *testing.T) {
 		require.NoError(t, lookupHandler(t, cmd, GetEntries)(&hr, bytes.NewBufferString(`{"alias":"maple2021"}`)))
 		require.NoError(t, json.Unmarshal(hr.Bytes(), &hrs))
 
-		require.Equal(t, frs.Entries, hrs.Entries)
-		require.NotEmpty(t, frs.Entries)
-		require.NotEmpty(t, hrs.Entries)
+		require.Equal(t, frs, hrs)
+		require.Len(t, frs.Entries, 1)
+		require.Contains(t, fr.String(), `"start":0,"end":0`)
+		require.Equal(t, int64(0), *frs.Start)
+		require.Equal(t, int64(0), *frs.End)
+		require.Equal(t, uint64(1), frs.TreeSize)
+	})
+
+	t.Run("Empty page", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		km := NewMockKeyManager(ctrl)
+		km.EXPECT().Get(kid).Return(nil, nil)
+		km.EXPECT().ExportPubKeyBytes(kid).Return([]byte(`public key`), keyType, nil)
+
+		client := NewMockTrillianLogClient(ctrl)
+		client.EXPECT().GetLeavesByRange(gomock.Any(), gomock.Any()).Return(
+			&trillian.GetLeavesByRangeResponse{SignedLogRoot: &trillian.SignedLogRoot{LogRoot: logRoot}}, nil,
+		)
+
+		cmd, err := New(&Config{
+			KMS: km,
+			Key: Key{
+				ID: kid,
+			},
+			Logs: []Log{{
+				Alias:      alias,
+				Permission: "r",
+				Client:     client,
+			}},
+		}, nil)
+		require.NoError(t, err)
+		require.NotNil(t, cmd)
+
+		var fr bytes.Buffer
+
+		require.NoError(t, cmd.GetEntries(&fr, bytes.NewBufferString(`{"alias":"maple2021"}`)))
+		require.Equal(t, `{"entries":[],"tree_size":1}`+"\n", fr.String())
 	})
 
 	t.Run("Decode error", func(t *testing.T) {
@@ -1268,6 +1555,24 @@ func TestCreateLeaf(t *testing.T) {
 	require.NoError(t, err)
 
 	require.Len(t, simpleVC.Proofs, 2)
+
+	// A duplicate key would be resolved differently by different JSON parsers.
+	_, err = CreateLeaf(1, []byte(`{"id":"http://example.edu/credentials/1","id":"http://example.edu/credentials/2"}`),
+		testutil.GetLoader(t))
+	require.ErrorIs(t, err, canonicalizer.ErrDuplicateKey)
+}
+
+func TestMarshalExtensions(t *testing.T) {
+	extensions, err := MarshalExtensions(nil)
+	require.NoError(t, err)
+	require.Nil(t, extensions)
+
+	extensions, err = MarshalExtensions(map[string]string{"correlation": "abc", "batch": "1"})
+	require.NoError(t, err)
+	require.Equal(t, `{"batch":"1","correlation":"abc"}`, string(extensions))
+
+	_, err = MarshalExtensions(map[string]string{"key": strings.Repeat("a", math.MaxUint16)})
+	require.EqualError(t, err, "extensions exceed the maximum length of 65535 bytes")
 }
 
 func TestCmd_AddVC(t *testing.T) {
@@ -1344,7 +1649,54 @@ func TestCmd_AddVC(t *testing.T) {
 		require.NotEmpty(t, sig.Algorithm.Signature)
 	})
 
-	t.Run("Document loader error", func(t *testing.T) {
+	t.Run("Extensions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		km, cr := createKMSAndCrypto(t)
+		newKID, _, err := km.Create(keyType)
+		require.NoError(t, err)
+
+		var queued MerkleTreeLeaf
+
+		client := NewMockTrillianLogClient(ctrl)
+		client.EXPECT().QueueLeaf(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req *trillian.QueueLeafRequest,
+				_ ...grpc.CallOption) (*trillian.QueueLeafResponse, error) {
+				require.NoError(t, json.Unmarshal(req.Leaf.LeafValue, &queued))
+
+				return &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{Leaf: req.Leaf}}, nil
+			},
+		)
+
+		cmd, err := New(&Config{
+			KMS:             km,
+			Crypto:          cr,
+			Logs:            []Log{{Alias: alias, Permission: "w", Client: client}},
+			VDR:             vdr.New(vdr.WithVDR(key.New())),
+			Key:             Key{ID: newKID},
+			DocumentLoaders: map[string]jsonld.DocumentLoader{alias: documentLoader},
+		}, nil)
+		require.NoError(t, err)
+
+		req, err := json.Marshal(AddVCRequest{
+			Alias:      alias,
+			VCEntry:    verifiableCredential,
+			Extensions: map[string]string{"b": "2", "a": "1"},
+		})
+		require.NoError(t, err)
+
+		var resp AddVCResponse
+
+		fr := bytes.Buffer{}
+		require.NoError(t, cmd.AddVC(&fr, bytes.NewBuffer(req)))
+		require.NoError(t, json.Unmarshal(fr.Bytes(), &resp))
+
+		require.Equal(t, `{"a":"1","b":"2"}`, string(queued.TimestampedEntry.Extensions))
+		require.Equal(t, base64.StdEncoding.EncodeToString([]byte(`{"a":"1","b":"2"}`)), resp.Extensions)
+	})
+
+	t.Run("Decode queued leaf", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -1352,21 +1704,33 @@ func TestCmd_AddVC(t *testing.T) {
 		newKID, _, err := km.Create(keyType)
 		require.NoError(t, err)
 
+		var queued *trillian.LogLeaf
+
+		client := NewMockTrillianLogClient(ctrl)
+		client.EXPECT().QueueLeaf(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req *trillian.QueueLeafRequest,
+				_ ...grpc.CallOption) (*trillian.QueueLeafResponse, error) {
+				queued = req.Leaf
+
+				return &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{Leaf: req.Leaf}}, nil
+			},
+		)
+
 		cmd, err := New(&Config{
 			KMS:    km,
 			Crypto: cr,
 			Logs: []Log{{
 				Alias:      alias,
 				Permission: "w",
-				Client:     NewMockTrillianLogClient(ctrl),
+				Client:     client,
 			}},
 			VDR: vdr.New(vdr.WithVDR(key.New())),
 			Key: Key{
 				ID: newKID,
 			},
+			DocumentLoaders: map[string]jsonld.DocumentLoader{alias: documentLoader},
 		}, nil)
 		require.NoError(t, err)
-		require.NotNil(t, cmd)
 
 		req, err := json.Marshal(AddVCRequest{
 			Alias:   alias,
@@ -1374,23 +1738,75 @@ func TestCmd_AddVC(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		const expErr = "document loader"
-		require.Contains(t, cmd.AddVC(nil, bytes.NewBuffer(req)).Error(), expErr)
-		require.Contains(t, lookupHandler(t, cmd, AddVC)(nil, bytes.NewBuffer(req)).Error(), expErr)
+		var buf bytes.Buffer
+
+		require.NoError(t, cmd.AddVC(&buf, bytes.NewBuffer(req)))
+
+		var resp AddVCResponse
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
+
+		entry := LeafEntry{LeafInput: queued.LeafValue, ExtraData: queued.ExtraData}
+
+		timestamp, vc, err := entry.DecodeTimestampedEntry()
+		require.NoError(t, err)
+		require.Equal(t, resp.Timestamp, timestamp)
+
+		leaf, err := CreateLeaf(timestamp, verifiableCredential, documentLoader)
+		require.NoError(t, err)
+		require.Equal(t, leaf.TimestampedEntry.VCEntry, vc)
+
+		var proofs []verifiable.Proof
+		require.NoError(t, json.Unmarshal(entry.ExtraData, &proofs))
+		require.Len(t, proofs, 1)
 	})
 
-	t.Run("Copy vc failed", func(t *testing.T) {
+	t.Run("Document loader error", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
-		km := NewMockKeyManager(ctrl)
-		km.EXPECT().Get(kid).Return(nil, nil)
-		km.EXPECT().ExportPubKeyBytes(kid).Return([]byte(`public key`), keyType, nil)
+		km, cr := createKMSAndCrypto(t)
+		newKID, _, err := km.Create(keyType)
+		require.NoError(t, err)
 
 		cmd, err := New(&Config{
-			KMS: km,
+			KMS:    km,
+			Crypto: cr,
+			Logs: []Log{{
+				Alias:      alias,
+				Permission: "w",
+				Client:     NewMockTrillianLogClient(ctrl),
+			}},
+			VDR: vdr.New(vdr.WithVDR(key.New())),
 			Key: Key{
-				ID: kid,
+				ID: newKID,
+			},
+		}, nil)
+		require.NoError(t, err)
+		require.NotNil(t, cmd)
+
+		req, err := json.Marshal(AddVCRequest{
+			Alias:   alias,
+			VCEntry: verifiableCredential,
+		})
+		require.NoError(t, err)
+
+		const expErr = "document loader"
+		require.Contains(t, cmd.AddVC(nil, bytes.NewBuffer(req)).Error(), expErr)
+		require.Contains(t, lookupHandler(t, cmd, AddVC)(nil, bytes.NewBuffer(req)).Error(), expErr)
+	})
+
+	t.Run("Copy vc failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		km := NewMockKeyManager(ctrl)
+		km.EXPECT().Get(kid).Return(nil, nil)
+		km.EXPECT().ExportPubKeyBytes(kid).Return([]byte(`public key`), keyType, nil)
+
+		cmd, err := New(&Config{
+			KMS: km,
+			Key: Key{
+				ID: kid,
 			},
 		}, nil)
 		require.NoError(t, err)
@@ -1667,6 +2083,511 @@ func TestCmd_AddVC(t *testing.T) {
 	})
 }
 
+func TestCmd_ValidateVC(t *testing.T) {
+	const (
+		kid     = "kid"
+		keyType = kms.ECDSAP256TypeIEEEP1363
+	)
+
+	documentLoader := documentLoader(t)
+
+	newCmd := func(t *testing.T, log Log) *Cmd {
+		t.Helper()
+
+		ctrl := gomock.NewController(t)
+
+		km := NewMockKeyManager(ctrl)
+		km.EXPECT().Get(kid).Return(nil, nil)
+		km.EXPECT().ExportPubKeyBytes(kid).Return([]byte(`public key`), keyType, nil)
+
+		// The credential must not be queued.
+		log.Alias = alias
+		log.Client = NewMockTrillianLogClient(ctrl)
+
+		cmd, err := New(&Config{
+			KMS:             km,
+			Logs:            []Log{log},
+			VDR:             vdr.New(vdr.WithVDR(key.New())),
+			Key:             Key{ID: kid},
+			DocumentLoaders: map[string]jsonld.DocumentLoader{alias: documentLoader},
+		}, nil)
+		require.NoError(t, err)
+
+		return cmd
+	}
+
+	req, err := json.Marshal(AddVCRequest{
+		Alias:   alias,
+		VCEntry: verifiableCredential,
+	})
+	require.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		cmd := newCmd(t, Log{Permission: "w"})
+
+		var fr bytes.Buffer
+
+		require.NoError(t, cmd.ValidateVC(&fr, bytes.NewBuffer(req)))
+
+		var hr bytes.Buffer
+
+		require.NoError(t, lookupHandler(t, cmd, ValidateVC)(&hr, bytes.NewBuffer(req)))
+
+		require.Equal(t, fr.String(), hr.String())
+		require.Equal(t, "{}\n", fr.String())
+	})
+
+	t.Run("Parse credential", func(t *testing.T) {
+		cmd := newCmd(t, Log{Permission: "w"})
+
+		const expErr = "parse credential: decode new credential: embedded proof is not JSON: unexpected end of JSON input"
+		require.EqualError(t, cmd.ValidateVC(nil, bytes.NewBufferString(`{"alias":"maple2021"}`)), expErr)
+	})
+
+	t.Run("Issuer is not trusted", func(t *testing.T) {
+		cmd := newCmd(t, Log{Permission: "w", Issuers: []string{"issuer_a"}})
+
+		const expErr = "bad request: issuer did:key:zUC724vuGvHpnCGFG1qqpXb81SiBLu3KLSqVzenwEZNPoY35i2Bscb8DLaVwHvRFs6F2NkNNXRcPWvqnPDUd9ukdjLkjZd3u9zzL4wDZDUpkPAatLDGLEYVo8kkAzuAKJQMr7N2 is not in a list" // nolint: lll
+		require.EqualError(t, cmd.ValidateVC(nil, bytes.NewBuffer(req)), expErr)
+	})
+
+	t.Run("Action forbidden", func(t *testing.T) {
+		cmd := newCmd(t, Log{Permission: "r"})
+
+		require.EqualError(t, cmd.ValidateVC(nil, bytes.NewBuffer(req)),
+			"has permissions: action forbidden for \"maple2021\"",
+		)
+	})
+
+	t.Run("Decode request failed", func(t *testing.T) {
+		cmd := newCmd(t, Log{Permission: "w"})
+
+		require.EqualError(t, cmd.ValidateVC(nil, bytes.NewBufferString("2021")),
+			"decode ValidateVC request: internal error",
+		)
+	})
+}
+
+type admissionPolicy func(vc []byte) error
+
+func (p admissionPolicy) Admit(vc []byte) error {
+	return p(vc)
+}
+
+func TestCmd_AdmissionPolicy(t *testing.T) {
+	const (
+		kid     = "kid"
+		keyType = kms.ECDSAP256TypeIEEEP1363
+	)
+
+	documentLoader := documentLoader(t)
+
+	newCmd := func(t *testing.T, policy AdmissionPolicy) *Cmd {
+		t.Helper()
+
+		ctrl := gomock.NewController(t)
+
+		km := NewMockKeyManager(ctrl)
+		km.EXPECT().Get(kid).Return(nil, nil)
+		km.EXPECT().ExportPubKeyBytes(kid).Return([]byte(`public key`), keyType, nil)
+
+		// A credential that is not admitted must not be queued.
+		cmd, err := New(&Config{
+			KMS:             km,
+			Logs:            []Log{{Alias: alias, Permission: "w", Client: NewMockTrillianLogClient(ctrl)}},
+			VDR:             vdr.New(vdr.WithVDR(key.New())),
+			Key:             Key{ID: kid},
+			DocumentLoaders: map[string]jsonld.DocumentLoader{alias: documentLoader},
+			AdmissionPolicy: policy,
+		}, nil)
+		require.NoError(t, err)
+
+		return cmd
+	}
+
+	req, err := json.Marshal(AddVCRequest{
+		Alias:   alias,
+		VCEntry: verifiableCredential,
+	})
+	require.NoError(t, err)
+
+	t.Run("Admitted", func(t *testing.T) {
+		var admitted []byte
+
+		cmd := newCmd(t, admissionPolicy(func(vc []byte) error {
+			admitted = vc
+
+			return nil
+		}))
+
+		require.NoError(t, cmd.ValidateVC(&bytes.Buffer{}, bytes.NewBuffer(req)))
+		require.Equal(t, verifiableCredential, admitted)
+	})
+
+	t.Run("Allow all", func(t *testing.T) {
+		require.NoError(t, AllowAllPolicy{}.Admit(verifiableCredential))
+		require.NoError(t, newCmd(t, nil).ValidateVC(&bytes.Buffer{}, bytes.NewBuffer(req)))
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		cmd := newCmd(t, admissionPolicy(func([]byte) error {
+			return fmt.Errorf("credential is expired")
+		}))
+
+		const expErr = "credential is not admitted: credential is expired"
+
+		err := cmd.AddVC(nil, bytes.NewBuffer(req))
+		require.EqualError(t, err, expErr)
+		require.Equal(t, http.StatusBadRequest, errors.StatusCodeFromError(err))
+
+		require.EqualError(t, cmd.ValidateVC(nil, bytes.NewBuffer(req)), expErr)
+
+		batch, err := json.Marshal(AddVCBatchRequest{
+			Alias:     alias,
+			VCEntries: [][]byte{verifiableCredential},
+		})
+		require.NoError(t, err)
+
+		var resp bytes.Buffer
+
+		require.NoError(t, cmd.AddVCBatch(&resp, bytes.NewBuffer(batch)))
+
+		var batchResp AddVCBatchResponse
+
+		require.NoError(t, json.Unmarshal(resp.Bytes(), &batchResp))
+		require.Equal(t, []*AddVCBatchResult{{Error: expErr, StatusCode: http.StatusBadRequest}}, batchResp.Results)
+	})
+}
+
+func TestCmd_AddVCBatch(t *testing.T) {
+	const (
+		kid     = "kid"
+		keyType = kms.ECDSAP256TypeIEEEP1363
+	)
+
+	documentLoader := documentLoader(t)
+
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		km, cr := createKMSAndCrypto(t)
+		newKID, _, err := km.Create(keyType)
+		require.NoError(t, err)
+
+		client := NewMockTrillianLogClient(ctrl)
+		client.EXPECT().QueueLeaf(gomock.Any(), gomock.Any()).Return(
+			&trillian.QueueLeafResponse{
+				QueuedLeaf: &trillian.QueuedLogLeaf{
+					Leaf: &trillian.LogLeaf{LeafValue: queuedLeafValue},
+				},
+			}, nil,
+		).Times(2)
+
+		cmd, err := New(&Config{
+			KMS:    km,
+			Crypto: cr,
+			Logs: []Log{{
+				Alias:      alias,
+				Permission: "w",
+				Client:     client,
+			}},
+			VDR: vdr.New(vdr.WithVDR(key.New())),
+			Key: Key{
+				ID: newKID,
+			},
+			DocumentLoaders: map[string]jsonld.DocumentLoader{alias: documentLoader},
+		}, nil)
+		require.NoError(t, err)
+
+		req, err := json.Marshal(AddVCBatchRequest{
+			Alias:     alias,
+			VCEntries: [][]byte{verifiableCredential, []byte(`{}`), verifiableCredential},
+		})
+		require.NoError(t, err)
+
+		var resp AddVCBatchResponse
+
+		w := bytes.Buffer{}
+		require.NoError(t, lookupHandler(t, cmd, AddVCBatch)(&w, bytes.NewBuffer(req)))
+		require.NoError(t, json.Unmarshal(w.Bytes(), &resp))
+
+		require.Len(t, resp.Results, 3)
+
+		for _, i := range []int{0, 2} {
+			require.Empty(t, resp.Results[i].Error)
+			require.NotNil(t, resp.Results[i].Response)
+			require.NotEmpty(t, resp.Results[i].Response.Signature)
+		}
+
+		require.Nil(t, resp.Results[1].Response)
+		require.Contains(t, resp.Results[1].Error, "parse credential")
+		require.Equal(t, http.StatusBadRequest, resp.Results[1].StatusCode)
+	})
+
+	t.Run("Empty batch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		km := NewMockKeyManager(ctrl)
+		km.EXPECT().Get(kid).Return(nil, nil)
+		km.EXPECT().ExportPubKeyBytes(kid).Return([]byte(`public key`), keyType, nil)
+
+		cmd, err := New(&Config{
+			KMS: km,
+			Key: Key{
+				ID: kid,
+			},
+			Logs: []Log{{
+				Alias:      alias,
+				Permission: "w",
+			}},
+			DocumentLoaders: map[string]jsonld.DocumentLoader{alias: documentLoader},
+		}, nil)
+		require.NoError(t, err)
+
+		require.EqualError(t, cmd.AddVCBatch(nil, bytes.NewBufferString(`{"alias":"maple2021"}`)), "batch is empty")
+
+		req, err := json.Marshal(AddVCBatchRequest{
+			Alias:     alias,
+			VCEntries: make([][]byte, MaxAddVCBatchSize+1),
+		})
+		require.NoError(t, err)
+
+		require.EqualError(t, cmd.AddVCBatch(nil, bytes.NewBuffer(req)), "batch size 1001 exceeds maximum 1000")
+	})
+
+	t.Run("Decode request failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		km := NewMockKeyManager(ctrl)
+		km.EXPECT().Get(kid).Return(nil, nil)
+		km.EXPECT().ExportPubKeyBytes(kid).Return([]byte(`public key`), keyType, nil)
+
+		cmd, err := New(&Config{
+			KMS: km,
+			Key: Key{
+				ID: kid,
+			},
+		}, nil)
+		require.NoError(t, err)
+
+		const expErr = "decode AddVCBatch request: internal error"
+		require.EqualError(t, cmd.AddVCBatch(nil, &readerMock{errors.New("EOF")}), expErr)
+	})
+
+	t.Run("No permissions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		km := NewMockKeyManager(ctrl)
+		km.EXPECT().Get(kid).Return(nil, nil)
+		km.EXPECT().ExportPubKeyBytes(kid).Return([]byte(`public key`), keyType, nil)
+
+		cmd, err := New(&Config{
+			KMS: km,
+			Logs: []Log{{
+				Alias:      alias,
+				Permission: "r",
+			}},
+			Key: Key{
+				ID: kid,
+			},
+			DocumentLoaders: map[string]jsonld.DocumentLoader{alias: documentLoader},
+		}, nil)
+		require.NoError(t, err)
+
+		const expErr = "has permissions: action forbidden for \"maple2021\""
+		require.EqualError(t, cmd.AddVCBatch(nil, bytes.NewBufferString(`{"alias":"maple2021"}`)), expErr)
+	})
+}
+
+func TestCreateVPLeaf(t *testing.T) {
+	vp := presentation(t)
+
+	leaf, err := CreateVPLeaf(1, vp, testutil.GetLoader(t))
+	require.NoError(t, err)
+	require.Equal(t, VPLogEntryType, leaf.TimestampedEntry.EntryType)
+
+	vcLeaf, err := CreateLeaf(1, verifiableCredential, testutil.GetLoader(t))
+	require.NoError(t, err)
+	require.Equal(t, VCLogEntryType, vcLeaf.TimestampedEntry.EntryType)
+	require.NotEqual(t, vcLeaf.TimestampedEntry.VCEntry, leaf.TimestampedEntry.VCEntry)
+
+	_, err = CreateVPLeaf(1, []byte(`{"id":"urn:uuid:1","id":"urn:uuid:2"}`), testutil.GetLoader(t))
+	require.ErrorIs(t, err, canonicalizer.ErrDuplicateKey)
+}
+
+func TestCmd_AddVP(t *testing.T) {
+	const (
+		kid     = "kid"
+		keyType = kms.ECDSAP256TypeIEEEP1363
+	)
+
+	documentLoader := documentLoader(t)
+
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		km, cr := createKMSAndCrypto(t)
+		newKID, _, err := km.Create(keyType)
+		require.NoError(t, err)
+
+		var queued *trillian.LogLeaf
+
+		client := NewMockTrillianLogClient(ctrl)
+		client.EXPECT().QueueLeaf(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req *trillian.QueueLeafRequest,
+				_ ...grpc.CallOption) (*trillian.QueueLeafResponse, error) {
+				queued = req.Leaf
+
+				return &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{Leaf: req.Leaf}}, nil
+			},
+		)
+
+		cmd, err := New(&Config{
+			KMS:    km,
+			Crypto: cr,
+			Logs: []Log{{
+				Alias:      alias,
+				Permission: "w",
+				Client:     client,
+			}},
+			VDR: vdr.New(vdr.WithVDR(key.New())),
+			Key: Key{
+				ID: newKID,
+			},
+			DocumentLoaders: map[string]jsonld.DocumentLoader{alias: documentLoader},
+		}, nil)
+		require.NoError(t, err)
+
+		req, err := json.Marshal(AddVPRequest{
+			Alias:   alias,
+			VPEntry: presentation(t),
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		require.NoError(t, lookupHandler(t, cmd, AddVP)(&buf, bytes.NewBuffer(req)))
+
+		var resp AddVCResponse
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
+		require.NotEmpty(t, resp.Signature)
+
+		entry := LeafEntry{LeafInput: queued.LeafValue, ExtraData: queued.ExtraData}
+
+		entryType, timestamp, vp, err := entry.DecodeTimestampedEntryWithType()
+		require.NoError(t, err)
+		require.Equal(t, VPLogEntryType, entryType)
+		require.Equal(t, resp.Timestamp, timestamp)
+
+		leaf, err := CreateVPLeaf(timestamp, presentation(t), documentLoader)
+		require.NoError(t, err)
+		require.Equal(t, leaf.TimestampedEntry.VCEntry, vp)
+
+		_, _, err = entry.DecodeTimestampedEntry()
+		require.EqualError(t, err, "unsupported entry type 101")
+	})
+
+	t.Run("Issuer is not trusted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		km := NewMockKeyManager(ctrl)
+		km.EXPECT().Get(kid).Return(nil, nil)
+		km.EXPECT().ExportPubKeyBytes(kid).Return([]byte(`public key`), keyType, nil)
+
+		cmd, err := New(&Config{
+			KMS: km,
+			Logs: []Log{{
+				Alias:      alias,
+				Permission: "w",
+				Issuers:    []string{"issuer_a"},
+			}},
+			VDR: vdr.New(vdr.WithVDR(key.New())),
+			Key: Key{
+				ID: kid,
+			},
+			DocumentLoaders: map[string]jsonld.DocumentLoader{alias: documentLoader},
+		}, nil)
+		require.NoError(t, err)
+
+		req, err := json.Marshal(AddVPRequest{
+			Alias:   alias,
+			VPEntry: presentation(t),
+		})
+		require.NoError(t, err)
+
+		err = cmd.AddVP(nil, bytes.NewBuffer(req))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not in a list")
+	})
+
+	t.Run("Bad presentation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		km := NewMockKeyManager(ctrl)
+		km.EXPECT().Get(kid).Return(nil, nil)
+		km.EXPECT().ExportPubKeyBytes(kid).Return([]byte(`public key`), keyType, nil)
+
+		cmd, err := New(&Config{
+			KMS: km,
+			Logs: []Log{{
+				Alias:      alias,
+				Permission: "w",
+			}},
+			VDR: vdr.New(vdr.WithVDR(key.New())),
+			Key: Key{
+				ID: kid,
+			},
+			DocumentLoaders: map[string]jsonld.DocumentLoader{alias: documentLoader},
+		}, nil)
+		require.NoError(t, err)
+
+		err = cmd.AddVP(nil, bytes.NewBufferString(`{"alias":"maple2021"}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse presentation")
+	})
+
+	t.Run("Decode request failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		km := NewMockKeyManager(ctrl)
+		km.EXPECT().Get(kid).Return(nil, nil)
+		km.EXPECT().ExportPubKeyBytes(kid).Return([]byte(`public key`), keyType, nil)
+
+		cmd, err := New(&Config{
+			KMS: km,
+			Key: Key{
+				ID: kid,
+			},
+		}, nil)
+		require.NoError(t, err)
+
+		const expErr = "decode AddVP request: internal error"
+		require.EqualError(t, cmd.AddVP(nil, &readerMock{errors.New("EOF")}), expErr)
+	})
+}
+
+// presentation returns a verifiable presentation of the test credential.
+func presentation(t *testing.T) []byte {
+	t.Helper()
+
+	vp, err := json.Marshal(map[string]interface{}{
+		"@context":             []string{"https://www.w3.org/2018/credentials/v1"},
+		"type":                 []string{"VerifiablePresentation"},
+		"verifiableCredential": []json.RawMessage{verifiableCredential},
+	})
+	require.NoError(t, err)
+
+	return vp
+}
+
 func lookupHandler(t *testing.T, cmd *Cmd, name string) Exec {
 	t.Helper()
 
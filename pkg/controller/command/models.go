@@ -7,7 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package command
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 
@@ -29,6 +32,7 @@ type SignatureAlgorithm string
 const (
 	ECDSASignature SignatureAlgorithm = "ECDSA"
 	EDDSASignature SignatureAlgorithm = "EDDSA"
+	RSASignature   SignatureAlgorithm = "RSA"
 )
 
 // SignatureType differentiates signatures.
@@ -51,9 +55,11 @@ const (
 // LogEntryType type definition.
 type LogEntryType uint64
 
-// LogEntryType constants.
+// LogEntryType constants. The entry type of the timestamped entry tells verifiable credentials and verifiable
+// presentations apart when walking the log, the entry itself is the canonical form of either of them.
 const (
 	VCLogEntryType LogEntryType = 100
+	VPLogEntryType LogEntryType = 101
 )
 
 // GetEntryAndProofRequest represents the request to get-entry-and-proof.
@@ -117,6 +123,36 @@ type GetProofByHashResponse struct {
 	AuditPath [][]byte `json:"audit_path"`
 }
 
+// GetRootsResponse represents the response to the get-roots.
+type GetRootsResponse struct {
+	// Certificates are the trust anchors of the issuers the log accepts.
+	Certificates [][]byte `json:"certificates"`
+}
+
+// LogInfoResponse represents the response to the get-log-info. Fields may be added to it, clients should ignore
+// the ones and the features they do not know.
+type LogInfoResponse struct {
+	// Version is the version of the software of the log, if known.
+	Version string `json:"version,omitempty"`
+	// HashAlgorithm is the hash algorithm of the Merkle tree of the log, e.g. SHA-256.
+	HashAlgorithm string `json:"hash_algorithm"`
+	// MaxGetEntries is the maximum number of entries returned by a get-entries request.
+	MaxGetEntries int64 `json:"max_get_entries"`
+	// Features are the optional features the log supports, e.g. FeatureAddVCBatch.
+	Features []string `json:"features"`
+}
+
+// HasFeature reports whether the log supports the given feature.
+func (r *LogInfoResponse) HasFeature(feature string) bool {
+	for _, f := range r.Features {
+		if f == feature {
+			return true
+		}
+	}
+
+	return false
+}
+
 // GetEntriesRequest represents the request to the get-entries.
 type GetEntriesRequest struct {
 	Alias string `json:"alias"`
@@ -127,6 +163,13 @@ type GetEntriesRequest struct {
 // GetEntriesResponse represents the response to the get-entries.
 type GetEntriesResponse struct {
 	Entries []LeafEntry `json:"entries"`
+	// Start and End are the leaf indexes of the first and the last of the entries. The log may return fewer
+	// entries than requested, so the next range starts at End+1. Neither is set if no entries were served.
+	Start *int64 `json:"start,omitempty"`
+	End   *int64 `json:"end,omitempty"`
+	// TreeSize is the size of the tree the entries were served from, i.e. the range ends at most at TreeSize-1.
+	// It is zero if the log does not return the range served, in which case Start and End are not set either.
+	TreeSize uint64 `json:"tree_size,omitempty"`
 }
 
 // LeafEntry represents a leaf in the Log's Merkle tree.
@@ -135,6 +178,84 @@ type LeafEntry struct {
 	ExtraData []byte `json:"extra_data"`
 }
 
+// DecodeTimestampedEntry decodes the MerkleTreeLeaf of the leaf input and returns the timestamp and the
+// credential of its timestamped entry. The credential is returned in the form the log stored it, i.e. the
+// URDNA2015 canonical form without the proof, the proofs of the credential are kept in the extra data.
+// Entries of verifiable presentations are rejected, see DecodeTimestampedEntryWithType.
+func (e LeafEntry) DecodeTimestampedEntry() (timestamp uint64, vc []byte, err error) {
+	entryType, timestamp, vc, err := e.DecodeTimestampedEntryWithType()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if entryType != VCLogEntryType {
+		return 0, nil, fmt.Errorf("unsupported entry type %d", entryType)
+	}
+
+	return timestamp, vc, nil
+}
+
+// DecodeTimestampedEntryWithType decodes the MerkleTreeLeaf of the leaf input like DecodeTimestampedEntry, but
+// accepts entries of verifiable presentations too. The entry type tells whether the entry is the canonical form
+// of a credential (VCLogEntryType) or of a presentation (VPLogEntryType).
+func (e LeafEntry) DecodeTimestampedEntryWithType() (entryType LogEntryType, timestamp uint64, entry []byte,
+	err error) {
+	var leaf MerkleTreeLeaf
+
+	if err = json.Unmarshal(e.LeafInput, &leaf); err != nil {
+		return 0, 0, nil, fmt.Errorf("unmarshal MerkleTreeLeaf: %w", err)
+	}
+
+	if leaf.Version != V1 {
+		return 0, 0, nil, fmt.Errorf("unsupported leaf version %d", leaf.Version)
+	}
+
+	if leaf.LeafType != TimestampedEntryLeafType {
+		return 0, 0, nil, fmt.Errorf("unsupported leaf type %d", leaf.LeafType)
+	}
+
+	if leaf.TimestampedEntry == nil {
+		return 0, 0, nil, fmt.Errorf("leaf has no timestamped entry")
+	}
+
+	switch leaf.TimestampedEntry.EntryType {
+	case VCLogEntryType, VPLogEntryType:
+	default:
+		return 0, 0, nil, fmt.Errorf("unsupported entry type %d", leaf.TimestampedEntry.EntryType)
+	}
+
+	return leaf.TimestampedEntry.EntryType, leaf.TimestampedEntry.Timestamp, leaf.TimestampedEntry.VCEntry, nil
+}
+
+// DecodeExtraData decodes the extra data and returns the proofs of the credential or presentation of the entry,
+// each as the JSON object of the proof. The log keeps the proofs in the extra data as the canonical JSON array of
+// the proofs, as the credential of the timestamped entry is stored without them; the extra data is empty if the
+// credential has no proofs, in which case no proofs are returned. The proofs are returned in the order of the
+// credential.
+func (e LeafEntry) DecodeExtraData() ([][]byte, error) {
+	if len(e.ExtraData) == 0 {
+		return nil, nil
+	}
+
+	var proofs []json.RawMessage
+
+	if err := json.Unmarshal(e.ExtraData, &proofs); err != nil {
+		return nil, fmt.Errorf("unmarshal extra data: %w", err)
+	}
+
+	result := make([][]byte, len(proofs))
+
+	for i, proof := range proofs {
+		if len(proof) == 0 || proof[0] != '{' {
+			return nil, fmt.Errorf("extra data: proof %d is not a JSON object", i)
+		}
+
+		result[i] = proof
+	}
+
+	return result, nil
+}
+
 // Validate validates data.
 func (r *GetEntriesRequest) Validate() error {
 	if r == nil {
@@ -253,6 +374,64 @@ type AddVCResponse struct {
 type AddVCRequest struct {
 	Alias   string `json:"alias"`
 	VCEntry []byte `json:"vc_entry"`
+	// Extensions are the submitter-supplied extensions of the entry, e.g. a correlation key. Ignored by validate-vc.
+	Extensions map[string]string `json:"extensions,omitempty"`
+}
+
+// ValidateVCResponse represents the response to validate-vc. It is empty: a credential that fails validation is
+// reported with an error instead.
+type ValidateVCResponse struct{}
+
+// AddVPRequest represents the request to add-vp.
+type AddVPRequest struct {
+	Alias   string `json:"alias"`
+	VPEntry []byte `json:"vp_entry"`
+}
+
+// AddVCBatchRequest represents the request to add-vc-batch.
+type AddVCBatchRequest struct {
+	Alias     string   `json:"alias"`
+	VCEntries [][]byte `json:"vc_entries"`
+}
+
+// AddVCBatchResult represents the result of adding a single credential of the batch. Either the response or
+// the error is set.
+type AddVCBatchResult struct {
+	Response *AddVCResponse `json:"response,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	// StatusCode is the HTTP status code add-vc would have responded with for the credential, set with the error,
+	// e.g. 400 Bad Request for a malformed credential or 409 Conflict for a duplicate.
+	StatusCode int `json:"statusCode,omitempty"`
+}
+
+// AddVCBatchResponse represents the response to add-vc-batch. The results are aligned by index with
+// the credentials of the request.
+type AddVCBatchResponse struct {
+	Results []*AddVCBatchResult `json:"results"`
+}
+
+// Health statuses of HealthResponse.
+const (
+	// HealthStatusSuccess is the status of a log whose storage, key manager and tree, if checked, are reachable.
+	HealthStatusSuccess = "success"
+	// HealthStatusUnavailable is the status of a log whose storage, key manager or tree is unreachable.
+	HealthStatusUnavailable = "unavailable"
+)
+
+// HealthResponse represents the response to the health check.
+type HealthResponse struct {
+	Status    string `json:"status"`
+	DBStatus  string `json:"dbStatus,omitempty"`
+	KMSStatus string `json:"kmsStatus,omitempty"`
+	// TrillianStatus is the status of the tree of the alias, it is only set by the health check of the alias.
+	TrillianStatus string `json:"trillianStatus,omitempty"`
+	// Time is the time of the log, in the RFC 3339 format of the health check of previous versions.
+	Time time.Time `json:"currentTime,omitempty"`
+	// CurrentTime is the time of the log in milliseconds since the epoch, as the timestamps of the log.
+	CurrentTime int64 `json:"currentTimeMillis,omitempty"`
+	// TreeSize is the size of the tree of the alias, it is only set by the health check of the alias.
+	TreeSize uint64 `json:"treeSize,omitempty"`
+	Version  string `json:"version,omitempty"`
 }
 
 // WebFingerResponse web finger response.
@@ -262,6 +441,64 @@ type WebFingerResponse struct {
 	Links      []WebFingerLink        `json:"links,omitempty"`
 }
 
+// LinkByRel returns the first link with the given relation, e.g. SelfRel.
+func (r WebFingerResponse) LinkByRel(rel string) (WebFingerLink, bool) {
+	for _, link := range r.Links {
+		if link.Rel == rel {
+			return link, true
+		}
+	}
+
+	return WebFingerLink{}, false
+}
+
+// LinksByRel returns all links with the given relation in the order of the response.
+func (r WebFingerResponse) LinksByRel(rel string) []WebFingerLink {
+	var links []WebFingerLink
+
+	for _, link := range r.Links {
+		if link.Rel == rel {
+			links = append(links, link)
+		}
+	}
+
+	return links
+}
+
+// PublicKey returns the public key of the log, decoded from the base64 PublicKeyType property.
+func (r WebFingerResponse) PublicKey() ([]byte, error) {
+	encoded, err := r.stringProperty(PublicKeyType)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decode public key: %w", err)
+	}
+
+	return pubKey, nil
+}
+
+// LedgerType returns the LedgerType property, e.g. vct-v1.
+func (r WebFingerResponse) LedgerType() (string, error) {
+	return r.stringProperty(LedgerType)
+}
+
+func (r WebFingerResponse) stringProperty(name string) (string, error) {
+	value, ok := r.Properties[name]
+	if !ok {
+		return "", fmt.Errorf("webfinger response has no %s property", name)
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s property is not a string", name)
+	}
+
+	return s, nil
+}
+
 // WebFingerLink web finger link.
 type WebFingerLink struct {
 	Rel  string `json:"rel,omitempty"`
@@ -7,10 +7,12 @@ SPDX-License-Identifier: Apache-2.0
 package command_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/trustbloc/vct/pkg/canonicalizer"
 	. "github.com/trustbloc/vct/pkg/controller/command"
 )
 
@@ -71,3 +73,200 @@ func TestGetSTHConsistencyRequest_Validate(t *testing.T) {
 		"validation failed: first_tree_size 2 and second_tree_size 1 values is not a valid range",
 	)
 }
+
+func TestWebFingerResponse_LinksByRel(t *testing.T) {
+	resp := WebFingerResponse{
+		Links: []WebFingerLink{
+			{Rel: SelfRel, Href: "https://vct.example.com/maple2021"},
+			{Rel: "alternate", Href: "https://vct-1.example.com/maple2021"},
+			{Rel: "alternate", Href: "https://vct-2.example.com/maple2021"},
+		},
+	}
+
+	t.Run("Single link", func(t *testing.T) {
+		link, ok := resp.LinkByRel(SelfRel)
+		require.True(t, ok)
+		require.Equal(t, "https://vct.example.com/maple2021", link.Href)
+
+		require.Equal(t, []WebFingerLink{resp.Links[0]}, resp.LinksByRel(SelfRel))
+	})
+
+	t.Run("Multiple links", func(t *testing.T) {
+		link, ok := resp.LinkByRel("alternate")
+		require.True(t, ok)
+		require.Equal(t, "https://vct-1.example.com/maple2021", link.Href)
+
+		require.Equal(t, resp.Links[1:], resp.LinksByRel("alternate"))
+	})
+
+	t.Run("Missing rel", func(t *testing.T) {
+		link, ok := resp.LinkByRel("monitor")
+		require.False(t, ok)
+		require.Empty(t, link)
+
+		require.Empty(t, resp.LinksByRel("monitor"))
+		require.Empty(t, WebFingerResponse{}.LinksByRel(SelfRel))
+	})
+}
+
+func TestWebFingerResponse_Properties(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		resp := WebFingerResponse{Properties: map[string]interface{}{
+			PublicKeyType: "cHVibGljIGtleQ==",
+			LedgerType:    "vct-v1",
+		}}
+
+		pubKey, err := resp.PublicKey()
+		require.NoError(t, err)
+		require.Equal(t, []byte("public key"), pubKey)
+
+		ledgerType, err := resp.LedgerType()
+		require.NoError(t, err)
+		require.Equal(t, "vct-v1", ledgerType)
+	})
+
+	t.Run("Absent", func(t *testing.T) {
+		_, err := WebFingerResponse{}.PublicKey()
+		require.EqualError(t, err, "webfinger response has no https://trustbloc.dev/ns/public-key property")
+
+		_, err = WebFingerResponse{}.LedgerType()
+		require.EqualError(t, err, "webfinger response has no https://trustbloc.dev/ns/ledger-type property")
+	})
+
+	t.Run("Wrong type", func(t *testing.T) {
+		resp := WebFingerResponse{Properties: map[string]interface{}{
+			PublicKeyType: []interface{}{"cHVibGljIGtleQ=="},
+			LedgerType:    1,
+		}}
+
+		_, err := resp.PublicKey()
+		require.EqualError(t, err, "https://trustbloc.dev/ns/public-key property is not a string")
+
+		_, err = resp.LedgerType()
+		require.EqualError(t, err, "https://trustbloc.dev/ns/ledger-type property is not a string")
+	})
+
+	t.Run("Invalid base64", func(t *testing.T) {
+		_, err := WebFingerResponse{Properties: map[string]interface{}{PublicKeyType: "%"}}.PublicKey()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decode public key")
+	})
+}
+
+func TestLeafEntry_DecodeTimestampedEntry(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		leafInput := `{"leaf_type":100,"timestamped_entry":{"entry_type":100,"extensions":null,` +
+			`"timestamp":1617107246070,"vc_entry":"Xzpi"},"version":0}`
+
+		timestamp, vc, err := LeafEntry{LeafInput: []byte(leafInput)}.DecodeTimestampedEntry()
+		require.NoError(t, err)
+		require.Equal(t, uint64(1617107246070), timestamp)
+		require.Equal(t, []byte("_:b"), vc)
+	})
+
+	t.Run("Presentation", func(t *testing.T) {
+		entry := LeafEntry{LeafInput: []byte(`{"leaf_type":100,"timestamped_entry":{"entry_type":101,` +
+			`"extensions":null,"timestamp":1617107246070,"vc_entry":"Xzpi"},"version":0}`)}
+
+		_, _, err := entry.DecodeTimestampedEntry()
+		require.EqualError(t, err, "unsupported entry type 101")
+
+		entryType, timestamp, vp, err := entry.DecodeTimestampedEntryWithType()
+		require.NoError(t, err)
+		require.Equal(t, VPLogEntryType, entryType)
+		require.Equal(t, uint64(1617107246070), timestamp)
+		require.Equal(t, []byte("_:b"), vp)
+	})
+
+	t.Run("Malformed leaf input", func(t *testing.T) {
+		for _, tc := range []struct {
+			name      string
+			leafInput string
+			expErr    string
+		}{
+			{
+				name:      "Not JSON",
+				leafInput: `[]`,
+				expErr:    "unmarshal MerkleTreeLeaf",
+			},
+			{
+				name:      "Unsupported version",
+				leafInput: `{"version":1,"leaf_type":100,"timestamped_entry":{"entry_type":100}}`,
+				expErr:    "unsupported leaf version 1",
+			},
+			{
+				name:      "Unsupported leaf type",
+				leafInput: `{"version":0,"leaf_type":1,"timestamped_entry":{"entry_type":100}}`,
+				expErr:    "unsupported leaf type 1",
+			},
+			{
+				name:      "No timestamped entry",
+				leafInput: `{"version":0,"leaf_type":100}`,
+				expErr:    "leaf has no timestamped entry",
+			},
+			{
+				name:      "Unsupported entry type",
+				leafInput: `{"version":0,"leaf_type":100,"timestamped_entry":{"entry_type":1}}`,
+				expErr:    "unsupported entry type 1",
+			},
+		} {
+			tc := tc
+			t.Run(tc.name, func(t *testing.T) {
+				_, _, err := LeafEntry{LeafInput: []byte(tc.leafInput)}.DecodeTimestampedEntry()
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expErr)
+			})
+		}
+	})
+}
+
+func TestLeafEntry_DecodeExtraData(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		proofs := []map[string]interface{}{
+			{"type": "Ed25519Signature2018", "jws": "eyJhbGciOiJFZERTQSJ9..sig1", "proofPurpose": "assertionMethod"},
+			{"type": "BbsBlsSignature2020", "proofValue": "c2lnMg=="},
+		}
+
+		extraData, err := canonicalizer.MarshalCanonical(proofs)
+		require.NoError(t, err)
+
+		decoded, err := LeafEntry{ExtraData: extraData}.DecodeExtraData()
+		require.NoError(t, err)
+		require.Len(t, decoded, 2)
+
+		for i, proof := range decoded {
+			var actual map[string]interface{}
+
+			require.NoError(t, json.Unmarshal(proof, &actual))
+			require.Equal(t, proofs[i], actual)
+		}
+
+		// proofs are returned in their canonical form, so they can be compared byte by byte
+		first, err := canonicalizer.MarshalCanonical(proofs[0])
+		require.NoError(t, err)
+		require.Equal(t, first, decoded[0])
+	})
+
+	t.Run("No proofs", func(t *testing.T) {
+		decoded, err := LeafEntry{}.DecodeExtraData()
+		require.NoError(t, err)
+		require.Empty(t, decoded)
+
+		decoded, err = LeafEntry{ExtraData: []byte(`[]`)}.DecodeExtraData()
+		require.NoError(t, err)
+		require.Empty(t, decoded)
+	})
+
+	t.Run("Malformed extra data", func(t *testing.T) {
+		_, err := LeafEntry{ExtraData: []byte(`{"type":"Ed25519Signature2018"}`)}.DecodeExtraData()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal extra data")
+
+		_, err = LeafEntry{ExtraData: []byte(`[{"type":"Ed25519Signature2018"`)}.DecodeExtraData()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal extra data")
+
+		_, err = LeafEntry{ExtraData: []byte(`[{"type":"Ed25519Signature2018"},"proof"]`)}.DecodeExtraData()
+		require.EqualError(t, err, "extra data: proof 1 is not a JSON object")
+	})
+}
@@ -0,0 +1,104 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	logIDSize      = 32
+	sctHeaderSize  = 1 + logIDSize + 8
+	sctLengthSize  = 2
+	sctMinimumSize = sctHeaderSize + 2*sctLengthSize
+)
+
+// MarshalSCT serializes the signed credential timestamp into the binary form it is stored and presented in.
+// The layout follows the TLS encoding of the signed certificate timestamp of RFC 6962, all integers are big-endian:
+//
+//	version         1 byte, the SVCTVersion
+//	log ID          32 bytes, the ID
+//	timestamp       8 bytes, the Timestamp in milliseconds since the epoch
+//	extensions      2 bytes length, followed by the raw (base64 decoded) Extensions
+//	signature       2 bytes length, followed by the Signature, the JSON encoded DigitallySigned
+func (r *AddVCResponse) MarshalSCT() ([]byte, error) {
+	if len(r.ID) != logIDSize {
+		return nil, fmt.Errorf("log ID has %d bytes, expected %d", len(r.ID), logIDSize)
+	}
+
+	extensions, err := base64.StdEncoding.DecodeString(r.Extensions)
+	if err != nil {
+		return nil, fmt.Errorf("decode extensions: %w", err)
+	}
+
+	if len(extensions) > math.MaxUint16 || len(r.Signature) > math.MaxUint16 {
+		return nil, errors.New("extensions or signature exceed the maximum length")
+	}
+
+	sct := make([]byte, 0, sctMinimumSize+len(extensions)+len(r.Signature))
+	sct = append(sct, byte(r.SVCTVersion))
+	sct = append(sct, r.ID...)
+	sct = binary.BigEndian.AppendUint64(sct, r.Timestamp)
+	sct = binary.BigEndian.AppendUint16(sct, uint16(len(extensions)))
+	sct = append(sct, extensions...)
+	sct = binary.BigEndian.AppendUint16(sct, uint16(len(r.Signature)))
+	sct = append(sct, r.Signature...)
+
+	return sct, nil
+}
+
+// UnmarshalSCT parses the signed credential timestamp serialized by MarshalSCT.
+func UnmarshalSCT(sct []byte) (*AddVCResponse, error) {
+	if len(sct) < sctMinimumSize {
+		return nil, fmt.Errorf("SCT has %d bytes, expected at least %d", len(sct), sctMinimumSize)
+	}
+
+	resp := &AddVCResponse{
+		SVCTVersion: Version(sct[0]),
+		ID:          append([]byte{}, sct[1:1+logIDSize]...),
+		Timestamp:   binary.BigEndian.Uint64(sct[1+logIDSize : sctHeaderSize]),
+	}
+
+	extensions, rest, err := readOpaque(sct[sctHeaderSize:])
+	if err != nil {
+		return nil, fmt.Errorf("read extensions: %w", err)
+	}
+
+	signature, rest, err := readOpaque(rest)
+	if err != nil {
+		return nil, fmt.Errorf("read signature: %w", err)
+	}
+
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("SCT has %d trailing bytes", len(rest))
+	}
+
+	resp.Extensions = base64.StdEncoding.EncodeToString(extensions)
+	resp.Signature = signature
+
+	return resp, nil
+}
+
+// readOpaque reads a value prefixed with its 2 bytes length and returns it with the remaining data.
+func readOpaque(data []byte) ([]byte, []byte, error) {
+	if len(data) < sctLengthSize {
+		return nil, nil, errors.New("missing length")
+	}
+
+	n := int(binary.BigEndian.Uint16(data))
+	data = data[sctLengthSize:]
+
+	if len(data) < n {
+		return nil, nil, fmt.Errorf("length %d exceeds the remaining %d bytes", n, len(data))
+	}
+
+	return append([]byte{}, data[:n]...), data[n:], nil
+}
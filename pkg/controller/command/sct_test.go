@@ -0,0 +1,87 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/trustbloc/vct/pkg/controller/command"
+)
+
+func TestAddVCResponse_MarshalSCT(t *testing.T) {
+	resp := &AddVCResponse{
+		SVCTVersion: V1,
+		ID:          bytes.Repeat([]byte{0xab}, 32),
+		Timestamp:   1662067083140,
+		Extensions:  base64.StdEncoding.EncodeToString([]byte{1, 2}),
+		Signature:   []byte(`{"algorithm":{"signature":"ECDSA","type":"ECDSAP256DER"},"signature":"c2ln"}`),
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		sct, err := resp.MarshalSCT()
+		require.NoError(t, err)
+
+		expected := append([]byte{0x00}, resp.ID...)
+		expected = append(expected, 0x00, 0x00, 0x01, 0x82, 0xfa, 0xeb, 0x07, 0x84)
+		expected = append(expected, 0x00, 0x02, 0x01, 0x02)
+		expected = append(expected, 0x00, byte(len(resp.Signature)))
+		expected = append(expected, resp.Signature...)
+		require.Equal(t, expected, sct)
+
+		parsed, err := UnmarshalSCT(sct)
+		require.NoError(t, err)
+		require.Equal(t, resp, parsed)
+	})
+
+	t.Run("No extensions", func(t *testing.T) {
+		sct, err := (&AddVCResponse{ID: resp.ID, Timestamp: 1}).MarshalSCT()
+		require.NoError(t, err)
+		require.Len(t, sct, 45)
+
+		parsed, err := UnmarshalSCT(sct)
+		require.NoError(t, err)
+		require.Empty(t, parsed.Extensions)
+		require.Empty(t, parsed.Signature)
+	})
+
+	t.Run("Invalid log ID", func(t *testing.T) {
+		_, err := (&AddVCResponse{ID: []byte{1}}).MarshalSCT()
+		require.EqualError(t, err, "log ID has 1 bytes, expected 32")
+	})
+
+	t.Run("Invalid extensions", func(t *testing.T) {
+		_, err := (&AddVCResponse{ID: resp.ID, Extensions: "%"}).MarshalSCT()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decode extensions")
+	})
+
+	t.Run("Signature too long", func(t *testing.T) {
+		_, err := (&AddVCResponse{ID: resp.ID, Signature: make([]byte, 1<<16)}).MarshalSCT()
+		require.EqualError(t, err, "extensions or signature exceed the maximum length")
+	})
+}
+
+func TestUnmarshalSCT(t *testing.T) {
+	sct, err := (&AddVCResponse{
+		ID:        bytes.Repeat([]byte{1}, 32),
+		Signature: []byte("signature"),
+	}).MarshalSCT()
+	require.NoError(t, err)
+
+	_, err = UnmarshalSCT(sct[:44])
+	require.EqualError(t, err, "SCT has 44 bytes, expected at least 45")
+
+	_, err = UnmarshalSCT(sct[:len(sct)-1])
+	require.EqualError(t, err, "read signature: length 9 exceeds the remaining 8 bytes")
+
+	_, err = UnmarshalSCT(append(sct, 0))
+	require.EqualError(t, err, "SCT has 1 trailing bytes")
+}
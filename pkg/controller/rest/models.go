@@ -30,6 +30,11 @@ type addVCRequest struct { // nolint: unused,deadcode
 	// required: true
 	Alias string `json:"alias"`
 
+	// Extensions of the entry, a JSON object of strings, e.g. {"correlation-key":"abc"}
+	//
+	// in: query
+	Extensions string `json:"extensions"`
+
 	// Verifiable Credentials https://www.w3.org/TR/vc-data-model
 	//
 	// in: body
@@ -59,6 +64,105 @@ type addVCResponse struct { // nolint: unused,deadcode
 	}
 }
 
+// Request message
+//
+// swagger:parameters validateVCRequest
+type validateVCRequest struct { // nolint: unused,deadcode
+	// Alias
+	//
+	// in: path
+	// required: true
+	Alias string `json:"alias"`
+
+	// Verifiable Credentials https://www.w3.org/TR/vc-data-model
+	//
+	// in: body
+	Body struct {
+		Context           []string `json:"@context"`
+		CredentialSubject struct {
+			ID string `json:"id"`
+		} `json:"credentialSubject"`
+		ID           string    `json:"id"`
+		IssuanceDate time.Time `json:"issuanceDate"`
+		Issuer       string    `json:"issuer"`
+		Type         []string  `json:"type"`
+	}
+}
+
+// Response message
+//
+// swagger:response validateVCResponse
+type validateVCResponse struct { // nolint: unused,deadcode
+	// in: body
+	Body struct{}
+}
+
+// Request message
+//
+// swagger:parameters addVPRequest
+type addVPRequest struct { // nolint: unused,deadcode
+	// Alias
+	//
+	// in: path
+	// required: true
+	Alias string `json:"alias"`
+
+	// Verifiable Presentation https://www.w3.org/TR/vc-data-model
+	//
+	// in: body
+	Body struct {
+		Context              []string      `json:"@context"`
+		ID                   string        `json:"id"`
+		Type                 []string      `json:"type"`
+		Holder               string        `json:"holder"`
+		VerifiableCredential []interface{} `json:"verifiableCredential"`
+	}
+}
+
+// Request message
+//
+// swagger:parameters addVCBatchRequest
+type addVCBatchRequest struct { // nolint: unused,deadcode
+	// Alias
+	//
+	// in: path
+	// required: true
+	Alias string `json:"alias"`
+
+	// Verifiable Credentials https://www.w3.org/TR/vc-data-model
+	//
+	// in: body
+	Body []struct {
+		Context           []string `json:"@context"`
+		CredentialSubject struct {
+			ID string `json:"id"`
+		} `json:"credentialSubject"`
+		ID           string    `json:"id"`
+		IssuanceDate time.Time `json:"issuanceDate"`
+		Issuer       string    `json:"issuer"`
+		Type         []string  `json:"type"`
+	}
+}
+
+// Response message
+//
+// swagger:response addVCBatchResponse
+type addVCBatchResponse struct { // nolint: unused,deadcode
+	// in: body
+	Body struct {
+		Results []struct {
+			Response *struct {
+				SVCTVersion uint8  `json:"svct_version"`
+				ID          string `json:"id"`
+				Timestamp   uint64 `json:"timestamp"`
+				Extensions  string `json:"extensions"`
+				Signature   string `json:"signature"`
+			} `json:"response,omitempty"`
+			Error string `json:"error,omitempty"`
+		} `json:"results"`
+	}
+}
+
 // Request message
 //
 // swagger:parameters getSTHRequest
@@ -68,6 +172,10 @@ type getSTHRequest struct { // nolint: unused,deadcode
 	// in: path
 	// required: true
 	Alias string `json:"alias"`
+	// The entity tag of the last seen tree size, see TreeSizeETag
+	//
+	// in: header
+	IfNoneMatch string `json:"If-None-Match"`
 }
 
 // Response message
@@ -102,20 +210,85 @@ type getIssuersResponse struct { // nolint: unused,deadcode
 	Body []string
 }
 
+// Request message
+//
+// swagger:parameters getRootsRequest
+type getRootsRequest struct { // nolint: unused,deadcode
+	// Alias
+	//
+	// in: path
+	// required: true
+	Alias string `json:"alias"`
+}
+
+// Response message
+//
+// swagger:response getRootsResponse
+type getRootsResponse struct { // nolint: unused,deadcode
+	// in: body
+	Body command.GetRootsResponse
+}
+
+// Request message
+//
+// swagger:parameters getAcceptedContextsRequest
+type getAcceptedContextsRequest struct { // nolint: unused,deadcode
+	// Alias
+	//
+	// in: path
+	// required: true
+	Alias string `json:"alias"`
+}
+
+// Response message
+//
+// swagger:response getAcceptedContextsResponse
+type getAcceptedContextsResponse struct { // nolint: unused,deadcode
+	// in: body
+	Body []string
+}
+
+// Request message
+//
+// swagger:parameters getLogInfoRequest
+type getLogInfoRequest struct { // nolint: unused,deadcode
+	// Alias
+	//
+	// in: path
+	// required: true
+	Alias string `json:"alias"`
+}
+
+// Response message
+//
+// swagger:response getLogInfoResponse
+type getLogInfoResponse struct { // nolint: unused,deadcode
+	// in: body
+	Body command.LogInfoResponse
+}
+
 // Request message
 //
 // swagger:parameters healthCheckRequest
 type healthCheckRequest struct{} // nolint: unused,deadcode
 
+// Request message
+//
+// swagger:parameters logHealthCheckRequest
+type logHealthCheckRequest struct { // nolint: unused,deadcode
+	// Alias
+	//
+	// in: path
+	// required: true
+	Alias string `json:"alias"`
+}
+
 // Response message
 //
 // swagger:response healthCheckResponse
 type healthCheckResponse struct { // nolint: unused,deadcode
 	// in: body
-	Body struct {
-		Status      string    `json:"status"`
-		CurrentTime time.Time `json:"current_time"`
-	}
+	Body command.HealthResponse
 }
 
 // Response message
@@ -208,6 +381,9 @@ type getEntriesResponse struct { // nolint: unused,deadcode
 			LeafInput string `json:"leaf_input"`
 			ExtraData string `json:"extra_data"`
 		} `json:"entries"`
+		Start    int64  `json:"start,omitempty"`
+		End      int64  `json:"end,omitempty"`
+		TreeSize uint64 `json:"tree_size,omitempty"`
 	}
 }
 
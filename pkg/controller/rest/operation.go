@@ -35,30 +35,40 @@ var (
 
 // API endpoints.
 const (
-	aliasVarName          = "alias"
-	AliasPath             = "/{" + aliasVarName + "}"
-	BasePath              = AliasPath + "/v1"
-	AddVCPath             = BasePath + "/add-vc"
-	GetSTHPath            = BasePath + "/get-sth"
-	GetSTHConsistencyPath = BasePath + "/get-sth-consistency"
-	GetProofByHashPath    = BasePath + "/get-proof-by-hash"
-	GetEntriesPath        = BasePath + "/get-entries"
-	GetIssuersPath        = BasePath + "/get-issuers"
-	GetEntryAndProofPath  = BasePath + "/get-entry-and-proof"
-	WebfingerPath         = "/.well-known/webfinger"
-	HealthCheckPath       = "/healthcheck"
-	MetricsPath           = "/metrics"
+	aliasVarName            = "alias"
+	AliasPath               = "/{" + aliasVarName + "}"
+	BasePath                = AliasPath + "/v1"
+	AddVCPath               = BasePath + "/add-vc"
+	AddVCBatchPath          = BasePath + "/add-vc-batch"
+	AddVPPath               = BasePath + "/add-vp"
+	ValidateVCPath          = BasePath + "/validate-vc"
+	GetSTHPath              = BasePath + "/get-sth"
+	GetSTHConsistencyPath   = BasePath + "/get-sth-consistency"
+	GetProofByHashPath      = BasePath + "/get-proof-by-hash"
+	GetEntriesPath          = BasePath + "/get-entries"
+	GetIssuersPath          = BasePath + "/get-issuers"
+	GetRootsPath            = BasePath + "/get-roots"
+	GetAcceptedContextsPath = BasePath + "/get-accepted-contexts"
+	GetLogInfoPath          = BasePath + "/get-log-info"
+	GetEntryAndProofPath    = BasePath + "/get-entry-and-proof"
+	LogHealthCheckPath      = BasePath + "/healthcheck"
+	WebfingerPath           = "/.well-known/webfinger"
+	HealthCheckPath         = "/healthcheck"
+	MetricsPath             = "/metrics"
 )
 
 // Parameters.
 const (
-	resourceParam = "resource"
+	resourceParam   = "resource"
+	extensionsParam = "extensions"
 )
 
 const (
-	success         = "success"
-	contentType     = "Content-Type"
-	applicationJSON = "application/json"
+	success           = "success"
+	contentType       = "Content-Type"
+	applicationJSON   = "application/json"
+	etagHeader        = "ETag"
+	ifNoneMatchHeader = "If-None-Match"
 )
 
 type db interface {
@@ -69,32 +79,37 @@ type keyManager interface {
 	HealthCheck() error
 }
 
-type healthCheckResp struct {
-	DBStatus    string    `json:"dbStatus,omitempty"`
-	KMSStatus   string    `json:"kmsStatus,omitempty"`
-	CurrentTime time.Time `json:"currentTime,omitempty"`
-	Version     string    `json:"version,omitempty"`
-}
-
 // nolint: gochecknoglobals
 var (
-	once                     sync.Once
-	addVCCounter             monitoring.Counter
-	addVCLatency             monitoring.Histogram
-	getSTHCounter            monitoring.Counter
-	getSTHLatency            monitoring.Histogram
-	getSTHConsistencyCounter monitoring.Counter
-	getSTHConsistencyLatency monitoring.Histogram
-	getProofByHashCounter    monitoring.Counter
-	getProofByHashLatency    monitoring.Histogram
-	getEntriesCounter        monitoring.Counter
-	getEntriesLatency        monitoring.Histogram
-	getEntryAndProofCounter  monitoring.Counter
-	getEntryAndProofLatency  monitoring.Histogram
-	getIssuersCounter        monitoring.Counter
-	getIssuersLatency        monitoring.Histogram
-	webfingerCounter         monitoring.Counter
-	webfingerLatency         monitoring.Histogram
+	once                       sync.Once
+	addVCCounter               monitoring.Counter
+	addVCLatency               monitoring.Histogram
+	addVCBatchCounter          monitoring.Counter
+	addVCBatchLatency          monitoring.Histogram
+	addVPCounter               monitoring.Counter
+	addVPLatency               monitoring.Histogram
+	getSTHCounter              monitoring.Counter
+	getSTHLatency              monitoring.Histogram
+	getSTHConsistencyCounter   monitoring.Counter
+	getSTHConsistencyLatency   monitoring.Histogram
+	getProofByHashCounter      monitoring.Counter
+	getProofByHashLatency      monitoring.Histogram
+	getEntriesCounter          monitoring.Counter
+	getEntriesLatency          monitoring.Histogram
+	getEntryAndProofCounter    monitoring.Counter
+	getEntryAndProofLatency    monitoring.Histogram
+	getIssuersCounter          monitoring.Counter
+	getIssuersLatency          monitoring.Histogram
+	getRootsCounter            monitoring.Counter
+	getRootsLatency            monitoring.Histogram
+	getAcceptedContextsCounter monitoring.Counter
+	getAcceptedContextsLatency monitoring.Histogram
+	getLogInfoCounter          monitoring.Counter
+	getLogInfoLatency          monitoring.Histogram
+	validateVCCounter          monitoring.Counter
+	validateVCLatency          monitoring.Histogram
+	webfingerCounter           monitoring.Counter
+	webfingerLatency           monitoring.Histogram
 )
 
 // nolint: lll
@@ -102,6 +117,12 @@ func createMetrics(mf monitoring.MetricFactory) {
 	addVCCounter = mf.NewCounter("add_vc", "Number of /add-vc operation", "alias")
 	addVCLatency = mf.NewHistogram("add_vc_latency", "Latency of /add-vc operation in seconds", "alias")
 
+	addVCBatchCounter = mf.NewCounter("add_vc_batch", "Number of /add-vc-batch operation", "alias")
+	addVCBatchLatency = mf.NewHistogram("add_vc_batch_latency", "Latency of /add-vc-batch operation in seconds", "alias")
+
+	addVPCounter = mf.NewCounter("add_vp", "Number of /add-vp operation", "alias")
+	addVPLatency = mf.NewHistogram("add_vp_latency", "Latency of /add-vp operation in seconds", "alias")
+
 	getSTHCounter = mf.NewCounter("get_sth", "Number of /get-sth operation", "alias")
 	getSTHLatency = mf.NewHistogram("get_sth_latency", "Latency of /get-sth operation in seconds", "alias")
 
@@ -120,6 +141,18 @@ func createMetrics(mf monitoring.MetricFactory) {
 	getIssuersCounter = mf.NewCounter("get_issuers", "Number of /get-issuers operation", "alias")
 	getIssuersLatency = mf.NewHistogram("get_issuers_latency", "Latency of /get-issuers operation in seconds", "alias")
 
+	getRootsCounter = mf.NewCounter("get_roots", "Number of /get-roots operation", "alias")
+	getRootsLatency = mf.NewHistogram("get_roots_latency", "Latency of /get-roots operation in seconds", "alias")
+
+	getAcceptedContextsCounter = mf.NewCounter("get_accepted_contexts", "Number of /get-accepted-contexts operation", "alias")
+	getAcceptedContextsLatency = mf.NewHistogram("get_accepted_contexts_latency", "Latency of /get-accepted-contexts operation in seconds", "alias")
+
+	getLogInfoCounter = mf.NewCounter("get_log_info", "Number of /get-log-info operation", "alias")
+	getLogInfoLatency = mf.NewHistogram("get_log_info_latency", "Latency of /get-log-info operation in seconds", "alias")
+
+	validateVCCounter = mf.NewCounter("validate_vc", "Number of /validate-vc operation", "alias")
+	validateVCLatency = mf.NewHistogram("validate_vc_latency", "Latency of /validate-vc operation in seconds", "alias")
+
 	webfingerCounter = mf.NewCounter("webfinger", "Number of /webfinger operation", "alias")
 	webfingerLatency = mf.NewHistogram("webfinger_latency", "Latency of /webfinger operation in seconds", "alias")
 }
@@ -127,7 +160,13 @@ func createMetrics(mf monitoring.MetricFactory) {
 // Cmd defines command methods.
 type Cmd interface {
 	AddVC(io.Writer, io.Reader) error
+	AddVCBatch(io.Writer, io.Reader) error
+	AddVP(io.Writer, io.Reader) error
+	ValidateVC(io.Writer, io.Reader) error
 	GetIssuers(io.Writer, io.Reader) error
+	GetRoots(io.Writer, io.Reader) error
+	GetAcceptedContexts(io.Writer, io.Reader) error
+	GetLogInfo(io.Writer, io.Reader) error
 	GetSTH(io.Writer, io.Reader) error
 	GetSTHConsistency(io.Writer, io.Reader) error
 	GetProofByHash(io.Writer, io.Reader) error
@@ -159,14 +198,21 @@ func New(cmd Cmd, db db, keyManager keyManager, mf monitoring.MetricFactory) *Op
 func (c *Operation) GetRESTHandlers() []Handler {
 	return []Handler{
 		NewHTTPHandler(AddVCPath, http.MethodPost, c.AddVC),
+		NewHTTPHandler(AddVCBatchPath, http.MethodPost, c.AddVCBatch),
+		NewHTTPHandler(AddVPPath, http.MethodPost, c.AddVP),
+		NewHTTPHandler(ValidateVCPath, http.MethodPost, c.ValidateVC),
 		NewHTTPHandler(GetSTHPath, http.MethodGet, c.GetSTH),
 		NewHTTPHandler(GetSTHConsistencyPath, http.MethodGet, c.GetSTHConsistency),
 		NewHTTPHandler(GetProofByHashPath, http.MethodGet, c.GetProofByHash),
 		NewHTTPHandler(GetEntriesPath, http.MethodGet, c.GetEntries),
 		NewHTTPHandler(GetIssuersPath, http.MethodGet, c.GetIssuers),
+		NewHTTPHandler(GetRootsPath, http.MethodGet, c.GetRoots),
+		NewHTTPHandler(GetAcceptedContextsPath, http.MethodGet, c.GetAcceptedContexts),
+		NewHTTPHandler(GetLogInfoPath, http.MethodGet, c.GetLogInfo),
 		NewHTTPHandler(WebfingerPath, http.MethodGet, c.Webfinger),
 		NewHTTPHandler(GetEntryAndProofPath, http.MethodGet, c.GetEntryAndProof),
 		NewHTTPHandler(HealthCheckPath, http.MethodGet, c.HealthCheck),
+		NewHTTPHandler(LogHealthCheckPath, http.MethodGet, c.LogHealthCheck),
 		// Metrics
 		NewHTTPHandler(MetricsPath, http.MethodGet, c.metrics()),
 	}
@@ -206,9 +252,20 @@ func (c *Operation) AddVC(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var extensions map[string]string
+
+	if param := r.URL.Query().Get(extensionsParam); param != "" {
+		if err = json.Unmarshal([]byte(param), &extensions); err != nil {
+			sendError(w, errors.NewBadRequestError(fmt.Errorf("decode extensions: %w", err)))
+
+			return
+		}
+	}
+
 	req, err := json.Marshal(command.AddVCRequest{
-		Alias:   mux.Vars(r)[aliasVarName],
-		VCEntry: vcEntry.Bytes(),
+		Alias:      mux.Vars(r)[aliasVarName],
+		VCEntry:    vcEntry.Bytes(),
+		Extensions: extensions,
 	})
 	if err != nil {
 		sendError(w, fmt.Errorf("%w: marshal AddVCRequest", errors.ErrInternal))
@@ -228,9 +285,143 @@ func (c *Operation) AddVC(w http.ResponseWriter, r *http.Request) {
 	}, w, bytes.NewBuffer(req))
 }
 
+// AddVCBatch swagger:route POST /{alias}/v1/add-vc-batch vct addVCBatchRequest
+//
+// Adds verifiable credentials to log.
+//
+// Responses:
+//
+//	default: genericError
+//	200: addVCBatchResponse
+func (c *Operation) AddVCBatch(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	var vcEntries []json.RawMessage
+
+	if err := json.NewDecoder(r.Body).Decode(&vcEntries); err != nil {
+		sendError(w, errors.NewBadRequestError(fmt.Errorf("decode credentials: %w", err)))
+
+		return
+	}
+
+	batch := command.AddVCBatchRequest{
+		Alias:     mux.Vars(r)[aliasVarName],
+		VCEntries: make([][]byte, len(vcEntries)),
+	}
+
+	for i, vcEntry := range vcEntries {
+		batch.VCEntries[i] = vcEntry
+	}
+
+	req, err := json.Marshal(batch)
+	if err != nil {
+		sendError(w, fmt.Errorf("%w: marshal AddVCBatchRequest", errors.ErrInternal))
+
+		return
+	}
+
+	execute(func(rw io.Writer, req io.Reader) error {
+		if err := c.cmd.AddVCBatch(rw, req); err != nil {
+			return err
+		}
+
+		addVCBatchCounter.Add(1, mux.Vars(r)[aliasVarName])
+		addVCBatchLatency.Observe(time.Since(start).Seconds(), mux.Vars(r)[aliasVarName])
+
+		return nil
+	}, w, bytes.NewBuffer(req))
+}
+
+// AddVP swagger:route POST /{alias}/v1/add-vp vct addVPRequest
+//
+// Adds verifiable presentation to log.
+//
+// Responses:
+//
+//	default: genericError
+//	200: addVCResponse
+func (c *Operation) AddVP(w http.ResponseWriter, r *http.Request) {
+	var (
+		start   = time.Now()
+		vpEntry bytes.Buffer
+	)
+
+	_, err := io.Copy(&vpEntry, r.Body)
+	if err != nil {
+		sendError(w, fmt.Errorf("%w: copy vp", errors.ErrInternal))
+
+		return
+	}
+
+	req, err := json.Marshal(command.AddVPRequest{
+		Alias:   mux.Vars(r)[aliasVarName],
+		VPEntry: vpEntry.Bytes(),
+	})
+	if err != nil {
+		sendError(w, fmt.Errorf("%w: marshal AddVPRequest", errors.ErrInternal))
+
+		return
+	}
+
+	execute(func(rw io.Writer, req io.Reader) error {
+		if err := c.cmd.AddVP(rw, req); err != nil {
+			return err
+		}
+
+		addVPCounter.Add(1, mux.Vars(r)[aliasVarName])
+		addVPLatency.Observe(time.Since(start).Seconds(), mux.Vars(r)[aliasVarName])
+
+		return nil
+	}, w, bytes.NewBuffer(req))
+}
+
+// ValidateVC swagger:route POST /{alias}/v1/validate-vc vct validateVCRequest
+//
+// Checks that the verifiable credential would be added to log, without adding it.
+//
+// Responses:
+//
+//	default: genericError
+//	200: validateVCResponse
+func (c *Operation) ValidateVC(w http.ResponseWriter, r *http.Request) {
+	var (
+		start   = time.Now()
+		vcEntry bytes.Buffer
+	)
+
+	_, err := io.Copy(&vcEntry, r.Body)
+	if err != nil {
+		sendError(w, fmt.Errorf("%w: copy vc", errors.ErrInternal))
+
+		return
+	}
+
+	req, err := json.Marshal(command.AddVCRequest{
+		Alias:   mux.Vars(r)[aliasVarName],
+		VCEntry: vcEntry.Bytes(),
+	})
+	if err != nil {
+		sendError(w, fmt.Errorf("%w: marshal ValidateVCRequest", errors.ErrInternal))
+
+		return
+	}
+
+	execute(func(rw io.Writer, req io.Reader) error {
+		if err := c.cmd.ValidateVC(rw, req); err != nil {
+			return err
+		}
+
+		validateVCCounter.Add(1, mux.Vars(r)[aliasVarName])
+		validateVCLatency.Observe(time.Since(start).Seconds(), mux.Vars(r)[aliasVarName])
+
+		return nil
+	}, w, bytes.NewBuffer(req))
+}
+
 // GetSTH swagger:route GET /{alias}/v1/get-sth vct getSTHRequest
 //
-// Retrieves the latest signed tree head.
+// Retrieves the latest signed tree head, or responds with 304 Not Modified if the If-None-Match header has the
+// entity tag of its tree size.
 //
 // Responses:
 //
@@ -240,6 +431,14 @@ func (c *Operation) GetSTH(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 
 	execute(func(rw io.Writer, req io.Reader) error {
+		ifNoneMatch := r.Header.Get(ifNoneMatchHeader)
+
+		var buf bytes.Buffer
+
+		if ifNoneMatch != "" {
+			rw = &buf
+		}
+
 		if err := c.cmd.GetSTH(rw, req); err != nil {
 			return err
 		}
@@ -247,10 +446,37 @@ func (c *Operation) GetSTH(w http.ResponseWriter, r *http.Request) {
 		getSTHCounter.Add(1, mux.Vars(r)[aliasVarName])
 		getSTHLatency.Observe(time.Since(start).Seconds(), mux.Vars(r)[aliasVarName])
 
-		return nil
+		if ifNoneMatch == "" {
+			return nil
+		}
+
+		var sth command.GetSTHResponse
+
+		if err := json.Unmarshal(buf.Bytes(), &sth); err == nil {
+			etag := TreeSizeETag(sth.TreeSize)
+
+			w.Header().Set(etagHeader, etag)
+
+			if ifNoneMatch == etag {
+				w.WriteHeader(http.StatusNotModified)
+
+				return nil
+			}
+		}
+
+		_, err := w.Write(buf.Bytes())
+
+		return err // nolint: wrapcheck
 	}, w, bytes.NewBufferString(fmt.Sprintf("%q", mux.Vars(r)[aliasVarName])))
 }
 
+// TreeSizeETag returns the entity tag of the signed tree head of a tree of the given size. If the If-None-Match
+// header of a get-sth request has the entity tag of the tree size of the latest signed tree head, the log
+// responds with 304 Not Modified instead of sending the signed tree head again.
+func TreeSizeETag(treeSize uint64) string {
+	return strconv.Quote(strconv.FormatUint(treeSize, 10))
+}
+
 // GetIssuers swagger:route GET /{alias}/v1/get-issuers vct getIssuersRequest
 //
 // Returns issuers.
@@ -274,6 +500,75 @@ func (c *Operation) GetIssuers(w http.ResponseWriter, r *http.Request) {
 	}, w, bytes.NewBufferString(fmt.Sprintf("%q", mux.Vars(r)[aliasVarName])))
 }
 
+// GetRoots swagger:route GET /{alias}/v1/get-roots vct getRootsRequest
+//
+// Returns the trust anchors of the issuers the log accepts.
+//
+// Responses:
+//
+//	default: genericError
+//	    200: getRootsResponse
+func (c *Operation) GetRoots(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	execute(func(rw io.Writer, req io.Reader) error {
+		if err := c.cmd.GetRoots(rw, req); err != nil {
+			return err
+		}
+
+		getRootsCounter.Add(1, mux.Vars(r)[aliasVarName])
+		getRootsLatency.Observe(time.Since(start).Seconds(), mux.Vars(r)[aliasVarName])
+
+		return nil
+	}, w, bytes.NewBufferString(fmt.Sprintf("%q", mux.Vars(r)[aliasVarName])))
+}
+
+// GetAcceptedContexts swagger:route GET /{alias}/v1/get-accepted-contexts vct getAcceptedContextsRequest
+//
+// Returns the JSON-LD contexts the log is provisioned with.
+//
+// Responses:
+//
+//	default: genericError
+//	    200: getAcceptedContextsResponse
+func (c *Operation) GetAcceptedContexts(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	execute(func(rw io.Writer, req io.Reader) error {
+		if err := c.cmd.GetAcceptedContexts(rw, req); err != nil {
+			return err
+		}
+
+		getAcceptedContextsCounter.Add(1, mux.Vars(r)[aliasVarName])
+		getAcceptedContextsLatency.Observe(time.Since(start).Seconds(), mux.Vars(r)[aliasVarName])
+
+		return nil
+	}, w, bytes.NewBufferString(fmt.Sprintf("%q", mux.Vars(r)[aliasVarName])))
+}
+
+// GetLogInfo swagger:route GET /{alias}/v1/get-log-info vct getLogInfoRequest
+//
+// Returns the version, the hash algorithm and the features of the log.
+//
+// Responses:
+//
+//	default: genericError
+//	    200: getLogInfoResponse
+func (c *Operation) GetLogInfo(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	execute(func(rw io.Writer, req io.Reader) error {
+		if err := c.cmd.GetLogInfo(rw, req); err != nil {
+			return err
+		}
+
+		getLogInfoCounter.Add(1, mux.Vars(r)[aliasVarName])
+		getLogInfoLatency.Observe(time.Since(start).Seconds(), mux.Vars(r)[aliasVarName])
+
+		return nil
+	}, w, bytes.NewBufferString(fmt.Sprintf("%q", mux.Vars(r)[aliasVarName])))
+}
+
 // HealthCheck swagger:route GET /healthcheck vct healthCheckRequest
 //
 // Returns health check status.
@@ -283,6 +578,23 @@ func (c *Operation) GetIssuers(w http.ResponseWriter, r *http.Request) {
 //	default: genericError
 //	    200: healthCheckResponse
 func (c *Operation) HealthCheck(rw http.ResponseWriter, _ *http.Request) {
+	c.healthCheck(rw, "")
+}
+
+// LogHealthCheck swagger:route GET /{alias}/v1/healthcheck vct logHealthCheckRequest
+//
+// Returns health check status, including the status and the size of the tree of the alias.
+//
+// Responses:
+//
+//	default: genericError
+//	    200: healthCheckResponse
+func (c *Operation) LogHealthCheck(rw http.ResponseWriter, r *http.Request) {
+	c.healthCheck(rw, mux.Vars(r)[aliasVarName])
+}
+
+// healthCheck checks the storage and the key manager and, if the alias is set, the tree of the alias.
+func (c *Operation) healthCheck(rw http.ResponseWriter, alias string) {
 	dbStatus := ""
 	kmsStatus := ""
 
@@ -302,23 +614,64 @@ func (c *Operation) HealthCheck(rw http.ResponseWriter, _ *http.Request) {
 		}
 	}
 
-	if dbStatus != success || kmsStatus != success {
+	var (
+		trillianStatus string
+		treeSize       uint64
+	)
+
+	if alias != "" {
+		trillianStatus = success
+
+		var err error
+		if treeSize, err = c.treeSize(alias); err != nil {
+			trillianStatus = err.Error()
+		}
+	}
+
+	status := command.HealthStatusSuccess
+
+	if dbStatus != success || kmsStatus != success || (alias != "" && trillianStatus != success) {
+		status = command.HealthStatusUnavailable
+
 		rw.WriteHeader(http.StatusServiceUnavailable)
 	} else {
 		rw.WriteHeader(http.StatusOK)
 	}
 
-	err := json.NewEncoder(rw).Encode(&healthCheckResp{
-		DBStatus:    dbStatus,
-		KMSStatus:   kmsStatus,
-		CurrentTime: time.Now(),
-		Version:     BuildVersion,
+	now := time.Now()
+
+	err := json.NewEncoder(rw).Encode(&command.HealthResponse{
+		Status:         status,
+		DBStatus:       dbStatus,
+		KMSStatus:      kmsStatus,
+		TrillianStatus: trillianStatus,
+		Time:           now,
+		CurrentTime:    now.UnixMilli(),
+		TreeSize:       treeSize,
+		Version:        BuildVersion,
 	})
 	if err != nil {
 		logger.Error("Healthcheck response failure", log.WithError(err))
 	}
 }
 
+// treeSize returns the size of the tree of the alias, from its latest signed tree head.
+func (c *Operation) treeSize(alias string) (uint64, error) {
+	var buf bytes.Buffer
+
+	if err := c.cmd.GetSTH(&buf, bytes.NewBufferString(fmt.Sprintf("%q", alias))); err != nil {
+		return 0, err // nolint: wrapcheck
+	}
+
+	var sth command.GetSTHResponse
+
+	if err := json.Unmarshal(buf.Bytes(), &sth); err != nil {
+		return 0, fmt.Errorf("unmarshal STH: %w", err)
+	}
+
+	return sth.TreeSize, nil
+}
+
 // Webfinger swagger:route GET /.well-known/webfinger vct webfingerRequest
 //
 // Returns discovery info.
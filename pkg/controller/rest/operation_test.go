@@ -16,8 +16,10 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
@@ -85,6 +87,204 @@ func TestOperation_AddVC(t *testing.T) {
 
 		require.Equal(t, http.StatusBadRequest, code)
 	})
+
+	t.Run("Extensions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const dummyVC = `{credentials}`
+
+		cmd := NewMockCmd(ctrl)
+		cmd.EXPECT().AddVC(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+			payload, err := io.ReadAll(r)
+			require.NoError(t, err)
+
+			require.Equal(t, `{"alias":"maple2021","vc_entry":"e2NyZWRlbnRpYWxzfQ==","extensions":{"key":"value"}}`,
+				string(payload))
+		}).Return(nil)
+
+		operation := New(cmd, &mockService{}, &mockService{}, nil)
+
+		_, code := sendRequestToHandler(t,
+			handlerLookup(t, operation, AddVCPath),
+			bytes.NewBufferString(dummyVC),
+			strings.Replace(AddVCPath, "{alias}", alias, 1)+"?extensions="+url.QueryEscape(`{"key":"value"}`),
+		)
+
+		require.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("Invalid extensions", func(t *testing.T) {
+		operation := New(nil, &mockService{}, &mockService{}, nil)
+
+		body, code := sendRequestToHandler(t,
+			handlerLookup(t, operation, AddVCPath),
+			bytes.NewBufferString(`{credentials}`),
+			strings.Replace(AddVCPath, "{alias}", alias, 1)+"?extensions="+url.QueryEscape(`{"key":1}`),
+		)
+
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body.String(), "decode extensions")
+	})
+}
+
+func TestOperation_ValidateVC(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		const dummyVC = `{credentials}`
+
+		cmd := NewMockCmd(ctrl)
+		cmd.EXPECT().ValidateVC(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+			payload, err := io.ReadAll(r)
+			require.NoError(t, err)
+
+			require.Equal(t, `{"alias":"maple2021","vc_entry":"e2NyZWRlbnRpYWxzfQ=="}`, string(payload))
+		}).Return(nil)
+
+		operation := New(cmd, &mockService{}, &mockService{}, nil)
+
+		_, code := sendRequestToHandler(t,
+			handlerLookup(t, operation, ValidateVCPath),
+			bytes.NewBufferString(dummyVC), strings.Replace(ValidateVCPath, "{alias}", alias, 1),
+		)
+
+		require.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("Copy vc failed", func(t *testing.T) {
+		operation := New(nil, &mockService{}, &mockService{}, nil)
+
+		_, code := sendRequestToHandler(t,
+			handlerLookup(t, operation, ValidateVCPath),
+			&readerMock{errors.New("EOF")}, ValidateVCPath,
+		)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+	})
+
+	t.Run("Bad request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCmd(ctrl)
+		cmd.EXPECT().ValidateVC(gomock.Any(), gomock.Any()).Return(errors.ErrBadRequest)
+
+		operation := New(cmd, &mockService{}, &mockService{}, nil)
+
+		_, code := sendRequestToHandler(t,
+			handlerLookup(t, operation, ValidateVCPath),
+			bytes.NewBufferString(`{credentials}`), ValidateVCPath,
+		)
+
+		require.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+func TestOperation_AddVCBatch(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCmd(ctrl)
+		cmd.EXPECT().AddVCBatch(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+			payload, err := io.ReadAll(r)
+			require.NoError(t, err)
+
+			require.Equal(t, `{"alias":"maple2021","vc_entries":["eyJpZCI6InZjLTEifQ==","eyJpZCI6InZjLTIifQ=="]}`,
+				string(payload))
+		}).Return(nil)
+
+		operation := New(cmd, &mockService{}, &mockService{}, nil)
+
+		_, code := sendRequestToHandler(t,
+			handlerLookup(t, operation, AddVCBatchPath),
+			bytes.NewBufferString(`[{"id":"vc-1"},{"id":"vc-2"}]`), strings.Replace(AddVCBatchPath, "{alias}", alias, 1),
+		)
+
+		require.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("Malformed batch", func(t *testing.T) {
+		operation := New(nil, &mockService{}, &mockService{}, nil)
+
+		_, code := sendRequestToHandler(t,
+			handlerLookup(t, operation, AddVCBatchPath),
+			bytes.NewBufferString(`{"id":"vc-1"}`), AddVCBatchPath,
+		)
+
+		require.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("Command error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCmd(ctrl)
+		cmd.EXPECT().AddVCBatch(gomock.Any(), gomock.Any()).Return(errors.ErrBadRequest)
+
+		operation := New(cmd, &mockService{}, &mockService{}, nil)
+
+		_, code := sendRequestToHandler(t,
+			handlerLookup(t, operation, AddVCBatchPath),
+			bytes.NewBufferString(`[]`), AddVCBatchPath,
+		)
+
+		require.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+func TestOperation_AddVP(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCmd(ctrl)
+		cmd.EXPECT().AddVP(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+			payload, err := io.ReadAll(r)
+			require.NoError(t, err)
+
+			require.Equal(t, `{"alias":"maple2021","vp_entry":"e3ByZXNlbnRhdGlvbn0="}`, string(payload))
+		}).Return(nil)
+
+		operation := New(cmd, &mockService{}, &mockService{}, nil)
+
+		_, code := sendRequestToHandler(t,
+			handlerLookup(t, operation, AddVPPath),
+			bytes.NewBufferString(`{presentation}`), strings.Replace(AddVPPath, "{alias}", alias, 1),
+		)
+
+		require.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("Read body failed", func(t *testing.T) {
+		operation := New(nil, &mockService{}, &mockService{}, nil)
+
+		_, code := sendRequestToHandler(t,
+			handlerLookup(t, operation, AddVPPath),
+			&readerMock{errors.New("EOF")}, AddVPPath,
+		)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+	})
+
+	t.Run("Command error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCmd(ctrl)
+		cmd.EXPECT().AddVP(gomock.Any(), gomock.Any()).Return(errors.ErrBadRequest)
+
+		operation := New(cmd, &mockService{}, &mockService{}, nil)
+
+		_, code := sendRequestToHandler(t,
+			handlerLookup(t, operation, AddVPPath),
+			bytes.NewBufferString(`{presentation}`), AddVPPath,
+		)
+
+		require.Equal(t, http.StatusBadRequest, code)
+	})
 }
 
 func TestOperation_GetSTH(t *testing.T) {
@@ -108,6 +308,45 @@ func TestOperation_GetSTH(t *testing.T) {
 
 		require.Equal(t, http.StatusOK, code)
 	})
+
+	t.Run("If-None-Match", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCmd(ctrl)
+		cmd.EXPECT().GetSTH(gomock.Any(), gomock.Any()).DoAndReturn(func(w io.Writer, _ io.Reader) error {
+			return json.NewEncoder(w).Encode(command.GetSTHResponse{TreeSize: 3})
+		}).Times(2)
+
+		operation := New(cmd, &mockService{}, &mockService{}, nil)
+
+		router := mux.NewRouter()
+		handler := handlerLookup(t, operation, GetSTHPath)
+		router.HandleFunc(handler.Path(), handler.Handle()).Methods(handler.Method())
+
+		send := func(treeSize uint64) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, strings.Replace(GetSTHPath, "{alias}", alias, 1), nil)
+			req.Header.Set("If-None-Match", TreeSizeETag(treeSize))
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			return rr
+		}
+
+		rr := send(3)
+		require.Equal(t, http.StatusNotModified, rr.Code)
+		require.Empty(t, rr.Body.Bytes())
+		require.Equal(t, `"3"`, rr.Header().Get("ETag"))
+
+		rr = send(2)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, `"3"`, rr.Header().Get("ETag"))
+
+		var sth command.GetSTHResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &sth))
+		require.Equal(t, uint64(3), sth.TreeSize)
+	})
 }
 
 func TestOperation_Metrics(t *testing.T) {
@@ -146,21 +385,111 @@ func TestOperation_GetIssuers(t *testing.T) {
 	})
 }
 
+func TestOperation_GetRoots(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCmd(ctrl)
+		cmd.EXPECT().GetRoots(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+			payload, err := io.ReadAll(r)
+			require.NoError(t, err)
+
+			require.Equal(t, fmt.Sprintf("%q", alias), string(payload))
+		}).Return(nil)
+
+		operation := New(cmd, &mockService{}, &mockService{}, nil)
+
+		_, code := sendRequestToHandler(t, handlerLookup(t, operation, GetRootsPath), nil,
+			strings.Replace(GetRootsPath, "{alias}", alias, 1),
+		)
+
+		require.Equal(t, http.StatusOK, code)
+	})
+}
+
+func TestOperation_GetAcceptedContexts(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCmd(ctrl)
+		cmd.EXPECT().GetAcceptedContexts(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+			payload, err := io.ReadAll(r)
+			require.NoError(t, err)
+
+			require.Equal(t, fmt.Sprintf("%q", alias), string(payload))
+		}).Return(nil)
+
+		operation := New(cmd, &mockService{}, &mockService{}, nil)
+
+		_, code := sendRequestToHandler(t, handlerLookup(t, operation, GetAcceptedContextsPath), nil,
+			strings.Replace(GetAcceptedContextsPath, "{alias}", alias, 1),
+		)
+
+		require.Equal(t, http.StatusOK, code)
+	})
+}
+
+func TestOperation_GetLogInfo(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCmd(ctrl)
+		cmd.EXPECT().GetLogInfo(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+			payload, err := io.ReadAll(r)
+			require.NoError(t, err)
+
+			require.Equal(t, fmt.Sprintf("%q", alias), string(payload))
+		}).Return(nil)
+
+		operation := New(cmd, &mockService{}, &mockService{}, nil)
+
+		_, code := sendRequestToHandler(t, handlerLookup(t, operation, GetLogInfoPath), nil,
+			strings.Replace(GetLogInfoPath, "{alias}", alias, 1),
+		)
+
+		require.Equal(t, http.StatusOK, code)
+	})
+}
+
 func TestOperation_HealthCheck(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		operation := New(nil, &mockService{}, &mockService{}, nil)
 
-		_, code := sendRequestToHandler(t, handlerLookup(t, operation, HealthCheckPath), nil, HealthCheckPath)
+		body, code := sendRequestToHandler(t, handlerLookup(t, operation, HealthCheckPath), nil, HealthCheckPath)
 
 		require.Equal(t, http.StatusOK, code)
+
+		var resp command.HealthResponse
+		require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+		require.Equal(t, command.HealthStatusSuccess, resp.Status)
+		require.InDelta(t, time.Now().UnixMilli(), resp.CurrentTime, float64(time.Minute.Milliseconds()))
+		require.Equal(t, resp.CurrentTime, resp.Time.UnixMilli())
+		require.Empty(t, resp.TrillianStatus)
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(body.Bytes(), &fields))
+
+		currentTime, ok := fields["currentTime"].(string)
+		require.True(t, ok)
+
+		_, err := time.Parse(time.RFC3339, currentTime)
+		require.NoError(t, err)
 	})
 
 	t.Run("failed to db check", func(t *testing.T) {
 		operation := New(nil, &mockService{pingErr: fmt.Errorf("failed to ping")}, &mockService{}, nil)
 
-		_, code := sendRequestToHandler(t, handlerLookup(t, operation, HealthCheckPath), nil, HealthCheckPath)
+		body, code := sendRequestToHandler(t, handlerLookup(t, operation, HealthCheckPath), nil, HealthCheckPath)
 
 		require.Equal(t, http.StatusServiceUnavailable, code)
+
+		var resp command.HealthResponse
+		require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+		require.Equal(t, command.HealthStatusUnavailable, resp.Status)
+		require.Equal(t, "failed to ping", resp.DBStatus)
 	})
 
 	t.Run("failed to key manager check", func(t *testing.T) {
@@ -172,6 +501,56 @@ func TestOperation_HealthCheck(t *testing.T) {
 	})
 }
 
+func TestOperation_LogHealthCheck(t *testing.T) {
+	path := strings.Replace(LogHealthCheckPath, "{alias}", alias, 1)
+
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCmd(ctrl)
+		cmd.EXPECT().GetSTH(gomock.Any(), gomock.Any()).DoAndReturn(func(w io.Writer, r io.Reader) error {
+			payload, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf("%q", alias), string(payload))
+
+			return json.NewEncoder(w).Encode(command.GetSTHResponse{TreeSize: 3})
+		})
+
+		operation := New(cmd, &mockService{}, &mockService{}, nil)
+
+		body, code := sendRequestToHandler(t, handlerLookup(t, operation, LogHealthCheckPath), nil, path)
+
+		require.Equal(t, http.StatusOK, code)
+
+		var resp command.HealthResponse
+		require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+		require.Equal(t, command.HealthStatusSuccess, resp.Status)
+		require.Equal(t, "success", resp.TrillianStatus)
+		require.Equal(t, uint64(3), resp.TreeSize)
+	})
+
+	t.Run("Trillian unavailable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCmd(ctrl)
+		cmd.EXPECT().GetSTH(gomock.Any(), gomock.Any()).Return(fmt.Errorf("get latest signed log root: unavailable"))
+
+		operation := New(cmd, &mockService{}, &mockService{}, nil)
+
+		body, code := sendRequestToHandler(t, handlerLookup(t, operation, LogHealthCheckPath), nil, path)
+
+		require.Equal(t, http.StatusServiceUnavailable, code)
+
+		var resp command.HealthResponse
+		require.NoError(t, json.Unmarshal(body.Bytes(), &resp))
+		require.Equal(t, command.HealthStatusUnavailable, resp.Status)
+		require.Equal(t, "get latest signed log root: unavailable", resp.TrillianStatus)
+		require.Zero(t, resp.TreeSize)
+	})
+}
+
 func TestOperation_Webfinger(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		const resourceID = "https://vct.example.com/" + alias
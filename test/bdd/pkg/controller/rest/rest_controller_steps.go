@@ -9,7 +9,6 @@ package rest
 import (
 	"context"
 	"embed"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -231,9 +230,9 @@ func (s *Steps) addVC(file string) error {
 		return fmt.Errorf("get public key: %w", err)
 	}
 
-	pubKey, err := base64.StdEncoding.DecodeString(webResp.Properties[command.PublicKeyType].(string))
+	pubKey, err := webResp.PublicKey()
 	if err != nil {
-		return fmt.Errorf("decode public key: %w", err)
+		return fmt.Errorf("get public key: %w", err)
 	}
 
 	err = vct.VerifyVCTimestampSignature(resp.Signature, pubKey, resp.Timestamp, src, getLoader())